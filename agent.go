@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"maps"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -34,9 +35,11 @@ type Agent struct {
 	metrics    metrics.Metrics
 
 	// Behavior
-	systemPrompt string
-	tools        []Tool
-	guardrails   *GuardrailManager
+	systemPrompt  string
+	tools         []Tool
+	toolRegistry  *ToolRegistry
+	guardrails    *GuardrailManager
+	toolAuditHook ToolAuditHook
 
 	// Execution
 	maxIterations int
@@ -101,6 +104,13 @@ type AgentOptions struct {
 	Temperature   float64
 	Guardrails    *GuardrailManager
 	Callbacks     AgentCallbacks
+
+	// ToolAuditHook receives authorization decisions for tools that require scopes
+	ToolAuditHook ToolAuditHook
+
+	// ToolRegistry adds the tools of a registry to Tools. The registry is
+	// read on every call, so tools registered later are picked up.
+	ToolRegistry *ToolRegistry
 }
 
 // Tool represents a tool/function the agent can use.
@@ -109,6 +119,28 @@ type Tool struct {
 	Description string                                             `json:"description"`
 	Parameters  map[string]any                                     `json:"parameters"`
 	Handler     func(context.Context, map[string]any) (any, error) `json:"-"`
+
+	// RequiredScopes lists the scopes the principal in the run context must
+	// hold to call the tool. See WithPrincipal.
+	RequiredScopes []string `json:"required_scopes,omitempty"`
+
+	// schema caches the compiled Parameters. selfValidating marks tools whose
+	// Handler validates its own arguments, such as registry and typed tools.
+	schema         *JSONSchema
+	schemaErr      error
+	selfValidating bool
+}
+
+// compiled returns the tool with its parameter schema compiled, so calls do
+// not recompile it.
+func (t Tool) compiled() Tool {
+	if t.selfValidating || t.schema != nil || t.schemaErr != nil || len(t.Parameters) == 0 {
+		return t
+	}
+
+	t.schema, t.schemaErr = CompileJSONSchema(t.Parameters)
+
+	return t
 }
 
 // AgentResponse represents the result of an agent execution.
@@ -127,11 +159,15 @@ type AgentResponse struct {
 // ToolRun represents a tool that was executed.
 // Renamed from ToolExecution for clarity.
 type ToolRun struct {
+	ID        string
 	Name      string
 	Arguments map[string]any
 	Result    any
 	Error     error
 	Duration  time.Duration
+
+	// Cached is set when the handler's registry call was served from the tool cache
+	Cached bool
 }
 
 // ToolExecution is an alias for ToolRun for backward compatibility.
@@ -191,7 +227,7 @@ func NewAgent(
 		}
 
 		if len(opts.Tools) > 0 {
-			agent.tools = opts.Tools
+			agent.AddTools(opts.Tools...)
 		}
 
 		if opts.MaxIterations > 0 {
@@ -204,6 +240,8 @@ func NewAgent(
 
 		agent.guardrails = opts.Guardrails
 		agent.callbacks = opts.Callbacks
+		agent.toolAuditHook = opts.ToolAuditHook
+		agent.toolRegistry = opts.ToolRegistry
 	}
 
 	return agent, nil
@@ -310,14 +348,20 @@ func (a *Agent) Execute(ctx context.Context, input string) (*AgentResponse, erro
 			for _, execution := range executions {
 				response.ToolCalls = append(response.ToolCalls, execution)
 
+				content := fmt.Sprintf("Tool: %s, Result: %v", execution.Name, execution.Result)
+				if execution.Error != nil {
+					content = fmt.Sprintf("Tool: %s, Error: %s", execution.Name, toolErrorContent(execution.Error))
+				}
+
 				// Add tool result to history
 				toolMsg := AgentMessage{
 					Role:      "tool",
-					Content:   fmt.Sprintf("Tool: %s, Result: %v", execution.Name, execution.Result),
+					Content:   content,
 					Timestamp: time.Now(),
 					Metadata: map[string]any{
-						"tool_name": execution.Name,
-						"duration":  execution.Duration.Milliseconds(),
+						"tool_name":    execution.Name,
+						"tool_call_id": execution.ID,
+						"duration":     execution.Duration.Milliseconds(),
 					},
 				}
 				if err := a.addToHistory(toolMsg); err != nil {
@@ -388,6 +432,11 @@ func (a *Agent) generateResponse(ctx context.Context) (*Result, error) {
 
 	prompt += promptSb322.String()
 
+	// Stream tokens to the caller's sink when one is attached to the context
+	if sink := StreamEventSinkFromContext(ctx); sink != nil && a.llmManager.SupportsStreaming(a.Provider) {
+		return a.streamResponse(ctx, prompt, sink)
+	}
+
 	// Create generate builder
 	builder := NewGenerateBuilder(ctx, a.llmManager, a.logger, a.metrics).
 		WithProvider(a.Provider).
@@ -401,20 +450,7 @@ func (a *Agent) generateResponse(ctx context.Context) (*Result, error) {
 	}
 
 	// Add tools if available
-	if len(a.tools) > 0 {
-		// Convert SDK tools to LLM tools format
-		llmTools := make([]llm.Tool, 0, len(a.tools))
-		for _, tool := range a.tools {
-			llmTools = append(llmTools, llm.Tool{
-				Type: "function",
-				Function: &llm.FunctionDefinition{
-					Name:        tool.Name,
-					Description: tool.Description,
-					Parameters:  tool.Parameters,
-				},
-			})
-		}
-
+	if llmTools := a.llmTools(); len(llmTools) > 0 {
 		builder.WithTools(llmTools...)
 		builder.WithToolChoice("auto")
 	}
@@ -422,11 +458,91 @@ func (a *Agent) generateResponse(ctx context.Context) (*Result, error) {
 	return builder.Execute()
 }
 
+// llmTools converts the agent's available tools to the LLM tool format.
+func (a *Agent) llmTools() []llm.Tool {
+	tools := a.availableTools()
+
+	llmTools := make([]llm.Tool, 0, len(tools))
+	for _, tool := range tools {
+		llmTools = append(llmTools, llm.Tool{
+			Type: "function",
+			Function: &llm.FunctionDefinition{
+				Name:        tool.Name,
+				Description: tool.Description,
+				Parameters:  tool.Parameters,
+			},
+		})
+	}
+
+	return llmTools
+}
+
+// streamResponse generates a response through the streaming generator,
+// forwarding its typed events to sink.
+func (a *Agent) streamResponse(ctx context.Context, prompt string, sink func(llm.ClientStreamEvent)) (*Result, error) {
+	generator := NewStreamingGenerator(ctx, a.llmManager, a.logger, a.metrics).
+		WithProvider(a.Provider).
+		WithModel(a.Model).
+		WithPrompt(prompt).
+		WithTemperature(a.temperature).
+		OnStreamEvent(sink)
+
+	if a.systemPrompt != "" {
+		generator.WithSystemPrompt(a.systemPrompt)
+	}
+
+	if llmTools := a.llmTools(); len(llmTools) > 0 {
+		generator.WithTools(llmTools...)
+		generator.WithToolChoice("auto")
+	}
+
+	response, err := generator.Stream()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{
+		Content:      response.Content,
+		Metadata:     response.Metadata,
+		Usage:        response.Usage,
+		FinishReason: "stop",
+		ToolCalls:    make([]ToolCallResult, 0, len(response.ToolCalls)),
+	}
+
+	// Streamed tool arguments arrive as raw JSON, possibly split across
+	// several deltas of which only the first carries the call ID
+	raw := make([]string, 0, len(response.ToolCalls))
+
+	for _, tc := range response.ToolCalls {
+		fragment, _ := tc.Arguments["raw"].(string)
+
+		if tc.ID == "" && tc.Name == "" && len(result.ToolCalls) > 0 {
+			raw[len(raw)-1] += fragment
+
+			continue
+		}
+
+		result.ToolCalls = append(result.ToolCalls, ToolCallResult{ID: tc.ID, Name: tc.Name})
+		raw = append(raw, fragment)
+	}
+
+	for i := range result.ToolCalls {
+		result.ToolCalls[i].Arguments = parseToolCallArguments(raw[i])
+	}
+
+	if len(result.ToolCalls) > 0 {
+		result.FinishReason = "tool_calls"
+	}
+
+	return result, nil
+}
+
 // executeTool executes a tool and returns the result.
 func (a *Agent) executeTool(ctx context.Context, toolCall ToolCallResult) ToolExecution {
 	startTime := time.Now()
 
 	execution := ToolExecution{
+		ID:        toolCall.ID,
 		Name:      toolCall.Name,
 		Arguments: toolCall.Arguments,
 	}
@@ -434,9 +550,10 @@ func (a *Agent) executeTool(ctx context.Context, toolCall ToolCallResult) ToolEx
 	// Find the tool
 	var tool *Tool
 
-	for i := range a.tools {
-		if a.tools[i].Name == toolCall.Name {
-			tool = &a.tools[i]
+	tools := a.availableTools()
+	for i := range tools {
+		if tools[i].Name == toolCall.Name {
+			tool = &tools[i]
 
 			break
 		}
@@ -449,11 +566,40 @@ func (a *Agent) executeTool(ctx context.Context, toolCall ToolCallResult) ToolEx
 		return execution
 	}
 
-	// Execute tool handler
-	if tool.Handler != nil {
+	sink := StreamEventSinkFromContext(ctx)
+	toolID := toolCall.ID
+	if toolID == "" {
+		toolID = fmt.Sprintf("%s_%d", toolCall.Name, startTime.UnixNano())
+	}
+
+	if sink != nil {
+		sink(llm.NewToolResultStartEvent(a.GetSessionID(), toolID, toolCall.Name))
+	}
+
+	ctx, cacheHit := withToolCacheRecorder(ctx)
+
+	// Check permissions, then arguments, so the model gets feedback it can act on
+	if err := AuthorizeTool(ctx, ToolOriginAgent, tool.Name, tool.RequiredScopes, a.toolAuditHook); err != nil {
+		execution.Error = err
+	} else if err := validateAgentToolArguments(tool, toolCall.Arguments); err != nil {
+		execution.Error = err
+	} else if tool.Handler != nil {
 		result, err := tool.Handler(ctx, toolCall.Arguments)
 		execution.Result = result
 		execution.Error = err
+		execution.Cached = cacheHit.Load()
+	}
+
+	if sink != nil {
+		delta := fmt.Sprintf("%v", execution.Result)
+		if execution.Error != nil {
+			delta = "Error: " + toolErrorContent(execution.Error)
+		} else if encoded, err := json.Marshal(execution.Result); err == nil {
+			delta = string(encoded)
+		}
+
+		sink(llm.NewToolResultDeltaEvent(a.GetSessionID(), toolID, delta, 0))
+		sink(llm.NewToolResultEndEvent(a.GetSessionID(), toolID))
 	}
 
 	execution.Duration = time.Since(startTime)
@@ -470,6 +616,58 @@ func (a *Agent) executeTool(ctx context.Context, toolCall ToolCallResult) ToolEx
 	return execution
 }
 
+// validateAgentToolArguments checks arguments against the tool's parameter
+// schema. Tools without parameters or that validate in their handler are left
+// to validate their own input; a schema the validator cannot compile rejects
+// the call.
+func validateAgentToolArguments(tool *Tool, args map[string]any) error {
+	if tool.selfValidating || len(tool.Parameters) == 0 {
+		return nil
+	}
+
+	compiled := tool.compiled()
+	if compiled.schemaErr != nil {
+		return fmt.Errorf("tool %s has an invalid parameter schema: %w", tool.Name, compiled.schemaErr)
+	}
+
+	return validateToolArguments(tool.Name, compiled.schema, args)
+}
+
+// toolErrorContent formats a tool error for the model. Argument and
+// permission errors are rendered as JSON so the model can correct its call or
+// explain why it cannot proceed.
+func toolErrorContent(err error) string {
+	var (
+		argErr  *ToolArgumentError
+		permErr *ToolPermissionError
+		content map[string]any
+	)
+
+	switch {
+	case errors.As(err, &argErr):
+		content = map[string]any{
+			"error":      "invalid_arguments",
+			"tool":       argErr.Tool,
+			"violations": argErr.Fields,
+		}
+	case errors.As(err, &permErr):
+		content = map[string]any{
+			"error":          "permission_denied",
+			"tool":           permErr.Tool,
+			"missing_scopes": permErr.Missing,
+		}
+	default:
+		return err.Error()
+	}
+
+	data, marshalErr := json.Marshal(content)
+	if marshalErr != nil {
+		return err.Error()
+	}
+
+	return string(data)
+}
+
 // executeToolsParallel executes multiple tools concurrently.
 // This significantly improves performance when an LLM requests multiple independent tool calls.
 func (a *Agent) executeToolsParallel(ctx context.Context, toolCalls []ToolCallResult) []ToolExecution {
@@ -743,9 +941,9 @@ func (a *Agent) getToolDescription() string {
 	}
 
 	// Add information about the agent's capabilities
-	if len(a.tools) > 0 {
-		toolNames := make([]string, len(a.tools))
-		for i, tool := range a.tools {
+	if tools := a.availableTools(); len(tools) > 0 {
+		toolNames := make([]string, len(tools))
+		for i, tool := range tools {
 			toolNames[i] = tool.Name
 		}
 
@@ -798,19 +996,50 @@ func (a *Agent) AsLLMTool() llm.Tool {
 	}
 }
 
-// GetTools returns the agent's available tools.
+// GetTools returns the agent's available tools, including those of its tool
+// registry.
 func (a *Agent) GetTools() []Tool {
-	return a.tools
+	return a.availableTools()
 }
 
 // AddTool adds a tool to the agent.
 func (a *Agent) AddTool(tool Tool) {
-	a.tools = append(a.tools, tool)
+	a.tools = append(a.tools, tool.compiled())
 }
 
 // AddTools adds multiple tools to the agent.
 func (a *Agent) AddTools(tools ...Tool) {
-	a.tools = append(a.tools, tools...)
+	for _, tool := range tools {
+		a.AddTool(tool)
+	}
+}
+
+// SetToolRegistry makes the tools of registry available to the agent.
+func (a *Agent) SetToolRegistry(registry *ToolRegistry) {
+	a.toolRegistry = registry
+}
+
+// availableTools returns the agent's tools followed by those of its tool
+// registry. Agent tools take precedence over registry tools of the same name.
+func (a *Agent) availableTools() []Tool {
+	if a.toolRegistry == nil {
+		return a.tools
+	}
+
+	names := make(map[string]bool, len(a.tools))
+	for _, tool := range a.tools {
+		names[tool.Name] = true
+	}
+
+	tools := slices.Clone(a.tools)
+
+	for _, tool := range a.toolRegistry.AgentTools() {
+		if !names[tool.Name] {
+			tools = append(tools, tool)
+		}
+	}
+
+	return tools
 }
 
 // SetSystemPrompt updates the agent's system prompt.
@@ -1059,9 +1288,9 @@ func (a *Agent) executeStep(ctx context.Context, step *AgentStep) (*AgentStep, e
 	}
 
 	// Add tools if any
-	if len(a.tools) > 0 {
-		llmTools := make([]llm.Tool, len(a.tools))
-		for i, tool := range a.tools {
+	if tools := a.availableTools(); len(tools) > 0 {
+		llmTools := make([]llm.Tool, len(tools))
+		for i, tool := range tools {
 			llmTools[i] = llm.Tool{
 				Type: "function",
 				Function: &llm.FunctionDefinition{
@@ -1124,16 +1353,18 @@ func (a *Agent) executeStep(ctx context.Context, step *AgentStep) (*AgentStep, e
 			step.ToolCalls = append(step.ToolCalls, toolCall)
 
 			// Execute tool
-			result, toolErr := a.executeToolByName(ctx, tc.Function.Name, toolCall.Arguments)
+			toolCtx, cacheHit := withToolCacheRecorder(ctx)
+			result, toolErr := a.executeToolByName(toolCtx, tc.Function.Name, toolCall.Arguments)
 
 			toolResult := StepToolResult{
 				ToolCallID: tc.ID,
 				Name:       tc.Function.Name,
 				Result:     result,
 				Duration:   time.Since(toolCall.StartTime),
+				Cached:     cacheHit.Load(),
 			}
 			if toolErr != nil {
-				toolResult.Error = toolErr.Error()
+				toolResult.Error = toolErrorContent(toolErr)
 			}
 
 			step.ToolResults = append(step.ToolResults, toolResult)
@@ -1203,8 +1434,16 @@ func (a *Agent) buildStepMessages(step *AgentStep) []llm.ChatMessage {
 
 // executeToolByName executes a tool by name (used by step-based execution).
 func (a *Agent) executeToolByName(ctx context.Context, name string, args map[string]any) (any, error) {
-	for _, tool := range a.tools {
+	for _, tool := range a.availableTools() {
 		if tool.Name == name {
+			if err := AuthorizeTool(ctx, ToolOriginAgent, tool.Name, tool.RequiredScopes, a.toolAuditHook); err != nil {
+				return nil, err
+			}
+
+			if err := validateAgentToolArguments(&tool, args); err != nil {
+				return nil, err
+			}
+
 			if tool.Handler != nil {
 				return tool.Handler(ctx, args)
 			}
@@ -54,6 +54,9 @@ type StepToolResult struct {
 	Result     any           `json:"result"`
 	Error      string        `json:"error,omitempty"`
 	Duration   time.Duration `json:"duration"`
+
+	// Cached is set when the result was served from the tool cache
+	Cached bool `json:"cached,omitempty"`
 }
 
 // StateModification represents a change to agent state.
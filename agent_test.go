@@ -111,6 +111,39 @@ func TestNewAgent_WithOptions(t *testing.T) {
 	}
 }
 
+func TestAgent_ToolRegistry(t *testing.T) {
+	registry := NewToolRegistry(nil, nil)
+
+	agent, _ := NewAgent("agent-1", "Test", &testhelpers.MockLLMManager{}, &MockStateStore{}, nil, nil, &AgentOptions{
+		Tools:        []Tool{{Name: "lookup", Description: "agent tool"}},
+		ToolRegistry: registry,
+	})
+
+	_ = registry.RegisterTool(&ToolDefinition{Name: "lookup", Handler: func(ctx context.Context, params map[string]any) (any, error) { return "registry", nil }})
+	_ = registry.RegisterTool(&ToolDefinition{
+		Name:       "weather",
+		Parameters: ToolParameterSchema{Type: "object", Properties: map[string]ToolParameterProperty{"city": {Type: "string"}}, Required: []string{"city"}},
+		Handler: func(ctx context.Context, params map[string]any) (any, error) {
+			return "sunny in " + params["city"].(string), nil
+		},
+	})
+
+	tools := agent.GetTools()
+	if len(tools) != 2 || tools[0].Description != "agent tool" || tools[1].Name != "weather" {
+		t.Fatalf("expected registry tools registered after creation, got %+v", tools)
+	}
+
+	execution := agent.executeTool(context.Background(), ToolCallResult{Name: "weather", Arguments: map[string]any{"city": "Oslo"}})
+	if execution.Error != nil || execution.Result != "sunny in Oslo" {
+		t.Errorf("unexpected execution %+v", execution)
+	}
+
+	execution = agent.executeTool(context.Background(), ToolCallResult{Name: "weather", Arguments: map[string]any{}})
+	if !errors.Is(execution.Error, ErrInvalidToolArguments) {
+		t.Errorf("expected registry validation, got %v", execution.Error)
+	}
+}
+
 func TestAgent_SetAndGetStateData(t *testing.T) {
 	agent, _ := NewAgent("agent-1", "Test", &testhelpers.MockLLMManager{}, &MockStateStore{}, nil, nil, nil)
 
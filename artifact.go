@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
 	"sync"
 	"time"
 
@@ -89,6 +90,14 @@ type ArtifactRegistry struct {
 	onArtifactCreated func(*Artifact)
 	onArtifactUpdated func(*Artifact)
 	onArtifactDeleted func(string)
+	listeners         []ArtifactListener
+}
+
+// ArtifactListener receives artifact changes. Nil fields are skipped.
+type ArtifactListener struct {
+	OnCreated func(*Artifact)
+	OnUpdated func(*Artifact)
+	OnDeleted func(id string)
 }
 
 // ArtifactStore provides persistence for artifacts.
@@ -145,6 +154,27 @@ func (r *ArtifactRegistry) OnArtifactDeleted(fn func(string)) *ArtifactRegistry
 	return r
 }
 
+// AddListener registers a listener that runs after the callbacks set with
+// OnArtifactCreated, OnArtifactUpdated and OnArtifactDeleted.
+func (r *ArtifactRegistry) AddListener(listener ArtifactListener) *ArtifactRegistry {
+	r.mu.Lock()
+	r.listeners = append(r.listeners, listener)
+	r.mu.Unlock()
+
+	return r
+}
+
+// notifyListeners calls fn for every registered listener.
+func (r *ArtifactRegistry) notifyListeners(fn func(ArtifactListener)) {
+	r.mu.RLock()
+	listeners := slices.Clone(r.listeners)
+	r.mu.RUnlock()
+
+	for _, listener := range listeners {
+		fn(listener)
+	}
+}
+
 // Create creates a new artifact.
 func (r *ArtifactRegistry) Create(artifact *Artifact) error {
 	if artifact.ID == "" {
@@ -182,6 +212,12 @@ func (r *ArtifactRegistry) Create(artifact *Artifact) error {
 		r.onArtifactCreated(artifact)
 	}
 
+	r.notifyListeners(func(l ArtifactListener) {
+		if l.OnCreated != nil {
+			l.OnCreated(artifact)
+		}
+	})
+
 	if r.logger != nil {
 		r.logger.Debug("Artifact created",
 			F("id", artifact.ID),
@@ -366,6 +402,12 @@ func (r *ArtifactRegistry) Update(id string, content string) error {
 		r.onArtifactUpdated(artifact)
 	}
 
+	r.notifyListeners(func(l ArtifactListener) {
+		if l.OnUpdated != nil {
+			l.OnUpdated(artifact)
+		}
+	})
+
 	if r.metrics != nil {
 		r.metrics.Counter("forge.ai.sdk.artifacts.updated",
 			metrics.WithLabel("name", artifact.Name),
@@ -397,6 +439,12 @@ func (r *ArtifactRegistry) Delete(id string) error {
 		r.onArtifactDeleted(id)
 	}
 
+	r.notifyListeners(func(l ArtifactListener) {
+		if l.OnDeleted != nil {
+			l.OnDeleted(id)
+		}
+	})
+
 	if r.metrics != nil {
 		r.metrics.Counter("forge.ai.sdk.artifacts.deleted",
 			metrics.WithLabel("id", id),
@@ -315,7 +315,7 @@ func (b *AgentBuilder) Build() (*Agent, error) {
 	if b.handoffManager != nil && len(b.subAgents) > 0 {
 		handoffTool := b.handoffManager.CreateHandoffTool(agent.ID)
 		routingTool := b.handoffManager.CreateToolRoutingTool(agent.ID)
-		agent.tools = append(agent.tools, handoffTool, routingTool)
+		agent.AddTools(handoffTool, routingTool)
 	}
 
 	return agent, nil
@@ -343,13 +343,15 @@ type WorkflowBuilder struct {
 	version     string
 	nodes       []*WorkflowNode
 	edges       [][2]string // pairs of [from, to]
+	errorEdges  [][2]string // pairs of [from, handler]
 	startNodes  []string
 
 	// Dependencies
-	toolRegistry  *ToolRegistry
-	agentRegistry *AgentRegistry
-	logger        logger.Logger
-	metrics       metrics.Metrics
+	toolRegistry     *ToolRegistry
+	agentRegistry    *AgentRegistry
+	workflowRegistry *WorkflowRegistry
+	logger           logger.Logger
+	metrics          metrics.Metrics
 }
 
 // NewWorkflowBuilder creates a new workflow builder.
@@ -358,6 +360,7 @@ func NewWorkflowBuilder() *WorkflowBuilder {
 		version:    "1.0.0",
 		nodes:      make([]*WorkflowNode, 0),
 		edges:      make([][2]string, 0),
+		errorEdges: make([][2]string, 0),
 		startNodes: make([]string, 0),
 	}
 }
@@ -404,6 +407,13 @@ func (b *WorkflowBuilder) WithAgentRegistry(ar *AgentRegistry) *WorkflowBuilder
 	return b
 }
 
+// WithWorkflowRegistry sets the workflow registry used by sub-workflow nodes.
+func (b *WorkflowBuilder) WithWorkflowRegistry(wr *WorkflowRegistry) *WorkflowBuilder {
+	b.workflowRegistry = wr
+
+	return b
+}
+
 // WithLogger sets the logger.
 func (b *WorkflowBuilder) WithLogger(logger logger.Logger) *WorkflowBuilder {
 	b.logger = logger
@@ -490,6 +500,45 @@ func (b *WorkflowBuilder) AddWaitNode(id, name string, duration time.Duration) *
 	return b.AddNode(node)
 }
 
+// AddSubWorkflowNode adds a node that executes another registered workflow.
+func (b *WorkflowBuilder) AddSubWorkflowNode(id, name, workflowID string, inputMapping, outputMapping map[string]string) *WorkflowBuilder {
+	node := &WorkflowNode{
+		ID:            id,
+		Type:          NodeTypeSubWorkflow,
+		Name:          name,
+		WorkflowID:    workflowID,
+		InputMapping:  inputMapping,
+		OutputMapping: outputMapping,
+		Timeout:       10 * time.Minute,
+	}
+
+	return b.AddNode(node)
+}
+
+// AddApprovalNode adds a node that waits for a human approval via Workflow.Signal.
+func (b *WorkflowBuilder) AddApprovalNode(id, name string, timeout time.Duration) *WorkflowBuilder {
+	node := &WorkflowNode{
+		ID:      id,
+		Type:    NodeTypeApproval,
+		Name:    name,
+		Timeout: timeout,
+	}
+
+	return b.AddNode(node)
+}
+
+// AddSignalNode adds a node that waits for an external event via Workflow.Signal.
+func (b *WorkflowBuilder) AddSignalNode(id, name string, timeout time.Duration) *WorkflowBuilder {
+	node := &WorkflowNode{
+		ID:      id,
+		Type:    NodeTypeSignal,
+		Name:    name,
+		Timeout: timeout,
+	}
+
+	return b.AddNode(node)
+}
+
 // AddEdge adds an edge between two nodes.
 func (b *WorkflowBuilder) AddEdge(from, to string) *WorkflowBuilder {
 	b.edges = append(b.edges, [2]string{from, to})
@@ -497,6 +546,13 @@ func (b *WorkflowBuilder) AddEdge(from, to string) *WorkflowBuilder {
 	return b
 }
 
+// AddErrorEdge routes failures of a node to a handler node.
+func (b *WorkflowBuilder) AddErrorEdge(from, handler string) *WorkflowBuilder {
+	b.errorEdges = append(b.errorEdges, [2]string{from, handler})
+
+	return b
+}
+
 // AddSequence adds a sequence of node IDs (creates edges between consecutive nodes).
 func (b *WorkflowBuilder) AddSequence(nodeIDs ...string) *WorkflowBuilder {
 	for i := range len(nodeIDs) - 1 {
@@ -565,6 +621,13 @@ func (b *WorkflowBuilder) Build() (*Workflow, error) {
 		}
 	}
 
+	// Add all error edges
+	for _, edge := range b.errorEdges {
+		if err := workflow.AddErrorEdge(edge[0], edge[1]); err != nil {
+			return nil, fmt.Errorf("failed to add error edge %s -> %s: %w", edge[0], edge[1], err)
+		}
+	}
+
 	// Set start nodes
 	for _, nodeID := range b.startNodes {
 		if err := workflow.SetStartNode(nodeID); err != nil {
@@ -583,9 +646,10 @@ func (b *WorkflowBuilder) BuildWithRegistries() (*WorkflowWithRegistries, error)
 	}
 
 	return &WorkflowWithRegistries{
-		Workflow:      workflow,
-		ToolRegistry:  b.toolRegistry,
-		AgentRegistry: b.agentRegistry,
+		Workflow:         workflow,
+		ToolRegistry:     b.toolRegistry,
+		AgentRegistry:    b.agentRegistry,
+		WorkflowRegistry: b.workflowRegistry,
 	}, nil
 }
 
@@ -593,8 +657,9 @@ func (b *WorkflowBuilder) BuildWithRegistries() (*WorkflowWithRegistries, error)
 type WorkflowWithRegistries struct {
 	*Workflow
 
-	ToolRegistry  *ToolRegistry
-	AgentRegistry *AgentRegistry
+	ToolRegistry     *ToolRegistry
+	AgentRegistry    *AgentRegistry
+	WorkflowRegistry *WorkflowRegistry
 }
 
 // ExecuteWithContext executes the workflow using the connected registries.
@@ -606,5 +671,9 @@ func (w *WorkflowWithRegistries) ExecuteWithContext(ctx context.Context, input m
 	enrichedInput["__tool_registry"] = w.ToolRegistry
 	enrichedInput["__agent_registry"] = w.AgentRegistry
 
+	if w.WorkflowRegistry != nil {
+		enrichedInput["__workflow_registry"] = w.WorkflowRegistry
+	}
+
 	return w.Execute(ctx, enrichedInput)
 }
@@ -0,0 +1,571 @@
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	logger "github.com/xraph/go-utils/log"
+	"github.com/xraph/go-utils/metrics"
+)
+
+// SandboxLanguage describes how to run snippets of one language.
+type SandboxLanguage struct {
+	// Name is the canonical language name, e.g. "python"
+	Name string
+
+	// Aliases are other names accepted for the language, e.g. "py"
+	Aliases []string
+
+	// FileName is the file the snippet is written to in the working directory
+	FileName string
+
+	// Command runs the snippet; it is executed inside the working directory
+	Command []string
+
+	// Env holds extra environment variables for this language
+	Env map[string]string
+}
+
+// DefaultSandboxLanguages returns the languages supported out of the box:
+// Python, JavaScript (Node.js), POSIX shell, Bash and Go.
+func DefaultSandboxLanguages() []SandboxLanguage {
+	return []SandboxLanguage{
+		{Name: "python", Aliases: []string{"py", "python3"}, FileName: "main.py", Command: []string{"python3", "main.py"}},
+		{Name: "javascript", Aliases: []string{"js", "node"}, FileName: "main.js", Command: []string{"node", "main.js"}},
+		{Name: "shell", Aliases: []string{"sh"}, FileName: "main.sh", Command: []string{"sh", "main.sh"}},
+		{Name: "bash", FileName: "main.sh", Command: []string{"bash", "main.sh"}},
+		{
+			Name:     "go",
+			Aliases:  []string{"golang"},
+			FileName: "main.go",
+			Command:  []string{"go", "run", "main.go"},
+			// The build cache defaults to $HOME/.cache, which is the scratch
+			// directory, so one run cannot plant build results for the next.
+			// Compiling the standard library takes several seconds per run.
+			Env: map[string]string{
+				"GOTOOLCHAIN": "local",
+				"GO111MODULE": "off",
+				"GOPROXY":     "off",
+			},
+		},
+	}
+}
+
+// CodeSandboxConfig configures a CodeSandbox.
+type CodeSandboxConfig struct {
+	// Timeout bounds the wall-clock time of a run
+	Timeout time.Duration
+
+	// MaxCPUTime bounds the CPU time of a run (RLIMIT_CPU). Defaults to Timeout.
+	MaxCPUTime time.Duration
+
+	// MaxMemoryBytes bounds the address space of each process (RLIMIT_AS).
+	// Node.js needs around 1 GiB to start.
+	//
+	// Zero selects the default of each resource limit and a negative value
+	// removes the limit.
+	MaxMemoryBytes int64
+
+	// MaxFileSize bounds the size of files a run may write (RLIMIT_FSIZE)
+	MaxFileSize int64
+
+	// MaxOpenFiles bounds the number of open file descriptors (RLIMIT_NOFILE)
+	MaxOpenFiles int
+
+	// MaxProcesses bounds the number of processes (RLIMIT_NPROC). The limit
+	// counts every process of the user, not just those of the run, so it must
+	// leave room for what the user already runs.
+	MaxProcesses int
+
+	// MaxOutputBytes caps captured stdout and stderr, each
+	MaxOutputBytes int
+
+	// MaxFiles caps the number of generated files returned
+	MaxFiles int
+
+	// MaxFileContentBytes is the largest generated text file whose content
+	// is returned; larger or binary files are listed with their size only
+	MaxFileContentBytes int
+
+	// AllowNetwork lets snippets use the network. By default runs happen in
+	// an empty network namespace.
+	AllowNetwork bool
+
+	// WorkDir is the parent directory of scratch directories; defaults to os.TempDir()
+	WorkDir string
+
+	// KeepWorkDir keeps scratch directories after a run, for debugging
+	KeepWorkDir bool
+
+	// Env holds extra environment variables for every run
+	Env map[string]string
+
+	// Languages replaces the supported languages when set
+	Languages []SandboxLanguage
+}
+
+// DefaultCodeSandboxConfig returns the default sandbox configuration.
+func DefaultCodeSandboxConfig() CodeSandboxConfig {
+	return CodeSandboxConfig{
+		Timeout:             30 * time.Second,
+		MaxMemoryBytes:      1 << 30,
+		MaxFileSize:         64 << 20,
+		MaxOpenFiles:        256,
+		MaxProcesses:        1024,
+		MaxOutputBytes:      64 * 1024,
+		MaxFiles:            20,
+		MaxFileContentBytes: 64 * 1024,
+		Languages:           DefaultSandboxLanguages(),
+	}
+}
+
+// CodeExecutionRequest is a snippet to run.
+type CodeExecutionRequest struct {
+	Language string `json:"language"`
+	Code     string `json:"code"`
+	Stdin    string `json:"stdin,omitempty"`
+
+	// Files are written to the working directory before the run, keyed by relative path
+	Files map[string]string `json:"files,omitempty"`
+}
+
+// CodeExecutionResult is the outcome of a run. A non-zero exit code or a
+// timeout is reported here rather than as an error.
+type CodeExecutionResult struct {
+	Language        string        `json:"language"`
+	Stdout          string        `json:"stdout"`
+	Stderr          string        `json:"stderr"`
+	ExitCode        int           `json:"exit_code"`
+	TimedOut        bool          `json:"timed_out,omitempty"`
+	StdoutTruncated bool          `json:"stdout_truncated,omitempty"`
+	StderrTruncated bool          `json:"stderr_truncated,omitempty"`
+	Files           []SandboxFile `json:"files,omitempty"`
+	Duration        time.Duration `json:"duration"`
+}
+
+// SandboxFile is a file a run created or modified in its working directory.
+type SandboxFile struct {
+	Path    string `json:"path"`
+	Size    int64  `json:"size"`
+	Content string `json:"content,omitempty"`
+
+	// Omitted is true when the content is not returned because the file is
+	// binary or larger than MaxFileContentBytes
+	Omitted bool `json:"omitted,omitempty"`
+}
+
+// CodeSandbox runs code snippets in subprocesses on Linux, each in its own
+// scratch directory with resource limits and, by default, no network access.
+//
+// The sandbox limits resources and isolates the network, but processes still
+// see the host filesystem with the permissions of the calling user. Run it as
+// an unprivileged user, or inside a container, when the code is untrusted.
+type CodeSandbox struct {
+	logger  logger.Logger
+	metrics metrics.Metrics
+	config  CodeSandboxConfig
+
+	languages map[string]*SandboxLanguage
+}
+
+// NewCodeSandbox creates a new code sandbox.
+func NewCodeSandbox(logger logger.Logger, metrics metrics.Metrics, config CodeSandboxConfig) *CodeSandbox {
+	defaults := DefaultCodeSandboxConfig()
+
+	if config.Timeout <= 0 {
+		config.Timeout = defaults.Timeout
+	}
+
+	if config.MaxCPUTime <= 0 {
+		config.MaxCPUTime = config.Timeout
+	}
+
+	if config.MaxMemoryBytes == 0 {
+		config.MaxMemoryBytes = defaults.MaxMemoryBytes
+	}
+
+	if config.MaxFileSize == 0 {
+		config.MaxFileSize = defaults.MaxFileSize
+	}
+
+	if config.MaxOpenFiles == 0 {
+		config.MaxOpenFiles = defaults.MaxOpenFiles
+	}
+
+	if config.MaxProcesses == 0 {
+		config.MaxProcesses = defaults.MaxProcesses
+	}
+
+	if config.MaxOutputBytes <= 0 {
+		config.MaxOutputBytes = defaults.MaxOutputBytes
+	}
+
+	if config.MaxFileContentBytes <= 0 {
+		config.MaxFileContentBytes = defaults.MaxFileContentBytes
+	}
+
+	if config.Languages == nil {
+		config.Languages = defaults.Languages
+	}
+
+	s := &CodeSandbox{
+		logger:    logger,
+		metrics:   metrics,
+		config:    config,
+		languages: make(map[string]*SandboxLanguage),
+	}
+
+	for i := range config.Languages {
+		lang := &config.Languages[i]
+		for _, name := range append([]string{lang.Name}, lang.Aliases...) {
+			s.languages[strings.ToLower(name)] = lang
+		}
+	}
+
+	return s
+}
+
+// Languages returns the canonical names of the supported languages.
+func (s *CodeSandbox) Languages() []string {
+	names := make([]string, 0, len(s.config.Languages))
+	for _, lang := range s.config.Languages {
+		names = append(names, lang.Name)
+	}
+
+	return names
+}
+
+// Supports reports whether the sandbox can run the given language.
+func (s *CodeSandbox) Supports(language string) bool {
+	_, ok := s.languages[strings.ToLower(language)]
+
+	return ok
+}
+
+// Run executes a snippet and returns its output, exit code and generated files.
+func (s *CodeSandbox) Run(ctx context.Context, req CodeExecutionRequest) (*CodeExecutionResult, error) {
+	if !sandboxSupported {
+		return nil, ErrSandboxUnsupported
+	}
+
+	lang, ok := s.languages[strings.ToLower(req.Language)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedLanguage, req.Language)
+	}
+
+	dir, err := os.MkdirTemp(s.config.WorkDir, "sandbox-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create working directory: %w", err)
+	}
+
+	if !s.config.KeepWorkDir {
+		defer os.RemoveAll(dir)
+	}
+
+	files := maps.Clone(req.Files)
+	if files == nil {
+		files = make(map[string]string)
+	}
+
+	files[lang.FileName] = req.Code
+
+	for name, content := range files {
+		path, err := sandboxPath(dir, name)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", name, err)
+		}
+
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+
+	startTime := time.Now()
+
+	runCtx, cancel := context.WithTimeout(ctx, s.config.Timeout)
+	defer cancel()
+
+	// A shell applies the resource limits, then replaces itself with the command
+	args := append([]string{"-c", s.limitScript() + `exec "$@"`, "sandbox"}, lang.Command...)
+
+	cmd := exec.CommandContext(runCtx, "/bin/sh", args...)
+	cmd.Dir = dir
+	cmd.Env = s.environment(dir, lang)
+	cmd.Stdin = strings.NewReader(req.Stdin)
+	cmd.SysProcAttr = sandboxSysProcAttr(s.config.AllowNetwork)
+	cmd.Cancel = func() error { return killSandboxProcess(cmd) }
+	cmd.WaitDelay = time.Second
+
+	stdout := &cappedBuffer{limit: s.config.MaxOutputBytes}
+	stderr := &cappedBuffer{limit: s.config.MaxOutputBytes}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	runErr := cmd.Run()
+
+	// Background processes outlive the command and must not survive the run
+	if cmd.Process != nil {
+		_ = killSandboxProcess(cmd)
+	}
+
+	result := &CodeExecutionResult{
+		Language:        lang.Name,
+		Stdout:          stdout.String(),
+		Stderr:          stderr.String(),
+		ExitCode:        cmd.ProcessState.ExitCode(),
+		TimedOut:        errors.Is(runCtx.Err(), context.DeadlineExceeded),
+		StdoutTruncated: stdout.truncated,
+		StderrTruncated: stderr.truncated,
+		Duration:        time.Since(startTime),
+	}
+
+	if runErr != nil && cmd.ProcessState == nil {
+		return nil, fmt.Errorf("failed to start %s: %w", lang.Name, runErr)
+	}
+
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	result.Files = s.collectFiles(dir, files, startTime)
+
+	if s.logger != nil {
+		s.logger.Debug("Sandbox run completed",
+			F("language", lang.Name),
+			F("exit_code", result.ExitCode),
+			F("timed_out", result.TimedOut),
+			F("duration", result.Duration),
+		)
+	}
+
+	if s.metrics != nil {
+		s.metrics.Counter("forge.ai.sdk.sandbox.runs", metrics.WithLabel("language", lang.Name), metrics.WithLabel("exit_code", strconv.Itoa(result.ExitCode))).Inc()
+		s.metrics.Histogram("forge.ai.sdk.sandbox.duration", metrics.WithLabel("language", lang.Name)).Observe(result.Duration.Seconds())
+	}
+
+	return result, nil
+}
+
+// RunArtifact executes a runnable code artifact.
+func (s *CodeSandbox) RunArtifact(ctx context.Context, artifact *Artifact, stdin string) (*CodeExecutionResult, error) {
+	if artifact == nil {
+		return nil, errors.New("artifact is nil")
+	}
+
+	if artifact.Type != ArtifactTypeCode || !artifact.Runnable {
+		return nil, fmt.Errorf("%w: %s", ErrArtifactNotRunnable, artifact.ID)
+	}
+
+	return s.Run(ctx, CodeExecutionRequest{Language: artifact.Language, Code: artifact.Content, Stdin: stdin})
+}
+
+// Run executes a runnable code artifact from the registry in the given sandbox.
+func (r *ArtifactRegistry) Run(ctx context.Context, id string, sandbox *CodeSandbox, stdin string) (*CodeExecutionResult, error) {
+	artifact, err := r.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return sandbox.RunArtifact(ctx, artifact, stdin)
+}
+
+// Tool returns an agent tool that executes code in the sandbox.
+func (s *CodeSandbox) Tool() Tool {
+	return Tool{
+		Name:        "execute_code",
+		Description: s.toolDescription(),
+		Parameters:  s.toolParameters().Map(),
+		Handler:     s.handleToolCall,
+	}
+}
+
+// Definition returns a tool definition for registering the sandbox in a ToolRegistry.
+func (s *CodeSandbox) Definition() *ToolDefinition {
+	return &ToolDefinition{
+		Name:        "execute_code",
+		Description: s.toolDescription(),
+		Category:    "code",
+		Tags:        []string{"code", "sandbox"},
+		Parameters:  s.toolParameters(),
+		Handler:     s.handleToolCall,
+		// The sandbox enforces its own timeout; leave room to collect files
+		Timeout: s.config.Timeout + 5*time.Second,
+	}
+}
+
+func (s *CodeSandbox) toolDescription() string {
+	description := "Runs a code snippet in an isolated sandbox and returns stdout, stderr, the exit code and any files it writes to the working directory."
+	if !s.config.AllowNetwork {
+		description += " The sandbox has no network access."
+	}
+
+	return description
+}
+
+func (s *CodeSandbox) toolParameters() ToolParameterSchema {
+	return ToolParameterSchema{
+		Type: "object",
+		Properties: map[string]ToolParameterProperty{
+			"language": {Type: "string", Description: "Programming language of the snippet", Enum: s.Languages()},
+			"code":     {Type: "string", Description: "Complete program to run"},
+			"stdin":    {Type: "string", Description: "Standard input for the program"},
+		},
+		Required: []string{"language", "code"},
+	}
+}
+
+func (s *CodeSandbox) handleToolCall(ctx context.Context, params map[string]any) (any, error) {
+	language, _ := params["language"].(string)
+	code, _ := params["code"].(string)
+	stdin, _ := params["stdin"].(string)
+
+	return s.Run(ctx, CodeExecutionRequest{Language: language, Code: code, Stdin: stdin})
+}
+
+// limitScript returns the ulimit commands applying the configured limits.
+func (s *CodeSandbox) limitScript() string {
+	var script strings.Builder
+
+	if seconds := int64(s.config.MaxCPUTime.Seconds()); seconds > 0 {
+		fmt.Fprintf(&script, "ulimit -t %d || exit 125\n", seconds)
+	}
+
+	if s.config.MaxMemoryBytes > 0 {
+		fmt.Fprintf(&script, "ulimit -v %d || exit 125\n", s.config.MaxMemoryBytes/1024)
+	}
+
+	// POSIX shells count file sizes in 512-byte blocks
+	if s.config.MaxFileSize > 0 {
+		fmt.Fprintf(&script, "ulimit -f %d || exit 125\n", max(s.config.MaxFileSize/512, 1))
+	}
+
+	if s.config.MaxOpenFiles > 0 {
+		fmt.Fprintf(&script, "ulimit -n %d || exit 125\n", s.config.MaxOpenFiles)
+	}
+
+	// dash calls the process limit -p, bash and others -u
+	if s.config.MaxProcesses > 0 {
+		fmt.Fprintf(&script, "ulimit -u %[1]d 2>/dev/null || ulimit -p %[1]d || exit 125\n", s.config.MaxProcesses)
+	}
+
+	return script.String()
+}
+
+// environment builds a minimal environment, so host secrets do not leak into runs.
+func (s *CodeSandbox) environment(dir string, lang *SandboxLanguage) []string {
+	env := map[string]string{
+		"PATH":   os.Getenv("PATH"),
+		"HOME":   dir,
+		"TMPDIR": dir,
+		"LANG":   "C.UTF-8",
+	}
+
+	maps.Copy(env, lang.Env)
+	maps.Copy(env, s.config.Env)
+
+	out := make([]string, 0, len(env))
+	for _, key := range slices.Sorted(maps.Keys(env)) {
+		out = append(out, key+"="+env[key])
+	}
+
+	return out
+}
+
+// collectFiles lists files created or modified by the run, skipping inputs
+// that were left unchanged and hidden directories such as caches.
+func (s *CodeSandbox) collectFiles(dir string, inputs map[string]string, startTime time.Time) []SandboxFile {
+	var files []SandboxFile
+
+	_ = filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+
+		if entry.IsDir() {
+			if path != dir && strings.HasPrefix(entry.Name(), ".") {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if s.config.MaxFiles > 0 && len(files) >= s.config.MaxFiles {
+			return filepath.SkipAll
+		}
+
+		info, err := entry.Info()
+		if err != nil || !info.Mode().IsRegular() {
+			return nil
+		}
+
+		rel, _ := filepath.Rel(dir, path)
+		rel = filepath.ToSlash(rel)
+
+		if _, isInput := inputs[rel]; isInput && info.ModTime().Before(startTime) {
+			return nil
+		}
+
+		file := SandboxFile{Path: rel, Size: info.Size(), Omitted: true}
+
+		if info.Size() <= int64(s.config.MaxFileContentBytes) {
+			if data, err := os.ReadFile(path); err == nil && utf8.Valid(data) {
+				file.Content = string(data)
+				file.Omitted = false
+			}
+		}
+
+		files = append(files, file)
+
+		return nil
+	})
+
+	return files
+}
+
+// sandboxPath resolves a relative file name inside the working directory.
+func sandboxPath(dir, name string) (string, error) {
+	if !filepath.IsLocal(name) {
+		return "", fmt.Errorf("invalid file name %q: must be a relative path inside the working directory", name)
+	}
+
+	return filepath.Join(dir, name), nil
+}
+
+// cappedBuffer keeps the first limit bytes written to it and discards the rest,
+// so a chatty process neither blocks nor exhausts memory.
+type cappedBuffer struct {
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if remaining := b.limit - b.buf.Len(); remaining < len(p) {
+		b.buf.Write(p[:max(remaining, 0)])
+		b.truncated = true
+	} else {
+		b.buf.Write(p)
+	}
+
+	return len(p), nil
+}
+
+func (b *cappedBuffer) String() string {
+	return b.buf.String()
+}
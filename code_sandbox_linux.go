@@ -0,0 +1,33 @@
+package sdk
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+const sandboxSupported = true
+
+// sandboxSysProcAttr starts runs in their own process group, so a timeout
+// kills every child, and without network access in a fresh user and network
+// namespace.
+func sandboxSysProcAttr(allowNetwork bool) *syscall.SysProcAttr {
+	attr := &syscall.SysProcAttr{
+		Setpgid:   true,
+		Pdeathsig: syscall.SIGKILL,
+	}
+
+	if !allowNetwork {
+		attr.Cloneflags = syscall.CLONE_NEWUSER | syscall.CLONE_NEWNET
+		attr.UidMappings = []syscall.SysProcIDMap{{ContainerID: os.Getuid(), HostID: os.Getuid(), Size: 1}}
+		attr.GidMappings = []syscall.SysProcIDMap{{ContainerID: os.Getgid(), HostID: os.Getgid(), Size: 1}}
+		attr.GidMappingsEnableSetgroups = false
+	}
+
+	return attr
+}
+
+// killSandboxProcess kills the process group of a run.
+func killSandboxProcess(cmd *exec.Cmd) error {
+	return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
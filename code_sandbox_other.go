@@ -0,0 +1,18 @@
+//go:build !linux
+
+package sdk
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+const sandboxSupported = false
+
+func sandboxSysProcAttr(bool) *syscall.SysProcAttr {
+	return nil
+}
+
+func killSandboxProcess(cmd *exec.Cmd) error {
+	return cmd.Process.Kill()
+}
@@ -0,0 +1,200 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestSandbox(t *testing.T, config CodeSandboxConfig) *CodeSandbox {
+	t.Helper()
+
+	if runtime.GOOS != "linux" {
+		t.Skip("code sandbox requires Linux")
+	}
+
+	sandbox := NewCodeSandbox(nil, nil, config)
+
+	// Skip when the host does not allow unprivileged user namespaces
+	result, err := sandbox.Run(context.Background(), CodeExecutionRequest{Language: "sh", Code: "true"})
+	if err != nil {
+		t.Skipf("sandbox unavailable: %v", err)
+	}
+
+	if result.ExitCode != 0 {
+		t.Skipf("sandbox unavailable: %s", result.Stderr)
+	}
+
+	return sandbox
+}
+
+func TestCodeSandbox_Run(t *testing.T) {
+	sandbox := newTestSandbox(t, DefaultCodeSandboxConfig())
+
+	result, err := sandbox.Run(context.Background(), CodeExecutionRequest{
+		Language: "shell",
+		Code:     "read name; echo \"hello $name\"; echo oops >&2; echo data > out.txt; cat input.txt; exit 3",
+		Stdin:    "sandbox\n",
+		Files:    map[string]string{"input.txt": "from input"},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if result.Stdout != "hello sandbox\nfrom input" || result.Stderr != "oops\n" || result.ExitCode != 3 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	if len(result.Files) != 1 || result.Files[0].Path != "out.txt" || result.Files[0].Content != "data\n" {
+		t.Errorf("expected generated file only, got %+v", result.Files)
+	}
+}
+
+func TestCodeSandbox_Limits(t *testing.T) {
+	config := DefaultCodeSandboxConfig()
+	config.Timeout = 500 * time.Millisecond
+	config.MaxOutputBytes = 8
+
+	sandbox := newTestSandbox(t, config)
+
+	result, err := sandbox.Run(context.Background(), CodeExecutionRequest{Language: "sh", Code: "echo 0123456789; sleep 5"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if !result.TimedOut || result.Duration > 3*time.Second {
+		t.Errorf("expected timeout, got %+v", result)
+	}
+
+	if result.Stdout != "01234567" || !result.StdoutTruncated {
+		t.Errorf("expected truncated output, got %q", result.Stdout)
+	}
+}
+
+func TestCodeSandbox_KillsBackgroundProcesses(t *testing.T) {
+	sandbox := newTestSandbox(t, DefaultCodeSandboxConfig())
+
+	result, err := sandbox.Run(context.Background(), CodeExecutionRequest{Language: "sh", Code: "sleep 600 >/dev/null 2>&1 & echo $!"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	pid := strings.TrimSpace(result.Stdout)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		// A killed process that nobody reaps stays behind as a zombie
+		stat, err := os.ReadFile("/proc/" + pid + "/stat")
+		if err != nil || strings.Contains(string(stat), ") Z ") {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("expected background process %s to be killed, got %s", pid, stat)
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestCodeSandbox_DefaultLimits(t *testing.T) {
+	sandbox := NewCodeSandbox(nil, nil, CodeSandboxConfig{MaxProcesses: -1})
+	script := sandbox.limitScript()
+
+	for _, limit := range []string{"ulimit -t 30", "ulimit -v 1048576", "ulimit -f 131072", "ulimit -n 256"} {
+		if !strings.Contains(script, limit) {
+			t.Errorf("expected default limit %q, got %q", limit, script)
+		}
+	}
+
+	if strings.Contains(script, "ulimit -u") {
+		t.Errorf("expected a negative limit to be disabled, got %q", script)
+	}
+}
+
+func TestCodeSandbox_NoNetwork(t *testing.T) {
+	sandbox := newTestSandbox(t, DefaultCodeSandboxConfig())
+
+	// Only the loopback interface exists in an empty network namespace
+	result, err := sandbox.Run(context.Background(), CodeExecutionRequest{Language: "sh", Code: "tail -n +3 /proc/net/dev | grep -v lo:"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if strings.TrimSpace(result.Stdout) != "" {
+		t.Errorf("expected no network interfaces, got %q", result.Stdout)
+	}
+}
+
+func TestCodeSandbox_Python(t *testing.T) {
+	if _, err := exec.LookPath("python3"); err != nil {
+		t.Skip("python3 not installed")
+	}
+
+	sandbox := newTestSandbox(t, DefaultCodeSandboxConfig())
+
+	result, err := sandbox.Run(context.Background(), CodeExecutionRequest{Language: "py", Code: "print(sum(range(10)))"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if result.Language != "python" || result.Stdout != "45\n" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+}
+
+func TestCodeSandbox_Artifacts(t *testing.T) {
+	sandbox := newTestSandbox(t, DefaultCodeSandboxConfig())
+	registry := NewArtifactRegistry(nil, nil)
+
+	script := NewCodeArtifact("greet", "bash", "echo hi")
+	notes := NewDocumentArtifact("notes", "# Notes")
+
+	for _, artifact := range []*Artifact{script, notes} {
+		if err := registry.Create(artifact); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+
+	result, err := registry.Run(context.Background(), script.ID, sandbox, "")
+	if err != nil || result.Stdout != "hi\n" {
+		t.Fatalf("expected artifact output, got %+v (%v)", result, err)
+	}
+
+	if _, err := registry.Run(context.Background(), notes.ID, sandbox, ""); !errors.Is(err, ErrArtifactNotRunnable) {
+		t.Errorf("expected ErrArtifactNotRunnable, got %v", err)
+	}
+}
+
+func TestCodeSandbox_Tool(t *testing.T) {
+	sandbox := NewCodeSandbox(nil, nil, DefaultCodeSandboxConfig())
+
+	if _, err := sandbox.Run(context.Background(), CodeExecutionRequest{Language: "cobol", Code: ""}); !errors.Is(err, ErrUnsupportedLanguage) && !errors.Is(err, ErrSandboxUnsupported) {
+		t.Errorf("expected ErrUnsupportedLanguage, got %v", err)
+	}
+
+	if _, err := sandbox.Run(context.Background(), CodeExecutionRequest{Language: "sh", Files: map[string]string{"../escape": ""}}); err == nil {
+		t.Error("expected error for file outside the working directory")
+	}
+
+	tool := sandbox.Tool()
+
+	properties, _ := tool.Parameters["properties"].(map[string]any)
+	if tool.Name != "execute_code" || properties["language"] == nil || properties["code"] == nil {
+		t.Errorf("unexpected tool: %+v", tool)
+	}
+
+	registry := NewToolRegistry(nil, nil)
+	if err := registry.RegisterTool(sandbox.Definition()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if _, err := registry.ExecuteTool(context.Background(), "execute_code", "", map[string]any{"language": "cobol", "code": ""}); !errors.Is(err, ErrInvalidToolArguments) {
+		t.Errorf("expected language enum to be enforced, got %v", err)
+	}
+}
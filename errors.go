@@ -42,6 +42,39 @@ var (
 
 	// ErrToolTimeout is returned when a tool execution times out.
 	ErrToolTimeout = errors.New("tool execution timed out")
+
+	// ErrInvalidToolArguments is returned when tool call arguments do not match the tool schema.
+	ErrInvalidToolArguments = errors.New("invalid tool arguments")
+
+	// ErrToolPermissionDenied is returned when the caller lacks scopes a tool requires.
+	ErrToolPermissionDenied = errors.New("tool permission denied")
+
+	// ErrInvalidOpenAPISpec is returned when an OpenAPI document cannot be turned into tools.
+	ErrInvalidOpenAPISpec = errors.New("invalid OpenAPI spec")
+
+	// ErrInvalidChainSpec is returned when a tool chain definition is invalid.
+	ErrInvalidChainSpec = errors.New("invalid tool chain spec")
+)
+
+// Sandbox-related errors.
+var (
+	// ErrSandboxUnsupported is returned when code execution is not supported on this platform.
+	ErrSandboxUnsupported = errors.New("code sandbox is only supported on Linux")
+
+	// ErrUnsupportedLanguage is returned when the sandbox cannot run a language.
+	ErrUnsupportedLanguage = errors.New("unsupported language")
+
+	// ErrArtifactNotRunnable is returned when executing an artifact that is not runnable code.
+	ErrArtifactNotRunnable = errors.New("artifact is not runnable")
+)
+
+// Schema-related errors.
+var (
+	// ErrInvalidSchema is returned when a JSON schema cannot be compiled.
+	ErrInvalidSchema = errors.New("invalid JSON schema")
+
+	// ErrSchemaValidation is returned when a value does not match a JSON schema.
+	ErrSchemaValidation = errors.New("schema validation failed")
 )
 
 // Handoff-related errors.
@@ -87,6 +120,39 @@ var (
 
 	// ErrWorkflowExecutionFailed is returned when workflow execution fails.
 	ErrWorkflowExecutionFailed = errors.New("workflow execution failed")
+
+	// ErrWorkflowAlreadyRegistered is returned when trying to register a workflow that already exists.
+	ErrWorkflowAlreadyRegistered = errors.New("workflow already registered")
+
+	// ErrWorkflowRecursion is returned when a sub-workflow would (transitively) invoke itself.
+	ErrWorkflowRecursion = errors.New("recursive sub-workflow invocation")
+
+	// ErrWorkflowExecutionNotFound is returned when a persisted workflow execution is not found.
+	ErrWorkflowExecutionNotFound = errors.New("workflow execution not found")
+
+	// ErrWorkflowNotResumable is returned when resuming an execution that was compensated or cancelled.
+	ErrWorkflowNotResumable = errors.New("workflow execution cannot be resumed")
+
+	// ErrWorkflowSignalNotPending is returned when signalling a node that is not waiting.
+	ErrWorkflowSignalNotPending = errors.New("workflow node is not waiting for a signal")
+
+	// ErrWorkflowSignalTimeout is returned when a signal or approval node times out.
+	ErrWorkflowSignalTimeout = errors.New("workflow signal timed out")
+
+	// ErrWorkflowApprovalRejected is returned when an approval node is rejected.
+	ErrWorkflowApprovalRejected = errors.New("workflow approval rejected")
+
+	// ErrExpressionLimitExceeded is returned when an expression exceeds its step, depth or size limit.
+	ErrExpressionLimitExceeded = errors.New("expression limit exceeded")
+
+	// ErrScheduleNotFound is returned when a workflow schedule is not found.
+	ErrScheduleNotFound = errors.New("schedule not found")
+
+	// ErrScheduleAlreadyExists is returned when adding a schedule with a duplicate ID.
+	ErrScheduleAlreadyExists = errors.New("schedule already exists")
+
+	// ErrSchedulerNotRunning is returned when triggering a schedule before the scheduler is started.
+	ErrSchedulerNotRunning = errors.New("scheduler is not running")
 )
 
 // Generation-related errors.
@@ -147,6 +213,9 @@ var (
 
 	// ErrDocumentTooLarge is returned when a document exceeds the maximum size.
 	ErrDocumentTooLarge = errors.New("document exceeds maximum size")
+
+	// ErrInvalidChunker is returned when a chunker lacks its tokenizer or embedder.
+	ErrInvalidChunker = errors.New("invalid chunker configuration")
 )
 
 // Guardrail-related errors.
@@ -308,20 +308,15 @@ func (b *TextGenerator) Execute() (*Result, error) {
 		if len(choice.Message.ToolCalls) > 0 {
 			result.ToolCalls = make([]ToolCallResult, 0, len(choice.Message.ToolCalls))
 			for _, tc := range choice.Message.ToolCalls {
-				// Parse arguments from JSON string to map
-				var args map[string]any
-				if tc.Function != nil && tc.Function.Arguments != "" {
-					if err := json.Unmarshal([]byte(tc.Function.Arguments), &args); err != nil {
-						// If parsing fails, store as string
-						args = map[string]any{"raw": tc.Function.Arguments}
-					}
-				} else {
-					args = make(map[string]any)
+				var arguments string
+				if tc.Function != nil {
+					arguments = tc.Function.Arguments
 				}
 
 				result.ToolCalls = append(result.ToolCalls, ToolCallResult{
+					ID:        tc.ID,
 					Name:      tc.Function.Name,
-					Arguments: args,
+					Arguments: parseToolCallArguments(arguments),
 				})
 			}
 		}
@@ -386,6 +381,21 @@ func (b *TextGenerator) buildMessages(userPrompt string) []llm.ChatMessage {
 	return messages.Build(b.systemPrompt, b.messages, userPrompt)
 }
 
+// parseToolCallArguments decodes the JSON arguments of a tool call. Arguments
+// that are not valid JSON are kept under "raw".
+func parseToolCallArguments(arguments string) map[string]any {
+	if arguments == "" {
+		return make(map[string]any)
+	}
+
+	var args map[string]any
+	if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+		return map[string]any{"raw": arguments}
+	}
+
+	return args
+}
+
 // String returns the generated content (convenience method).
 func (r *Result) String() string {
 	return r.Content
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"maps"
 	"reflect"
+	"slices"
 	"strings"
 	"time"
 
@@ -306,6 +307,18 @@ func (b *ObjectGenerator[T]) Execute() (T, error) {
 	// Build messages
 	messages := b.buildMessages(renderedPrompt, schema)
 
+	// Compile the schema for strict validation; schemas the validator cannot
+	// compile are only used as instructions
+	var validator *JSONSchema
+
+	if b.schemaStrict {
+		if validator, err = CompileJSONSchema(schema); err != nil && b.logger != nil {
+			b.logger.Warn("Schema cannot be validated, skipping strict validation",
+				F("error", err.Error()),
+			)
+		}
+	}
+
 	// Log execution
 	if b.logger != nil {
 		b.logger.Debug("Executing structured generation",
@@ -391,6 +404,20 @@ func (b *ObjectGenerator[T]) Execute() (T, error) {
 			continue
 		}
 
+		// Validate against the schema, asking the model to fix violations on retry
+		if validator != nil {
+			if err := validator.ValidateJSON([]byte(content)); err != nil {
+				lastErr = err
+				messages = appendSchemaFeedback(messages, content, err)
+
+				if b.metrics != nil {
+					b.metrics.Counter("forge.ai.sdk.generate_object.errors", metrics.WithLabel("error", "schema_validation")).Inc()
+				}
+
+				continue
+			}
+		}
+
 		// Run validators
 		validationFailed := false
 
@@ -453,6 +480,27 @@ func (b *ObjectGenerator[T]) Execute() (T, error) {
 	return zero, fmt.Errorf("generation failed after %d attempts: %w", b.retries+1, lastErr)
 }
 
+// appendSchemaFeedback adds the rejected response and its schema violations to
+// the conversation so the next attempt can correct them.
+func appendSchemaFeedback(messages []llm.ChatMessage, content string, err error) []llm.ChatMessage {
+	feedback := err.Error()
+
+	var validationErr *SchemaValidationError
+	if errors.As(err, &validationErr) {
+		if data, marshalErr := json.Marshal(validationErr.Violations); marshalErr == nil {
+			feedback = string(data)
+		}
+	}
+
+	return append(slices.Clone(messages),
+		llm.ChatMessage{Role: "assistant", Content: content},
+		llm.ChatMessage{
+			Role:    "user",
+			Content: "The JSON does not match the schema. Fix these violations and return only the corrected JSON:\n" + feedback,
+		},
+	)
+}
+
 // renderPrompt renders the prompt template with variables.
 func (b *ObjectGenerator[T]) renderPrompt() (string, error) {
 	if len(b.vars) == 0 {
@@ -582,6 +630,14 @@ func (b *ObjectGenerator[T]) generatePropertySchema(t reflect.Type, description
 		t = t.Elem()
 	}
 
+	// time.Time marshals as an RFC 3339 string
+	if t == timeType {
+		schema["type"] = "string"
+		schema["format"] = "date-time"
+
+		return schema
+	}
+
 	switch t.Kind() {
 	case reflect.String:
 		schema["type"] = "string"
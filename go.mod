@@ -5,6 +5,7 @@ go 1.25.7
 require (
 	github.com/google/uuid v1.6.0
 	github.com/xraph/go-utils v0.0.10
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
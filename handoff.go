@@ -205,8 +205,9 @@ func (r *AgentRegistry) ListInfo() []AgentInfo {
 
 	infos := make([]AgentInfo, 0, len(r.agents))
 	for _, agent := range r.agents {
-		tools := make([]string, len(agent.tools))
-		for i, tool := range agent.tools {
+		agentTools := agent.availableTools()
+		tools := make([]string, len(agentTools))
+		for i, tool := range agentTools {
 			tools[i] = tool.Name
 		}
 
@@ -231,7 +232,7 @@ func (r *AgentRegistry) FindByTool(toolName string) []*Agent {
 	result := make([]*Agent, 0)
 
 	for _, agent := range r.agents {
-		for _, tool := range agent.tools {
+		for _, tool := range agent.availableTools() {
 			if tool.Name == toolName {
 				result = append(result, agent)
 
@@ -340,7 +341,7 @@ func (r *DefaultAgentRouter) findBestMatch(input string, agents []*Agent) *Agent
 		}
 
 		// Check if any tool is mentioned
-		for _, tool := range agent.tools {
+		for _, tool := range agent.availableTools() {
 			if strings.Contains(inputLower, strings.ToLower(tool.Name)) {
 				score += 5
 			}
@@ -752,7 +753,7 @@ func NewAgentWithHandoff(agent *Agent, handoffManager *HandoffManager) *AgentWit
 	handoffTool := handoffManager.CreateHandoffTool(agent.ID)
 	routingTool := handoffManager.CreateToolRoutingTool(agent.ID)
 
-	agent.tools = append(agent.tools, handoffTool, routingTool)
+	agent.AddTools(handoffTool, routingTool)
 
 	return &AgentWithHandoff{
 		Agent:          agent,
@@ -0,0 +1,823 @@
+package sdk
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"net"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// JSONSchema is a compiled JSON Schema. It supports the subset of draft
+// 2020-12 used for tool arguments and structured outputs:
+//   - type (single or list), enum and const
+//   - minLength, maxLength, pattern and format (date-time, date, time,
+//     email, uri, uuid, ipv4, ipv6; other formats are annotations)
+//   - minimum, maximum, exclusiveMinimum, exclusiveMaximum and multipleOf
+//   - items, prefixItems, minItems, maxItems and uniqueItems
+//   - properties, required, additionalProperties, minProperties and maxProperties
+//   - allOf, anyOf, oneOf and not
+//   - $ref to local definitions ("#", "#/$defs/..." or "#/definitions/...")
+//
+// Unknown keywords are ignored. A compiled schema is safe for concurrent use.
+type JSONSchema struct {
+	root *schemaNode
+}
+
+// SchemaViolation describes one place where a value does not match a schema.
+// Path uses dots for properties and brackets for array indexes
+// (e.g. "items[2].name") and is empty for the root value.
+type SchemaViolation struct {
+	Path    string `json:"path"`
+	Keyword string `json:"keyword,omitempty"`
+	Message string `json:"message"`
+}
+
+// SchemaValidationError lists every violation found while validating a value.
+type SchemaValidationError struct {
+	Violations []SchemaViolation
+}
+
+func (e *SchemaValidationError) Error() string {
+	return "schema validation failed: " + formatViolations(e.Violations)
+}
+
+// Unwrap allows errors.Is(err, ErrSchemaValidation).
+func (e *SchemaValidationError) Unwrap() error {
+	return ErrSchemaValidation
+}
+
+// formatViolations joins violations into a single readable line.
+func formatViolations(violations []SchemaViolation) string {
+	msgs := make([]string, len(violations))
+	for i, v := range violations {
+		if v.Path == "" {
+			msgs[i] = v.Message
+		} else {
+			msgs[i] = v.Path + ": " + v.Message
+		}
+	}
+
+	return strings.Join(msgs, "; ")
+}
+
+// schemaNode is one compiled (sub)schema.
+type schemaNode struct {
+	never bool // the "false" schema
+
+	types    []string
+	enum     []any
+	constVal any
+	hasConst bool
+
+	minLength *int
+	maxLength *int
+	pattern   *regexp.Regexp
+	format    string
+
+	minimum          *float64
+	maximum          *float64
+	exclusiveMinimum *float64
+	exclusiveMaximum *float64
+	multipleOf       *float64
+
+	items       *schemaNode
+	prefixItems []*schemaNode
+	minItems    *int
+	maxItems    *int
+	uniqueItems bool
+
+	properties    map[string]*schemaNode
+	required      []string
+	additional    *schemaNode
+	minProperties *int
+	maxProperties *int
+
+	allOf []*schemaNode
+	anyOf []*schemaNode
+	oneOf []*schemaNode
+	not   *schemaNode
+	ref   *schemaNode
+}
+
+// schemaCompiler compiles a schema document, sharing nodes between $refs so
+// recursive definitions terminate.
+type schemaCompiler struct {
+	root map[string]any
+	refs map[string]*schemaNode
+}
+
+// CompileJSONSchema compiles a schema given as a map, a JSON document
+// ([]byte, json.RawMessage or string) or any value that marshals to one,
+// such as a ToolParameterSchema.
+func CompileJSONSchema(schema any) (*JSONSchema, error) {
+	var raw any
+
+	switch s := schema.(type) {
+	case bool:
+		raw = s
+	case map[string]any:
+		// Schemas built in Go may hold typed values such as []string
+		raw = normalizeJSONValue(s)
+	case []byte:
+		if err := json.Unmarshal(s, &raw); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidSchema, err)
+		}
+	case json.RawMessage:
+		if err := json.Unmarshal(s, &raw); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidSchema, err)
+		}
+	case string:
+		if err := json.Unmarshal([]byte(s), &raw); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidSchema, err)
+		}
+	default:
+		data, err := json.Marshal(schema)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidSchema, err)
+		}
+
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidSchema, err)
+		}
+	}
+
+	c := &schemaCompiler{refs: make(map[string]*schemaNode)}
+	c.root, _ = raw.(map[string]any)
+
+	root, err := c.compile(raw, "#")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSchema, err)
+	}
+
+	return &JSONSchema{root: root}, nil
+}
+
+// MustCompileJSONSchema is like CompileJSONSchema but panics on error.
+func MustCompileJSONSchema(schema any) *JSONSchema {
+	s, err := CompileJSONSchema(schema)
+	if err != nil {
+		panic(err)
+	}
+
+	return s
+}
+
+// Validate checks a value against the schema. Go values that are not plain
+// JSON (structs, typed slices and maps) are compared in their JSON form.
+// It returns a *SchemaValidationError listing every violation.
+func (s *JSONSchema) Validate(value any) error {
+	violations := s.Violations(value)
+	if len(violations) == 0 {
+		return nil
+	}
+
+	return &SchemaValidationError{Violations: violations}
+}
+
+// ValidateJSON parses a JSON document and checks it against the schema.
+func (s *JSONSchema) ValidateJSON(data []byte) error {
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return &SchemaValidationError{Violations: []SchemaViolation{{Message: "invalid JSON: " + err.Error()}}}
+	}
+
+	return s.Validate(value)
+}
+
+// Violations returns every violation of the schema by value.
+func (s *JSONSchema) Violations(value any) []SchemaViolation {
+	var violations []SchemaViolation
+
+	s.root.validate("", normalizeJSONValue(value), &violations)
+
+	return violations
+}
+
+// compile compiles a schema value found at the given JSON pointer.
+func (c *schemaCompiler) compile(raw any, pointer string) (*schemaNode, error) {
+	switch s := raw.(type) {
+	case bool:
+		return &schemaNode{never: !s}, nil
+	case map[string]any:
+		node := &schemaNode{}
+
+		return node, c.compileInto(node, s, pointer)
+	default:
+		return nil, fmt.Errorf("%s: schema must be an object or boolean", pointer)
+	}
+}
+
+// compileInto fills node from a schema object.
+func (c *schemaCompiler) compileInto(node *schemaNode, s map[string]any, pointer string) error {
+	var err error
+
+	if ref, ok := s["$ref"].(string); ok {
+		if node.ref, err = c.resolve(ref); err != nil {
+			return fmt.Errorf("%s: %w", pointer, err)
+		}
+	}
+
+	switch t := s["type"].(type) {
+	case nil:
+	case string:
+		node.types = []string{t}
+	case []any:
+		for _, item := range t {
+			name, ok := item.(string)
+			if !ok {
+				return fmt.Errorf("%s/type: expected strings", pointer)
+			}
+
+			node.types = append(node.types, name)
+		}
+	default:
+		return fmt.Errorf("%s/type: expected string or array", pointer)
+	}
+
+	if enum, ok := s["enum"]; ok {
+		values, ok := enum.([]any)
+		if !ok {
+			return fmt.Errorf("%s/enum: expected array", pointer)
+		}
+
+		for _, v := range values {
+			node.enum = append(node.enum, normalizeJSONValue(v))
+		}
+	}
+
+	if v, ok := s["const"]; ok {
+		node.constVal = normalizeJSONValue(v)
+		node.hasConst = true
+	}
+
+	intKeywords := map[string]**int{
+		"minLength":     &node.minLength,
+		"maxLength":     &node.maxLength,
+		"minItems":      &node.minItems,
+		"maxItems":      &node.maxItems,
+		"minProperties": &node.minProperties,
+		"maxProperties": &node.maxProperties,
+	}
+	for key, target := range intKeywords {
+		if v, ok := s[key]; ok {
+			n, isNum := jsonNumber(v)
+			if !isNum || n < 0 || n != math.Trunc(n) {
+				return fmt.Errorf("%s/%s: expected non-negative integer", pointer, key)
+			}
+
+			i := int(n)
+			*target = &i
+		}
+	}
+
+	numKeywords := map[string]**float64{
+		"minimum":          &node.minimum,
+		"maximum":          &node.maximum,
+		"exclusiveMinimum": &node.exclusiveMinimum,
+		"exclusiveMaximum": &node.exclusiveMaximum,
+		"multipleOf":       &node.multipleOf,
+	}
+	for key, target := range numKeywords {
+		if v, ok := s[key]; ok {
+			n, isNum := jsonNumber(v)
+			if !isNum {
+				return fmt.Errorf("%s/%s: expected number", pointer, key)
+			}
+
+			*target = &n
+		}
+	}
+
+	if node.multipleOf != nil && *node.multipleOf <= 0 {
+		return fmt.Errorf("%s/multipleOf: must be greater than 0", pointer)
+	}
+
+	if pattern, ok := s["pattern"]; ok {
+		p, isString := pattern.(string)
+		if !isString {
+			return fmt.Errorf("%s/pattern: expected string", pointer)
+		}
+
+		if node.pattern, err = regexp.Compile(p); err != nil {
+			return fmt.Errorf("%s/pattern: %w", pointer, err)
+		}
+	}
+
+	node.format, _ = s["format"].(string)
+	node.uniqueItems, _ = s["uniqueItems"].(bool)
+
+	if v, ok := s["items"]; ok {
+		// Draft 4-7 tuple form
+		if tuple, isTuple := v.([]any); isTuple {
+			if node.prefixItems, err = c.compileList(tuple, pointer+"/items"); err != nil {
+				return err
+			}
+		} else if node.items, err = c.compile(v, pointer+"/items"); err != nil {
+			return err
+		}
+	}
+
+	if v, ok := s["prefixItems"]; ok {
+		list, isList := v.([]any)
+		if !isList {
+			return fmt.Errorf("%s/prefixItems: expected array", pointer)
+		}
+
+		if node.prefixItems, err = c.compileList(list, pointer+"/prefixItems"); err != nil {
+			return err
+		}
+	}
+
+	if v, ok := s["properties"]; ok {
+		props, isMap := v.(map[string]any)
+		if !isMap {
+			return fmt.Errorf("%s/properties: expected object", pointer)
+		}
+
+		node.properties = make(map[string]*schemaNode, len(props))
+
+		for name, prop := range props {
+			if node.properties[name], err = c.compile(prop, pointer+"/properties/"+name); err != nil {
+				return err
+			}
+		}
+	}
+
+	if v, ok := s["required"]; ok {
+		list, isList := v.([]any)
+		if !isList {
+			return fmt.Errorf("%s/required: expected array", pointer)
+		}
+
+		for _, item := range list {
+			name, isString := item.(string)
+			if !isString {
+				return fmt.Errorf("%s/required: expected strings", pointer)
+			}
+
+			node.required = append(node.required, name)
+		}
+	}
+
+	if v, ok := s["additionalProperties"]; ok {
+		if node.additional, err = c.compile(v, pointer+"/additionalProperties"); err != nil {
+			return err
+		}
+	}
+
+	combinators := map[string]*[]*schemaNode{
+		"allOf": &node.allOf,
+		"anyOf": &node.anyOf,
+		"oneOf": &node.oneOf,
+	}
+	for key, target := range combinators {
+		if v, ok := s[key]; ok {
+			list, isList := v.([]any)
+			if !isList || len(list) == 0 {
+				return fmt.Errorf("%s/%s: expected non-empty array", pointer, key)
+			}
+
+			if *target, err = c.compileList(list, pointer+"/"+key); err != nil {
+				return err
+			}
+		}
+	}
+
+	if v, ok := s["not"]; ok {
+		if node.not, err = c.compile(v, pointer+"/not"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// compileList compiles an array of schemas.
+func (c *schemaCompiler) compileList(list []any, pointer string) ([]*schemaNode, error) {
+	nodes := make([]*schemaNode, len(list))
+
+	for i, item := range list {
+		node, err := c.compile(item, pointer+"/"+strconv.Itoa(i))
+		if err != nil {
+			return nil, err
+		}
+
+		nodes[i] = node
+	}
+
+	return nodes, nil
+}
+
+// resolve returns the node a local $ref points to, compiling it on first use.
+func (c *schemaCompiler) resolve(ref string) (*schemaNode, error) {
+	if node, ok := c.refs[ref]; ok {
+		return node, nil
+	}
+
+	if !strings.HasPrefix(ref, "#") {
+		return nil, fmt.Errorf("only local $ref is supported, got %q", ref)
+	}
+
+	var target any = c.root
+
+	if fragment := strings.TrimPrefix(ref, "#"); fragment != "" {
+		for token := range strings.SplitSeq(strings.TrimPrefix(fragment, "/"), "/") {
+			token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+
+			object, ok := target.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("unresolvable $ref %q", ref)
+			}
+
+			if target, ok = object[token]; !ok {
+				return nil, fmt.Errorf("unresolvable $ref %q", ref)
+			}
+		}
+	}
+
+	// Register the node before compiling so recursive references reuse it
+	node := &schemaNode{}
+	c.refs[ref] = node
+
+	switch s := target.(type) {
+	case bool:
+		node.never = !s
+	case map[string]any:
+		if err := c.compileInto(node, s, ref); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unresolvable $ref %q", ref)
+	}
+
+	return node, nil
+}
+
+// validate appends the violations of value to out.
+func (n *schemaNode) validate(path string, value any, out *[]SchemaViolation) {
+	fail := func(keyword, format string, args ...any) {
+		*out = append(*out, SchemaViolation{Path: path, Keyword: keyword, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if n.never {
+		fail("false", "no value is allowed here")
+
+		return
+	}
+
+	if n.ref != nil {
+		n.ref.validate(path, value, out)
+	}
+
+	if len(n.types) > 0 && !slices.ContainsFunc(n.types, func(t string) bool { return jsonTypeMatches(t, value) }) {
+		fail("type", "expected %s, got %s", strings.Join(n.types, " or "), schemaTypeName(value))
+
+		// Further keywords would only repeat the type mismatch
+		return
+	}
+
+	if n.enum != nil && !slices.ContainsFunc(n.enum, func(e any) bool { return reflect.DeepEqual(e, value) }) {
+		fail("enum", "must be one of %s", formatEnum(n.enum))
+	}
+
+	if n.hasConst && !reflect.DeepEqual(n.constVal, value) {
+		fail("const", "must be %s", formatEnum([]any{n.constVal}))
+	}
+
+	switch v := value.(type) {
+	case string:
+		n.validateString(v, fail)
+	case float64:
+		n.validateNumber(v, fail)
+	case []any:
+		n.validateArray(path, v, out, fail)
+	case map[string]any:
+		n.validateObject(path, v, out, fail)
+	}
+
+	for _, sub := range n.allOf {
+		sub.validate(path, value, out)
+	}
+
+	if n.anyOf != nil && countMatches(n.anyOf, value) == 0 {
+		fail("anyOf", "must match at least one of the allowed schemas")
+	}
+
+	if n.oneOf != nil {
+		if matched := countMatches(n.oneOf, value); matched != 1 {
+			fail("oneOf", "must match exactly one of the allowed schemas, matched %d", matched)
+		}
+	}
+
+	if n.not != nil && countMatches([]*schemaNode{n.not}, value) == 1 {
+		fail("not", "must not match the excluded schema")
+	}
+}
+
+// validateString checks string keywords.
+func (n *schemaNode) validateString(s string, fail func(keyword, format string, args ...any)) {
+	length := utf8.RuneCountInString(s)
+
+	if n.minLength != nil && length < *n.minLength {
+		fail("minLength", "must be at least %d characters", *n.minLength)
+	}
+
+	if n.maxLength != nil && length > *n.maxLength {
+		fail("maxLength", "must be at most %d characters", *n.maxLength)
+	}
+
+	if n.pattern != nil && !n.pattern.MatchString(s) {
+		fail("pattern", "must match pattern %s", n.pattern)
+	}
+
+	if n.format != "" && !formatMatches(n.format, s) {
+		fail("format", "must be a valid %s", n.format)
+	}
+}
+
+// validateNumber checks numeric keywords.
+func (n *schemaNode) validateNumber(f float64, fail func(keyword, format string, args ...any)) {
+	if n.minimum != nil && f < *n.minimum {
+		fail("minimum", "must be >= %v", *n.minimum)
+	}
+
+	if n.maximum != nil && f > *n.maximum {
+		fail("maximum", "must be <= %v", *n.maximum)
+	}
+
+	if n.exclusiveMinimum != nil && f <= *n.exclusiveMinimum {
+		fail("exclusiveMinimum", "must be > %v", *n.exclusiveMinimum)
+	}
+
+	if n.exclusiveMaximum != nil && f >= *n.exclusiveMaximum {
+		fail("exclusiveMaximum", "must be < %v", *n.exclusiveMaximum)
+	}
+
+	if n.multipleOf != nil {
+		if q := f / *n.multipleOf; math.Abs(q-math.Round(q)) > 1e-9 {
+			fail("multipleOf", "must be a multiple of %v", *n.multipleOf)
+		}
+	}
+}
+
+// validateArray checks array keywords and items.
+func (n *schemaNode) validateArray(path string, items []any, out *[]SchemaViolation, fail func(keyword, format string, args ...any)) {
+	if n.minItems != nil && len(items) < *n.minItems {
+		fail("minItems", "must have at least %d items", *n.minItems)
+	}
+
+	if n.maxItems != nil && len(items) > *n.maxItems {
+		fail("maxItems", "must have at most %d items", *n.maxItems)
+	}
+
+	if n.uniqueItems {
+		for i := 1; i < len(items); i++ {
+			if slices.ContainsFunc(items[:i], func(prev any) bool { return reflect.DeepEqual(prev, items[i]) }) {
+				fail("uniqueItems", "must not contain duplicates (item %d)", i)
+
+				break
+			}
+		}
+	}
+
+	for i, item := range items {
+		itemPath := fmt.Sprintf("%s[%d]", path, i)
+
+		switch {
+		case i < len(n.prefixItems):
+			n.prefixItems[i].validate(itemPath, item, out)
+		case n.items != nil:
+			n.items.validate(itemPath, item, out)
+		}
+	}
+}
+
+// validateObject checks object keywords and properties.
+func (n *schemaNode) validateObject(path string, object map[string]any, out *[]SchemaViolation, fail func(keyword, format string, args ...any)) {
+	if n.minProperties != nil && len(object) < *n.minProperties {
+		fail("minProperties", "must have at least %d properties", *n.minProperties)
+	}
+
+	if n.maxProperties != nil && len(object) > *n.maxProperties {
+		fail("maxProperties", "must have at most %d properties", *n.maxProperties)
+	}
+
+	for _, name := range n.required {
+		if _, ok := object[name]; !ok {
+			*out = append(*out, SchemaViolation{Path: joinSchemaPath(path, name), Keyword: "required", Message: "is required"})
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(object)) {
+		propPath := joinSchemaPath(path, name)
+
+		if prop, ok := n.properties[name]; ok {
+			prop.validate(propPath, object[name], out)
+
+			continue
+		}
+
+		if n.additional == nil {
+			continue
+		}
+
+		if n.additional.never {
+			*out = append(*out, SchemaViolation{Path: propPath, Keyword: "additionalProperties", Message: "unknown property"})
+
+			continue
+		}
+
+		n.additional.validate(propPath, object[name], out)
+	}
+}
+
+// countMatches returns how many schemas value satisfies.
+func countMatches(nodes []*schemaNode, value any) int {
+	matched := 0
+
+	for _, node := range nodes {
+		var violations []SchemaViolation
+		if node.validate("", value, &violations); len(violations) == 0 {
+			matched++
+		}
+	}
+
+	return matched
+}
+
+// jsonTypeMatches reports whether a normalized value has the given JSON type.
+func jsonTypeMatches(typ string, value any) bool {
+	switch typ {
+	case "null":
+		return value == nil
+	case "boolean":
+		_, ok := value.(bool)
+
+		return ok
+	case "string":
+		_, ok := value.(string)
+
+		return ok
+	case "number":
+		_, ok := value.(float64)
+
+		return ok
+	case "integer":
+		f, ok := value.(float64)
+
+		return ok && f == math.Trunc(f) && !math.IsInf(f, 0)
+	case "array":
+		_, ok := value.([]any)
+
+		return ok
+	case "object":
+		_, ok := value.(map[string]any)
+
+		return ok
+	default:
+		return false
+	}
+}
+
+// schemaTypeName names the JSON type of a normalized value.
+func schemaTypeName(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+
+		return "number"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+// formatMatches checks the formats the validator asserts.
+func formatMatches(format, s string) bool {
+	var err error
+
+	switch format {
+	case "date-time":
+		_, err = time.Parse(time.RFC3339, s)
+	case "date":
+		_, err = time.Parse(time.DateOnly, s)
+	case "time":
+		_, err = time.Parse("15:04:05Z07:00", s)
+	case "email":
+		var addr *mail.Address
+		if addr, err = mail.ParseAddress(s); err == nil && addr.Address != s {
+			return false
+		}
+	case "uri":
+		var u *url.URL
+		if u, err = url.Parse(s); err == nil && u.Scheme == "" {
+			return false
+		}
+	case "uuid":
+		_, err = uuid.Parse(s)
+	case "ipv4":
+		ip := net.ParseIP(s)
+
+		return ip != nil && ip.To4() != nil && !strings.Contains(s, ":")
+	case "ipv6":
+		ip := net.ParseIP(s)
+
+		return ip != nil && strings.Contains(s, ":")
+	}
+
+	return err == nil
+}
+
+// formatEnum renders allowed values for error messages.
+func formatEnum(values []any) string {
+	parts := make([]string, len(values))
+
+	for i, v := range values {
+		data, err := json.Marshal(v)
+		if err != nil {
+			parts[i] = fmt.Sprint(v)
+		} else {
+			parts[i] = string(data)
+		}
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// joinSchemaPath appends a property name to a violation path.
+func joinSchemaPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+
+	return path + "." + name
+}
+
+// normalizeJSONValue converts a value to the types encoding/json decodes into:
+// nil, bool, float64, string, []any and map[string]any.
+func normalizeJSONValue(value any) any {
+	switch v := value.(type) {
+	case nil, bool, string, float64:
+		return v
+	case json.Number:
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+
+		return v.String()
+	case []any:
+		items := make([]any, len(v))
+		for i, item := range v {
+			items[i] = normalizeJSONValue(item)
+		}
+
+		return items
+	case map[string]any:
+		object := make(map[string]any, len(v))
+		for key, item := range v {
+			object[key] = normalizeJSONValue(item)
+		}
+
+		return object
+	}
+
+	if f, ok := jsonNumber(value); ok {
+		return f
+	}
+
+	// Structs, typed slices and maps are compared in their JSON form
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+
+	var decoded any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return value
+	}
+
+	return decoded
+}
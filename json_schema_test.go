@@ -0,0 +1,400 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/xraph/ai-sdk/llm"
+	"github.com/xraph/ai-sdk/testhelpers"
+)
+
+const orderSchema = `{
+	"type": "object",
+	"properties": {
+		"id": {"type": "string", "pattern": "^ord_[0-9]+$"},
+		"status": {"enum": ["pending", "shipped"]},
+		"email": {"type": "string", "format": "email"},
+		"total": {"type": "number", "exclusiveMinimum": 0, "multipleOf": 0.01},
+		"items": {
+			"type": "array",
+			"minItems": 1,
+			"uniqueItems": true,
+			"items": {"$ref": "#/$defs/item"}
+		},
+		"payment": {
+			"oneOf": [
+				{"type": "object", "properties": {"card": {"type": "string", "minLength": 4}}, "required": ["card"], "additionalProperties": false},
+				{"type": "object", "properties": {"iban": {"type": "string"}}, "required": ["iban"], "additionalProperties": false}
+			]
+		},
+		"note": {"type": ["string", "null"], "maxLength": 10}
+	},
+	"required": ["id", "status", "items"],
+	"additionalProperties": false,
+	"$defs": {
+		"item": {
+			"type": "object",
+			"properties": {
+				"sku": {"type": "string"},
+				"qty": {"type": "integer", "minimum": 1, "maximum": 99}
+			},
+			"required": ["sku", "qty"]
+		}
+	}
+}`
+
+func violationPaths(violations []SchemaViolation) map[string]string {
+	paths := make(map[string]string, len(violations))
+	for _, v := range violations {
+		paths[v.Path] = v.Keyword
+	}
+
+	return paths
+}
+
+func TestJSONSchema_Valid(t *testing.T) {
+	schema := MustCompileJSONSchema(orderSchema)
+
+	err := schema.ValidateJSON([]byte(`{
+		"id": "ord_42",
+		"status": "pending",
+		"email": "ada@example.com",
+		"total": 19.99,
+		"items": [{"sku": "a", "qty": 2}, {"sku": "b", "qty": 1}],
+		"payment": {"card": "4242"},
+		"note": null
+	}`))
+	if err != nil {
+		t.Errorf("expected valid document, got %v", err)
+	}
+}
+
+func TestJSONSchema_Violations(t *testing.T) {
+	schema := MustCompileJSONSchema(orderSchema)
+
+	err := schema.ValidateJSON([]byte(`{
+		"id": "42",
+		"status": "lost",
+		"email": "not-an-email",
+		"total": 0,
+		"items": [{"sku": "a", "qty": 1.5}, {"qty": 100}, {"sku": "a", "qty": 1.5}],
+		"payment": {"card": "4242", "iban": "DE00"},
+		"note": "far too long for this",
+		"coupon": "FREE"
+	}`))
+
+	var validationErr *SchemaValidationError
+	if !errors.As(err, &validationErr) || !errors.Is(err, ErrSchemaValidation) {
+		t.Fatalf("expected SchemaValidationError, got %v", err)
+	}
+
+	expected := map[string]string{
+		"id":           "pattern",
+		"status":       "enum",
+		"email":        "format",
+		"total":        "exclusiveMinimum",
+		"items":        "uniqueItems",
+		"items[0].qty": "type",
+		"items[1].sku": "required",
+		"items[1].qty": "maximum",
+		"payment":      "oneOf",
+		"note":         "maxLength",
+		"coupon":       "additionalProperties",
+	}
+
+	paths := violationPaths(validationErr.Violations)
+	for path, keyword := range expected {
+		if paths[path] != keyword {
+			t.Errorf("expected %s violation at %q, got %q", keyword, path, paths[path])
+		}
+	}
+}
+
+func TestJSONSchema_Combinators(t *testing.T) {
+	schema := MustCompileJSONSchema(map[string]any{
+		"anyOf": []any{
+			map[string]any{"type": "string", "minLength": 3},
+			map[string]any{"type": "integer"},
+		},
+		"not": map[string]any{"const": "nope"},
+	})
+
+	for value, valid := range map[any]bool{"abc": true, 7.0: true, "ab": false, 1.5: false, "nope": false} {
+		if err := schema.Validate(value); (err == nil) != valid {
+			t.Errorf("%v: expected valid=%v, got %v", value, valid, err)
+		}
+	}
+}
+
+func TestJSONSchema_RecursiveRef(t *testing.T) {
+	schema := MustCompileJSONSchema(`{
+		"$defs": {"node": {"type": "object", "properties": {"children": {"type": "array", "items": {"$ref": "#/$defs/node"}}}, "additionalProperties": false}},
+		"$ref": "#/$defs/node"
+	}`)
+
+	violations := schema.Violations(map[string]any{
+		"children": []any{map[string]any{"children": []any{map[string]any{"extra": true}}}},
+	})
+
+	if len(violations) != 1 || violations[0].Path != "children[0].children[0].extra" {
+		t.Errorf("expected nested violation, got %+v", violations)
+	}
+}
+
+func TestJSONSchema_GoValues(t *testing.T) {
+	schema := MustCompileJSONSchema(map[string]any{
+		"type":     "object",
+		"required": []string{"name", "tags"},
+		"properties": map[string]any{
+			"name": map[string]any{"type": "string"},
+			"tags": map[string]any{"type": "array", "items": map[string]any{"type": "string"}, "maxItems": 2},
+			"age":  map[string]any{"type": "integer", "minimum": 0},
+		},
+	})
+
+	type person struct {
+		Name string   `json:"name"`
+		Tags []string `json:"tags"`
+		Age  int      `json:"age"`
+	}
+
+	if err := schema.Validate(person{Name: "Ada", Tags: []string{"a"}, Age: 36}); err != nil {
+		t.Errorf("expected struct to validate, got %v", err)
+	}
+
+	violations := schema.Violations(person{Tags: []string{"a", "b", "c"}, Age: -1})
+	if paths := violationPaths(violations); paths["tags"] != "maxItems" || paths["age"] != "minimum" {
+		t.Errorf("unexpected violations: %+v", violations)
+	}
+}
+
+func TestCompileJSONSchema_Invalid(t *testing.T) {
+	for _, schema := range []string{
+		`{"type": 5}`,
+		`{"pattern": "("}`,
+		`{"minLength": -1}`,
+		`{"oneOf": []}`,
+		`{"$ref": "#/$defs/missing"}`,
+		`{"$ref": "https://example.com/schema.json"}`,
+		`{"properties": {"a": 1}}`,
+	} {
+		if _, err := CompileJSONSchema(schema); !errors.Is(err, ErrInvalidSchema) {
+			t.Errorf("%s: expected ErrInvalidSchema, got %v", schema, err)
+		}
+	}
+}
+
+func TestToolRegistry_ExecuteTool_SchemaViolations(t *testing.T) {
+	minQty := 1.0
+	registry := NewToolRegistry(nil, nil)
+	_ = registry.RegisterTool(&ToolDefinition{
+		Name: "order",
+		Parameters: ToolParameterSchema{
+			Type: "object",
+			Properties: map[string]ToolParameterProperty{
+				"sku": {Type: "string", Pattern: "^[A-Z]{3}$"},
+				"lines": {
+					Type: "array",
+					Items: &ToolParameterProperty{
+						Type:       "object",
+						Properties: map[string]ToolParameterProperty{"qty": {Type: "integer", Minimum: &minQty}},
+						Required:   []string{"qty"},
+					},
+				},
+			},
+			Required: []string{"sku"},
+		},
+		Handler: func(ctx context.Context, params map[string]any) (any, error) {
+			return "ok", nil
+		},
+	})
+
+	_, err := registry.ExecuteTool(context.Background(), "order", "", map[string]any{
+		"sku":   "abc",
+		"lines": []any{map[string]any{"qty": float64(0)}, map[string]any{}},
+		"note":  nil,
+	})
+
+	var argErr *ToolArgumentError
+	if !errors.As(err, &argErr) {
+		t.Fatalf("expected ToolArgumentError, got %v", err)
+	}
+
+	paths := violationPaths(argErr.Fields)
+	if paths["sku"] != "pattern" || paths["lines[0].qty"] != "minimum" || paths["lines[1].qty"] != "required" || len(paths) != 3 {
+		t.Errorf("unexpected violations: %+v", argErr.Fields)
+	}
+
+	badPattern := &ToolDefinition{
+		Name:       "broken",
+		Parameters: ToolParameterSchema{Properties: map[string]ToolParameterProperty{"x": {Type: "string", Pattern: "("}}},
+		Handler:    func(ctx context.Context, params map[string]any) (any, error) { return nil, nil },
+	}
+	if err := registry.RegisterTool(badPattern); !errors.Is(err, ErrInvalidSchema) {
+		t.Errorf("expected ErrInvalidSchema for bad pattern, got %v", err)
+	}
+}
+
+func TestAgent_ToolArgumentFeedback(t *testing.T) {
+	calls := 0
+	mockLLM := testhelpers.NewMockLLM()
+	mockLLM.ChatFunc = func(ctx context.Context, req llm.ChatRequest) (llm.ChatResponse, error) {
+		calls++
+		if calls == 1 {
+			return llm.ChatResponse{Choices: []llm.ChatChoice{{
+				Message: llm.ChatMessage{ToolCalls: []llm.ToolCall{{
+					ID:       "call_1",
+					Type:     "function",
+					Function: &llm.FunctionCall{Name: "lookup", Arguments: `{"city": 42}`},
+				}}},
+				FinishReason: "tool_calls",
+			}}}, nil
+		}
+
+		return llm.ChatResponse{Choices: []llm.ChatChoice{{Message: llm.ChatMessage{Content: "done"}, FinishReason: "stop"}}}, nil
+	}
+
+	handled := false
+	store := &inMemoryStateStore{states: make(map[string]*AgentState)}
+	agent, _ := NewAgent("feedback", "Feedback", mockLLM, store, nil, nil, &AgentOptions{
+		Tools: []Tool{{
+			Name: "lookup",
+			Parameters: map[string]any{
+				"type":       "object",
+				"properties": map[string]any{"city": map[string]any{"type": "string"}},
+				"required":   []string{"city"},
+			},
+			Handler: func(ctx context.Context, args map[string]any) (any, error) {
+				handled = true
+
+				return "sunny", nil
+			},
+		}},
+	})
+
+	response, err := agent.Execute(context.Background(), "Weather?")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if handled {
+		t.Error("expected handler not to run with invalid arguments")
+	}
+
+	if len(response.ToolCalls) != 1 || !errors.Is(response.ToolCalls[0].Error, ErrInvalidToolArguments) {
+		t.Fatalf("expected invalid arguments error, got %+v", response.ToolCalls)
+	}
+
+	feedback := toolErrorContent(response.ToolCalls[0].Error)
+
+	var payload struct {
+		Violations []SchemaViolation `json:"violations"`
+	}
+	if err := json.Unmarshal([]byte(feedback), &payload); err != nil || len(payload.Violations) != 1 || payload.Violations[0].Path != "city" {
+		t.Errorf("expected machine-readable feedback, got %s", feedback)
+	}
+}
+
+func TestAgent_ToolInvalidSchema(t *testing.T) {
+	handled := false
+	agent, _ := NewAgent("invalid", "Invalid", &testhelpers.MockLLMManager{}, &MockStateStore{}, nil, nil, &AgentOptions{
+		Tools: []Tool{{
+			Name:       "lookup",
+			Parameters: map[string]any{"type": 42},
+			Handler: func(ctx context.Context, args map[string]any) (any, error) {
+				handled = true
+
+				return "sunny", nil
+			},
+		}},
+	})
+
+	execution := agent.executeTool(context.Background(), ToolCallResult{Name: "lookup", Arguments: map[string]any{}})
+	if !errors.Is(execution.Error, ErrInvalidSchema) {
+		t.Errorf("expected ErrInvalidSchema, got %v", execution.Error)
+	}
+
+	if handled {
+		t.Error("expected handler not to run with an invalid schema")
+	}
+}
+
+func TestAgent_ToolSchemaCompiledOnce(t *testing.T) {
+	agent, _ := NewAgent("cached", "Cached", &testhelpers.MockLLMManager{}, &MockStateStore{}, nil, nil, &AgentOptions{
+		Tools: []Tool{{
+			Name:       "lookup",
+			Parameters: map[string]any{"type": "object", "required": []any{"city"}},
+		}},
+	})
+
+	if agent.tools[0].schema == nil {
+		t.Fatal("expected the schema to be compiled when the tool is added")
+	}
+
+	registry := NewToolRegistry(nil, nil)
+	_ = registry.RegisterTool(&ToolDefinition{
+		Name:       "forecast",
+		Version:    "1.0.0",
+		Parameters: ToolParameterSchema{Type: "object", Required: []string{"city"}},
+		Handler: func(ctx context.Context, params map[string]any) (any, error) {
+			return "sunny", nil
+		},
+	})
+
+	for _, tool := range registry.AgentTools() {
+		if !tool.selfValidating {
+			t.Errorf("expected registry tool %s to be validated by the registry only", tool.Name)
+		}
+	}
+}
+
+func TestGenerateObjectBuilder_Execute_SchemaFeedback(t *testing.T) {
+	var lastMessages []llm.ChatMessage
+
+	mockLLM := &testhelpers.MockLLMManager{
+		ChatFunc: func(ctx context.Context, request llm.ChatRequest) (llm.ChatResponse, error) {
+			lastMessages = request.Messages
+
+			content := `{"name": "Ada"}`
+			if len(request.Messages) > 2 {
+				content = `{"name": "Ada", "age": 36}`
+			}
+
+			return llm.ChatResponse{Choices: []llm.ChatChoice{{Message: llm.ChatMessage{Role: "assistant", Content: content}}}}, nil
+		},
+	}
+
+	person, err := NewGenerateObjectBuilder[Person](context.Background(), mockLLM, nil, nil).
+		WithPrompt("Extract person").
+		WithRetries(1, 0).
+		Execute()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if person.Age != 36 {
+		t.Errorf("expected corrected object, got %+v", person)
+	}
+
+	feedback := lastMessages[len(lastMessages)-1]
+	if feedback.Role != "user" || !strings.Contains(feedback.Content, `"path":"age"`) {
+		t.Errorf("expected schema feedback message, got %+v", feedback)
+	}
+
+	_, err = NewGenerateObjectBuilder[Person](context.Background(), &testhelpers.MockLLMManager{
+		ChatFunc: func(ctx context.Context, request llm.ChatRequest) (llm.ChatResponse, error) {
+			return llm.ChatResponse{Choices: []llm.ChatChoice{{Message: llm.ChatMessage{Content: `{"name": "Ada"}`}}}}, nil
+		},
+	}, nil, nil).WithPrompt("Extract").WithRetries(0, 0).Execute()
+	if !errors.Is(err, ErrSchemaValidation) {
+		t.Errorf("expected ErrSchemaValidation, got %v", err)
+	}
+
+	if !slices.ContainsFunc(lastMessages, func(m llm.ChatMessage) bool { return m.Role == "assistant" }) {
+		t.Error("expected rejected response to be kept in the conversation")
+	}
+}
@@ -220,6 +220,9 @@ func (m *LLMManager) Chat(ctx context.Context, request ChatRequest) (ChatRespons
 		defer cancel()
 	}
 
+	// Providers reject some tool names; rename them for the request
+	request, toolNames := providerToolNames(request)
+
 	// Execute chat request with retries
 	var (
 		response ChatResponse
@@ -250,6 +253,8 @@ func (m *LLMManager) Chat(ctx context.Context, request ChatRequest) (ChatRespons
 		return ChatResponse{}, lastErr
 	}
 
+	restoreToolNames(response.Choices, toolNames)
+
 	return response, nil
 }
 
@@ -490,6 +495,17 @@ func (m *LLMManager) ChatStream(ctx context.Context, request ChatRequest, handle
 		defer cancel()
 	}
 
+	// Providers reject some tool names; rename them for the request
+	request, toolNames := providerToolNames(request)
+	if toolNames != nil {
+		next := handler
+		handler = func(event ChatStreamEvent) error {
+			restoreToolNames(event.Choices, toolNames)
+
+			return next(event)
+		}
+	}
+
 	// Check if provider supports streaming
 	streamer, ok := provider.(StreamingProvider)
 	if !ok {
@@ -2,6 +2,7 @@ package llm
 
 import (
 	"context"
+	"slices"
 	"testing"
 
 	logger "github.com/xraph/go-utils/log"
@@ -135,3 +136,76 @@ func TestLLMManager_SupportsStreaming_ThreadSafety(t *testing.T) {
 		<-done
 	}
 }
+
+// toolCallingProvider calls the first tool of each request.
+type toolCallingProvider struct {
+	mockBasicProvider
+
+	request ChatRequest
+}
+
+func (p *toolCallingProvider) Chat(ctx context.Context, request ChatRequest) (ChatResponse, error) {
+	p.request = request
+
+	return ChatResponse{Choices: []ChatChoice{{Message: ChatMessage{
+		Role:      "assistant",
+		ToolCalls: []ToolCall{{ID: "call_1", Type: "function", Function: &FunctionCall{Name: request.Tools[0].Function.Name}}},
+	}}}}, nil
+}
+
+func TestLLMManager_Chat_ProviderToolNames(t *testing.T) {
+	provider := &toolCallingProvider{mockBasicProvider: mockBasicProvider{name: "tools"}}
+
+	manager, err := NewLLMManager(LLMManagerConfig{
+		Logger:          logger.NewTestLogger(),
+		Metrics:         metrics.NewMockMetrics(),
+		DefaultProvider: "tools",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create LLM manager: %v", err)
+	}
+
+	_ = manager.RegisterProvider(provider)
+
+	request := ChatRequest{
+		Tools: []Tool{
+			{Type: "function", Function: &FunctionDefinition{Name: "a.b.c"}},
+			{Type: "function", Function: &FunctionDefinition{Name: "a_b.c"}},
+			{Type: "function", Function: &FunctionDefinition{Name: "a_b_c"}},
+		},
+		Messages: []ChatMessage{
+			{Role: "assistant", ToolCalls: []ToolCall{{ID: "call_0", Function: &FunctionCall{Name: "a_b.c"}}}},
+			{Role: "tool", Name: "a_b.c", ToolCallID: "call_0"},
+		},
+	}
+
+	response, err := manager.Chat(context.Background(), request)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var names []string
+	for _, tool := range provider.request.Tools {
+		names = append(names, tool.Function.Name)
+	}
+
+	if want := []string{"a_b_c_2", "a_b_c_3", "a_b_c"}; !slices.Equal(names, want) {
+		t.Errorf("expected provider tool names %v, got %v", want, names)
+	}
+
+	if name := provider.request.Messages[0].ToolCalls[0].Function.Name; name != "a_b_c_3" {
+		t.Errorf("expected earlier tool calls to be renamed, got %q", name)
+	}
+
+	if name := provider.request.Messages[1].Name; name != "a_b_c_3" {
+		t.Errorf("expected tool results to be renamed, got %q", name)
+	}
+
+	if name := request.Tools[0].Function.Name; name != "a.b.c" {
+		t.Errorf("expected the caller's request to be unchanged, got %q", name)
+	}
+
+	if name := response.Choices[0].Message.ToolCalls[0].Function.Name; name != "a.b.c" {
+		t.Errorf("expected the tool call to use the original name, got %q", name)
+	}
+}
@@ -0,0 +1,152 @@
+package llm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ProviderToolName returns name as LLM providers accept it: they require tool
+// names to match ^[a-zA-Z0-9_-]+$, so other characters, such as the dot in a
+// bridged MCP tool name, become "_".
+func ProviderToolName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || r == '-' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+
+		return '_'
+	}, name)
+}
+
+// providerToolNames rewrites the tool names of a request to provider-safe
+// names, including those of earlier tool calls and results in its messages.
+// Names that become equal get a numeric suffix. It returns the provider names
+// mapped to the original ones, or nil if no name changes.
+func providerToolNames(request ChatRequest) (ChatRequest, map[string]string) {
+	renamed := make(map[string]string)
+	used := make(map[string]bool)
+
+	// Names providers accept are kept first, so they never get a suffix
+	for _, tool := range request.Tools {
+		if tool.Function != nil && ProviderToolName(tool.Function.Name) == tool.Function.Name {
+			used[tool.Function.Name] = true
+		}
+	}
+
+	for _, tool := range request.Tools {
+		if tool.Function == nil || used[tool.Function.Name] {
+			continue
+		}
+
+		if _, ok := renamed[tool.Function.Name]; ok {
+			continue
+		}
+
+		base := ProviderToolName(tool.Function.Name)
+
+		name := base
+		for n := 2; used[name]; n++ {
+			name = fmt.Sprintf("%s_%d", base, n)
+		}
+
+		used[name] = true
+		renamed[tool.Function.Name] = name
+	}
+
+	if len(renamed) == 0 {
+		return request, nil
+	}
+
+	rename := func(name string) string {
+		if provider, ok := renamed[name]; ok {
+			return provider
+		}
+
+		return name
+	}
+
+	tools := make([]Tool, len(request.Tools))
+
+	for i, tool := range request.Tools {
+		if tool.Function != nil {
+			function := *tool.Function
+			function.Name = rename(function.Name)
+			tool.Function = &function
+		}
+
+		tools[i] = tool
+	}
+
+	messages := make([]ChatMessage, len(request.Messages))
+
+	for i, msg := range request.Messages {
+		renameMessageToolNames(&msg, rename)
+
+		if msg.Role == "tool" || msg.Role == "function" {
+			msg.Name = rename(msg.Name)
+		}
+
+		messages[i] = msg
+	}
+
+	request.Tools = tools
+	request.Messages = messages
+	request.ToolChoice = rename(request.ToolChoice)
+
+	original := make(map[string]string, len(renamed))
+	for name, provider := range renamed {
+		original[provider] = name
+	}
+
+	return request, original
+}
+
+// restoreToolNames maps the tool calls of provider choices back to the
+// request's tool names.
+func restoreToolNames(choices []ChatChoice, original map[string]string) {
+	if original == nil {
+		return
+	}
+
+	restore := func(name string) string {
+		if name, ok := original[name]; ok {
+			return name
+		}
+
+		return name
+	}
+
+	for i := range choices {
+		renameMessageToolNames(&choices[i].Message, restore)
+
+		if choices[i].Delta != nil {
+			renameMessageToolNames(choices[i].Delta, restore)
+		}
+	}
+}
+
+// renameMessageToolNames renames the tool calls of a message, copying them so
+// the caller's message is left unchanged.
+func renameMessageToolNames(msg *ChatMessage, rename func(string) string) {
+	if len(msg.ToolCalls) > 0 {
+		calls := make([]ToolCall, len(msg.ToolCalls))
+
+		for i, call := range msg.ToolCalls {
+			if call.Function != nil {
+				function := *call.Function
+				function.Name = rename(function.Name)
+				call.Function = &function
+			}
+
+			calls[i] = call
+		}
+
+		msg.ToolCalls = calls
+	}
+
+	if msg.FunctionCall != nil {
+		function := *msg.FunctionCall
+		function.Name = rename(function.Name)
+		msg.FunctionCall = &function
+	}
+}
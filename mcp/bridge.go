@@ -0,0 +1,297 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	sdk "github.com/xraph/ai-sdk"
+)
+
+// ToolOutput is the result of an MCP tool called through an sdk.ToolRegistry.
+type ToolOutput struct {
+	// Text joins the text content of the result.
+	Text      string            `json:"text,omitempty"`
+	Images    []ImageContent    `json:"images,omitempty"`
+	Resources []ResourceContent `json:"resources,omitempty"`
+}
+
+// ImageContent is an image returned by an MCP tool.
+type ImageContent struct {
+	MimeType string `json:"mimeType"`
+	Data     string `json:"data"` // Base64
+}
+
+// SyncTools registers the tools of every connected server in registry as
+// "server.tool" and keeps them current: clients added later are synced,
+// removed clients' tools are unregistered, and a server's tools are reloaded
+// when it sends notifications/tools/list_changed. Agents using the registry
+// (see sdk.AgentOptions.ToolRegistry) see the live tool sets. A tool whose
+// name is already taken by another server's tool is skipped and reported
+// with ErrToolNameCollision.
+func (m *MCPClientManager) SyncTools(ctx context.Context, registry *sdk.ToolRegistry) error {
+	m.mu.Lock()
+	m.syncMu.Lock()
+	m.registry = registry
+	m.syncMu.Unlock()
+
+	clients := make(map[string]*Client, len(m.clients))
+	maps.Copy(clients, m.clients)
+	m.mu.Unlock()
+
+	var errs []error
+
+	for _, name := range slices.Sorted(maps.Keys(clients)) {
+		m.watchTools(name, clients[name])
+
+		if err := m.RefreshTools(ctx, name); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// RefreshTools reloads the tools of one server into the synced registry.
+func (m *MCPClientManager) RefreshTools(ctx context.Context, name string) error {
+	client, ok := m.GetClient(name)
+	if !ok {
+		return fmt.Errorf("mcp server %s not found", name)
+	}
+
+	tools, err := client.ListTools(ctx)
+	if err != nil {
+		return fmt.Errorf("list tools of mcp server %s: %w", name, err)
+	}
+
+	m.syncMu.Lock()
+	defer m.syncMu.Unlock()
+
+	if m.registry == nil {
+		return nil
+	}
+
+	// Re-register every tool so that changed schemas and descriptions apply
+	m.unregisterToolsLocked(name)
+
+	var (
+		registered []string
+		errs       []error
+	)
+
+	for _, tool := range tools {
+		def := m.bridgeTool(name, tool)
+		if owner, ok := m.toolOwnerLocked(def.Name, name, registered); ok {
+			errs = append(errs, fmt.Errorf("%w: tool %s of mcp server %s is named %s, like a tool of mcp server %s",
+				ErrToolNameCollision, tool.Name, name, def.Name, owner))
+
+			continue
+		}
+
+		if err := m.registry.RegisterTool(def); err != nil {
+			errs = append(errs, fmt.Errorf("register tool %s of mcp server %s: %w", tool.Name, name, err))
+
+			continue
+		}
+
+		registered = append(registered, def.Name)
+	}
+
+	m.registered[name] = registered
+
+	return errors.Join(errs...)
+}
+
+// watchTools reloads a client's tools when its server reports a change.
+func (m *MCPClientManager) watchTools(name string, client *Client) {
+	m.syncMu.Lock()
+	defer m.syncMu.Unlock()
+
+	if m.watched[client] {
+		return
+	}
+
+	m.watched[client] = true
+
+	client.HandleNotification(MethodToolListChanged, func(json.RawMessage) {
+		go func() {
+			// The client may have been replaced or removed meanwhile
+			if current, ok := m.GetClient(name); ok && current == client {
+				_ = m.RefreshTools(client.ctx, name)
+			}
+		}()
+	})
+}
+
+// toolOwnerLocked returns the server that registered a tool name, counting
+// the names just registered for the server being refreshed.
+func (m *MCPClientManager) toolOwnerLocked(tool, server string, refreshing []string) (string, bool) {
+	if slices.Contains(refreshing, tool) {
+		return server, true
+	}
+
+	for server, tools := range m.registered {
+		if slices.Contains(tools, tool) {
+			return server, true
+		}
+	}
+
+	return "", false
+}
+
+func (m *MCPClientManager) unregisterTools(name string) {
+	m.syncMu.Lock()
+	defer m.syncMu.Unlock()
+
+	m.unregisterToolsLocked(name)
+}
+
+func (m *MCPClientManager) unregisterToolsLocked(name string) {
+	if m.registry != nil {
+		for _, tool := range m.registered[name] {
+			_ = m.registry.UnregisterTool(tool, "")
+		}
+	}
+
+	delete(m.registered, name)
+}
+
+// bridgeTool converts an MCP tool into a registry tool calling the server's
+// current client.
+func (m *MCPClientManager) bridgeTool(server string, tool Tool) *sdk.ToolDefinition {
+	return &sdk.ToolDefinition{
+		Name:        BridgedToolName(server, tool.Name),
+		Description: tool.Description,
+		Category:    "mcp",
+		Tags:        []string{"mcp", server},
+		Parameters:  toolParameters(tool.InputSchema),
+		Metadata:    map[string]any{"mcp_server": server, "mcp_tool": tool.Name},
+		Handler: func(ctx context.Context, params map[string]any) (any, error) {
+			client, ok := m.GetClient(server)
+			if !ok {
+				return nil, fmt.Errorf("mcp server %s not connected", server)
+			}
+
+			resp, err := client.CallTool(ctx, tool.Name, params)
+			if err != nil {
+				return nil, err
+			}
+
+			output := toolOutput(resp.Content)
+			if resp.IsError {
+				return nil, fmt.Errorf("%w: %s", ErrToolCallFailed, output.Text)
+			}
+
+			return output, nil
+		},
+	}
+}
+
+// BridgedToolName returns the registry name of an MCP tool: the server and
+// tool names joined by ".". The llm.LLMManager renames tools for providers
+// that reject such names; see llm.ProviderToolName.
+func BridgedToolName(server, tool string) string {
+	return server + "." + tool
+}
+
+// toolOutput groups the content of a tool result by kind.
+func toolOutput(content []ToolResultContent) *ToolOutput {
+	output := &ToolOutput{}
+
+	var texts []string
+
+	for _, item := range content {
+		switch item.Type {
+		case "text":
+			texts = append(texts, item.Text)
+		case "image":
+			output.Images = append(output.Images, ImageContent{MimeType: item.MimeType, Data: item.Data})
+		case "resource":
+			if item.Resource != nil {
+				output.Resources = append(output.Resources, *item.Resource)
+			}
+		}
+	}
+
+	output.Text = strings.Join(texts, "\n")
+
+	return output
+}
+
+// toolParameters converts an MCP input schema. Properties the SDK schema
+// cannot express keep their description and loosest type; the server still
+// validates the arguments it receives.
+func toolParameters(inputSchema json.RawMessage) sdk.ToolParameterSchema {
+	// JSON Schema allows properties that are not listed
+	open := true
+	params := sdk.ToolParameterSchema{
+		Type:                 "object",
+		Properties:           map[string]sdk.ToolParameterProperty{},
+		AdditionalProperties: &open,
+	}
+
+	var raw struct {
+		Properties           map[string]json.RawMessage `json:"properties"`
+		Required             []string                   `json:"required"`
+		AdditionalProperties json.RawMessage            `json:"additionalProperties"`
+	}
+
+	if err := json.Unmarshal(inputSchema, &raw); err != nil {
+		return params
+	}
+
+	if string(raw.AdditionalProperties) == "false" {
+		params.AdditionalProperties = nil
+	}
+
+	params.Required = raw.Required
+
+	for name, data := range raw.Properties {
+		params.Properties[name] = schemaProperty(data)
+	}
+
+	if _, err := params.Compile(); err != nil {
+		for name, prop := range params.Properties {
+			params.Properties[name] = sdk.ToolParameterProperty{Type: prop.Type, Description: prop.Description}
+		}
+	}
+
+	return params
+}
+
+// schemaProperty converts one property schema, reducing schemas that do not
+// fit ToolParameterProperty to their description and first non-null type.
+func schemaProperty(data json.RawMessage) sdk.ToolParameterProperty {
+	var prop sdk.ToolParameterProperty
+	if err := json.Unmarshal(data, &prop); err == nil {
+		return prop
+	}
+
+	var loose struct {
+		Type        any    `json:"type"`
+		Description string `json:"description"`
+	}
+
+	_ = json.Unmarshal(data, &loose)
+
+	prop = sdk.ToolParameterProperty{Description: loose.Description}
+
+	switch typ := loose.Type.(type) {
+	case string:
+		prop.Type = typ
+	case []any:
+		for _, t := range typ {
+			if s, ok := t.(string); ok && s != "null" {
+				prop.Type = s
+
+				break
+			}
+		}
+	}
+
+	return prop
+}
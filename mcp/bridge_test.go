@@ -0,0 +1,153 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	sdk "github.com/xraph/ai-sdk"
+)
+
+func TestMCPClientManager_SyncTools(t *testing.T) {
+	httpServer, server := newHTTPTestServer(t, HTTPServerConfig{})
+
+	server.RegisterTool(Tool{
+		Name:        "screenshot",
+		Description: "Capture a page",
+		InputSchema: []byte(`{"type":"object","properties":{"url":{"type":["string","null"],"description":"Page URL"}},"required":["url"]}`),
+	}, func(ctx context.Context, args map[string]any) ([]ToolResultContent, error) {
+		return []ToolResultContent{
+			{Type: "text", Text: "captured"},
+			{Type: "image", MimeType: "image/png", Data: "iVBORw0KGgo="},
+			{Type: "resource", Resource: &ResourceContent{URI: "file:///shot.png", MimeType: "image/png"}},
+		}, nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client, err := ConnectHTTP(ctx, HTTPTransportConfig{URL: httpServer.URL + "/mcp"}, Implementation{Name: "client"})
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+
+	manager := NewMCPClientManager()
+	defer manager.Close()
+
+	manager.AddClient("browser", client)
+
+	registry := sdk.NewToolRegistry(nil, nil)
+	if err := manager.SyncTools(ctx, registry); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+
+	tool, err := registry.GetTool("browser.screenshot", "")
+	if err != nil {
+		t.Fatalf("expected namespaced tool: %v", err)
+	}
+
+	if prop := tool.Parameters.Properties["url"]; prop.Type != "string" || prop.Description != "Page URL" {
+		t.Errorf("unexpected converted property %+v", prop)
+	}
+
+	result, err := registry.ExecuteTool(ctx, "browser.screenshot", "", map[string]any{"url": "https://example.com"})
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+
+	output := result.Result.(*ToolOutput)
+	if output.Text != "captured" || len(output.Images) != 1 || output.Resources[0].URI != "file:///shot.png" {
+		t.Errorf("unexpected output %+v", output)
+	}
+
+	if _, err := registry.ExecuteTool(ctx, "browser.admin", "", map[string]any{}); !errors.Is(err, ErrToolCallFailed) {
+		t.Errorf("expected tool errors to be returned, got %v", err)
+	}
+
+	server.RegisterTool(Tool{Name: "page.title", InputSchema: []byte(`{"type":"object"}`)},
+		func(ctx context.Context, args map[string]any) ([]ToolResultContent, error) {
+			return []ToolResultContent{{Type: "text", Text: "Example"}}, nil
+		})
+
+	if err := manager.RefreshTools(ctx, "browser"); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+
+	result, err = registry.ExecuteTool(ctx, "browser.page.title", "", map[string]any{})
+	if err != nil {
+		t.Fatalf("execute dotted tool: %v", err)
+	}
+
+	if output := result.Result.(*ToolOutput); output.Text != "Example" {
+		t.Errorf("expected the original tool to be called, got %+v", output)
+	}
+
+	server.RegisterTool(Tool{Name: "scroll", InputSchema: []byte(`{"type":"object"}`)},
+		func(ctx context.Context, args map[string]any) ([]ToolResultContent, error) { return nil, nil })
+	_ = server.NotifyToolListChanged()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := registry.GetTool("browser.scroll", ""); err == nil {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatal("expected list_changed to register the new tool")
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	manager.RemoveClient("browser")
+
+	if tools := registry.ListTools(); len(tools) != 0 {
+		t.Errorf("expected removed server's tools to be unregistered, got %d", len(tools))
+	}
+}
+
+func TestMCPClientManager_SyncTools_NameCollision(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	manager := NewMCPClientManager()
+	defer manager.Close()
+
+	// "a" + "b.c" and "a.b" + "c" both bridge to "a.b.c"
+	for server, tool := range map[string]string{"a": "b.c", "a.b": "c"} {
+		httpServer, mcpServer := newHTTPTestServer(t, HTTPServerConfig{})
+		mcpServer.RegisterTool(Tool{Name: tool, InputSchema: []byte(`{"type":"object"}`)},
+			func(ctx context.Context, args map[string]any) ([]ToolResultContent, error) {
+				return []ToolResultContent{{Type: "text", Text: server}}, nil
+			})
+
+		client, err := ConnectHTTP(ctx, HTTPTransportConfig{URL: httpServer.URL + "/mcp"}, Implementation{Name: "client"})
+		if err != nil {
+			t.Fatalf("connect: %v", err)
+		}
+
+		manager.AddClient(server, client)
+	}
+
+	registry := sdk.NewToolRegistry(nil, nil)
+
+	err := manager.SyncTools(ctx, registry)
+	if !errors.Is(err, ErrToolNameCollision) {
+		t.Fatalf("expected ErrToolNameCollision, got %v", err)
+	}
+
+	if !strings.Contains(err.Error(), "tool c of mcp server a.b") {
+		t.Errorf("expected the error to name the skipped tool and its server, got %v", err)
+	}
+
+	result, err := registry.ExecuteTool(ctx, "a.b.c", "", map[string]any{})
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+
+	if output := result.Result.(*ToolOutput); output.Text != "a" {
+		t.Errorf("expected the first server to keep the name, got %+v", output)
+	}
+}
@@ -11,7 +11,9 @@ import (
 	"strings"
 	"sync"
 	"sync/atomic"
-	"time"
+
+	"github.com/google/uuid"
+	sdk "github.com/xraph/ai-sdk"
 )
 
 // Client is an MCP client that connects to MCP servers.
@@ -19,14 +21,24 @@ type Client struct {
 	transport       Transport
 	capabilities    Capability
 	serverInfo      *Implementation
+	serverCaps      *Capability
 	requestID       int64
 	pendingRequests map[int64]chan *Message
 	subscriptions   map[string][]func(ResourceContent)
 	onNotification  func(Method, json.RawMessage)
+	handlers        map[Method][]func(json.RawMessage)
+	sampling        SamplingHandler
+	elicitation     ElicitationHandler
+	roots           []Root
+	progress        map[string]func(ProgressNotification)
+	inflight        map[string]context.CancelCauseFunc
+	updates         []string
+	updatesReady    chan struct{}
 	mu              sync.RWMutex
 	connected       bool
 	ctx             context.Context
 	cancel          context.CancelFunc
+	done            chan struct{}
 }
 
 // Transport represents a communication transport for MCP.
@@ -36,25 +48,58 @@ type Transport interface {
 	Close() error
 }
 
-// ClientConfig configures the MCP client.
+// ClientConfig configures the MCP client. Setting Sampling, Roots or
+// Elicitation declares the matching capability to the server.
 type ClientConfig struct {
 	ClientInfo     Implementation
 	Capabilities   Capability
 	OnNotification func(Method, json.RawMessage)
+
+	// Sampling answers the server's requests to sample the client's LLM,
+	// see NewLLMSamplingHandler.
+	Sampling SamplingHandler
+
+	// Roots are the directories and files exposed to the server. Change them
+	// with SetRoots.
+	Roots []Root
+
+	// Elicitation asks the user for input requested by the server.
+	Elicitation ElicitationHandler
 }
 
 // NewClient creates a new MCP client.
 func NewClient(transport Transport, config ClientConfig) *Client {
 	ctx, cancel := context.WithCancel(context.Background())
 
+	capabilities := config.Capabilities
+	if config.Sampling != nil && capabilities.Sampling == nil {
+		capabilities.Sampling = &SamplingCapability{}
+	}
+
+	if config.Roots != nil && capabilities.Roots == nil {
+		capabilities.Roots = &RootsCapability{ListChanged: true}
+	}
+
+	if config.Elicitation != nil && capabilities.Elicitation == nil {
+		capabilities.Elicitation = &ElicitationCapability{}
+	}
+
 	return &Client{
 		transport:       transport,
-		capabilities:    config.Capabilities,
+		capabilities:    capabilities,
 		pendingRequests: make(map[int64]chan *Message),
 		subscriptions:   make(map[string][]func(ResourceContent)),
 		onNotification:  config.OnNotification,
+		handlers:        make(map[Method][]func(json.RawMessage)),
+		sampling:        config.Sampling,
+		elicitation:     config.Elicitation,
+		roots:           config.Roots,
+		progress:        make(map[string]func(ProgressNotification)),
+		inflight:        make(map[string]context.CancelCauseFunc),
+		updatesReady:    make(chan struct{}, 1),
 		ctx:             ctx,
 		cancel:          cancel,
+		done:            make(chan struct{}),
 	}
 }
 
@@ -62,6 +107,7 @@ func NewClient(transport Transport, config ClientConfig) *Client {
 func (c *Client) Connect(ctx context.Context, clientInfo Implementation) error {
 	// Start message receiver
 	go c.receiveLoop()
+	go c.dispatchResourceUpdates()
 
 	// Send initialize request
 	resp, err := c.request(ctx, MethodInitialize, InitializeRequest{
@@ -80,6 +126,7 @@ func (c *Client) Connect(ctx context.Context, clientInfo Implementation) error {
 
 	c.mu.Lock()
 	c.serverInfo = &initResp.ServerInfo
+	c.serverCaps = &initResp.Capabilities
 	c.connected = true
 	c.mu.Unlock()
 
@@ -104,6 +151,21 @@ func (c *Client) IsConnected() bool {
 	return c.connected
 }
 
+// Done returns a channel that is closed when the connection ends, because
+// the client was closed or the transport failed, e.g. when a stdio server
+// exits.
+func (c *Client) Done() <-chan struct{} {
+	return c.done
+}
+
+// Ping checks that the server answers requests. An error response counts
+// as an answer.
+func (c *Client) Ping(ctx context.Context) error {
+	_, err := c.roundTrip(ctx, MethodPing, nil)
+
+	return err
+}
+
 // ServerInfo returns the connected server's information.
 func (c *Client) ServerInfo() *Implementation {
 	c.mu.RLock()
@@ -112,19 +174,44 @@ func (c *Client) ServerInfo() *Implementation {
 	return c.serverInfo
 }
 
-// ListResources lists available resources.
+// ServerCapabilities returns the capabilities the server declared at
+// initialization.
+func (c *Client) ServerCapabilities() *Capability {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.serverCaps
+}
+
+// HandleNotification registers a handler for server notifications of method.
+// Handlers run on the receive loop and must not block; start a goroutine to
+// make requests from them.
+func (c *Client) HandleNotification(method Method, handler func(params json.RawMessage)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.handlers[method] = append(c.handlers[method], handler)
+}
+
+// ListResources lists available resources, following every page.
 func (c *Client) ListResources(ctx context.Context) ([]Resource, error) {
-	resp, err := c.request(ctx, MethodListResources, ListRequest{})
-	if err != nil {
-		return nil, err
-	}
+	return listAll(ctx, c, MethodListResources, func(result json.RawMessage) ([]Resource, string, error) {
+		var page ListResourcesResponse
+		err := json.Unmarshal(result, &page)
 
-	var result ListResourcesResponse
-	if err := json.Unmarshal(resp.Result, &result); err != nil {
-		return nil, err
-	}
+		return page.Resources, page.NextCursor, err
+	})
+}
 
-	return result.Resources, nil
+// ListResourceTemplates lists available resource templates, following every
+// page.
+func (c *Client) ListResourceTemplates(ctx context.Context) ([]ResourceTemplate, error) {
+	return listAll(ctx, c, MethodListResourceTemplates, func(result json.RawMessage) ([]ResourceTemplate, string, error) {
+		var page ListResourceTemplatesResponse
+		err := json.Unmarshal(result, &page)
+
+		return page.ResourceTemplates, page.NextCursor, err
+	})
 }
 
 // ReadResource reads a resource by URI.
@@ -170,27 +257,60 @@ func (c *Client) UnsubscribeResource(ctx context.Context, uri string) error {
 	return nil
 }
 
-// ListTools lists available tools.
+// ListTools lists available tools, following every page.
 func (c *Client) ListTools(ctx context.Context) ([]Tool, error) {
-	resp, err := c.request(ctx, MethodListTools, ListRequest{})
-	if err != nil {
-		return nil, err
-	}
+	return listAll(ctx, c, MethodListTools, func(result json.RawMessage) ([]Tool, string, error) {
+		var page ListToolsResponse
+		err := json.Unmarshal(result, &page)
 
-	var result ListToolsResponse
-	if err := json.Unmarshal(resp.Result, &result); err != nil {
-		return nil, err
-	}
+		return page.Tools, page.NextCursor, err
+	})
+}
+
+// CallOption configures a tool call.
+type CallOption func(*callOptions)
+
+type callOptions struct {
+	onProgress func(ProgressNotification)
+}
 
-	return result.Tools, nil
+// WithProgress asks the server to report the progress of the call to fn.
+// fn runs on the receive loop and must not block.
+func WithProgress(fn func(ProgressNotification)) CallOption {
+	return func(o *callOptions) {
+		o.onProgress = fn
+	}
 }
 
-// CallTool calls a tool with the given arguments.
-func (c *Client) CallTool(ctx context.Context, name string, args map[string]any) (*CallToolResponse, error) {
-	resp, err := c.request(ctx, MethodCallTool, CallToolRequest{
+// CallTool calls a tool with the given arguments. Cancelling ctx cancels the
+// call on the server.
+func (c *Client) CallTool(ctx context.Context, name string, args map[string]any, opts ...CallOption) (*CallToolResponse, error) {
+	var options callOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	req := CallToolRequest{
 		Name:      name,
 		Arguments: args,
-	})
+	}
+
+	if options.onProgress != nil {
+		token := uuid.NewString()
+		req.Meta = &RequestMeta{ProgressToken: token}
+
+		c.mu.Lock()
+		c.progress[token] = options.onProgress
+		c.mu.Unlock()
+
+		defer func() {
+			c.mu.Lock()
+			delete(c.progress, token)
+			c.mu.Unlock()
+		}()
+	}
+
+	resp, err := c.request(ctx, MethodCallTool, req)
 	if err != nil {
 		return nil, err
 	}
@@ -203,19 +323,14 @@ func (c *Client) CallTool(ctx context.Context, name string, args map[string]any)
 	return &result, nil
 }
 
-// ListPrompts lists available prompts.
+// ListPrompts lists available prompts, following every page.
 func (c *Client) ListPrompts(ctx context.Context) ([]Prompt, error) {
-	resp, err := c.request(ctx, MethodListPrompts, ListRequest{})
-	if err != nil {
-		return nil, err
-	}
-
-	var result ListPromptsResponse
-	if err := json.Unmarshal(resp.Result, &result); err != nil {
-		return nil, err
-	}
+	return listAll(ctx, c, MethodListPrompts, func(result json.RawMessage) ([]Prompt, string, error) {
+		var page ListPromptsResponse
+		err := json.Unmarshal(result, &page)
 
-	return result.Prompts, nil
+		return page.Prompts, page.NextCursor, err
+	})
 }
 
 // GetPrompt retrieves a prompt by name with arguments.
@@ -236,6 +351,22 @@ func (c *Client) GetPrompt(ctx context.Context, name string, args map[string]any
 	return &result, nil
 }
 
+// Complete asks the server for completions of a prompt argument or resource
+// template variable.
+func (c *Client) Complete(ctx context.Context, req CompleteRequest) (*Completion, error) {
+	resp, err := c.request(ctx, MethodComplete, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var result CompleteResponse
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		return nil, err
+	}
+
+	return &result.Completion, nil
+}
+
 // SetLogLevel sets the server's log level.
 func (c *Client) SetLogLevel(ctx context.Context, level LogLevel) error {
 	_, err := c.request(ctx, MethodSetLogLevel, SetLogLevelRequest{Level: level})
@@ -243,8 +374,67 @@ func (c *Client) SetLogLevel(ctx context.Context, level LogLevel) error {
 	return err
 }
 
-// request sends a request and waits for a response.
+// maxListPages bounds the pages listAll requests from a server.
+const maxListPages = 1000
+
+// listAll requests the pages of a list method until the server returns no
+// next cursor. decode returns the items and next cursor of a page. A server
+// that returns a cursor twice, or more than maxListPages pages, is an error.
+func listAll[T any](ctx context.Context, c *Client, method Method, decode func(json.RawMessage) ([]T, string, error)) ([]T, error) {
+	var (
+		items  []T
+		cursor string
+		seen   = make(map[string]bool)
+	)
+
+	for pages := 1; ; pages++ {
+		resp, err := c.request(ctx, method, ListRequest{Cursor: cursor})
+		if err != nil {
+			return nil, err
+		}
+
+		page, next, err := decode(resp.Result)
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, page...)
+
+		if next == "" {
+			return items, nil
+		}
+
+		if seen[next] {
+			return nil, fmt.Errorf("%s: server repeated cursor %q", method, next)
+		}
+
+		if pages >= maxListPages {
+			return nil, fmt.Errorf("%s: server returned more than %d pages", method, maxListPages)
+		}
+
+		seen[next] = true
+
+		cursor = next
+	}
+}
+
+// request sends a request and waits for a successful response.
 func (c *Client) request(ctx context.Context, method Method, params interface{}) (*Message, error) {
+	resp, err := c.roundTrip(ctx, method, params)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Error != nil {
+		return nil, fmt.Errorf("MCP error %d: %s", resp.Error.Code, resp.Error.Message)
+	}
+
+	return resp, nil
+}
+
+// roundTrip sends a request and waits for its response. When ctx ends first,
+// the server is told to cancel the request.
+func (c *Client) roundTrip(ctx context.Context, method Method, params interface{}) (*Message, error) {
 	id := atomic.AddInt64(&c.requestID, 1)
 
 	msg, err := NewMessage(method, id, params)
@@ -273,18 +463,37 @@ func (c *Client) request(ctx context.Context, method Method, params interface{})
 	// Wait for response
 	select {
 	case <-ctx.Done():
+		// The initialize request must not be cancelled
+		if method != MethodInitialize {
+			_ = sendCancelled(c.transport.Send, msg.ID, ctx.Err())
+		}
+
 		return nil, ctx.Err()
-	case resp := <-respChan:
-		if resp.Error != nil {
-			return nil, fmt.Errorf("MCP error %d: %s", resp.Error.Code, resp.Error.Message)
+	case <-c.done:
+		// The response may have arrived just before the transport ended
+		select {
+		case resp := <-respChan:
+			return resp, nil
+		default:
 		}
 
+		return nil, ErrConnectionLost
+	case resp := <-respChan:
 		return resp, nil
 	}
 }
 
-// receiveLoop processes incoming messages.
+// receiveLoop processes incoming messages until the client is closed or the
+// transport ends.
 func (c *Client) receiveLoop() {
+	defer func() {
+		c.mu.Lock()
+		c.connected = false
+		c.mu.Unlock()
+
+		close(c.done)
+	}()
+
 	for {
 		select {
 		case <-c.ctx.Done():
@@ -307,8 +516,16 @@ func (c *Client) receiveLoop() {
 
 // handleMessage processes an incoming message.
 func (c *Client) handleMessage(msg *Message) {
+	// Server requests are answered off the receive loop, which must stay free
+	// to deliver the responses their handlers wait for
+	if isRequest(msg) {
+		go c.handleRequest(msg)
+
+		return
+	}
+
 	// Check if it's a response to a pending request
-	if len(msg.ID) > 0 {
+	if len(msg.ID) > 0 && msg.Method == "" {
 		var id int64
 		if err := json.Unmarshal(msg.ID, &id); err == nil {
 			c.mu.RLock()
@@ -331,15 +548,98 @@ func (c *Client) handleMessage(msg *Message) {
 		c.onNotification(msg.Method, msg.Params)
 	}
 
-	// Handle resource update notifications
+	switch msg.Method {
+	case MethodProgress:
+		c.handleProgress(msg.Params)
+	case MethodCancelled:
+		var notif CancelledNotification
+		if err := json.Unmarshal(msg.Params, &notif); err == nil {
+			c.mu.RLock()
+			cancel, ok := c.inflight[string(notif.RequestID)]
+			c.mu.RUnlock()
+
+			if ok {
+				cancel(ErrRequestCancelled)
+			}
+		}
+	}
+
+	c.mu.RLock()
+	handlers := c.handlers[msg.Method]
+	c.mu.RUnlock()
+
+	for _, handler := range handlers {
+		handler(msg.Params)
+	}
+
+	// Handle resource update notifications off the receive loop, which must
+	// stay free to deliver the read response
 	if msg.Method == MethodResourceUpdated {
 		var notif ResourceUpdatedNotification
 		if err := json.Unmarshal(msg.Params, &notif); err == nil {
-			c.handleResourceUpdate(notif.URI)
+			c.queueResourceUpdate(notif.URI)
+		}
+	}
+}
+
+// queueResourceUpdate hands an updated URI to dispatchResourceUpdates. The
+// queue is unbounded so that the receive loop never waits for handlers.
+func (c *Client) queueResourceUpdate(uri string) {
+	c.mu.Lock()
+	c.updates = append(c.updates, uri)
+	c.mu.Unlock()
+
+	select {
+	case c.updatesReady <- struct{}{}:
+	default:
+	}
+}
+
+// dispatchResourceUpdates handles queued resource updates one at a time, so
+// that subscribers see them in the order the server sent them.
+func (c *Client) dispatchResourceUpdates() {
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-c.done:
+			return
+		case <-c.updatesReady:
+		}
+
+		for {
+			c.mu.Lock()
+			if len(c.updates) == 0 {
+				c.mu.Unlock()
+
+				break
+			}
+
+			uri := c.updates[0]
+			c.updates = c.updates[1:]
+			c.mu.Unlock()
+
+			c.handleResourceUpdate(uri)
 		}
 	}
 }
 
+// handleProgress passes a progress notification to the call that asked for it.
+func (c *Client) handleProgress(params json.RawMessage) {
+	var notif ProgressNotification
+	if err := json.Unmarshal(params, &notif); err != nil {
+		return
+	}
+
+	c.mu.RLock()
+	handler, ok := c.progress[fmt.Sprint(notif.ProgressToken)]
+	c.mu.RUnlock()
+
+	if ok {
+		handler(notif)
+	}
+}
+
 // handleResourceUpdate handles resource update notifications.
 func (c *Client) handleResourceUpdate(uri string) {
 	c.mu.RLock()
@@ -465,23 +765,61 @@ func (t *StdioTransport) Close() error {
 
 // MCPClientManager manages multiple MCP clients.
 type MCPClientManager struct {
-	clients map[string]*Client
-	mu      sync.RWMutex
+	clients     map[string]*Client
+	supervisors map[string]*supervisor
+	mu          sync.RWMutex
+
+	// Tool registry sync, see SyncTools
+	registry   *sdk.ToolRegistry
+	registered map[string][]string
+	watched    map[*Client]bool
+	syncMu     sync.Mutex
 }
 
 // NewMCPClientManager creates a new client manager.
 func NewMCPClientManager() *MCPClientManager {
 	return &MCPClientManager{
-		clients: make(map[string]*Client),
+		clients:     make(map[string]*Client),
+		supervisors: make(map[string]*supervisor),
+		registered:  make(map[string][]string),
+		watched:     make(map[*Client]bool),
 	}
 }
 
-// AddClient adds a client with the given name.
+// AddClient adds a client with the given name, replacing a supervised server
+// of that name. When tools are synced to a registry, the client's tools are
+// registered in the background.
 func (m *MCPClientManager) AddClient(name string, client *Client) {
+	m.addClient(name, client, nil)
+}
+
+// addClient sets the client of name on behalf of owner, the supervisor of
+// name or nil. It reports false when owner no longer supervises name.
+func (m *MCPClientManager) addClient(name string, client *Client, owner *supervisor) bool {
 	m.mu.Lock()
-	defer m.mu.Unlock()
+
+	if current := m.supervisors[name]; current != owner {
+		if owner != nil {
+			m.mu.Unlock()
+
+			return false
+		}
+
+		current.cancel()
+		delete(m.supervisors, name)
+	}
 
 	m.clients[name] = client
+	syncing := m.registry != nil
+	m.mu.Unlock()
+
+	if syncing {
+		m.watchTools(name, client)
+
+		go func() { _ = m.RefreshTools(context.Background(), name) }()
+	}
+
+	return true
 }
 
 // GetClient returns a client by name.
@@ -494,14 +832,25 @@ func (m *MCPClientManager) GetClient(name string) (*Client, bool) {
 	return client, ok
 }
 
-// RemoveClient removes a client by name.
+// RemoveClient removes a client by name. A supervised server is stopped.
 func (m *MCPClientManager) RemoveClient(name string) {
 	m.mu.Lock()
-	defer m.mu.Unlock()
 
-	if client, ok := m.clients[name]; ok {
+	s, supervised := m.supervisors[name]
+	if supervised {
+		// The supervisor closes its own client
+		s.cancel()
+		delete(m.supervisors, name)
+	} else if client, ok := m.clients[name]; ok {
 		_ = client.Close()
-		delete(m.clients, name)
+	}
+
+	delete(m.clients, name)
+	m.unregisterTools(name)
+	m.mu.Unlock()
+
+	if supervised {
+		<-s.done
 	}
 }
 
@@ -518,16 +867,31 @@ func (m *MCPClientManager) ListClients() []string {
 	return names
 }
 
-// Close closes all clients.
+// Close closes all clients and stops supervised servers.
 func (m *MCPClientManager) Close() error {
 	m.mu.Lock()
-	defer m.mu.Unlock()
 
-	for _, client := range m.clients {
-		_ = client.Close()
+	supervisors := m.supervisors
+
+	for name, client := range m.clients {
+		if _, supervised := supervisors[name]; !supervised {
+			_ = client.Close()
+		}
+
+		m.unregisterTools(name)
+	}
+
+	for _, s := range supervisors {
+		s.cancel()
 	}
 
 	m.clients = make(map[string]*Client)
+	m.supervisors = make(map[string]*supervisor)
+	m.mu.Unlock()
+
+	for _, s := range supervisors {
+		<-s.done
+	}
 
 	return nil
 }
@@ -652,11 +1016,9 @@ func NewMCPToolAdapter(client *Client, toolName string) *MCPToolAdapter {
 	}
 }
 
-// Call calls the MCP tool.
+// Call calls the MCP tool. The call is bounded by ctx, and cancelled on the
+// server when ctx ends.
 func (a *MCPToolAdapter) Call(ctx context.Context, args map[string]any) (string, error) {
-	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
-	defer cancel()
-
 	resp, err := a.client.CallTool(ctx, a.toolName, args)
 	if err != nil {
 		return "", err
@@ -0,0 +1,304 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	sdk "github.com/xraph/ai-sdk"
+	"github.com/xraph/ai-sdk/llm"
+)
+
+// SamplingHandler answers a server's sampling request with the client's LLM.
+type SamplingHandler func(ctx context.Context, req *CreateMessageRequest) (*CreateMessageResponse, error)
+
+// ElicitationHandler asks the user for the input a server requests.
+type ElicitationHandler func(ctx context.Context, req *ElicitRequest) (*ElicitResponse, error)
+
+// DefaultSamplingMaxTokens caps the tokens a server can request per sampling
+// request, see WithSamplingMaxTokens.
+const DefaultSamplingMaxTokens = 4096
+
+// SamplingOption configures NewLLMSamplingHandler.
+type SamplingOption func(*samplingOptions)
+
+type samplingOptions struct {
+	maxTokens int
+	approve   func(ctx context.Context, req *CreateMessageRequest) error
+}
+
+// WithSamplingMaxTokens caps the tokens of each sampling request; requests
+// asking for more, or for no limit, get n. n <= 0 removes the cap.
+func WithSamplingMaxTokens(n int) SamplingOption {
+	return func(o *samplingOptions) {
+		o.maxTokens = n
+	}
+}
+
+// WithSamplingApproval calls approve before each sampling request reaches
+// the LLM, e.g. to ask the user. An error rejects the request.
+func WithSamplingApproval(approve func(ctx context.Context, req *CreateMessageRequest) error) SamplingOption {
+	return func(o *samplingOptions) {
+		o.approve = approve
+	}
+}
+
+// NewLLMSamplingHandler returns a SamplingHandler that answers sampling
+// requests with the given provider and model. Only text content and the user
+// and assistant roles are supported; servers contribute system instructions
+// through SystemPrompt only.
+func NewLLMSamplingHandler(llmManager sdk.LLMManager, provider, model string, opts ...SamplingOption) SamplingHandler {
+	options := samplingOptions{maxTokens: DefaultSamplingMaxTokens}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return func(ctx context.Context, req *CreateMessageRequest) (*CreateMessageResponse, error) {
+		messages := make([]llm.ChatMessage, 0, len(req.Messages)+1)
+		if req.SystemPrompt != "" {
+			messages = append(messages, llm.ChatMessage{Role: "system", Content: req.SystemPrompt})
+		}
+
+		for _, msg := range req.Messages {
+			if msg.Role != "user" && msg.Role != "assistant" {
+				return nil, fmt.Errorf("%w: unsupported role %q", ErrSamplingRejected, msg.Role)
+			}
+
+			if msg.Content.Type != "text" {
+				return nil, fmt.Errorf("unsupported sampling content type %q", msg.Content.Type)
+			}
+
+			messages = append(messages, llm.ChatMessage{Role: msg.Role, Content: msg.Content.Text})
+		}
+
+		if options.approve != nil {
+			if err := options.approve(ctx, req); err != nil {
+				return nil, fmt.Errorf("%w: %w", ErrSamplingRejected, err)
+			}
+		}
+
+		chatReq := llm.ChatRequest{
+			Provider:    provider,
+			Model:       model,
+			Messages:    messages,
+			Temperature: req.Temperature,
+			Stop:        req.StopSequences,
+			Metadata:    req.Metadata,
+		}
+
+		maxTokens := req.MaxTokens
+		if options.maxTokens > 0 && (maxTokens <= 0 || maxTokens > options.maxTokens) {
+			maxTokens = options.maxTokens
+		}
+
+		if maxTokens > 0 {
+			chatReq.MaxTokens = &maxTokens
+		}
+
+		resp, err := llmManager.Chat(ctx, chatReq)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(resp.Choices) == 0 {
+			return nil, errors.New("no response from LLM")
+		}
+
+		choice := resp.Choices[0]
+
+		result := &CreateMessageResponse{
+			Role:    "assistant",
+			Content: PromptMessageContent{Type: "text", Text: choice.Message.Content},
+			Model:   resp.Model,
+		}
+
+		if result.Model == "" {
+			result.Model = model
+		}
+
+		switch choice.FinishReason {
+		case "stop":
+			result.StopReason = "endTurn"
+		case "length":
+			result.StopReason = "maxTokens"
+		default:
+			result.StopReason = choice.FinishReason
+		}
+
+		return result, nil
+	}
+}
+
+// CreateMessage asks the client addressed by ctx to sample its LLM. Call it
+// from a tool, resource or prompt handler with the handler's context.
+func (s *Server) CreateMessage(ctx context.Context, req CreateMessageRequest) (*CreateMessageResponse, error) {
+	if s.clientCapabilities(ctx).Sampling == nil {
+		return nil, fmt.Errorf("%w: sampling", ErrCapabilityNotSupported)
+	}
+
+	resp, err := s.request(ctx, MethodCreateMessage, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var result CreateMessageResponse
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// ListRoots returns the roots of the client addressed by ctx.
+func (s *Server) ListRoots(ctx context.Context) ([]Root, error) {
+	if s.clientCapabilities(ctx).Roots == nil {
+		return nil, fmt.Errorf("%w: roots", ErrCapabilityNotSupported)
+	}
+
+	resp, err := s.request(ctx, MethodListRoots, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var result ListRootsResponse
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		return nil, err
+	}
+
+	return result.Roots, nil
+}
+
+// Elicit asks the user of the client addressed by ctx for input matching
+// req.RequestedSchema.
+func (s *Server) Elicit(ctx context.Context, req ElicitRequest) (*ElicitResponse, error) {
+	if s.clientCapabilities(ctx).Elicitation == nil {
+		return nil, fmt.Errorf("%w: elicitation", ErrCapabilityNotSupported)
+	}
+
+	resp, err := s.request(ctx, MethodElicit, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var result ElicitResponse
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// SetRoots replaces the roots exposed to the server and notifies it of the
+// change.
+func (c *Client) SetRoots(roots []Root) error {
+	c.mu.Lock()
+	c.roots = append([]Root(nil), roots...)
+	notify := c.connected && c.capabilities.Roots != nil && c.capabilities.Roots.ListChanged
+	c.mu.Unlock()
+
+	if !notify {
+		return nil
+	}
+
+	msg, err := NewMessage(MethodRootsListChanged, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	return c.transport.Send(msg)
+}
+
+// handleRequest answers a server request, unless the server cancels it.
+func (c *Client) handleRequest(msg *Message) {
+	ctx, cancel := context.WithCancelCause(c.ctx)
+	key := string(msg.ID)
+
+	c.mu.Lock()
+	c.inflight[key] = cancel
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		delete(c.inflight, key)
+		c.mu.Unlock()
+
+		cancel(nil)
+	}()
+
+	var resp *Message
+
+	switch msg.Method {
+	case MethodCreateMessage:
+		resp = c.handleCreateMessage(ctx, msg)
+	case MethodListRoots:
+		resp = c.handleListRoots(msg)
+	case MethodPing:
+		resp, _ = NewResponse(msg.ID, struct{}{})
+	case MethodElicit:
+		resp = c.handleElicit(ctx, msg)
+	default:
+		resp = NewErrorResponse(msg.ID, ErrorCodeMethodNotFound, "method not found")
+	}
+
+	if errors.Is(context.Cause(ctx), ErrRequestCancelled) {
+		return
+	}
+
+	_ = c.transport.Send(resp)
+}
+
+func (c *Client) handleCreateMessage(ctx context.Context, msg *Message) *Message {
+	if c.sampling == nil {
+		return NewErrorResponse(msg.ID, ErrorCodeMethodNotFound, "sampling not supported")
+	}
+
+	var req CreateMessageRequest
+	if err := json.Unmarshal(msg.Params, &req); err != nil {
+		return NewErrorResponse(msg.ID, ErrorCodeInvalidParams, err.Error())
+	}
+
+	result, err := c.sampling(ctx, &req)
+	if err != nil {
+		return NewErrorResponse(msg.ID, ErrorCodeInternal, err.Error())
+	}
+
+	resp, _ := NewResponse(msg.ID, result)
+
+	return resp
+}
+
+func (c *Client) handleListRoots(msg *Message) *Message {
+	c.mu.RLock()
+	supported := c.capabilities.Roots != nil
+	roots := append([]Root{}, c.roots...)
+	c.mu.RUnlock()
+
+	if !supported {
+		return NewErrorResponse(msg.ID, ErrorCodeMethodNotFound, "roots not supported")
+	}
+
+	resp, _ := NewResponse(msg.ID, ListRootsResponse{Roots: roots})
+
+	return resp
+}
+
+func (c *Client) handleElicit(ctx context.Context, msg *Message) *Message {
+	if c.elicitation == nil {
+		return NewErrorResponse(msg.ID, ErrorCodeMethodNotFound, "elicitation not supported")
+	}
+
+	var req ElicitRequest
+	if err := json.Unmarshal(msg.Params, &req); err != nil {
+		return NewErrorResponse(msg.ID, ErrorCodeInvalidParams, err.Error())
+	}
+
+	result, err := c.elicitation(ctx, &req)
+	if err != nil {
+		return NewErrorResponse(msg.ID, ErrorCodeInternal, err.Error())
+	}
+
+	resp, _ := NewResponse(msg.ID, result)
+
+	return resp
+}
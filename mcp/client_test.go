@@ -0,0 +1,389 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestClient_Pagination(t *testing.T) {
+	transport := NewHTTPServerTransport(HTTPServerConfig{})
+	server := NewServer(transport, ServerConfig{Info: Implementation{Name: "test"}, PageSize: 2})
+
+	for _, name := range []string{"e", "d", "c", "b", "a"} {
+		server.RegisterTool(Tool{Name: name, InputSchema: []byte(`{"type":"object"}`)}, nil)
+		server.RegisterPrompt(Prompt{Name: name}, nil)
+		server.RegisterResource(Resource{URI: "test://" + name, Name: name}, nil)
+	}
+
+	go func() { _ = server.Start() }()
+
+	httpServer := httptest.NewServer(transport)
+
+	t.Cleanup(func() {
+		_ = server.Stop()
+
+		httpServer.Close()
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client, err := ConnectHTTP(ctx, HTTPTransportConfig{URL: httpServer.URL}, Implementation{Name: "client"})
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer client.Close()
+
+	want := []string{"a", "b", "c", "d", "e"}
+
+	tools, err := client.ListTools(ctx)
+	if err != nil {
+		t.Fatalf("list tools: %v", err)
+	}
+
+	if names := toolNames(tools); !slices.Equal(names, want) {
+		t.Errorf("unexpected tools %v", names)
+	}
+
+	prompts, err := client.ListPrompts(ctx)
+	if err != nil || len(prompts) != len(want) {
+		t.Errorf("expected %d prompts, got %d: %v", len(want), len(prompts), err)
+	}
+
+	resources, err := client.ListResources(ctx)
+	if err != nil || len(resources) != len(want) {
+		t.Errorf("expected %d resources, got %d: %v", len(want), len(resources), err)
+	}
+
+	if _, err := client.request(ctx, MethodListTools, ListRequest{Cursor: "bogus"}); err == nil {
+		t.Error("expected an invalid cursor to be rejected")
+	}
+}
+
+// cursorTransport answers tools/list requests with pages whose next cursor
+// comes from next, to simulate misbehaving servers.
+type cursorTransport struct {
+	next      func(cursor string) string
+	responses chan *Message
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func newCursorTransport(next func(cursor string) string) *cursorTransport {
+	return &cursorTransport{next: next, responses: make(chan *Message, 1), done: make(chan struct{})}
+}
+
+func (t *cursorTransport) Send(msg *Message) error {
+	if msg.Method != MethodListTools {
+		return nil
+	}
+
+	var req ListRequest
+	_ = json.Unmarshal(msg.Params, &req)
+
+	resp, err := NewResponse(msg.ID, ListToolsResponse{NextCursor: t.next(req.Cursor)})
+	if err != nil {
+		return err
+	}
+
+	t.responses <- resp
+
+	return nil
+}
+
+func (t *cursorTransport) Receive() (*Message, error) {
+	select {
+	case msg := <-t.responses:
+		return msg, nil
+	case <-t.done:
+		return nil, io.EOF
+	}
+}
+
+func (t *cursorTransport) Close() error {
+	t.closeOnce.Do(func() { close(t.done) })
+
+	return nil
+}
+
+func TestClient_PaginationLoop(t *testing.T) {
+	tests := map[string]func(cursor string) string{
+		"cycle": func(cursor string) string {
+			return map[string]string{"": "a", "a": "b", "b": "a"}[cursor]
+		},
+		"endless": func(cursor string) string {
+			n, _ := strconv.Atoi(cursor)
+
+			return strconv.Itoa(n + 1)
+		},
+	}
+
+	for name, next := range tests {
+		t.Run(name, func(t *testing.T) {
+			client := NewClient(newCursorTransport(next), ClientConfig{})
+			go client.receiveLoop()
+			defer client.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			if _, err := client.ListTools(ctx); err == nil || errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("expected the pagination loop to be detected, got %v", err)
+			}
+		})
+	}
+}
+
+func TestClient_ProgressAndCancellation(t *testing.T) {
+	httpServer, server := newHTTPTestServer(t, HTTPServerConfig{})
+
+	cancelled := make(chan error, 1)
+
+	server.RegisterTool(Tool{Name: "slow", InputSchema: []byte(`{"type":"object"}`)}, func(ctx context.Context, args map[string]any) ([]ToolResultContent, error) {
+		for step := 1; step <= 2; step++ {
+			_ = server.NotifyProgress(ctx, float64(step), 2, "working")
+		}
+
+		if args["wait"] == true {
+			<-ctx.Done()
+			cancelled <- context.Cause(ctx)
+
+			return nil, ctx.Err()
+		}
+
+		return []ToolResultContent{{Type: "text", Text: "done"}}, nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client, err := ConnectHTTP(ctx, HTTPTransportConfig{URL: httpServer.URL + "/mcp"}, Implementation{Name: "client"})
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer client.Close()
+
+	var progress []float64
+
+	resp, err := client.CallTool(ctx, "slow", nil, WithProgress(func(p ProgressNotification) {
+		progress = append(progress, p.Progress)
+	}))
+	if err != nil || resp.Content[0].Text != "done" {
+		t.Fatalf("unexpected result %+v: %v", resp, err)
+	}
+
+	if !slices.Equal(progress, []float64{1, 2}) {
+		t.Errorf("expected progress before the result, got %v", progress)
+	}
+
+	callCtx, cancelCall := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancelCall()
+
+	if _, err := client.CallTool(callCtx, "slow", map[string]any{"wait": true}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the call to time out, got %v", err)
+	}
+
+	select {
+	case cause := <-cancelled:
+		if !errors.Is(cause, ErrRequestCancelled) {
+			t.Errorf("expected the server handler to be cancelled by the client, got %v", cause)
+		}
+	case <-ctx.Done():
+		t.Fatal("expected the cancellation to reach the server")
+	}
+
+	if text, _ := callTool(t, client, "echo", map[string]any{"text": "still alive"}); text != "still alive" {
+		t.Errorf("unexpected echo %q", text)
+	}
+}
+
+func TestClient_ResourceTemplatesAndCompletion(t *testing.T) {
+	httpServer, server := newHTTPTestServer(t, HTTPServerConfig{})
+
+	table := func(ctx context.Context, uri string, vars map[string]string) (*ResourceContent, error) {
+		return &ResourceContent{URI: uri, Text: "table " + vars["name"]}, nil
+	}
+
+	file := func(ctx context.Context, uri string, vars map[string]string) (*ResourceContent, error) {
+		return &ResourceContent{URI: uri, Text: "file " + vars["path"]}, nil
+	}
+
+	if err := server.RegisterResourceTemplate(ResourceTemplate{URITemplate: "db://tables/{name}", Name: "table"}, table); err != nil {
+		t.Fatalf("register table template: %v", err)
+	}
+
+	if err := server.RegisterResourceTemplate(ResourceTemplate{URITemplate: "file://{+path}", Name: "file"}, file); err != nil {
+		t.Fatalf("register file template: %v", err)
+	}
+
+	for _, invalid := range []string{"db://{name", "db://{a,b}", "db://{?q}", "db://}"} {
+		if err := server.RegisterResourceTemplate(ResourceTemplate{URITemplate: invalid}, table); err == nil {
+			t.Errorf("expected %q to be rejected", invalid)
+		}
+	}
+
+	server.RegisterResource(Resource{URI: "db://tables/schema", Name: "schema"}, TextResourceHandler("schema", "text/plain"))
+	server.RegisterPrompt(Prompt{Name: "query", Arguments: []PromptArgument{{Name: "table"}}}, nil)
+	server.RegisterCompletion(PromptRef("query"), PrefixCompletion(map[string][]string{"table": {"Users", "usage", "orders"}}))
+	server.RegisterCompletion(ResourceTemplateRef("db://tables/{name}"), func(ctx context.Context, req *CompleteRequest) ([]string, error) {
+		values := make([]string, 150)
+		for i := range values {
+			values[i] = req.Argument.Value
+		}
+
+		return values, nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client, err := ConnectHTTP(ctx, HTTPTransportConfig{URL: httpServer.URL + "/mcp"}, Implementation{Name: "client"})
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer client.Close()
+
+	templates, err := client.ListResourceTemplates(ctx)
+	if err != nil || len(templates) != 2 || templates[0].URITemplate != "db://tables/{name}" {
+		t.Fatalf("unexpected templates %+v: %v", templates, err)
+	}
+
+	reads := map[string]string{
+		"db://tables/users":       "table users",
+		"db://tables/my%20table":  "table my table",
+		"db://tables/schema":      "schema",
+		"file:///etc/hosts":       "file /etc/hosts",
+		"file://docs/a%2Fb/c.txt": "file docs/a/b/c.txt",
+	}
+
+	for uri, want := range reads {
+		content, err := client.ReadResource(ctx, uri)
+		if err != nil || len(content) != 1 || content[0].Text != want {
+			t.Errorf("read %s: expected %q, got %+v: %v", uri, want, content, err)
+		}
+	}
+
+	if _, err := client.ReadResource(ctx, "db://tables/a/b"); err == nil {
+		t.Error("expected a simple variable not to match a slash")
+	}
+
+	completion, err := client.Complete(ctx, CompleteRequest{
+		Ref:      PromptRef("query"),
+		Argument: CompletionArgument{Name: "table", Value: "us"},
+	})
+	if err != nil || !slices.Equal(completion.Values, []string{"Users", "usage"}) {
+		t.Errorf("unexpected prompt completion %+v: %v", completion, err)
+	}
+
+	completion, err = client.Complete(ctx, CompleteRequest{
+		Ref:      ResourceTemplateRef("db://tables/{name}"),
+		Argument: CompletionArgument{Name: "name", Value: "x"},
+	})
+	if err != nil || len(completion.Values) != 100 || completion.Total != 150 || !completion.HasMore {
+		t.Errorf("expected a truncated completion, got %d values, total %d: %v", len(completion.Values), completion.Total, err)
+	}
+
+	completion, err = client.Complete(ctx, CompleteRequest{
+		Ref:      ResourceTemplateRef("file://{+path}"),
+		Argument: CompletionArgument{Name: "path", Value: "/"},
+	})
+	if err != nil || len(completion.Values) != 0 {
+		t.Errorf("expected no completions without a handler, got %+v: %v", completion, err)
+	}
+
+	if _, err := client.Complete(ctx, CompleteRequest{Ref: PromptRef("missing")}); err == nil {
+		t.Error("expected an unknown prompt to be rejected")
+	}
+}
+
+func TestClient_ResourceUpdatesInOrder(t *testing.T) {
+	httpServer, server := newHTTPTestServer(t, HTTPServerConfig{})
+
+	// The first read is slow, so concurrent handling would deliver b first
+	server.RegisterResource(Resource{URI: "test://a", Name: "a"}, func(ctx context.Context, uri string) (*ResourceContent, error) {
+		time.Sleep(100 * time.Millisecond)
+
+		return &ResourceContent{URI: uri, Text: "a"}, nil
+	})
+	server.RegisterResource(Resource{URI: "test://b", Name: "b"}, TextResourceHandler("b", "text/plain"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client, err := ConnectHTTP(ctx, HTTPTransportConfig{URL: httpServer.URL + "/mcp"}, Implementation{Name: "client"})
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer client.Close()
+
+	updates := make(chan string, 4)
+	for _, uri := range []string{"test://a", "test://b"} {
+		if err := client.SubscribeResource(ctx, uri, func(content ResourceContent) { updates <- content.Text }); err != nil {
+			t.Fatalf("subscribe %s: %v", uri, err)
+		}
+	}
+
+	_ = server.NotifyResourceUpdated("test://a")
+	_ = server.NotifyResourceUpdated("test://b")
+
+	var got []string
+	for len(got) < 2 {
+		select {
+		case text := <-updates:
+			got = append(got, text)
+		case <-ctx.Done():
+			t.Fatalf("timed out, got %v", got)
+		}
+	}
+
+	if !slices.Equal(got, []string{"a", "b"}) {
+		t.Errorf("expected updates in the order sent, got %v", got)
+	}
+}
+
+func TestURITemplate_Expand(t *testing.T) {
+	tests := []struct {
+		template string
+		vars     map[string]string
+		want     string
+	}{
+		{"db://tables/{name}", map[string]string{"name": "my table"}, "db://tables/my%20table"},
+		{"file://{+path}", map[string]string{"path": "/a/b c"}, "file:///a/b%20c"},
+		{"doc://page{#section}", map[string]string{"section": "intro"}, "doc://page#intro"},
+		{"doc://page{#section}", nil, "doc://page"},
+	}
+
+	for _, tt := range tests {
+		template, err := ParseURITemplate(tt.template)
+		if err != nil {
+			t.Fatalf("parse %q: %v", tt.template, err)
+		}
+
+		got := template.Expand(tt.vars)
+		if got != tt.want {
+			t.Errorf("expand %q: expected %q, got %q", tt.template, tt.want, got)
+		}
+
+		vars, ok := template.Match(got)
+		if !ok || vars[template.Variables()[0]] != tt.vars[template.Variables()[0]] {
+			t.Errorf("match %q: unexpected %v", got, vars)
+		}
+	}
+}
+
+func toolNames(tools []Tool) []string {
+	names := make([]string, 0, len(tools))
+	for _, tool := range tools {
+		names = append(names, tool.Name)
+	}
+
+	return names
+}
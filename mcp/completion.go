@@ -0,0 +1,106 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// maxCompletionValues is the maximum number of values in a completion.
+const maxCompletionValues = 100
+
+// CompletionHandler suggests values for req.Argument, given its partial
+// value and the arguments already resolved in req.Context.
+type CompletionHandler func(ctx context.Context, req *CompleteRequest) ([]string, error)
+
+// PromptRef returns the completion reference of a prompt.
+func PromptRef(name string) CompletionReference {
+	return CompletionReference{Type: CompletionRefPrompt, Name: name}
+}
+
+// ResourceTemplateRef returns the completion reference of a resource
+// template.
+func ResourceTemplateRef(uriTemplate string) CompletionReference {
+	return CompletionReference{Type: CompletionRefResource, URI: uriTemplate}
+}
+
+// RegisterCompletion registers the completion handler of a prompt or
+// resource template, see PromptRef and ResourceTemplateRef.
+func (s *Server) RegisterCompletion(ref CompletionReference, handler CompletionHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.completions[ref] = handler
+}
+
+// PrefixCompletion returns a handler completing each argument from a fixed
+// list of values, keeping those that start with the partial value, ignoring
+// case.
+func PrefixCompletion(values map[string][]string) CompletionHandler {
+	return func(ctx context.Context, req *CompleteRequest) ([]string, error) {
+		prefix := strings.ToLower(req.Argument.Value)
+
+		var matches []string
+
+		for _, value := range values[req.Argument.Name] {
+			if strings.HasPrefix(strings.ToLower(value), prefix) {
+				matches = append(matches, value)
+			}
+		}
+
+		return matches, nil
+	}
+}
+
+func (s *Server) handleComplete(ctx context.Context, msg *Message) *Message {
+	var req CompleteRequest
+	if err := json.Unmarshal(msg.Params, &req); err != nil {
+		return NewErrorResponse(msg.ID, ErrorCodeInvalidParams, err.Error())
+	}
+
+	ref := CompletionReference{Type: req.Ref.Type}
+
+	switch req.Ref.Type {
+	case CompletionRefPrompt:
+		ref.Name = req.Ref.Name
+		if !s.prompts.Has(ref.Name) {
+			return NewErrorResponse(msg.ID, ErrorCodeInvalidParams, fmt.Sprintf("prompt not found: %s", ref.Name))
+		}
+	case CompletionRefResource:
+		ref.URI = req.Ref.URI
+		if !s.resources.HasTemplate(ref.URI) {
+			return NewErrorResponse(msg.ID, ErrorCodeInvalidParams, fmt.Sprintf("resource template not found: %s", ref.URI))
+		}
+	default:
+		return NewErrorResponse(msg.ID, ErrorCodeInvalidParams, fmt.Sprintf("unknown completion reference %q", req.Ref.Type))
+	}
+
+	s.mu.RLock()
+	handler, ok := s.completions[ref]
+	s.mu.RUnlock()
+
+	// Arguments without a handler have no suggestions
+	values := []string{}
+
+	if ok {
+		suggested, err := handler(ctx, &req)
+		if err != nil {
+			return NewErrorResponse(msg.ID, ErrorCodeInternal, err.Error())
+		}
+
+		if suggested != nil {
+			values = suggested
+		}
+	}
+
+	completion := Completion{Values: values, Total: len(values)}
+	if len(values) > maxCompletionValues {
+		completion.Values = values[:maxCompletionValues]
+		completion.HasMore = true
+	}
+
+	resp, _ := NewResponse(msg.ID, CompleteResponse{Completion: completion})
+
+	return resp
+}
@@ -0,0 +1,61 @@
+package mcp
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Transport errors.
+var (
+	// ErrSessionNotFound is returned when the server no longer knows an HTTP
+	// session; the client must initialize a new one.
+	ErrSessionNotFound = errors.New("mcp session not found")
+
+	// ErrTransportClosed is returned when sending on a closed transport.
+	ErrTransportClosed = errors.New("mcp transport closed")
+
+	// ErrConnectionLost is returned for requests pending when a client's
+	// transport stops delivering messages, e.g. because the server exited.
+	ErrConnectionLost = errors.New("mcp connection lost")
+
+	// ErrRequestCancelled is the cause of a handler's context when the peer
+	// cancels the request, see context.Cause.
+	ErrRequestCancelled = errors.New("mcp request cancelled")
+)
+
+// Tool errors.
+var (
+	// ErrToolCallFailed is returned by bridged tools when the server reports
+	// the call as an error.
+	ErrToolCallFailed = errors.New("mcp tool call failed")
+
+	// ErrToolNameCollision is returned when syncing a tool whose registry
+	// name is already used by a tool of another, or the same, server.
+	ErrToolNameCollision = errors.New("mcp tool name collision")
+)
+
+// Client feature errors.
+var (
+	// ErrCapabilityNotSupported is returned when a server asks a client for a
+	// feature the client did not declare at initialization.
+	ErrCapabilityNotSupported = errors.New("mcp capability not supported by client")
+
+	// ErrSamplingRejected is returned to a server whose sampling request
+	// was refused, by the approval callback or for an unsupported role.
+	ErrSamplingRejected = errors.New("mcp sampling request rejected")
+)
+
+// HTTPStatusError reports an unexpected HTTP status from an MCP server.
+type HTTPStatusError struct {
+	StatusCode int
+	Status     string
+	Body       string
+}
+
+func (e *HTTPStatusError) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("mcp http request failed: %s", e.Status)
+	}
+
+	return fmt.Sprintf("mcp http request failed: %s: %s", e.Status, e.Body)
+}
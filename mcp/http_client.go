@@ -0,0 +1,715 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HTTPTransportConfig configures the HTTP client transports.
+type HTTPTransportConfig struct {
+	// URL is the MCP endpoint, or the event stream URL for the legacy
+	// HTTP+SSE transport.
+	URL string
+
+	// Client performs the requests. It must not set a timeout shorter than
+	// the lifetime of event streams. Defaults to a new http.Client.
+	Client *http.Client
+
+	// Headers are added to every request.
+	Headers map[string]string
+
+	// Auth is called for every request, e.g. to set a fresh bearer token.
+	Auth func(r *http.Request) error
+
+	// ReconnectDelay is the base delay before reconnecting a broken stream.
+	ReconnectDelay time.Duration
+
+	// MaxReconnects limits consecutive reconnection attempts of a stream.
+	MaxReconnects int
+}
+
+func (c HTTPTransportConfig) withDefaults() HTTPTransportConfig {
+	if c.Client == nil {
+		c.Client = &http.Client{}
+	}
+
+	if c.ReconnectDelay <= 0 {
+		c.ReconnectDelay = time.Second
+	}
+
+	if c.MaxReconnects <= 0 {
+		c.MaxReconnects = 5
+	}
+
+	return c
+}
+
+// newRequest builds a request carrying the configured headers and auth.
+func (c HTTPTransportConfig) newRequest(ctx context.Context, method, target string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, value := range c.Headers {
+		req.Header.Set(key, value)
+	}
+
+	if c.Auth != nil {
+		if err := c.Auth(req); err != nil {
+			return nil, fmt.Errorf("authenticate mcp request: %w", err)
+		}
+	}
+
+	return req, nil
+}
+
+// StreamableHTTPTransport implements Transport over the MCP Streamable HTTP
+// transport. Responses arrive as JSON or event streams; broken streams are
+// resumed with Last-Event-ID, and server-initiated messages are read from a
+// standalone stream opened once the session is established.
+type StreamableHTTPTransport struct {
+	config   HTTPTransportConfig
+	incoming chan *Message
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+
+	mu        sync.Mutex
+	sessionID string
+	listening bool
+	awaiting  map[string]bool // requests answered on a stream, false once cancelled
+	closeOnce sync.Once
+}
+
+// NewStreamableHTTPTransport creates a Streamable HTTP client transport.
+func NewStreamableHTTPTransport(config HTTPTransportConfig) *StreamableHTTPTransport {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &StreamableHTTPTransport{
+		config:   config.withDefaults(),
+		incoming: make(chan *Message, 64),
+		awaiting: make(map[string]bool),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+// SessionID returns the session assigned by the server, if any.
+func (t *StreamableHTTPTransport) SessionID() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.sessionID
+}
+
+// Send implements Transport.
+func (t *StreamableHTTPTransport) Send(msg *Message) error {
+	if t.ctx.Err() != nil {
+		return ErrTransportClosed
+	}
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	if msg.Method == MethodCancelled {
+		var notif CancelledNotification
+		if json.Unmarshal(msg.Params, &notif) == nil {
+			t.mu.Lock()
+			if _, ok := t.awaiting[string(notif.RequestID)]; ok {
+				t.awaiting[string(notif.RequestID)] = false
+			}
+			t.mu.Unlock()
+		}
+	}
+
+	req, err := t.newRequest(http.MethodPost, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+
+	resp, err := t.config.Client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	if sessionID := resp.Header.Get(HeaderSessionID); sessionID != "" {
+		t.mu.Lock()
+		t.sessionID = sessionID
+		t.mu.Unlock()
+	}
+
+	if err := t.checkStatus(req, resp); err != nil {
+		return err
+	}
+
+	defer t.listen()
+
+	if resp.StatusCode == http.StatusAccepted || resp.StatusCode == http.StatusNoContent {
+		_ = resp.Body.Close()
+
+		return nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType == "text/event-stream" {
+		var wait json.RawMessage
+		if isRequest(msg) {
+			wait = msg.ID
+
+			t.mu.Lock()
+			t.awaiting[string(wait)] = true
+			t.mu.Unlock()
+		}
+
+		t.wg.Add(1)
+
+		go t.readResponseStream(resp.Body, wait)
+
+		return nil
+	}
+
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	msgs, err := decodeMessages(body)
+	if err != nil {
+		return err
+	}
+
+	for _, msg := range msgs {
+		t.push(msg)
+	}
+
+	return nil
+}
+
+// Receive implements Transport. It returns io.EOF once the transport is closed.
+func (t *StreamableHTTPTransport) Receive() (*Message, error) {
+	select {
+	case msg := <-t.incoming:
+		return msg, nil
+	case <-t.ctx.Done():
+		return nil, io.EOF
+	}
+}
+
+// Close implements Transport. It ends the server session and open streams.
+func (t *StreamableHTTPTransport) Close() error {
+	t.closeOnce.Do(func() {
+		if sessionID := t.SessionID(); sessionID != "" {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+
+			req, err := t.config.newRequest(ctx, http.MethodDelete, t.config.URL, nil)
+			if err == nil {
+				req.Header.Set(HeaderSessionID, sessionID)
+
+				if resp, err := t.config.Client.Do(req); err == nil {
+					_ = resp.Body.Close()
+				}
+			}
+
+			cancel()
+		}
+
+		t.cancel()
+		t.wg.Wait()
+	})
+
+	return nil
+}
+
+func (t *StreamableHTTPTransport) newRequest(method string, body io.Reader) (*http.Request, error) {
+	req, err := t.config.newRequest(t.ctx, method, t.config.URL, body)
+	if err != nil {
+		return nil, err
+	}
+
+	if sessionID := t.SessionID(); sessionID != "" {
+		req.Header.Set(HeaderSessionID, sessionID)
+	}
+
+	return req, nil
+}
+
+// checkStatus closes the body of failed responses and reports the failure.
+// A 404 for a request that carried a session means the session has ended.
+func (t *StreamableHTTPTransport) checkStatus(req *http.Request, resp *http.Response) error {
+	if resp.StatusCode < 300 {
+		return nil
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound && req.Header.Get(HeaderSessionID) != "" {
+		t.mu.Lock()
+		t.sessionID = ""
+		t.mu.Unlock()
+
+		return ErrSessionNotFound
+	}
+
+	return newHTTPStatusError(resp)
+}
+
+// listen opens the standalone event stream once a session exists.
+func (t *StreamableHTTPTransport) listen() {
+	t.mu.Lock()
+	start := t.sessionID != "" && !t.listening && t.ctx.Err() == nil
+	t.listening = t.listening || start
+	t.mu.Unlock()
+
+	if !start {
+		return
+	}
+
+	t.wg.Add(1)
+
+	go func() {
+		defer t.wg.Done()
+
+		var (
+			lastEventID string
+			failures    int
+		)
+
+		for t.ctx.Err() == nil {
+			body, err := t.openStream(lastEventID)
+			if err != nil {
+				var statusErr *HTTPStatusError
+				if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusMethodNotAllowed ||
+					errors.Is(err, ErrSessionNotFound) {
+					return
+				}
+
+				failures++
+				if failures > t.config.MaxReconnects || !t.sleep(time.Duration(failures)*t.config.ReconnectDelay) {
+					return
+				}
+
+				continue
+			}
+
+			failures = 0
+
+			t.consume(body, nil, &lastEventID)
+			_ = body.Close()
+		}
+	}()
+}
+
+// readResponseStream reads the event stream answering a POST until the
+// response to wait arrives, resuming the stream if it breaks. Streams of
+// cancelled requests are not resumed.
+func (t *StreamableHTTPTransport) readResponseStream(body io.ReadCloser, wait json.RawMessage) {
+	defer t.wg.Done()
+
+	defer func() {
+		t.mu.Lock()
+		delete(t.awaiting, string(wait))
+		t.mu.Unlock()
+	}()
+
+	var lastEventID string
+
+	for {
+		done := t.consume(body, wait, &lastEventID)
+		_ = body.Close()
+
+		t.mu.Lock()
+		awaiting := t.awaiting[string(wait)]
+		t.mu.Unlock()
+
+		if done || !awaiting || lastEventID == "" {
+			return
+		}
+
+		var err error
+
+		for attempt := 1; ; attempt++ {
+			if attempt > t.config.MaxReconnects || !t.sleep(time.Duration(attempt)*t.config.ReconnectDelay) {
+				return
+			}
+
+			body, err = t.openStream(lastEventID)
+			if err == nil {
+				break
+			}
+
+			if errors.Is(err, ErrSessionNotFound) {
+				return
+			}
+		}
+	}
+}
+
+// openStream opens a GET event stream, resuming after lastEventID if set.
+func (t *StreamableHTTPTransport) openStream(lastEventID string) (io.ReadCloser, error) {
+	req, err := t.newRequest(http.MethodGet, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", "text/event-stream")
+
+	if lastEventID != "" {
+		req.Header.Set(headerLastEventID, lastEventID)
+	}
+
+	resp, err := t.config.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := t.checkStatus(req, resp); err != nil {
+		return nil, err
+	}
+
+	return resp.Body, nil
+}
+
+// consume pushes the messages of an event stream, recording the last event
+// ID. It reports whether the response to wait was received.
+func (t *StreamableHTTPTransport) consume(body io.Reader, wait json.RawMessage, lastEventID *string) bool {
+	reader := bufio.NewReader(body)
+
+	for {
+		event, err := readSSEEvent(reader)
+		if err != nil {
+			return false
+		}
+
+		if event.id != "" {
+			*lastEventID = event.id
+		}
+
+		if event.event != "" && event.event != "message" {
+			continue
+		}
+
+		var msg Message
+		if err := json.Unmarshal([]byte(event.data), &msg); err != nil {
+			continue
+		}
+
+		t.push(&msg)
+
+		if wait != nil && msg.Method == "" && bytes.Equal(msg.ID, wait) {
+			return true
+		}
+	}
+}
+
+func (t *StreamableHTTPTransport) push(msg *Message) {
+	select {
+	case t.incoming <- msg:
+	case <-t.ctx.Done():
+	}
+}
+
+// sleep waits for d, reporting false if the transport closes first.
+func (t *StreamableHTTPTransport) sleep(d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-t.ctx.Done():
+		return false
+	}
+}
+
+// SSETransport implements Transport over the legacy HTTP+SSE transport of
+// protocol version 2024-11-05: messages arrive on one event stream and are
+// sent to the endpoint it announces.
+type SSETransport struct {
+	config   HTTPTransportConfig
+	endpoint string
+	incoming chan *Message
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+// NewSSETransport opens the event stream at config.URL and waits for the
+// server to announce its message endpoint.
+func NewSSETransport(ctx context.Context, config HTTPTransportConfig) (*SSETransport, error) {
+	streamCtx, cancel := context.WithCancel(context.Background())
+
+	t := &SSETransport{
+		config:   config.withDefaults(),
+		incoming: make(chan *Message, 64),
+		ctx:      streamCtx,
+		cancel:   cancel,
+	}
+
+	req, err := t.config.newRequest(streamCtx, http.MethodGet, t.config.URL, nil)
+	if err != nil {
+		cancel()
+
+		return nil, err
+	}
+
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := t.config.Client.Do(req)
+	if err != nil {
+		cancel()
+
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+
+		cancel()
+
+		return nil, newHTTPStatusError(resp)
+	}
+
+	endpoint := make(chan string, 1)
+
+	t.wg.Add(1)
+
+	go t.read(resp.Body, endpoint)
+
+	select {
+	case ref, ok := <-endpoint:
+		if !ok {
+			_ = t.Close()
+
+			return nil, errors.New("mcp event stream ended before announcing an endpoint")
+		}
+
+		base, err := url.Parse(t.config.URL)
+		if err != nil {
+			_ = t.Close()
+
+			return nil, err
+		}
+
+		resolved, err := base.Parse(ref)
+		if err != nil {
+			_ = t.Close()
+
+			return nil, fmt.Errorf("invalid mcp endpoint %q: %w", ref, err)
+		}
+
+		// Messages carry the configured headers, credentials included, so
+		// they must not leave the server's origin.
+		if !strings.EqualFold(resolved.Scheme, base.Scheme) || !strings.EqualFold(resolved.Host, base.Host) {
+			_ = t.Close()
+
+			return nil, fmt.Errorf("mcp endpoint %q is not on the origin of %s", ref, t.config.URL)
+		}
+
+		t.endpoint = resolved.String()
+
+		return t, nil
+	case <-ctx.Done():
+		_ = t.Close()
+
+		return nil, ctx.Err()
+	}
+}
+
+// Send implements Transport.
+func (t *SSETransport) Send(msg *Message) error {
+	if t.ctx.Err() != nil {
+		return ErrTransportClosed
+	}
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	req, err := t.config.newRequest(t.ctx, http.MethodPost, t.endpoint, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := t.config.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return newHTTPStatusError(resp)
+	}
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	return nil
+}
+
+// Receive implements Transport. It returns io.EOF once the stream has ended.
+func (t *SSETransport) Receive() (*Message, error) {
+	select {
+	case msg := <-t.incoming:
+		return msg, nil
+	case <-t.ctx.Done():
+		return nil, io.EOF
+	}
+}
+
+// Close implements Transport.
+func (t *SSETransport) Close() error {
+	t.cancel()
+	t.wg.Wait()
+
+	return nil
+}
+
+// read delivers the endpoint event and then the messages of the stream.
+func (t *SSETransport) read(body io.ReadCloser, endpoint chan<- string) {
+	defer t.wg.Done()
+	defer body.Close()
+	defer t.cancel()
+
+	announced := false
+	defer func() {
+		if !announced {
+			close(endpoint)
+		}
+	}()
+
+	reader := bufio.NewReader(body)
+
+	for {
+		event, err := readSSEEvent(reader)
+		if err != nil {
+			return
+		}
+
+		switch event.event {
+		case "endpoint":
+			if !announced {
+				announced = true
+				endpoint <- strings.TrimSpace(event.data)
+			}
+		case "", "message":
+			var msg Message
+			if err := json.Unmarshal([]byte(event.data), &msg); err != nil {
+				continue
+			}
+
+			select {
+			case t.incoming <- &msg:
+			case <-t.ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// ConnectHTTP connects to an MCP server over Streamable HTTP, falling back to
+// the legacy HTTP+SSE transport when the server rejects the initialize POST.
+func ConnectHTTP(ctx context.Context, config HTTPTransportConfig, clientInfo Implementation) (*Client, error) {
+	return connectHTTP(ctx, config, ClientConfig{ClientInfo: clientInfo})
+}
+
+func connectHTTP(ctx context.Context, config HTTPTransportConfig, clientConfig ClientConfig) (*Client, error) {
+	client := NewClient(NewStreamableHTTPTransport(config), clientConfig)
+
+	err := client.Connect(ctx, clientConfig.ClientInfo)
+	if err == nil {
+		return client, nil
+	}
+
+	_ = client.Close()
+
+	var statusErr *HTTPStatusError
+	if !errors.As(err, &statusErr) {
+		return nil, err
+	}
+
+	switch statusErr.StatusCode {
+	case http.StatusBadRequest, http.StatusNotFound, http.StatusMethodNotAllowed:
+	default:
+		return nil, err
+	}
+
+	client, sseErr := connectSSE(ctx, config, clientConfig)
+	if sseErr != nil {
+		return nil, fmt.Errorf("%w (legacy SSE fallback: %w)", err, sseErr)
+	}
+
+	return client, nil
+}
+
+// connectSSE connects to an MCP server over the legacy HTTP+SSE transport.
+func connectSSE(ctx context.Context, config HTTPTransportConfig, clientConfig ClientConfig) (*Client, error) {
+	transport, err := NewSSETransport(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	client := NewClient(transport, clientConfig)
+
+	if err := client.Connect(ctx, clientConfig.ClientInfo); err != nil {
+		_ = client.Close()
+
+		return nil, err
+	}
+
+	return client, nil
+}
+
+// decodeMessages decodes a JSON body holding one message or a batch.
+func decodeMessages(body []byte) ([]*Message, error) {
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 {
+		return nil, nil
+	}
+
+	if body[0] == '[' {
+		var msgs []*Message
+		if err := json.Unmarshal(body, &msgs); err != nil {
+			return nil, err
+		}
+
+		return msgs, nil
+	}
+
+	var msg Message
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return nil, err
+	}
+
+	return []*Message{&msg}, nil
+}
+
+func newHTTPStatusError(resp *http.Response) *HTTPStatusError {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+
+	return &HTTPStatusError{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Body:       strings.TrimSpace(string(body)),
+	}
+}
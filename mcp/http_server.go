@@ -0,0 +1,1092 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	sdk "github.com/xraph/ai-sdk"
+)
+
+// HeaderSessionID carries the session ID of the Streamable HTTP transport.
+const HeaderSessionID = "Mcp-Session-Id"
+
+const headerLastEventID = "Last-Event-ID"
+
+// Streams of a session. Each POST that carries requests gets its own stream;
+// these two receive server-initiated messages.
+const (
+	streamGet    = "get"
+	streamLegacy = "sse"
+)
+
+// HTTPServerConfig configures HTTPServerTransport.
+type HTTPServerConfig struct {
+	// Authenticate resolves the caller of every request, typically from its
+	// Authorization header. An error rejects the request with 401. The
+	// principal is bound to the session and checked for tool scopes.
+	Authenticate func(r *http.Request) (*sdk.Principal, error)
+
+	// AllowedOrigins lists the Origin header values accepted from browsers.
+	// Requests without an Origin header are always accepted; an empty list
+	// accepts the server's own origin and loopback origins only.
+	AllowedOrigins []string
+
+	// SessionTimeout ends sessions that have been idle for this long.
+	SessionTimeout time.Duration
+
+	// EventHistory is the number of events kept per session for clients that
+	// resume a stream with Last-Event-ID.
+	EventHistory int
+
+	// JSONResponse answers POST requests with a JSON body instead of an
+	// event stream.
+	JSONResponse bool
+
+	// MaxBodyBytes limits the size of a POST body.
+	MaxBodyBytes int64
+
+	// KeepAlive is the interval of comment lines sent on idle event streams.
+	KeepAlive time.Duration
+}
+
+// DefaultHTTPServerConfig returns the default HTTP server transport config.
+func DefaultHTTPServerConfig() HTTPServerConfig {
+	return HTTPServerConfig{
+		SessionTimeout: 30 * time.Minute,
+		EventHistory:   256,
+		MaxBodyBytes:   4 << 20,
+		KeepAlive:      25 * time.Second,
+	}
+}
+
+// HTTPServerTransport implements ServerTransport over the MCP Streamable HTTP
+// transport. It is an http.Handler serving the MCP endpoint, and SSEHandler
+// serves the legacy HTTP+SSE transport from the same sessions.
+//
+//	transport := mcp.NewHTTPServerTransport(mcp.DefaultHTTPServerConfig())
+//	server := mcp.NewServer(transport, config)
+//	go server.Start()
+//	mux.Handle("/mcp", transport)
+type HTTPServerTransport struct {
+	config   HTTPServerConfig
+	incoming chan incomingMessage
+	nextID   atomic.Int64
+
+	mu       sync.Mutex
+	sessions map[string]*httpSession
+	pending  map[int64]pendingRequest
+	onClosed []func(sessionID string)
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+type incomingMessage struct {
+	session *httpSession
+	stream  string
+	msg     *Message
+}
+
+// pendingRequest routes the response of a client request back to its stream.
+type pendingRequest struct {
+	session *httpSession
+	id      json.RawMessage
+	stream  string
+}
+
+type sessionIDKey struct{}
+
+// streamKey carries the stream of the request being handled.
+type streamKey struct{}
+
+// SessionIDFromContext returns the HTTP session of a request handled by the
+// server, or "" for other transports.
+func SessionIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(sessionIDKey{}).(string)
+
+	return id
+}
+
+// NewHTTPServerTransport creates a Streamable HTTP server transport. Zero
+// config fields take their defaults.
+func NewHTTPServerTransport(config HTTPServerConfig) *HTTPServerTransport {
+	defaults := DefaultHTTPServerConfig()
+	if config.SessionTimeout <= 0 {
+		config.SessionTimeout = defaults.SessionTimeout
+	}
+
+	if config.EventHistory <= 0 {
+		config.EventHistory = defaults.EventHistory
+	}
+
+	if config.MaxBodyBytes <= 0 {
+		config.MaxBodyBytes = defaults.MaxBodyBytes
+	}
+
+	if config.KeepAlive <= 0 {
+		config.KeepAlive = defaults.KeepAlive
+	}
+
+	t := &HTTPServerTransport{
+		config:   config,
+		incoming: make(chan incomingMessage, 64),
+		sessions: make(map[string]*httpSession),
+		pending:  make(map[int64]pendingRequest),
+		done:     make(chan struct{}),
+	}
+
+	go t.expireSessions()
+
+	return t
+}
+
+// Send implements ServerTransport. Responses go to the stream of the request
+// they answer; other messages go to the standalone stream of every session.
+func (t *HTTPServerTransport) Send(msg *Message) error {
+	select {
+	case <-t.done:
+		return ErrTransportClosed
+	default:
+	}
+
+	if len(msg.ID) > 0 && msg.Method == "" {
+		return t.sendResponse(msg)
+	}
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	sessions := make([]*httpSession, 0, len(t.sessions))
+	for _, session := range t.sessions {
+		sessions = append(sessions, session)
+	}
+	t.mu.Unlock()
+
+	for _, session := range sessions {
+		session.append(session.notifyStream(), data, false)
+	}
+
+	return nil
+}
+
+// SendContext implements ContextTransport. Messages go to the session of ctx
+// only: notifications about a request, such as progress, precede its
+// response on the request's stream, and requests go to the standalone stream.
+func (t *HTTPServerTransport) SendContext(ctx context.Context, msg *Message) error {
+	id := SessionIDFromContext(ctx)
+	if id == "" || len(msg.ID) > 0 && msg.Method == "" {
+		return t.Send(msg)
+	}
+
+	select {
+	case <-t.done:
+		return ErrTransportClosed
+	default:
+	}
+
+	t.mu.Lock()
+	session, ok := t.sessions[id]
+	t.mu.Unlock()
+
+	if !ok {
+		return ErrSessionNotFound
+	}
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	stream := session.notifyStream()
+	if related, ok := ctx.Value(streamKey{}).(string); ok && !isRequest(msg) {
+		stream = related
+	}
+
+	session.append(stream, data, false)
+
+	return nil
+}
+
+// OnSessionClosed implements SessionNotifier.
+func (t *HTTPServerTransport) OnSessionClosed(fn func(sessionID string)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.onClosed = append(t.onClosed, fn)
+}
+
+func (t *HTTPServerTransport) sendResponse(msg *Message) error {
+	var id int64
+	if err := json.Unmarshal(msg.ID, &id); err != nil {
+		return fmt.Errorf("unknown response id %s", msg.ID)
+	}
+
+	t.mu.Lock()
+	req, ok := t.pending[id]
+	delete(t.pending, id)
+	t.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("unknown response id %d", id)
+	}
+
+	out := *msg
+	out.ID = req.id
+
+	data, err := json.Marshal(&out)
+	if err != nil {
+		return err
+	}
+
+	req.session.append(req.stream, data, true)
+
+	return nil
+}
+
+// Receive implements ServerTransport.
+func (t *HTTPServerTransport) Receive() (*Message, error) {
+	_, msg, err := t.ReceiveContext(context.Background())
+
+	return msg, err
+}
+
+// ReceiveContext implements ContextTransport. The returned context carries the
+// session ID and the principal of the session.
+func (t *HTTPServerTransport) ReceiveContext(ctx context.Context) (context.Context, *Message, error) {
+	select {
+	case in := <-t.incoming:
+		ctx = context.WithValue(ctx, sessionIDKey{}, in.session.id)
+		if in.session.principal != nil {
+			ctx = sdk.WithPrincipal(ctx, in.session.principal)
+		}
+
+		if isRequest(in.msg) {
+			ctx = context.WithValue(ctx, streamKey{}, in.stream)
+		}
+
+		return ctx, in.msg, nil
+	case <-t.done:
+		return ctx, nil, io.EOF
+	case <-ctx.Done():
+		return ctx, nil, io.EOF
+	}
+}
+
+// Close implements ServerTransport. It ends every session and open stream.
+func (t *HTTPServerTransport) Close() error {
+	t.closeOnce.Do(func() {
+		close(t.done)
+
+		t.mu.Lock()
+		for id, session := range t.sessions {
+			session.close()
+			delete(t.sessions, id)
+		}
+		t.mu.Unlock()
+	})
+
+	return nil
+}
+
+// ServeHTTP implements http.Handler for the Streamable HTTP MCP endpoint.
+func (t *HTTPServerTransport) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	principal, ok := t.authorize(w, r)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		t.handlePost(w, r, principal)
+	case http.MethodGet:
+		t.handleGet(w, r, principal)
+	case http.MethodDelete:
+		t.handleDelete(w, r, principal)
+	default:
+		w.Header().Set("Allow", "GET, POST, DELETE")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// SSEHandler returns an http.Handler for the legacy HTTP+SSE transport. GET
+// opens the event stream, whose first event announces messageURL; clients
+// POST their messages there. Mount the handler on both paths.
+func (t *HTTPServerTransport) SSEHandler(messageURL string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := t.authorize(w, r)
+		if !ok {
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			t.handleLegacyStream(w, r, principal, messageURL)
+		case http.MethodPost:
+			t.handleLegacyPost(w, r, principal)
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+// authorize checks the origin and authenticates the caller.
+func (t *HTTPServerTransport) authorize(w http.ResponseWriter, r *http.Request) (*sdk.Principal, bool) {
+	if origin := r.Header.Get("Origin"); origin != "" && !t.originAllowed(r, origin) {
+		http.Error(w, "origin not allowed", http.StatusForbidden)
+
+		return nil, false
+	}
+
+	if t.config.Authenticate == nil {
+		return nil, true
+	}
+
+	principal, err := t.config.Authenticate(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+
+		return nil, false
+	}
+
+	return principal, true
+}
+
+// originAllowed checks the Origin header of a browser request against
+// AllowedOrigins, or against the request's host and loopback when none are
+// configured, so that other sites cannot drive a local server.
+func (t *HTTPServerTransport) originAllowed(r *http.Request, origin string) bool {
+	if len(t.config.AllowedOrigins) > 0 {
+		return slices.Contains(t.config.AllowedOrigins, origin)
+	}
+
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return false
+	}
+
+	if strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+
+	host := u.Hostname()
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+
+	return ip != nil && ip.IsLoopback()
+}
+
+func (t *HTTPServerTransport) handlePost(w http.ResponseWriter, r *http.Request, principal *sdk.Principal) {
+	msgs, batch, err := t.readMessages(w, r)
+	if err != nil {
+		writeJSONRPCError(w, http.StatusBadRequest, ErrorCodeParse, err.Error())
+
+		return
+	}
+
+	if !slices.ContainsFunc(msgs, func(msg *Message) bool { return msg.Method == MethodInitialize }) {
+		session, ok := t.lookupSession(w, r.Header.Get(HeaderSessionID), principal, false)
+		if !ok {
+			return
+		}
+		defer session.release()
+
+		t.handleSessionPost(w, r, session, msgs, batch)
+
+		return
+	}
+
+	if len(msgs) > 1 {
+		writeJSONRPCError(w, http.StatusBadRequest, ErrorCodeInvalidRequest, "initialize must not be batched")
+
+		return
+	}
+
+	session := t.newSession(principal, false)
+	defer session.release()
+
+	w.Header().Set(HeaderSessionID, session.id)
+	t.handleSessionPost(w, r, session, msgs, batch)
+}
+
+// handleSessionPost enqueues the messages of a POST and streams the responses
+// to its requests.
+func (t *HTTPServerTransport) handleSessionPost(w http.ResponseWriter, r *http.Request, session *httpSession, msgs []*Message, batch bool) {
+	stream := "post-" + strconv.FormatInt(t.nextID.Add(1), 10)
+
+	requests := 0
+	for _, msg := range msgs {
+		if isRequest(msg) {
+			requests++
+		}
+	}
+
+	if requests > 0 {
+		session.expect(stream, requests)
+	}
+
+	for _, msg := range msgs {
+		if !t.enqueue(r.Context(), session, stream, msg) {
+			http.Error(w, "server unavailable", http.StatusServiceUnavailable)
+
+			return
+		}
+	}
+
+	if requests == 0 {
+		w.WriteHeader(http.StatusAccepted)
+
+		return
+	}
+
+	if t.config.JSONResponse || !accepts(r, "text/event-stream") {
+		t.writeJSONResponses(w, r, session, stream, batch)
+
+		return
+	}
+
+	t.writeStream(w, r, session, stream, 0, "")
+}
+
+func (t *HTTPServerTransport) handleGet(w http.ResponseWriter, r *http.Request, principal *sdk.Principal) {
+	if !accepts(r, "text/event-stream") {
+		http.Error(w, "event stream not accepted", http.StatusNotAcceptable)
+
+		return
+	}
+
+	session, ok := t.lookupSession(w, r.Header.Get(HeaderSessionID), principal, false)
+	if !ok {
+		return
+	}
+	defer session.release()
+
+	stream, after := streamGet, session.delivered()
+
+	if lastID := r.Header.Get(headerLastEventID); lastID != "" {
+		seq, err := strconv.ParseInt(lastID, 10, 64)
+		if err != nil {
+			http.Error(w, "invalid Last-Event-ID", http.StatusBadRequest)
+
+			return
+		}
+
+		resumed, ok := session.streamOf(seq)
+		if !ok {
+			http.Error(w, "event no longer available", http.StatusNotFound)
+
+			return
+		}
+
+		stream, after = resumed, seq
+	}
+
+	t.writeStream(w, r, session, stream, after, "")
+}
+
+func (t *HTTPServerTransport) handleDelete(w http.ResponseWriter, r *http.Request, principal *sdk.Principal) {
+	session, ok := t.lookupSession(w, r.Header.Get(HeaderSessionID), principal, false)
+	if !ok {
+		return
+	}
+
+	session.release()
+	t.endSession(session)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (t *HTTPServerTransport) handleLegacyStream(w http.ResponseWriter, r *http.Request, principal *sdk.Principal, messageURL string) {
+	session := t.newSession(principal, true)
+	defer func() {
+		session.release()
+		t.endSession(session)
+	}()
+
+	endpoint, err := url.Parse(messageURL)
+	if err != nil {
+		http.Error(w, "invalid message endpoint", http.StatusInternalServerError)
+
+		return
+	}
+
+	query := endpoint.Query()
+	query.Set("sessionId", session.id)
+	endpoint.RawQuery = query.Encode()
+
+	t.writeStream(w, r, session, streamLegacy, 0, endpoint.String())
+}
+
+func (t *HTTPServerTransport) handleLegacyPost(w http.ResponseWriter, r *http.Request, principal *sdk.Principal) {
+	session, ok := t.lookupSession(w, r.URL.Query().Get("sessionId"), principal, true)
+	if !ok {
+		return
+	}
+	defer session.release()
+
+	msgs, _, err := t.readMessages(w, r)
+	if err != nil {
+		writeJSONRPCError(w, http.StatusBadRequest, ErrorCodeParse, err.Error())
+
+		return
+	}
+
+	for _, msg := range msgs {
+		if !t.enqueue(r.Context(), session, streamLegacy, msg) {
+			http.Error(w, "server unavailable", http.StatusServiceUnavailable)
+
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// readMessages decodes a POST body holding one message or a batch.
+func (t *HTTPServerTransport) readMessages(w http.ResponseWriter, r *http.Request) ([]*Message, bool, error) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, t.config.MaxBodyBytes))
+	if err != nil {
+		return nil, false, err
+	}
+
+	body = bytes.TrimSpace(body)
+	batch := len(body) > 0 && body[0] == '['
+
+	msgs, err := decodeMessages(body)
+	if err != nil {
+		return nil, batch, err
+	}
+
+	if len(msgs) == 0 || slices.Contains(msgs, nil) {
+		return nil, batch, errors.New("invalid message")
+	}
+
+	return msgs, batch, nil
+}
+
+// enqueue hands a client message to the server. Request IDs are replaced by
+// transport-wide IDs so that responses can be routed to their stream.
+func (t *HTTPServerTransport) enqueue(ctx context.Context, session *httpSession, stream string, msg *Message) bool {
+	if msg.Method == MethodCancelled && !t.translateCancel(session, msg) {
+		return true
+	}
+
+	if isRequest(msg) {
+		id := t.nextID.Add(1)
+
+		t.mu.Lock()
+		t.pending[id] = pendingRequest{session: session, id: msg.ID, stream: stream}
+		t.mu.Unlock()
+
+		msg.ID = json.RawMessage(strconv.FormatInt(id, 10))
+	}
+
+	session.touch()
+
+	select {
+	case t.incoming <- incomingMessage{session: session, stream: stream, msg: msg}:
+		return true
+	case <-ctx.Done():
+		return false
+	case <-t.done:
+		return false
+	}
+}
+
+// translateCancel rewrites the request ID of a cancellation to the ID the
+// server knows and completes the request's stream, since the server will not
+// answer it. It reports false for unknown requests, which need no cancelling.
+func (t *HTTPServerTransport) translateCancel(session *httpSession, msg *Message) bool {
+	var notif CancelledNotification
+	if err := json.Unmarshal(msg.Params, &notif); err != nil {
+		return false
+	}
+
+	t.mu.Lock()
+	var (
+		internal int64
+		req      pendingRequest
+		found    bool
+	)
+
+	for id, pending := range t.pending {
+		if pending.session == session && bytes.Equal(pending.id, notif.RequestID) {
+			internal, req, found = id, pending, true
+			delete(t.pending, id)
+
+			break
+		}
+	}
+	t.mu.Unlock()
+
+	if !found {
+		return false
+	}
+
+	session.abandon(req.stream)
+
+	notif.RequestID = json.RawMessage(strconv.FormatInt(internal, 10))
+
+	params, err := json.Marshal(notif)
+	if err != nil {
+		return false
+	}
+
+	msg.Params = params
+
+	return true
+}
+
+// writeStream writes the events of stream after seq until the stream is
+// complete, the client disconnects or the session ends. A non-empty endpoint
+// is announced first, as the legacy transport requires.
+func (t *HTTPServerTransport) writeStream(w http.ResponseWriter, r *http.Request, session *httpSession, stream string, after int64, endpoint string) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+
+		return
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	if stream == streamGet {
+		session.attachGet(cancel)
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	if endpoint != "" {
+		if err := writeSSEEvent(w, "", "endpoint", []byte(endpoint)); err != nil {
+			return
+		}
+	}
+
+	flusher.Flush()
+
+	ticker := time.NewTicker(t.config.KeepAlive)
+	defer ticker.Stop()
+
+	for {
+		events, complete, changed := session.after(stream, after)
+
+		for _, event := range events {
+			id := ""
+			if stream != streamLegacy {
+				id = strconv.FormatInt(event.seq, 10)
+			}
+
+			if err := writeSSEEvent(w, id, "message", event.data); err != nil {
+				return
+			}
+
+			after = event.seq
+		}
+
+		flusher.Flush()
+
+		if stream == streamGet {
+			session.markDelivered(after)
+		}
+
+		if complete {
+			return
+		}
+
+		select {
+		case <-changed:
+		case <-ticker.C:
+			if _, err := io.WriteString(w, ": ping\n\n"); err != nil {
+				return
+			}
+
+			flusher.Flush()
+		case <-ctx.Done():
+			return
+		case <-t.done:
+			return
+		}
+	}
+}
+
+// writeJSONResponses waits for every response of stream and writes them as a
+// JSON body. Notifications sent on the stream are dropped.
+func (t *HTTPServerTransport) writeJSONResponses(w http.ResponseWriter, r *http.Request, session *httpSession, stream string, batch bool) {
+	var (
+		responses []json.RawMessage
+		after     int64
+	)
+
+	for {
+		events, complete, changed := session.after(stream, after)
+		for _, event := range events {
+			if event.response {
+				responses = append(responses, event.data)
+			}
+
+			after = event.seq
+		}
+
+		if complete {
+			break
+		}
+
+		select {
+		case <-changed:
+		case <-r.Context().Done():
+			return
+		case <-t.done:
+			http.Error(w, "server closed", http.StatusServiceUnavailable)
+
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if batch {
+		_ = json.NewEncoder(w).Encode(responses)
+
+		return
+	}
+
+	if len(responses) > 0 {
+		_, _ = w.Write(responses[0])
+	}
+}
+
+// newSession creates and registers a session, marked as in use.
+func (t *HTTPServerTransport) newSession(principal *sdk.Principal, legacy bool) *httpSession {
+	session := &httpSession{
+		id:        uuid.NewString(),
+		principal: principal,
+		legacy:    legacy,
+		history:   t.config.EventHistory,
+		waiting:   make(map[string]int),
+		changed:   make(chan struct{}),
+		lastSeen:  time.Now(),
+		active:    1,
+	}
+
+	t.mu.Lock()
+	t.sessions[session.id] = session
+	t.mu.Unlock()
+
+	return session
+}
+
+// lookupSession finds a session and marks it as in use, writing the error
+// response when it cannot be used by the caller.
+func (t *HTTPServerTransport) lookupSession(w http.ResponseWriter, id string, principal *sdk.Principal, legacy bool) (*httpSession, bool) {
+	if id == "" {
+		http.Error(w, "missing session id", http.StatusBadRequest)
+
+		return nil, false
+	}
+
+	t.mu.Lock()
+	session, ok := t.sessions[id]
+	t.mu.Unlock()
+
+	if !ok || session.legacy != legacy {
+		http.Error(w, ErrSessionNotFound.Error(), http.StatusNotFound)
+
+		return nil, false
+	}
+
+	if session.principal != nil && (principal == nil || principal.ID != session.principal.ID) {
+		http.Error(w, "session belongs to another principal", http.StatusForbidden)
+
+		return nil, false
+	}
+
+	session.acquire()
+
+	return session, true
+}
+
+// endSession removes a session, ends its streams and runs the session closed
+// callbacks.
+func (t *HTTPServerTransport) endSession(session *httpSession) {
+	t.mu.Lock()
+	_, registered := t.sessions[session.id]
+	delete(t.sessions, session.id)
+
+	for id, req := range t.pending {
+		if req.session == session {
+			delete(t.pending, id)
+		}
+	}
+
+	onClosed := slices.Clone(t.onClosed)
+	t.mu.Unlock()
+
+	session.close()
+
+	if !registered {
+		return
+	}
+
+	for _, fn := range onClosed {
+		fn(session.id)
+	}
+}
+
+// expireSessions ends sessions that have been idle for the session timeout.
+func (t *HTTPServerTransport) expireSessions() {
+	ticker := time.NewTicker(max(t.config.SessionTimeout/4, time.Second))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-t.done:
+			return
+		case <-ticker.C:
+		}
+
+		t.mu.Lock()
+		var expired []*httpSession
+		for _, session := range t.sessions {
+			if session.idle(t.config.SessionTimeout) {
+				expired = append(expired, session)
+			}
+		}
+		t.mu.Unlock()
+
+		for _, session := range expired {
+			t.endSession(session)
+		}
+	}
+}
+
+// httpSession is the state of one client: an event log shared by its streams.
+type httpSession struct {
+	id        string
+	principal *sdk.Principal
+	legacy    bool
+	history   int
+
+	mu       sync.Mutex
+	events   []sessionEvent
+	seq      int64
+	sent     int64
+	waiting  map[string]int
+	changed  chan struct{}
+	cancel   context.CancelFunc
+	lastSeen time.Time
+	active   int
+	closed   bool
+}
+
+type sessionEvent struct {
+	seq      int64
+	stream   string
+	data     []byte
+	response bool
+}
+
+// notifyStream returns the stream for server-initiated messages.
+func (s *httpSession) notifyStream() string {
+	if s.legacy {
+		return streamLegacy
+	}
+
+	return streamGet
+}
+
+// append adds an event to the log and wakes the stream writers.
+func (s *httpSession) append(stream string, data []byte, response bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return
+	}
+
+	s.seq++
+	s.events = append(s.events, sessionEvent{seq: s.seq, stream: stream, data: data, response: response})
+
+	if len(s.events) > s.history {
+		s.events = slices.Delete(s.events, 0, len(s.events)-s.history)
+	}
+
+	if response {
+		if _, ok := s.waiting[stream]; ok {
+			s.waiting[stream]--
+		}
+	}
+
+	s.wake()
+}
+
+// wake signals a change to the stream writers. Callers hold s.mu.
+func (s *httpSession) wake() {
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+// expect records the number of responses a POST stream waits for.
+func (s *httpSession) expect(stream string, responses int) {
+	s.mu.Lock()
+	s.waiting[stream] = responses
+	s.mu.Unlock()
+}
+
+// abandon stops a POST stream waiting for the response of a cancelled request.
+func (s *httpSession) abandon(stream string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.waiting[stream]; ok {
+		s.waiting[stream]--
+		s.wake()
+	}
+}
+
+// after returns the events of stream after seq, whether the stream is
+// complete, and a channel closed on the next change.
+func (s *httpSession) after(stream string, seq int64) ([]sessionEvent, bool, <-chan struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var events []sessionEvent
+
+	for _, event := range s.events {
+		if event.seq > seq && event.stream == stream {
+			events = append(events, event)
+		}
+	}
+
+	remaining, ok := s.waiting[stream]
+
+	return events, s.closed || (ok && remaining <= 0), s.changed
+}
+
+// streamOf returns the stream of a retained event.
+func (s *httpSession) streamOf(seq int64) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, event := range s.events {
+		if event.seq == seq {
+			return event.stream, true
+		}
+	}
+
+	return "", false
+}
+
+// delivered returns the last event written to the standalone stream; a new
+// standalone stream continues after it, so server requests sent while no
+// stream was open are not lost.
+func (s *httpSession) delivered() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.sent
+}
+
+func (s *httpSession) markDelivered(seq int64) {
+	s.mu.Lock()
+	s.sent = max(s.sent, seq)
+	s.mu.Unlock()
+}
+
+// attachGet registers the standalone stream, ending the previous one.
+func (s *httpSession) attachGet(cancel context.CancelFunc) {
+	s.mu.Lock()
+	previous := s.cancel
+	s.cancel = cancel
+	s.mu.Unlock()
+
+	if previous != nil {
+		previous()
+	}
+}
+
+func (s *httpSession) acquire() {
+	s.mu.Lock()
+	s.active++
+	s.lastSeen = time.Now()
+	s.mu.Unlock()
+}
+
+func (s *httpSession) release() {
+	s.mu.Lock()
+	s.active--
+	s.lastSeen = time.Now()
+	s.mu.Unlock()
+}
+
+func (s *httpSession) touch() {
+	s.mu.Lock()
+	s.lastSeen = time.Now()
+	s.mu.Unlock()
+}
+
+func (s *httpSession) idle(timeout time.Duration) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.active <= 0 && time.Since(s.lastSeen) > timeout
+}
+
+func (s *httpSession) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return
+	}
+
+	s.closed = true
+	s.wake()
+}
+
+// isRequest reports whether msg expects a response.
+func isRequest(msg *Message) bool {
+	return msg.Method != "" && len(msg.ID) > 0 && string(msg.ID) != "null"
+}
+
+// accepts reports whether the Accept header of r allows mediaType.
+func accepts(r *http.Request, mediaType string) bool {
+	for value := range strings.SplitSeq(r.Header.Get("Accept"), ",") {
+		parsed, _, err := mime.ParseMediaType(strings.TrimSpace(value))
+		if err == nil && (parsed == mediaType || parsed == "*/*") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func writeJSONRPCError(w http.ResponseWriter, status int, code int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(NewErrorResponse(nil, code, message))
+}
@@ -0,0 +1,362 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	sdk "github.com/xraph/ai-sdk"
+)
+
+// newHTTPTestServer starts an MCP server with an echo tool and a scoped admin
+// tool, serving Streamable HTTP on /mcp and legacy SSE on /sse and /messages.
+func newHTTPTestServer(t *testing.T, config HTTPServerConfig) (*httptest.Server, *Server) {
+	t.Helper()
+
+	transport := NewHTTPServerTransport(config)
+	server := NewServer(transport, ServerConfig{Info: Implementation{Name: "test", Version: "1.0.0"}})
+
+	echo := func(ctx context.Context, args map[string]any) ([]ToolResultContent, error) {
+		text, _ := args["text"].(string)
+
+		return []ToolResultContent{{Type: "text", Text: text}}, nil
+	}
+
+	server.RegisterTool(Tool{Name: "echo", InputSchema: []byte(`{"type":"object"}`)}, echo)
+	server.RegisterTool(Tool{Name: "admin", InputSchema: []byte(`{"type":"object"}`), RequiredScopes: []string{"admin"}}, echo)
+
+	go func() { _ = server.Start() }()
+
+	legacy := transport.SSEHandler("/messages")
+
+	mux := http.NewServeMux()
+	mux.Handle("/mcp", transport)
+	mux.Handle("/sse", legacy)
+	mux.Handle("/messages", legacy)
+
+	httpServer := httptest.NewServer(mux)
+
+	t.Cleanup(func() {
+		_ = server.Stop()
+
+		httpServer.Close()
+	})
+
+	return httpServer, server
+}
+
+func callTool(t *testing.T, client *Client, name string, args map[string]any) (string, bool) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	resp, err := client.CallTool(ctx, name, args)
+	if err != nil {
+		t.Fatalf("call %s: %v", name, err)
+	}
+
+	return resp.Content[0].Text, resp.IsError
+}
+
+func TestStreamableHTTP_RoundTrip(t *testing.T) {
+	for _, jsonResponse := range []bool{false, true} {
+		httpServer, _ := newHTTPTestServer(t, HTTPServerConfig{JSONResponse: jsonResponse})
+
+		transport := NewStreamableHTTPTransport(HTTPTransportConfig{URL: httpServer.URL + "/mcp"})
+		client := NewClient(transport, ClientConfig{})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+
+		if err := client.Connect(ctx, Implementation{Name: "client", Version: "1.0.0"}); err != nil {
+			t.Fatalf("connect: %v", err)
+		}
+
+		cancel()
+
+		sessionID := transport.SessionID()
+		if sessionID == "" {
+			t.Fatal("expected the server to assign a session")
+		}
+
+		if text, _ := callTool(t, client, "echo", map[string]any{"text": "hello"}); text != "hello" {
+			t.Errorf("unexpected echo %q", text)
+		}
+
+		_ = client.Close()
+
+		req, _ := http.NewRequest(http.MethodPost, httpServer.URL+"/mcp", strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`))
+		req.Header.Set(HeaderSessionID, sessionID)
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("post: %v", err)
+		}
+
+		_ = resp.Body.Close()
+
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("expected closed session to be gone, got %d", resp.StatusCode)
+		}
+	}
+}
+
+func TestStreamableHTTP_Auth(t *testing.T) {
+	httpServer, _ := newHTTPTestServer(t, HTTPServerConfig{
+		Authenticate: func(r *http.Request) (*sdk.Principal, error) {
+			switch r.Header.Get("Authorization") {
+			case "Bearer admin":
+				return &sdk.Principal{ID: "root", Scopes: []string{"admin"}}, nil
+			case "Bearer user":
+				return &sdk.Principal{ID: "user"}, nil
+			default:
+				return nil, errors.New("missing token")
+			}
+		},
+	})
+
+	connect := func(token string) (*Client, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		return ConnectHTTP(ctx, HTTPTransportConfig{
+			URL:     httpServer.URL + "/mcp",
+			Headers: map[string]string{"Authorization": "Bearer " + token},
+		}, Implementation{Name: "client"})
+	}
+
+	var statusErr *HTTPStatusError
+	if _, err := connect("bad"); !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %v", err)
+	}
+
+	admin, err := connect("admin")
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer admin.Close()
+
+	if text, isError := callTool(t, admin, "admin", map[string]any{"text": "hello"}); isError {
+		t.Errorf("expected admin to be allowed, got %q", text)
+	}
+
+	user, err := connect("user")
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer user.Close()
+
+	if text, isError := callTool(t, user, "admin", map[string]any{"text": "hello"}); !isError || !strings.Contains(text, "admin") {
+		t.Errorf("expected missing scope to be reported, got %q", text)
+	}
+}
+
+func TestStreamableHTTP_ResumeStream(t *testing.T) {
+	httpServer, server := newHTTPTestServer(t, HTTPServerConfig{})
+
+	resp, err := http.Post(httpServer.URL+"/mcp", "application/json",
+		strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05"}}`))
+	if err != nil {
+		t.Fatalf("initialize: %v", err)
+	}
+
+	_ = resp.Body.Close()
+	sessionID := resp.Header.Get(HeaderSessionID)
+
+	open := func(lastEventID string) (*http.Response, *bufio.Reader) {
+		req, _ := http.NewRequest(http.MethodGet, httpServer.URL+"/mcp", nil)
+		req.Header.Set("Accept", "text/event-stream")
+		req.Header.Set(HeaderSessionID, sessionID)
+
+		if lastEventID != "" {
+			req.Header.Set(headerLastEventID, lastEventID)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil || resp.StatusCode != http.StatusOK {
+			t.Fatalf("open stream: %v %v", err, resp)
+		}
+
+		return resp, bufio.NewReader(resp.Body)
+	}
+
+	stream, reader := open("")
+
+	_ = server.NotifyToolListChanged()
+
+	first, err := readSSEEvent(reader)
+	if err != nil || !strings.Contains(first.data, string(MethodToolListChanged)) {
+		t.Fatalf("unexpected event %+v: %v", first, err)
+	}
+
+	_ = stream.Body.Close()
+
+	_ = server.NotifyResourceListChanged()
+
+	stream, reader = open(first.id)
+	defer stream.Body.Close()
+
+	missed, err := readSSEEvent(reader)
+	if err != nil || !strings.Contains(missed.data, string(MethodResourceListChanged)) {
+		t.Fatalf("expected the missed event to be replayed, got %+v: %v", missed, err)
+	}
+}
+
+func TestConnectHTTP_LegacySSEFallback(t *testing.T) {
+	httpServer, _ := newHTTPTestServer(t, HTTPServerConfig{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client, err := ConnectHTTP(ctx, HTTPTransportConfig{URL: httpServer.URL + "/sse"}, Implementation{Name: "client"})
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer client.Close()
+
+	if _, ok := client.transport.(*SSETransport); !ok {
+		t.Fatalf("expected legacy transport, got %T", client.transport)
+	}
+
+	if text, _ := callTool(t, client, "echo", map[string]any{"text": "hello"}); text != "hello" {
+		t.Errorf("unexpected echo %q", text)
+	}
+}
+
+func TestStreamableHTTP_SessionNotifications(t *testing.T) {
+	httpServer, server := newHTTPTestServer(t, HTTPServerConfig{})
+
+	server.RegisterResource(Resource{URI: "test://status", Name: "status"}, func(ctx context.Context, uri string) (*ResourceContent, error) {
+		return &ResourceContent{URI: uri, Text: "ok"}, nil
+	})
+
+	connect := func() (*Client, chan Method) {
+		received := make(chan Method, 16)
+
+		transport := NewStreamableHTTPTransport(HTTPTransportConfig{URL: httpServer.URL + "/mcp"})
+		client := NewClient(transport, ClientConfig{
+			OnNotification: func(method Method, _ json.RawMessage) { received <- method },
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if err := client.Connect(ctx, Implementation{Name: "client"}); err != nil {
+			t.Fatalf("connect: %v", err)
+		}
+
+		t.Cleanup(func() { _ = client.Close() })
+
+		return client, received
+	}
+
+	subscriber, subscriberReceived := connect()
+	quiet, quietReceived := connect()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := subscriber.SubscribeResource(ctx, "test://status", func(ResourceContent) {}); err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+
+	if err := quiet.SetLogLevel(ctx, LogLevelError); err != nil {
+		t.Fatalf("set log level: %v", err)
+	}
+
+	_ = server.NotifyResourceUpdated("test://status")
+	_ = server.Log(LogLevelInfo, "test", "hello")
+	_ = server.NotifyToolListChanged()
+
+	collect := func(received chan Method) []Method {
+		var methods []Method
+
+		for {
+			select {
+			case method := <-received:
+				methods = append(methods, method)
+				if method == MethodToolListChanged {
+					return methods
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("timed out, got %v", methods)
+			}
+		}
+	}
+
+	want := []Method{MethodResourceUpdated, MethodLog, MethodToolListChanged}
+	if got := collect(subscriberReceived); !slices.Equal(got, want) {
+		t.Errorf("expected the subscriber to get %v, got %v", want, got)
+	}
+
+	if got := collect(quietReceived); !slices.Equal(got, []Method{MethodToolListChanged}) {
+		t.Errorf("expected the other session to get only the broadcast, got %v", got)
+	}
+}
+
+func TestStreamableHTTP_Origin(t *testing.T) {
+	httpServer, _ := newHTTPTestServer(t, HTTPServerConfig{})
+
+	for origin, allowed := range map[string]bool{
+		"":                      true,
+		httpServer.URL:          true,
+		"http://localhost:3000": true,
+		"http://127.0.0.1:3000": true,
+		"https://evil.example":  false,
+		"null":                  false,
+	} {
+		req, _ := http.NewRequest(http.MethodPost, httpServer.URL+"/mcp",
+			strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05"}}`))
+		req.Header.Set("Content-Type", "application/json")
+
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("post: %v", err)
+		}
+
+		_ = resp.Body.Close()
+
+		if forbidden := resp.StatusCode == http.StatusForbidden; forbidden == allowed {
+			t.Errorf("origin %q: expected allowed=%v, got status %d", origin, allowed, resp.StatusCode)
+		}
+	}
+}
+
+func TestSSETransport_RejectsForeignEndpoint(t *testing.T) {
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte("event: endpoint\ndata: https://evil.example/messages\n\n"))
+		w.(http.Flusher).Flush()
+
+		<-r.Context().Done()
+	}))
+	defer httpServer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	transport, err := NewSSETransport(ctx, HTTPTransportConfig{
+		URL:     httpServer.URL + "/sse",
+		Headers: map[string]string{"Authorization": "Bearer secret"},
+	})
+	if err == nil {
+		_ = transport.Close()
+
+		t.Fatal("expected the foreign endpoint to be rejected")
+	}
+
+	if !strings.Contains(err.Error(), "origin") {
+		t.Errorf("expected an origin error, got %v", err)
+	}
+}
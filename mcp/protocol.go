@@ -27,6 +27,7 @@ const (
 	// Initialization methods.
 	MethodInitialize Method = "initialize"
 	MethodShutdown   Method = "shutdown"
+	MethodPing       Method = "ping"
 
 	// Resource methods.
 	MethodListResources       Method = "resources/list"
@@ -34,6 +35,8 @@ const (
 	MethodSubscribeResource   Method = "resources/subscribe"
 	MethodUnsubscribeResource Method = "resources/unsubscribe"
 
+	MethodListResourceTemplates Method = "resources/templates/list"
+
 	// Tool methods.
 	MethodListTools Method = "tools/list"
 	MethodCallTool  Method = "tools/call"
@@ -45,6 +48,14 @@ const (
 	// Logging methods.
 	MethodSetLogLevel Method = "logging/setLevel"
 
+	// Completion methods.
+	MethodComplete Method = "completion/complete"
+
+	// Client feature methods, sent by the server to the client.
+	MethodCreateMessage Method = "sampling/createMessage"
+	MethodListRoots     Method = "roots/list"
+	MethodElicit        Method = "elicitation/create"
+
 	// Notification methods.
 	MethodResourceUpdated     Method = "notifications/resources/updated"
 	MethodResourceListChanged Method = "notifications/resources/list_changed"
@@ -52,6 +63,8 @@ const (
 	MethodPromptListChanged   Method = "notifications/prompts/list_changed"
 	MethodProgress            Method = "notifications/progress"
 	MethodLog                 Method = "notifications/message"
+	MethodRootsListChanged    Method = "notifications/roots/list_changed"
+	MethodCancelled           Method = "notifications/cancelled"
 )
 
 // Message is the base MCP message structure.
@@ -141,13 +154,29 @@ func NewErrorResponse(id json.RawMessage, code int, message string) *Message {
 	}
 }
 
+// sendCancelled tells the peer that the request id was abandoned.
+func sendCancelled(send func(*Message) error, id json.RawMessage, reason error) error {
+	msg, err := NewMessage(MethodCancelled, nil, CancelledNotification{RequestID: id, Reason: reason.Error()})
+	if err != nil {
+		return err
+	}
+
+	return send(msg)
+}
+
 // Capability represents server or client capabilities.
 type Capability struct {
-	Experimental map[string]any       `json:"experimental,omitempty"`
-	Logging      *LoggingCapability   `json:"logging,omitempty"`
-	Prompts      *PromptsCapability   `json:"prompts,omitempty"`
-	Resources    *ResourcesCapability `json:"resources,omitempty"`
-	Tools        *ToolsCapability     `json:"tools,omitempty"`
+	Experimental map[string]any         `json:"experimental,omitempty"`
+	Logging      *LoggingCapability     `json:"logging,omitempty"`
+	Prompts      *PromptsCapability     `json:"prompts,omitempty"`
+	Resources    *ResourcesCapability   `json:"resources,omitempty"`
+	Tools        *ToolsCapability       `json:"tools,omitempty"`
+	Completions  *CompletionsCapability `json:"completions,omitempty"`
+
+	// Client capabilities.
+	Sampling    *SamplingCapability    `json:"sampling,omitempty"`
+	Roots       *RootsCapability       `json:"roots,omitempty"`
+	Elicitation *ElicitationCapability `json:"elicitation,omitempty"`
 }
 
 // LoggingCapability represents logging capabilities.
@@ -169,6 +198,21 @@ type ToolsCapability struct {
 	ListChanged bool `json:"listChanged,omitempty"`
 }
 
+// CompletionsCapability represents argument completion capabilities.
+type CompletionsCapability struct{}
+
+// SamplingCapability represents the client's support for sampling requests.
+type SamplingCapability struct{}
+
+// RootsCapability represents the client's support for roots requests.
+type RootsCapability struct {
+	ListChanged bool `json:"listChanged,omitempty"`
+}
+
+// ElicitationCapability represents the client's support for elicitation
+// requests.
+type ElicitationCapability struct{}
+
 // Implementation describes the server or client implementation.
 type Implementation struct {
 	Name    string `json:"name"`
@@ -224,17 +268,28 @@ type ReadResourceResponse struct {
 
 // ResourceTemplate represents a resource template.
 type ResourceTemplate struct {
-	URITemplate string `json:"uriTemplate"`
+	URITemplate string `json:"uriTemplate"` // RFC 6570, see ParseURITemplate
 	Name        string `json:"name"`
 	Description string `json:"description,omitempty"`
 	MimeType    string `json:"mimeType,omitempty"`
 }
 
+// ListResourceTemplatesResponse is the response for listing resource
+// templates.
+type ListResourceTemplatesResponse struct {
+	ResourceTemplates []ResourceTemplate `json:"resourceTemplates"`
+	NextCursor        string             `json:"nextCursor,omitempty"`
+}
+
 // Tool represents an MCP tool.
 type Tool struct {
 	Name        string          `json:"name"`
 	Description string          `json:"description,omitempty"`
 	InputSchema json.RawMessage `json:"inputSchema"`
+
+	// RequiredScopes lists the scopes the calling principal must hold to
+	// call the tool. It is enforced by Server and never sent to clients.
+	RequiredScopes []string `json:"-"`
 }
 
 // ListToolsResponse is the response for listing tools.
@@ -247,6 +302,14 @@ type ListToolsResponse struct {
 type CallToolRequest struct {
 	Name      string         `json:"name"`
 	Arguments map[string]any `json:"arguments,omitempty"`
+	Meta      *RequestMeta   `json:"_meta,omitempty"`
+}
+
+// RequestMeta is the metadata of a request.
+type RequestMeta struct {
+	// ProgressToken asks the receiver to report progress in notifications
+	// carrying the token.
+	ProgressToken any `json:"progressToken,omitempty"` // string or number
 }
 
 // CallToolResponse is the response for calling a tool.
@@ -311,6 +374,51 @@ type PromptMessageContent struct {
 	Resource *ResourceContent `json:"resource,omitempty"`
 }
 
+// Completion reference types.
+const (
+	CompletionRefPrompt   = "ref/prompt"
+	CompletionRefResource = "ref/resource"
+)
+
+// CompleteRequest asks for completions of a prompt argument or a resource
+// template variable.
+type CompleteRequest struct {
+	Ref      CompletionReference `json:"ref"`
+	Argument CompletionArgument  `json:"argument"`
+	Context  *CompletionContext  `json:"context,omitempty"`
+}
+
+// CompletionReference identifies the prompt, by name, or the resource
+// template, by URI template, being completed.
+type CompletionReference struct {
+	Type string `json:"type"`
+	Name string `json:"name,omitempty"`
+	URI  string `json:"uri,omitempty"`
+}
+
+// CompletionArgument is the argument being completed and its partial value.
+type CompletionArgument struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// CompletionContext holds the values of arguments already resolved.
+type CompletionContext struct {
+	Arguments map[string]string `json:"arguments,omitempty"`
+}
+
+// CompleteResponse is the response for a completion request.
+type CompleteResponse struct {
+	Completion Completion `json:"completion"`
+}
+
+// Completion lists the suggested values, at most 100.
+type Completion struct {
+	Values  []string `json:"values"`
+	Total   int      `json:"total,omitempty"`
+	HasMore bool     `json:"hasMore,omitempty"`
+}
+
 // LogLevel represents a log level.
 type LogLevel string
 
@@ -339,9 +447,17 @@ type LogNotification struct {
 
 // ProgressNotification is a progress notification.
 type ProgressNotification struct {
-	ProgressToken string  `json:"progressToken"`
+	ProgressToken any     `json:"progressToken"` // string or number
 	Progress      float64 `json:"progress"`
 	Total         float64 `json:"total,omitempty"`
+	Message       string  `json:"message,omitempty"`
+}
+
+// CancelledNotification tells the receiver that a request was abandoned and
+// needs no response.
+type CancelledNotification struct {
+	RequestID json.RawMessage `json:"requestId"`
+	Reason    string          `json:"reason,omitempty"`
 }
 
 // ResourceUpdatedNotification is a resource updated notification.
@@ -364,6 +480,79 @@ type ListRequest struct {
 	Cursor string `json:"cursor,omitempty"`
 }
 
+// CreateMessageRequest asks the client to sample its LLM.
+type CreateMessageRequest struct {
+	Messages         []SamplingMessage `json:"messages"`
+	ModelPreferences *ModelPreferences `json:"modelPreferences,omitempty"`
+	SystemPrompt     string            `json:"systemPrompt,omitempty"`
+	IncludeContext   string            `json:"includeContext,omitempty"` // "none", "thisServer", "allServers"
+	Temperature      *float64          `json:"temperature,omitempty"`
+	MaxTokens        int               `json:"maxTokens"`
+	StopSequences    []string          `json:"stopSequences,omitempty"`
+	Metadata         map[string]any    `json:"metadata,omitempty"`
+}
+
+// SamplingMessage represents a message in a sampling request.
+type SamplingMessage struct {
+	Role    string               `json:"role"` // "user", "assistant"
+	Content PromptMessageContent `json:"content"`
+}
+
+// ModelPreferences are the server's hints for the client's model choice.
+// Priorities range from 0 to 1.
+type ModelPreferences struct {
+	Hints                []ModelHint `json:"hints,omitempty"`
+	CostPriority         float64     `json:"costPriority,omitempty"`
+	SpeedPriority        float64     `json:"speedPriority,omitempty"`
+	IntelligencePriority float64     `json:"intelligencePriority,omitempty"`
+}
+
+// ModelHint suggests a model by full or partial name.
+type ModelHint struct {
+	Name string `json:"name,omitempty"`
+}
+
+// CreateMessageResponse is the response for a sampling request.
+type CreateMessageResponse struct {
+	Role       string               `json:"role"`
+	Content    PromptMessageContent `json:"content"`
+	Model      string               `json:"model"`
+	StopReason string               `json:"stopReason,omitempty"` // "endTurn", "stopSequence", "maxTokens"
+}
+
+// Root represents a directory or file the client exposes to servers.
+type Root struct {
+	URI  string `json:"uri"` // file:// URI
+	Name string `json:"name,omitempty"`
+}
+
+// ListRootsResponse is the response for listing roots.
+type ListRootsResponse struct {
+	Roots []Root `json:"roots"`
+}
+
+// ElicitRequest asks the client to collect input from the user.
+type ElicitRequest struct {
+	Message         string          `json:"message"`
+	RequestedSchema json.RawMessage `json:"requestedSchema"` // flat object schema of primitive properties
+}
+
+// ElicitAction is the user's answer to an elicitation request.
+type ElicitAction string
+
+const (
+	ElicitActionAccept  ElicitAction = "accept"
+	ElicitActionDecline ElicitAction = "decline"
+	ElicitActionCancel  ElicitAction = "cancel"
+)
+
+// ElicitResponse is the response for an elicitation request. Content is set
+// when the user accepts.
+type ElicitResponse struct {
+	Action  ElicitAction   `json:"action"`
+	Content map[string]any `json:"content,omitempty"`
+}
+
 // ConnectionState represents the state of an MCP connection.
 type ConnectionState string
 
@@ -371,14 +560,27 @@ const (
 	ConnectionStateDisconnected ConnectionState = "disconnected"
 	ConnectionStateConnecting   ConnectionState = "connecting"
 	ConnectionStateConnected    ConnectionState = "connected"
-	ConnectionStateError        ConnectionState = "error"
+	ConnectionStateReconnecting ConnectionState = "reconnecting"
+	ConnectionStateError        ConnectionState = "error" // Supervisor gave up, see SupervisorConfig.MaxRetries
 )
 
-// ConnectionInfo contains information about an MCP connection.
+// ConnectionInfo contains information about an MCP connection, see
+// MCPClientManager.Connection.
 type ConnectionInfo struct {
+	Name         string
 	State        ConnectionState
+	Supervised   bool
 	ServerInfo   *Implementation
 	Capabilities *Capability
 	ConnectedAt  time.Time
 	LastError    error
+
+	// Health checks of supervised servers
+	LastPing    time.Time
+	PingLatency time.Duration
+
+	// Restarts counts reconnections after lost connections, Failures the
+	// consecutive failed connection attempts.
+	Restarts int
+	Failures int
 }
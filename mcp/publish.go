@@ -0,0 +1,338 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	sdk "github.com/xraph/ai-sdk"
+)
+
+// AgentToolProvider is implemented by agents that can be called as a tool,
+// such as sdk.Agent, sdk.ReactAgent and sdk.PlanExecuteAgent.
+type AgentToolProvider interface {
+	AsTool() sdk.Tool
+}
+
+// PublishToolRegistry publishes every tool of registry. Calls go through
+// ToolRegistry.ExecuteTool with the session's principal, so argument
+// validation, scopes and caching apply. Call it again after registering
+// more tools; connected clients are notified of the change.
+func (s *Server) PublishToolRegistry(registry *sdk.ToolRegistry) error {
+	for _, tool := range registry.AgentTools() {
+		if err := s.publishTool(tool); err != nil {
+			return err
+		}
+	}
+
+	s.enableCapabilities(func(c *Capability) {
+		if c.Tools == nil {
+			c.Tools = &ToolsCapability{ListChanged: true}
+		}
+	})
+	s.notifyListChanged(MethodToolListChanged)
+
+	return nil
+}
+
+// PublishAgent publishes an agent as a tool taking the agent's input, so MCP
+// clients can delegate tasks to it.
+func (s *Server) PublishAgent(agent AgentToolProvider) error {
+	tool := agent.AsTool()
+
+	if err := s.publishTool(tool); err != nil {
+		return err
+	}
+
+	s.enableCapabilities(func(c *Capability) {
+		if c.Tools == nil {
+			c.Tools = &ToolsCapability{ListChanged: true}
+		}
+	})
+	s.notifyListChanged(MethodToolListChanged)
+
+	return nil
+}
+
+// publishTool registers an SDK tool, converting its result to MCP content.
+func (s *Server) publishTool(tool sdk.Tool) error {
+	schema, err := json.Marshal(tool.Parameters)
+	if err != nil {
+		return fmt.Errorf("tool %s: %w", tool.Name, err)
+	}
+
+	handler := tool.Handler
+
+	s.RegisterTool(Tool{
+		Name:           tool.Name,
+		Description:    tool.Description,
+		InputSchema:    schema,
+		RequiredScopes: tool.RequiredScopes,
+	}, func(ctx context.Context, args map[string]any) ([]ToolResultContent, error) {
+		if handler == nil {
+			return nil, fmt.Errorf("tool %s has no handler", tool.Name)
+		}
+
+		if args == nil {
+			args = map[string]any{}
+		}
+
+		result, err := handler(ctx, args)
+		if err != nil {
+			return nil, err
+		}
+
+		return resultContent(result)
+	})
+
+	return nil
+}
+
+// PublishPrompts publishes the latest version of every template as a prompt
+// whose arguments are the template's variables. Getting the prompt renders
+// the template as a user message.
+func (s *Server) PublishPrompts(templates *sdk.PromptTemplateManager) {
+	published := make(map[string]bool)
+
+	for _, tmpl := range templates.ListTemplates() {
+		if published[tmpl.Name] {
+			continue
+		}
+
+		published[tmpl.Name] = true
+
+		latest, err := templates.GetTemplate(tmpl.Name, "")
+		if err != nil {
+			continue
+		}
+
+		prompt := Prompt{Name: latest.Name, Description: latest.Description}
+		for _, variable := range latest.Variables {
+			prompt.Arguments = append(prompt.Arguments, PromptArgument{Name: variable, Required: true})
+		}
+
+		name := latest.Name
+
+		s.RegisterPrompt(prompt, func(ctx context.Context, args map[string]any) (*GetPromptResponse, error) {
+			current, err := templates.GetTemplate(name, "")
+			if err != nil {
+				return nil, err
+			}
+
+			for _, variable := range current.Variables {
+				if _, ok := args[variable]; !ok {
+					return nil, fmt.Errorf("missing argument %s", variable)
+				}
+			}
+
+			text, err := templates.Render(name, "", args)
+			if err != nil {
+				return nil, err
+			}
+
+			return &GetPromptResponse{
+				Description: current.Description,
+				Messages: []PromptMessage{{
+					Role:    "user",
+					Content: PromptMessageContent{Type: "text", Text: text},
+				}},
+			}, nil
+		})
+	}
+
+	s.enableCapabilities(func(c *Capability) {
+		if c.Prompts == nil {
+			c.Prompts = &PromptsCapability{ListChanged: true}
+		}
+	})
+	s.notifyListChanged(MethodPromptListChanged)
+}
+
+// ArtifactURI returns the resource URI of an artifact.
+func ArtifactURI(id string) string {
+	return "artifact://" + url.PathEscape(id)
+}
+
+// DocumentURI returns the resource URI of a RAG document.
+func DocumentURI(id string) string {
+	return "rag://" + url.PathEscape(id)
+}
+
+// PublishArtifacts publishes the artifacts of registry as resources and keeps
+// them current: created and deleted artifacts change the resource list, and
+// updates notify clients subscribed to the artifact.
+func (s *Server) PublishArtifacts(artifacts *sdk.ArtifactRegistry) {
+	for _, artifact := range artifacts.List(nil) {
+		s.registerArtifact(artifacts, artifact)
+	}
+
+	artifacts.AddListener(sdk.ArtifactListener{
+		OnCreated: func(artifact *sdk.Artifact) {
+			s.registerArtifact(artifacts, artifact)
+			s.notifyListChanged(MethodResourceListChanged)
+		},
+		OnUpdated: func(artifact *sdk.Artifact) {
+			s.notifyResourceUpdated(ArtifactURI(artifact.ID))
+		},
+		OnDeleted: func(id string) {
+			s.resources.Unregister(ArtifactURI(id))
+			s.notifyListChanged(MethodResourceListChanged)
+		},
+	})
+
+	s.enableResources()
+	s.notifyListChanged(MethodResourceListChanged)
+}
+
+func (s *Server) registerArtifact(artifacts *sdk.ArtifactRegistry, artifact *sdk.Artifact) {
+	id := artifact.ID
+
+	mimeType := artifact.ContentType
+	if mimeType == "" {
+		mimeType = "text/plain"
+	}
+
+	description := artifact.Description
+	if description == "" {
+		description = artifact.Title
+	}
+
+	s.RegisterResource(Resource{
+		URI:         ArtifactURI(id),
+		Name:        artifact.Name,
+		Description: description,
+		MimeType:    mimeType,
+	}, func(ctx context.Context, uri string) (*ResourceContent, error) {
+		current, err := artifacts.Get(id)
+		if err != nil {
+			return nil, err
+		}
+
+		return &ResourceContent{URI: uri, MimeType: mimeType, Text: current.Content}, nil
+	})
+}
+
+// PublishDocuments indexes documents into rag, when it is not nil, and
+// publishes them as text resources. Publishing a document again replaces it
+// and notifies subscribed clients.
+func (s *Server) PublishDocuments(ctx context.Context, rag *sdk.RAG, docs ...sdk.Document) error {
+	var added, updated []string
+
+	for _, doc := range docs {
+		if rag != nil {
+			if err := rag.IndexDocument(ctx, doc); err != nil {
+				return fmt.Errorf("index document %s: %w", doc.ID, err)
+			}
+		}
+
+		uri := DocumentURI(doc.ID)
+
+		if _, exists := s.resources.Get(uri); exists {
+			updated = append(updated, uri)
+		} else {
+			added = append(added, uri)
+		}
+
+		name := doc.ID
+		if title, ok := doc.Metadata["title"].(string); ok && title != "" {
+			name = title
+		}
+
+		description, _ := doc.Metadata["description"].(string)
+
+		s.RegisterResource(Resource{
+			URI:         uri,
+			Name:        name,
+			Description: description,
+			MimeType:    "text/plain",
+		}, TextResourceHandler(doc.Content, "text/plain"))
+	}
+
+	s.enableResources()
+
+	if len(added) > 0 {
+		s.notifyListChanged(MethodResourceListChanged)
+	}
+
+	for _, uri := range updated {
+		s.notifyResourceUpdated(uri)
+	}
+
+	return nil
+}
+
+func (s *Server) enableResources() {
+	s.enableCapabilities(func(c *Capability) {
+		if c.Resources == nil {
+			c.Resources = &ResourcesCapability{Subscribe: true, ListChanged: true}
+		}
+	})
+}
+
+// enableCapabilities updates the capabilities announced at initialization.
+func (s *Server) enableCapabilities(fn func(*Capability)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fn(&s.capabilities)
+}
+
+// notifyListChanged tells initialized clients that a list has changed.
+func (s *Server) notifyListChanged(method Method) {
+	s.mu.RLock()
+	initialized := s.initialized
+	s.mu.RUnlock()
+
+	if !initialized {
+		return
+	}
+
+	if msg, err := NewMessage(method, nil, nil); err == nil {
+		_ = s.transport.Send(msg)
+	}
+}
+
+// notifyResourceUpdated notifies clients subscribed to uri.
+func (s *Server) notifyResourceUpdated(uri string) {
+	if s.subscribed(uri) {
+		_ = s.NotifyResourceUpdated(uri)
+	}
+}
+
+// resultContent converts a tool result to MCP content: strings become text,
+// MCP content and bridged tool outputs pass through, and other values are
+// sent as JSON text.
+func resultContent(result any) ([]ToolResultContent, error) {
+	switch value := result.(type) {
+	case nil:
+		return []ToolResultContent{}, nil
+	case string:
+		return []ToolResultContent{{Type: "text", Text: value}}, nil
+	case []ToolResultContent:
+		return value, nil
+	case *ToolOutput:
+		content := []ToolResultContent{}
+
+		if value.Text != "" {
+			content = append(content, ToolResultContent{Type: "text", Text: value.Text})
+		}
+
+		for _, image := range value.Images {
+			content = append(content, ToolResultContent{Type: "image", MimeType: image.MimeType, Data: image.Data})
+		}
+
+		for _, resource := range value.Resources {
+			content = append(content, ToolResultContent{Type: "resource", Resource: &resource})
+		}
+
+		return content, nil
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("encode tool result: %w", err)
+	}
+
+	return []ToolResultContent{{Type: "text", Text: string(data)}}, nil
+}
@@ -0,0 +1,109 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	sdk "github.com/xraph/ai-sdk"
+)
+
+type stubAgent struct{}
+
+func (stubAgent) AsTool() sdk.Tool {
+	return sdk.Tool{
+		Name:        "call_researcher",
+		Description: "Delegate to the researcher",
+		Parameters:  map[string]any{"type": "object", "properties": map[string]any{"input": map[string]any{"type": "string"}}},
+		Handler: func(ctx context.Context, params map[string]any) (any, error) {
+			return map[string]any{"content": "researched " + params["input"].(string)}, nil
+		},
+	}
+}
+
+func TestServer_Publish(t *testing.T) {
+	httpServer, server := newHTTPTestServer(t, HTTPServerConfig{})
+
+	registry := sdk.NewToolRegistry(nil, nil)
+	_ = registry.RegisterTool(&sdk.ToolDefinition{
+		Name:       "weather",
+		Parameters: sdk.ToolParameterSchema{Type: "object", Properties: map[string]sdk.ToolParameterProperty{"city": {Type: "string"}}, Required: []string{"city"}},
+		Handler: func(ctx context.Context, params map[string]any) (any, error) {
+			return "sunny in " + params["city"].(string), nil
+		},
+	})
+
+	templates := sdk.NewPromptTemplateManager(nil, nil)
+	_ = templates.RegisterTemplate(&sdk.PromptTemplate{Name: "greet", Description: "Greeting", Template: "Hello {{.name}}"})
+
+	artifacts := sdk.NewArtifactRegistry(nil, nil)
+	report := &sdk.Artifact{Name: "report", Type: sdk.ArtifactTypeMarkdown, Content: "# Draft"}
+	_ = artifacts.Create(report)
+
+	if err := server.PublishToolRegistry(registry); err != nil {
+		t.Fatalf("publish registry: %v", err)
+	}
+
+	if err := server.PublishAgent(stubAgent{}); err != nil {
+		t.Fatalf("publish agent: %v", err)
+	}
+
+	server.PublishPrompts(templates)
+	server.PublishArtifacts(artifacts)
+
+	if err := server.PublishDocuments(context.Background(), nil, sdk.Document{ID: "handbook", Content: "Be kind.", Metadata: map[string]any{"title": "Handbook"}}); err != nil {
+		t.Fatalf("publish documents: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client, err := ConnectHTTP(ctx, HTTPTransportConfig{URL: httpServer.URL + "/mcp"}, Implementation{Name: "client"})
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer client.Close()
+
+	if text, _ := callTool(t, client, "weather", map[string]any{"city": "Oslo"}); text != "sunny in Oslo" {
+		t.Errorf("unexpected registry tool result %q", text)
+	}
+
+	if text, isError := callTool(t, client, "weather", map[string]any{}); !isError || !strings.Contains(text, "city") {
+		t.Errorf("expected registry validation error, got %q", text)
+	}
+
+	if text, _ := callTool(t, client, "call_researcher", map[string]any{"input": "mcp"}); text != `{"content":"researched mcp"}` {
+		t.Errorf("unexpected agent result %q", text)
+	}
+
+	prompt, err := client.GetPrompt(ctx, "greet", map[string]any{"name": "Ada"})
+	if err != nil || prompt.Messages[0].Content.Text != "Hello Ada" {
+		t.Fatalf("unexpected prompt %+v: %v", prompt, err)
+	}
+
+	if _, err := client.GetPrompt(ctx, "greet", nil); err == nil {
+		t.Error("expected missing prompt arguments to be rejected")
+	}
+
+	contents, err := client.ReadResource(ctx, DocumentURI("handbook"))
+	if err != nil || contents[0].Text != "Be kind." {
+		t.Fatalf("unexpected document %+v: %v", contents, err)
+	}
+
+	updates := make(chan string, 1)
+	if err := client.SubscribeResource(ctx, ArtifactURI(report.ID), func(content ResourceContent) { updates <- content.Text }); err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+
+	_ = artifacts.Update(report.ID, "# Final")
+
+	select {
+	case text := <-updates:
+		if text != "# Final" {
+			t.Errorf("unexpected artifact update %q", text)
+		}
+	case <-ctx.Done():
+		t.Fatal("expected artifact update to reach the subscriber")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"slices"
 	"strings"
 	"sync"
 )
@@ -11,20 +12,31 @@ import (
 // ResourceHandler handles resource read requests.
 type ResourceHandler func(ctx context.Context, uri string) (*ResourceContent, error)
 
+// ResourceTemplateHandler handles reads of resources matching a template;
+// vars holds the decoded template variables.
+type ResourceTemplateHandler func(ctx context.Context, uri string, vars map[string]string) (*ResourceContent, error)
+
 // ToolHandler handles tool call requests.
 type ToolHandler func(ctx context.Context, args map[string]any) ([]ToolResultContent, error)
 
 // PromptHandler handles prompt get requests.
 type PromptHandler func(ctx context.Context, args map[string]any) (*GetPromptResponse, error)
 
-// ResourceRegistry manages registered resources.
+// ResourceRegistry manages registered resources and resource templates.
 type ResourceRegistry struct {
 	resources     map[string]Resource
 	handlers      map[string]ResourceHandler
+	templates     []*registeredTemplate
 	subscriptions map[string]bool
 	mu            sync.RWMutex
 }
 
+type registeredTemplate struct {
+	template ResourceTemplate
+	uri      *URITemplate
+	handler  ResourceTemplateHandler
+}
+
 // NewResourceRegistry creates a new resource registry.
 func NewResourceRegistry() *ResourceRegistry {
 	return &ResourceRegistry{
@@ -34,6 +46,83 @@ func NewResourceRegistry() *ResourceRegistry {
 	}
 }
 
+// RegisterTemplate registers a resource template with its handler, replacing
+// a template with the same URI template. Reads of URIs that match no
+// registered resource go to the matching template with the most literal
+// characters.
+func (r *ResourceRegistry) RegisterTemplate(template ResourceTemplate, handler ResourceTemplateHandler) error {
+	uri, err := ParseURITemplate(template.URITemplate)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.templates = slices.DeleteFunc(r.templates, func(t *registeredTemplate) bool {
+		return t.template.URITemplate == template.URITemplate
+	})
+	r.templates = append(r.templates, &registeredTemplate{template: template, uri: uri, handler: handler})
+
+	// Most specific first
+	slices.SortStableFunc(r.templates, func(a, b *registeredTemplate) int {
+		if a.uri.literal != b.uri.literal {
+			return b.uri.literal - a.uri.literal
+		}
+
+		return strings.Compare(a.template.URITemplate, b.template.URITemplate)
+	})
+
+	return nil
+}
+
+// UnregisterTemplate removes a resource template.
+func (r *ResourceRegistry) UnregisterTemplate(uriTemplate string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.templates = slices.DeleteFunc(r.templates, func(t *registeredTemplate) bool {
+		return t.template.URITemplate == uriTemplate
+	})
+}
+
+// ListTemplates returns all registered resource templates.
+func (r *ResourceRegistry) ListTemplates() []ResourceTemplate {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	templates := make([]ResourceTemplate, 0, len(r.templates))
+	for _, t := range r.templates {
+		templates = append(templates, t.template)
+	}
+
+	slices.SortFunc(templates, func(a, b ResourceTemplate) int { return strings.Compare(a.URITemplate, b.URITemplate) })
+
+	return templates
+}
+
+// HasTemplate reports whether a resource template is registered.
+func (r *ResourceRegistry) HasTemplate(uriTemplate string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return slices.ContainsFunc(r.templates, func(t *registeredTemplate) bool {
+		return t.template.URITemplate == uriTemplate
+	})
+}
+
+// matchTemplate returns the most specific template matching uri. Callers
+// hold r.mu.
+func (r *ResourceRegistry) matchTemplate(uri string) (*registeredTemplate, map[string]string) {
+	for _, t := range r.templates {
+		if vars, ok := t.uri.Match(uri); ok {
+			return t, vars
+		}
+	}
+
+	return nil, nil
+}
+
 // Register registers a resource with its handler.
 func (r *ResourceRegistry) Register(resource Resource, handler ResourceHandler) {
 	r.mu.Lock()
@@ -63,6 +152,8 @@ func (r *ResourceRegistry) List() []Resource {
 		resources = append(resources, res)
 	}
 
+	slices.SortFunc(resources, func(a, b Resource) int { return strings.Compare(a.URI, b.URI) })
+
 	return resources
 }
 
@@ -76,25 +167,56 @@ func (r *ResourceRegistry) Get(uri string) (Resource, bool) {
 	return res, ok
 }
 
-// Read reads a resource by URI.
+// Read reads a resource by URI, falling back to the matching template.
 func (r *ResourceRegistry) Read(ctx context.Context, uri string) (*ResourceContent, error) {
 	r.mu.RLock()
 	handler, ok := r.handlers[uri]
-	r.mu.RUnlock()
+
+	var (
+		template *registeredTemplate
+		vars     map[string]string
+	)
 
 	if !ok {
-		return nil, fmt.Errorf("resource not found: %s", uri)
+		template, vars = r.matchTemplate(uri)
+	}
+	r.mu.RUnlock()
+
+	if ok {
+		return handler(ctx, uri)
+	}
+
+	if template != nil {
+		return template.handler(ctx, uri, vars)
+	}
+
+	return nil, fmt.Errorf("resource not found: %s", uri)
+}
+
+// Exists reports whether uri is a registered resource or matches a template.
+func (r *ResourceRegistry) Exists(uri string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.exists(uri)
+}
+
+func (r *ResourceRegistry) exists(uri string) bool {
+	if _, ok := r.resources[uri]; ok {
+		return true
 	}
 
-	return handler(ctx, uri)
+	template, _ := r.matchTemplate(uri)
+
+	return template != nil
 }
 
-// Subscribe marks a resource as subscribed.
+// Subscribe marks a resource, or a URI matching a template, as subscribed.
 func (r *ResourceRegistry) Subscribe(uri string) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	if _, ok := r.resources[uri]; !ok {
+	if !r.exists(uri) {
 		return fmt.Errorf("resource not found: %s", uri)
 	}
 
@@ -162,6 +284,8 @@ func (r *ToolRegistry) List() []Tool {
 		tools = append(tools, tool)
 	}
 
+	slices.SortFunc(tools, func(a, b Tool) int { return strings.Compare(a.Name, b.Name) })
+
 	return tools
 }
 
@@ -231,9 +355,21 @@ func (r *PromptRegistry) List() []Prompt {
 		prompts = append(prompts, prompt)
 	}
 
+	slices.SortFunc(prompts, func(a, b Prompt) int { return strings.Compare(a.Name, b.Name) })
+
 	return prompts
 }
 
+// Has reports whether a prompt is registered.
+func (r *PromptRegistry) Has(name string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, ok := r.prompts[name]
+
+	return ok
+}
+
 // Get retrieves a prompt by name with the given arguments.
 func (r *PromptRegistry) Get(ctx context.Context, name string, args map[string]any) (*GetPromptResponse, error) {
 	r.mu.RLock()
@@ -3,12 +3,17 @@ package mcp
 import (
 	"bufio"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"os"
+	"strconv"
 	"sync"
+	"sync/atomic"
+
+	sdk "github.com/xraph/ai-sdk"
 )
 
 // Server is an MCP server implementation.
@@ -21,10 +26,38 @@ type Server struct {
 	transport    ServerTransport
 	logLevel     LogLevel
 	onLog        func(LogNotification)
+	principal    *sdk.Principal
+	auditHook    sdk.ToolAuditHook
+	pageSize     int
+	inflight     map[inflightKey]context.CancelCauseFunc
+	completions  map[CompletionReference]CompletionHandler
 	mu           sync.RWMutex
 	initialized  bool
 	ctx          context.Context
 	cancel       context.CancelFunc
+
+	// Client features, see CreateMessage, ListRoots and Elicit
+	clients        map[string]Capability
+	requestID      atomic.Int64
+	pending        map[int64]serverRequest
+	onRootsChanged func(ctx context.Context)
+
+	// Per-session state: the sessions subscribed to each resource URI and
+	// the log level each session asked for. logLevel is the default.
+	subscriptions map[string]map[string]bool
+	logLevels     map[string]LogLevel
+}
+
+// inflightKey identifies a client request being handled.
+type inflightKey struct {
+	session string
+	id      string
+}
+
+// serverRequest is a request sent to a client, awaiting its response.
+type serverRequest struct {
+	session  string
+	response chan *Message
 }
 
 // ServerTransport represents a server-side transport.
@@ -34,29 +67,82 @@ type ServerTransport interface {
 	Close() error
 }
 
+// ContextTransport is implemented by server transports that multiplex several
+// clients, such as HTTPServerTransport. The received context carries the
+// caller's session and principal; sending with it addresses that session.
+type ContextTransport interface {
+	ReceiveContext(ctx context.Context) (context.Context, *Message, error)
+	SendContext(ctx context.Context, msg *Message) error
+}
+
+// SessionNotifier is implemented by transports whose sessions end on their
+// own, so that the server can drop their state.
+type SessionNotifier interface {
+	OnSessionClosed(fn func(sessionID string))
+}
+
 // ServerConfig configures the MCP server.
 type ServerConfig struct {
 	Info         Implementation
 	Capabilities Capability
 	OnLog        func(LogNotification)
+
+	// Principal is the identity of the connected client. Tools that declare
+	// RequiredScopes can only be called when it holds those scopes. A principal
+	// supplied per session by the transport takes precedence.
+	Principal *sdk.Principal
+
+	// AuditHook receives authorization decisions for tools that require scopes
+	AuditHook sdk.ToolAuditHook
+
+	// OnRootsChanged is called when a client reports that its roots changed.
+	// ctx addresses the client, so the handler can call ListRoots with it.
+	OnRootsChanged func(ctx context.Context)
+
+	// PageSize is the number of tools, resources or prompts per list page.
+	// Defaults to DefaultPageSize.
+	PageSize int
 }
 
+// DefaultPageSize is the default number of items per list page.
+const DefaultPageSize = 100
+
 // NewServer creates a new MCP server.
 func NewServer(transport ServerTransport, config ServerConfig) *Server {
 	ctx, cancel := context.WithCancel(context.Background())
 
-	return &Server{
-		info:         config.Info,
-		capabilities: config.Capabilities,
-		resources:    NewResourceRegistry(),
-		tools:        NewToolRegistry(),
-		prompts:      NewPromptRegistry(),
-		transport:    transport,
-		logLevel:     LogLevelInfo,
-		onLog:        config.OnLog,
-		ctx:          ctx,
-		cancel:       cancel,
+	if config.PageSize <= 0 {
+		config.PageSize = DefaultPageSize
+	}
+
+	s := &Server{
+		info:           config.Info,
+		capabilities:   config.Capabilities,
+		resources:      NewResourceRegistry(),
+		tools:          NewToolRegistry(),
+		prompts:        NewPromptRegistry(),
+		transport:      transport,
+		logLevel:       LogLevelInfo,
+		onLog:          config.OnLog,
+		principal:      config.Principal,
+		auditHook:      config.AuditHook,
+		pageSize:       config.PageSize,
+		inflight:       make(map[inflightKey]context.CancelCauseFunc),
+		completions:    make(map[CompletionReference]CompletionHandler),
+		ctx:            ctx,
+		cancel:         cancel,
+		clients:        make(map[string]Capability),
+		pending:        make(map[int64]serverRequest),
+		onRootsChanged: config.OnRootsChanged,
+		subscriptions:  make(map[string]map[string]bool),
+		logLevels:      make(map[string]LogLevel),
+	}
+
+	if notifier, ok := transport.(SessionNotifier); ok {
+		notifier.OnSessionClosed(s.forgetSession)
 	}
+
+	return s
 }
 
 // Start starts the server message processing loop.
@@ -68,6 +154,21 @@ func (s *Server) Start() error {
 		default:
 		}
 
+		if transport, ok := s.transport.(ContextTransport); ok {
+			ctx, msg, err := transport.ReceiveContext(s.ctx)
+			if err != nil {
+				if errors.Is(err, io.EOF) {
+					return nil
+				}
+
+				continue
+			}
+
+			go s.serve(ctx, msg)
+
+			continue
+		}
+
 		msg, err := s.transport.Receive()
 		if err != nil {
 			if errors.Is(err, io.EOF) {
@@ -77,15 +178,57 @@ func (s *Server) Start() error {
 			continue
 		}
 
-		resp := s.handleMessage(msg)
-		if resp != nil {
-			if err := s.transport.Send(resp); err != nil {
-				continue
-			}
+		// Handlers may wait for the client to answer a server request, which
+		// arrives through this loop
+		go s.serve(s.ctx, msg)
+	}
+}
+
+// serve handles one message and sends its response, if any. Requests can be
+// cancelled by the client, in which case no response is sent.
+func (s *Server) serve(ctx context.Context, msg *Message) {
+	if isRequest(msg) {
+		var cancel context.CancelCauseFunc
+
+		ctx, cancel = context.WithCancelCause(ctx)
+		key := inflightKey{session: SessionIDFromContext(ctx), id: string(msg.ID)}
+
+		s.mu.Lock()
+		s.inflight[key] = cancel
+		s.mu.Unlock()
+
+		defer func() {
+			s.mu.Lock()
+			delete(s.inflight, key)
+			s.mu.Unlock()
+
+			cancel(nil)
+		}()
+
+		var params struct {
+			Meta *RequestMeta `json:"_meta"`
 		}
+
+		if json.Unmarshal(msg.Params, &params) == nil && params.Meta != nil && params.Meta.ProgressToken != nil {
+			ctx = context.WithValue(ctx, progressTokenKey{}, params.Meta.ProgressToken)
+		}
+	}
+
+	resp := s.handleMessage(ctx, msg)
+	if resp != nil && !errors.Is(context.Cause(ctx), ErrRequestCancelled) {
+		_ = s.send(ctx, resp)
 	}
 }
 
+// send sends msg to the client addressed by ctx.
+func (s *Server) send(ctx context.Context, msg *Message) error {
+	if transport, ok := s.transport.(ContextTransport); ok {
+		return transport.SendContext(ctx, msg)
+	}
+
+	return s.transport.Send(msg)
+}
+
 // Stop stops the server.
 func (s *Server) Stop() error {
 	s.cancel()
@@ -94,36 +237,56 @@ func (s *Server) Stop() error {
 }
 
 // handleMessage processes an incoming message.
-func (s *Server) handleMessage(msg *Message) *Message {
+func (s *Server) handleMessage(ctx context.Context, msg *Message) *Message {
+	if msg.Method == "" {
+		s.handleResponse(ctx, msg)
+
+		return nil
+	}
+
+	if !isRequest(msg) {
+		s.handleNotification(ctx, msg)
+
+		return nil
+	}
+
 	switch msg.Method {
 	case MethodInitialize:
-		return s.handleInitialize(msg)
+		return s.handleInitialize(ctx, msg)
 	case MethodShutdown:
 		return s.handleShutdown(msg)
+	case MethodPing:
+		resp, _ := NewResponse(msg.ID, struct{}{})
+
+		return resp
 	case MethodListResources:
 		return s.handleListResources(msg)
 	case MethodReadResource:
-		return s.handleReadResource(msg)
+		return s.handleReadResource(ctx, msg)
 	case MethodSubscribeResource:
-		return s.handleSubscribeResource(msg)
+		return s.handleSubscribeResource(ctx, msg)
 	case MethodUnsubscribeResource:
-		return s.handleUnsubscribeResource(msg)
+		return s.handleUnsubscribeResource(ctx, msg)
+	case MethodListResourceTemplates:
+		return s.handleListResourceTemplates(msg)
 	case MethodListTools:
 		return s.handleListTools(msg)
 	case MethodCallTool:
-		return s.handleCallTool(msg)
+		return s.handleCallTool(ctx, msg)
 	case MethodListPrompts:
 		return s.handleListPrompts(msg)
 	case MethodGetPrompt:
-		return s.handleGetPrompt(msg)
+		return s.handleGetPrompt(ctx, msg)
 	case MethodSetLogLevel:
-		return s.handleSetLogLevel(msg)
+		return s.handleSetLogLevel(ctx, msg)
+	case MethodComplete:
+		return s.handleComplete(ctx, msg)
 	default:
 		return NewErrorResponse(msg.ID, ErrorCodeMethodNotFound, "method not found")
 	}
 }
 
-func (s *Server) handleInitialize(msg *Message) *Message {
+func (s *Server) handleInitialize(ctx context.Context, msg *Message) *Message {
 	var req InitializeRequest
 	if err := json.Unmarshal(msg.Params, &req); err != nil {
 		return NewErrorResponse(msg.ID, ErrorCodeInvalidParams, err.Error())
@@ -131,17 +294,143 @@ func (s *Server) handleInitialize(msg *Message) *Message {
 
 	s.mu.Lock()
 	s.initialized = true
+	s.clients[SessionIDFromContext(ctx)] = req.Capabilities
+	capabilities := s.capabilities
 	s.mu.Unlock()
 
 	resp, _ := NewResponse(msg.ID, InitializeResponse{
 		ProtocolVersion: ProtocolVersion,
-		Capabilities:    s.capabilities,
+		Capabilities:    capabilities,
 		ServerInfo:      s.info,
 	})
 
 	return resp
 }
 
+// handleNotification processes a client notification.
+func (s *Server) handleNotification(ctx context.Context, msg *Message) {
+	switch msg.Method {
+	case MethodRootsListChanged:
+		if s.onRootsChanged != nil {
+			s.onRootsChanged(ctx)
+		}
+	case MethodCancelled:
+		var notif CancelledNotification
+		if err := json.Unmarshal(msg.Params, &notif); err != nil {
+			return
+		}
+
+		s.mu.RLock()
+		cancel, ok := s.inflight[inflightKey{session: SessionIDFromContext(ctx), id: string(notif.RequestID)}]
+		s.mu.RUnlock()
+
+		if ok {
+			cancel(ErrRequestCancelled)
+		}
+	}
+}
+
+// handleResponse delivers the client's response to a server request. Only the
+// session the request was sent to can answer it.
+func (s *Server) handleResponse(ctx context.Context, msg *Message) {
+	var id int64
+	if err := json.Unmarshal(msg.ID, &id); err != nil {
+		return
+	}
+
+	s.mu.Lock()
+	req, ok := s.pending[id]
+	ok = ok && req.session == SessionIDFromContext(ctx)
+	if ok {
+		delete(s.pending, id)
+	}
+	s.mu.Unlock()
+
+	if ok {
+		req.response <- msg
+	}
+}
+
+// request sends a request to the client addressed by ctx and waits for its
+// response.
+func (s *Server) request(ctx context.Context, method Method, params any) (*Message, error) {
+	id := s.requestID.Add(1)
+
+	msg, err := NewMessage(method, id, params)
+	if err != nil {
+		return nil, err
+	}
+
+	req := serverRequest{session: SessionIDFromContext(ctx), response: make(chan *Message, 1)}
+
+	s.mu.Lock()
+	s.pending[id] = req
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.pending, id)
+		s.mu.Unlock()
+	}()
+
+	if err := s.send(ctx, msg); err != nil {
+		return nil, err
+	}
+
+	select {
+	case <-ctx.Done():
+		_ = sendCancelled(func(msg *Message) error { return s.send(ctx, msg) }, msg.ID, ctx.Err())
+
+		return nil, ctx.Err()
+	case <-s.ctx.Done():
+		return nil, ErrTransportClosed
+	case resp, ok := <-req.response:
+		if !ok {
+			return nil, ErrSessionNotFound
+		}
+
+		if resp.Error != nil {
+			return nil, fmt.Errorf("MCP error %d: %s", resp.Error.Code, resp.Error.Message)
+		}
+
+		return resp, nil
+	}
+}
+
+// clientCapabilities returns the capabilities declared by the client
+// addressed by ctx.
+func (s *Server) clientCapabilities(ctx context.Context) Capability {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.clients[SessionIDFromContext(ctx)]
+}
+
+// forgetSession drops the state of an ended session and fails its pending
+// requests.
+func (s *Server) forgetSession(sessionID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.clients, sessionID)
+	delete(s.logLevels, sessionID)
+
+	for uri, sessions := range s.subscriptions {
+		delete(sessions, sessionID)
+
+		if len(sessions) == 0 {
+			delete(s.subscriptions, uri)
+		}
+	}
+
+	for id, req := range s.pending {
+		if req.session == sessionID {
+			delete(s.pending, id)
+			close(req.response)
+		}
+	}
+}
+
 func (s *Server) handleShutdown(msg *Message) *Message {
 	s.cancel()
 
@@ -151,21 +440,40 @@ func (s *Server) handleShutdown(msg *Message) *Message {
 }
 
 func (s *Server) handleListResources(msg *Message) *Message {
-	resources := s.resources.List()
+	resources, next, err := paginate(s.resources.List(), msg.Params, s.pageSize)
+	if err != nil {
+		return NewErrorResponse(msg.ID, ErrorCodeInvalidParams, err.Error())
+	}
+
 	resp, _ := NewResponse(msg.ID, ListResourcesResponse{
-		Resources: resources,
+		Resources:  resources,
+		NextCursor: next,
+	})
+
+	return resp
+}
+
+func (s *Server) handleListResourceTemplates(msg *Message) *Message {
+	templates, next, err := paginate(s.resources.ListTemplates(), msg.Params, s.pageSize)
+	if err != nil {
+		return NewErrorResponse(msg.ID, ErrorCodeInvalidParams, err.Error())
+	}
+
+	resp, _ := NewResponse(msg.ID, ListResourceTemplatesResponse{
+		ResourceTemplates: templates,
+		NextCursor:        next,
 	})
 
 	return resp
 }
 
-func (s *Server) handleReadResource(msg *Message) *Message {
+func (s *Server) handleReadResource(ctx context.Context, msg *Message) *Message {
 	var req ReadResourceRequest
 	if err := json.Unmarshal(msg.Params, &req); err != nil {
 		return NewErrorResponse(msg.ID, ErrorCodeInvalidParams, err.Error())
 	}
 
-	content, err := s.resources.Read(s.ctx, req.URI)
+	content, err := s.resources.Read(ctx, req.URI)
 	if err != nil {
 		return NewErrorResponse(msg.ID, ErrorCodeResourceNotFound, err.Error())
 	}
@@ -177,28 +485,46 @@ func (s *Server) handleReadResource(msg *Message) *Message {
 	return resp
 }
 
-func (s *Server) handleSubscribeResource(msg *Message) *Message {
+func (s *Server) handleSubscribeResource(ctx context.Context, msg *Message) *Message {
 	var req SubscribeRequest
 	if err := json.Unmarshal(msg.Params, &req); err != nil {
 		return NewErrorResponse(msg.ID, ErrorCodeInvalidParams, err.Error())
 	}
 
-	if err := s.resources.Subscribe(req.URI); err != nil {
-		return NewErrorResponse(msg.ID, ErrorCodeResourceNotFound, err.Error())
+	if !s.resources.Exists(req.URI) {
+		return NewErrorResponse(msg.ID, ErrorCodeResourceNotFound, "resource not found: "+req.URI)
+	}
+
+	s.mu.Lock()
+	sessions := s.subscriptions[req.URI]
+	if sessions == nil {
+		sessions = make(map[string]bool)
+		s.subscriptions[req.URI] = sessions
 	}
 
+	sessions[SessionIDFromContext(ctx)] = true
+	s.mu.Unlock()
+
 	resp, _ := NewResponse(msg.ID, nil)
 
 	return resp
 }
 
-func (s *Server) handleUnsubscribeResource(msg *Message) *Message {
+func (s *Server) handleUnsubscribeResource(ctx context.Context, msg *Message) *Message {
 	var req UnsubscribeRequest
 	if err := json.Unmarshal(msg.Params, &req); err != nil {
 		return NewErrorResponse(msg.ID, ErrorCodeInvalidParams, err.Error())
 	}
 
-	s.resources.Unsubscribe(req.URI)
+	s.mu.Lock()
+	if sessions := s.subscriptions[req.URI]; sessions != nil {
+		delete(sessions, SessionIDFromContext(ctx))
+
+		if len(sessions) == 0 {
+			delete(s.subscriptions, req.URI)
+		}
+	}
+	s.mu.Unlock()
 
 	resp, _ := NewResponse(msg.ID, nil)
 
@@ -206,21 +532,30 @@ func (s *Server) handleUnsubscribeResource(msg *Message) *Message {
 }
 
 func (s *Server) handleListTools(msg *Message) *Message {
-	tools := s.tools.List()
+	tools, next, err := paginate(s.tools.List(), msg.Params, s.pageSize)
+	if err != nil {
+		return NewErrorResponse(msg.ID, ErrorCodeInvalidParams, err.Error())
+	}
+
 	resp, _ := NewResponse(msg.ID, ListToolsResponse{
-		Tools: tools,
+		Tools:      tools,
+		NextCursor: next,
 	})
 
 	return resp
 }
 
-func (s *Server) handleCallTool(msg *Message) *Message {
+func (s *Server) handleCallTool(ctx context.Context, msg *Message) *Message {
 	var req CallToolRequest
 	if err := json.Unmarshal(msg.Params, &req); err != nil {
 		return NewErrorResponse(msg.ID, ErrorCodeInvalidParams, err.Error())
 	}
 
-	result, err := s.tools.Call(s.ctx, req.Name, req.Arguments)
+	if sdk.PrincipalFromContext(ctx) == nil && s.principal != nil {
+		ctx = sdk.WithPrincipal(ctx, s.principal)
+	}
+
+	result, err := s.callTool(ctx, req.Name, req.Arguments)
 	if err != nil {
 		resp, _ := NewResponse(msg.ID, CallToolResponse{
 			Content: []ToolResultContent{{
@@ -240,22 +575,71 @@ func (s *Server) handleCallTool(msg *Message) *Message {
 	return resp
 }
 
+// callTool authorizes and runs a tool call; denials are returned as tool errors.
+func (s *Server) callTool(ctx context.Context, name string, args map[string]any) ([]ToolResultContent, error) {
+	ctx = sdk.WithToolOrigin(ctx, sdk.ToolOriginMCP)
+
+	if tool, ok := s.tools.Get(name); ok {
+		if err := sdk.AuthorizeTool(ctx, sdk.ToolOriginMCP, name, tool.RequiredScopes, s.auditHook); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.tools.Call(ctx, name, args)
+}
+
 func (s *Server) handleListPrompts(msg *Message) *Message {
-	prompts := s.prompts.List()
+	prompts, next, err := paginate(s.prompts.List(), msg.Params, s.pageSize)
+	if err != nil {
+		return NewErrorResponse(msg.ID, ErrorCodeInvalidParams, err.Error())
+	}
+
 	resp, _ := NewResponse(msg.ID, ListPromptsResponse{
-		Prompts: prompts,
+		Prompts:    prompts,
+		NextCursor: next,
 	})
 
 	return resp
 }
 
-func (s *Server) handleGetPrompt(msg *Message) *Message {
+// paginate returns the page of items selected by the cursor of a list
+// request, and the cursor of the next page. Cursors encode the page offset.
+func paginate[T any](items []T, params json.RawMessage, size int) ([]T, string, error) {
+	var req ListRequest
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &req); err != nil {
+			return nil, "", err
+		}
+	}
+
+	offset := 0
+
+	if req.Cursor != "" {
+		decoded, err := base64.RawURLEncoding.DecodeString(req.Cursor)
+		if err == nil {
+			offset, err = strconv.Atoi(string(decoded))
+		}
+
+		if err != nil || offset < 0 || offset > len(items) {
+			return nil, "", fmt.Errorf("invalid cursor %q", req.Cursor)
+		}
+	}
+
+	end := min(offset+size, len(items))
+	if end == len(items) {
+		return items[offset:end], "", nil
+	}
+
+	return items[offset:end], base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(end))), nil
+}
+
+func (s *Server) handleGetPrompt(ctx context.Context, msg *Message) *Message {
 	var req GetPromptRequest
 	if err := json.Unmarshal(msg.Params, &req); err != nil {
 		return NewErrorResponse(msg.ID, ErrorCodeInvalidParams, err.Error())
 	}
 
-	result, err := s.prompts.Get(s.ctx, req.Name, req.Arguments)
+	result, err := s.prompts.Get(ctx, req.Name, req.Arguments)
 	if err != nil {
 		return NewErrorResponse(msg.ID, ErrorCodeInvalidParams, err.Error())
 	}
@@ -265,14 +649,14 @@ func (s *Server) handleGetPrompt(msg *Message) *Message {
 	return resp
 }
 
-func (s *Server) handleSetLogLevel(msg *Message) *Message {
+func (s *Server) handleSetLogLevel(ctx context.Context, msg *Message) *Message {
 	var req SetLogLevelRequest
 	if err := json.Unmarshal(msg.Params, &req); err != nil {
 		return NewErrorResponse(msg.ID, ErrorCodeInvalidParams, err.Error())
 	}
 
 	s.mu.Lock()
-	s.logLevel = req.Level
+	s.logLevels[SessionIDFromContext(ctx)] = req.Level
 	s.mu.Unlock()
 
 	resp, _ := NewResponse(msg.ID, nil)
@@ -285,6 +669,14 @@ func (s *Server) RegisterResource(resource Resource, handler ResourceHandler) {
 	s.resources.Register(resource, handler)
 }
 
+// RegisterResourceTemplate registers a resource template with the server.
+// Reads of URIs matching the template go to handler with the template's
+// variables, e.g. {"name": "users"} for db://tables/users and the template
+// db://tables/{name}.
+func (s *Server) RegisterResourceTemplate(template ResourceTemplate, handler ResourceTemplateHandler) error {
+	return s.resources.RegisterTemplate(template, handler)
+}
+
 // RegisterTool registers a tool with the server.
 func (s *Server) RegisterTool(tool Tool, handler ToolHandler) {
 	s.tools.Register(tool, handler)
@@ -295,14 +687,49 @@ func (s *Server) RegisterPrompt(prompt Prompt, handler PromptHandler) {
 	s.prompts.Register(prompt, handler)
 }
 
-// NotifyResourceUpdated sends a resource updated notification.
+// NotifyResourceUpdated sends a resource updated notification to the
+// sessions subscribed to uri.
 func (s *Server) NotifyResourceUpdated(uri string) error {
+	s.mu.RLock()
+	sessions := make([]string, 0, len(s.subscriptions[uri]))
+	for session := range s.subscriptions[uri] {
+		sessions = append(sessions, session)
+	}
+	s.mu.RUnlock()
+
+	if len(sessions) == 0 {
+		return nil
+	}
+
 	msg, err := NewMessage(MethodResourceUpdated, nil, ResourceUpdatedNotification{URI: uri})
 	if err != nil {
 		return err
 	}
 
-	return s.transport.Send(msg)
+	return s.sendToSessions(sessions, msg)
+}
+
+// subscribed reports whether any session is subscribed to uri.
+func (s *Server) subscribed(uri string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return len(s.subscriptions[uri]) > 0
+}
+
+// sendToSessions sends msg to each of sessions. Sessions that ended in the
+// meantime are skipped.
+func (s *Server) sendToSessions(sessions []string, msg *Message) error {
+	var errs []error
+
+	for _, session := range sessions {
+		ctx := context.WithValue(s.ctx, sessionIDKey{}, session)
+		if err := s.send(ctx, msg); err != nil && !errors.Is(err, ErrSessionNotFound) {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
 }
 
 // NotifyResourceListChanged sends a resource list changed notification.
@@ -315,6 +742,29 @@ func (s *Server) NotifyResourceListChanged() error {
 	return s.transport.Send(msg)
 }
 
+type progressTokenKey struct{}
+
+// NotifyProgress reports the progress of the request handled with ctx, if the
+// client asked for progress. total is 0 when unknown.
+func (s *Server) NotifyProgress(ctx context.Context, progress, total float64, message string) error {
+	token := ctx.Value(progressTokenKey{})
+	if token == nil {
+		return nil
+	}
+
+	msg, err := NewMessage(MethodProgress, nil, ProgressNotification{
+		ProgressToken: token,
+		Progress:      progress,
+		Total:         total,
+		Message:       message,
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.send(ctx, msg)
+}
+
 // NotifyToolListChanged sends a tool list changed notification.
 func (s *Server) NotifyToolListChanged() error {
 	msg, err := NewMessage(MethodToolListChanged, nil, nil)
@@ -325,14 +775,28 @@ func (s *Server) NotifyToolListChanged() error {
 	return s.transport.Send(msg)
 }
 
-// Log sends a log notification.
+// Log sends a log notification to each session whose log level, set with
+// logging/setLevel, is at or below level. Sessions that set no level get
+// notifications from LogLevelInfo up.
 func (s *Server) Log(level LogLevel, logger string, data any) error {
+	var sessions []string
+
 	s.mu.RLock()
-	currentLevel := s.logLevel
+	if _, ok := s.transport.(ContextTransport); ok {
+		for session := range s.clients {
+			if shouldLog(level, s.sessionLogLevel(session)) {
+				sessions = append(sessions, session)
+			}
+		}
+	} else if shouldLog(level, s.sessionLogLevel("")) {
+		// A single-client transport has one session, "".
+		sessions = append(sessions, "")
+	}
+
+	defaultLevel := s.logLevel
 	s.mu.RUnlock()
 
-	// Check if level is at or above current log level
-	if !shouldLog(level, currentLevel) {
+	if len(sessions) == 0 && !shouldLog(level, defaultLevel) {
 		return nil
 	}
 
@@ -351,7 +815,16 @@ func (s *Server) Log(level LogLevel, logger string, data any) error {
 		return err
 	}
 
-	return s.transport.Send(msg)
+	return s.sendToSessions(sessions, msg)
+}
+
+// sessionLogLevel returns the log level of a session. s.mu must be held.
+func (s *Server) sessionLogLevel(session string) LogLevel {
+	if level, ok := s.logLevels[session]; ok {
+		return level
+	}
+
+	return s.logLevel
 }
 
 func shouldLog(level, threshold LogLevel) bool {
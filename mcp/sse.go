@@ -0,0 +1,98 @@
+package mcp
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// sseEvent is one server-sent event.
+type sseEvent struct {
+	id    string
+	event string
+	data  string
+}
+
+// readSSEEvent reads the next event from an event stream, skipping comments.
+// An event cut off by the end of the stream is discarded.
+func readSSEEvent(r *bufio.Reader) (sseEvent, error) {
+	var (
+		event   sseEvent
+		data    []string
+		hasData bool
+	)
+
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			if err == io.EOF && line == "" {
+				return sseEvent{}, io.EOF
+			}
+
+			if err != io.EOF {
+				return sseEvent{}, err
+			}
+		}
+
+		line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+
+		if line == "" {
+			if err == io.EOF {
+				return sseEvent{}, io.EOF
+			}
+
+			if !hasData && event.id == "" && event.event == "" {
+				continue
+			}
+
+			event.data = strings.Join(data, "\n")
+
+			return event, nil
+		}
+
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+
+		switch field {
+		case "id":
+			event.id = value
+		case "event":
+			event.event = value
+		case "data":
+			data = append(data, value)
+			hasData = true
+		}
+
+		if err == io.EOF {
+			return sseEvent{}, io.EOF
+		}
+	}
+}
+
+// writeSSEEvent writes one event. Empty id and event fields are omitted.
+func writeSSEEvent(w io.Writer, id, event string, data []byte) error {
+	var b strings.Builder
+
+	if id != "" {
+		fmt.Fprintf(&b, "id: %s\n", id)
+	}
+
+	if event != "" {
+		fmt.Fprintf(&b, "event: %s\n", event)
+	}
+
+	for line := range strings.SplitSeq(string(data), "\n") {
+		fmt.Fprintf(&b, "data: %s\n", line)
+	}
+
+	b.WriteString("\n")
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
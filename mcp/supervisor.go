@@ -0,0 +1,566 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ServerDefinition describes how to reach an MCP server, in the format of an
+// entry of the "mcpServers" object of MCP configuration files. Command starts
+// a stdio server; URL reaches a Streamable HTTP or legacy SSE server.
+type ServerDefinition struct {
+	// Type is "stdio", "http" (or "streamable-http") or "sse". When empty it
+	// is "stdio" for commands, and URLs try Streamable HTTP before SSE.
+	Type string `json:"type,omitempty"`
+
+	Command string            `json:"command,omitempty"`
+	Args    []string          `json:"args,omitempty"`
+	Env     map[string]string `json:"env,omitempty"` // Added to the inherited environment
+	Cwd     string            `json:"cwd,omitempty"`
+
+	URL     string            `json:"url,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// Disabled servers are skipped by SuperviseServers.
+	Disabled bool `json:"disabled,omitempty"`
+}
+
+// Validate reports whether the definition describes one server.
+func (d ServerDefinition) Validate() error {
+	switch {
+	case d.Command == "" && d.URL == "":
+		return errors.New("mcp server definition needs a command or a url")
+	case d.Command != "" && d.URL != "":
+		return errors.New("mcp server definition has both a command and a url")
+	}
+
+	switch d.Type {
+	case "":
+	case "stdio":
+		if d.Command == "" {
+			return errors.New("stdio mcp server definition needs a command")
+		}
+	case "http", "streamable-http", "sse":
+		if d.URL == "" {
+			return fmt.Errorf("%s mcp server definition needs a url", d.Type)
+		}
+	default:
+		return fmt.Errorf("unknown mcp server type %q", d.Type)
+	}
+
+	return nil
+}
+
+// ParseServersConfig parses the "mcpServers" object of an MCP configuration
+// file:
+//
+//	{
+//	  "mcpServers": {
+//	    "files": {"command": "npx", "args": ["-y", "@modelcontextprotocol/server-filesystem", "/tmp"]},
+//	    "search": {"url": "https://search.example.com/mcp", "headers": {"Authorization": "Bearer ..."}}
+//	  }
+//	}
+func ParseServersConfig(data []byte) (map[string]ServerDefinition, error) {
+	var config struct {
+		MCPServers map[string]ServerDefinition `json:"mcpServers"`
+	}
+
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("parse mcp servers config: %w", err)
+	}
+
+	var errs []error
+
+	for _, name := range slices.Sorted(maps.Keys(config.MCPServers)) {
+		if err := config.MCPServers[name].Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("mcp server %s: %w", name, err))
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	return config.MCPServers, nil
+}
+
+// LoadServersConfig reads an MCP configuration file, see ParseServersConfig.
+func LoadServersConfig(path string) (map[string]ServerDefinition, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read mcp servers config: %w", err)
+	}
+
+	return ParseServersConfig(data)
+}
+
+// SupervisorConfig configures supervised server connections.
+type SupervisorConfig struct {
+	// Client configures every client of the server. Notification handlers
+	// must be set with Client.OnNotification: handlers registered with
+	// HandleNotification are not carried over to reconnected clients.
+	Client ClientConfig
+
+	// HTTP is the base transport configuration of URL servers, e.g. to set
+	// the http.Client or Auth. The definition's URL and headers are added.
+	HTTP HTTPTransportConfig
+
+	// ConnectTimeout bounds starting the server and initializing a session.
+	ConnectTimeout time.Duration
+
+	// PingInterval is the time between health checks; negative disables
+	// them. A ping that fails within PingTimeout reconnects the server.
+	PingInterval time.Duration
+	PingTimeout  time.Duration
+
+	// RestartDelay is the first delay before reconnecting, doubled after each
+	// failed attempt up to MaxRestartDelay.
+	RestartDelay    time.Duration
+	MaxRestartDelay time.Duration
+
+	// MaxRetries limits consecutive failed connection attempts before the
+	// server is given up as failed. Zero retries forever.
+	MaxRetries int
+
+	// OnStateChange is called with the connection's info on every state
+	// change.
+	OnStateChange func(ConnectionInfo)
+}
+
+func (c SupervisorConfig) withDefaults() SupervisorConfig {
+	if c.ConnectTimeout <= 0 {
+		c.ConnectTimeout = 30 * time.Second
+	}
+
+	if c.PingInterval == 0 {
+		c.PingInterval = 30 * time.Second
+	}
+
+	if c.PingTimeout <= 0 {
+		c.PingTimeout = 10 * time.Second
+	}
+
+	if c.RestartDelay <= 0 {
+		c.RestartDelay = 500 * time.Millisecond
+	}
+
+	if c.MaxRestartDelay < c.RestartDelay {
+		c.MaxRestartDelay = max(30*time.Second, c.RestartDelay)
+	}
+
+	return c
+}
+
+// stdioStopTimeout is how long a stdio server may take to exit after its
+// stdin is closed before it is killed.
+const stdioStopTimeout = 5 * time.Second
+
+// Supervise connects to a server and keeps it connected under name,
+// replacing any client of that name. Stdio servers are restarted when they
+// exit and HTTP servers reconnected when their session ends, with backoff;
+// servers that stop answering pings are reconnected too. Reconnected clients
+// are initialized again, resubscribed to their resources and given their
+// current roots. Supervise returns once the supervisor has started; follow
+// the connection with Connection or SupervisorConfig.OnStateChange.
+func (m *MCPClientManager) Supervise(name string, def ServerDefinition, config SupervisorConfig) error {
+	if err := def.Validate(); err != nil {
+		return fmt.Errorf("mcp server %s: %w", name, err)
+	}
+
+	m.RemoveClient(name)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	s := &supervisor{
+		name:    name,
+		def:     def,
+		config:  config.withDefaults(),
+		manager: m,
+		ctx:     ctx,
+		cancel:  cancel,
+		done:    make(chan struct{}),
+		info: ConnectionInfo{
+			Name:       name,
+			State:      ConnectionStateConnecting,
+			Supervised: true,
+		},
+	}
+
+	m.mu.Lock()
+	m.supervisors[name] = s
+	m.mu.Unlock()
+
+	go s.run()
+
+	return nil
+}
+
+// SuperviseServers supervises every enabled server of a configuration, see
+// LoadServersConfig and Supervise.
+func (m *MCPClientManager) SuperviseServers(servers map[string]ServerDefinition, config SupervisorConfig) error {
+	var errs []error
+
+	for _, name := range slices.Sorted(maps.Keys(servers)) {
+		if servers[name].Disabled {
+			continue
+		}
+
+		if err := m.Supervise(name, servers[name], config); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Connection returns the connection info of a server.
+func (m *MCPClientManager) Connection(name string) (ConnectionInfo, bool) {
+	m.mu.RLock()
+	s, supervised := m.supervisors[name]
+	client, ok := m.clients[name]
+	m.mu.RUnlock()
+
+	if supervised {
+		return s.connectionInfo(), true
+	}
+
+	if !ok {
+		return ConnectionInfo{}, false
+	}
+
+	info := ConnectionInfo{
+		Name:         name,
+		State:        ConnectionStateDisconnected,
+		ServerInfo:   client.ServerInfo(),
+		Capabilities: client.ServerCapabilities(),
+	}
+	if client.IsConnected() {
+		info.State = ConnectionStateConnected
+	}
+
+	return info, true
+}
+
+// Connections returns the connection info of every server, sorted by name.
+func (m *MCPClientManager) Connections() []ConnectionInfo {
+	m.mu.RLock()
+
+	names := make(map[string]bool, len(m.clients)+len(m.supervisors))
+	for name := range m.clients {
+		names[name] = true
+	}
+
+	for name := range m.supervisors {
+		names[name] = true
+	}
+
+	m.mu.RUnlock()
+
+	infos := make([]ConnectionInfo, 0, len(names))
+
+	for _, name := range slices.Sorted(maps.Keys(names)) {
+		if info, ok := m.Connection(name); ok {
+			infos = append(infos, info)
+		}
+	}
+
+	return infos
+}
+
+// supervisor keeps one server connected.
+type supervisor struct {
+	name    string
+	def     ServerDefinition
+	config  SupervisorConfig
+	manager *MCPClientManager
+	ctx     context.Context
+	cancel  context.CancelFunc
+	done    chan struct{}
+
+	mu   sync.Mutex
+	info ConnectionInfo
+}
+
+// connectionState carries what a reconnected client inherits.
+type connectionState struct {
+	subscriptions map[string][]func(ResourceContent)
+	roots         []Root
+}
+
+func (s *supervisor) run() {
+	defer close(s.done)
+
+	delay := s.config.RestartDelay
+	state := connectionState{roots: s.config.Client.Roots}
+
+	for {
+		client, stop, err := s.connect(state)
+		if err != nil {
+			if s.ctx.Err() != nil {
+				s.update(func(info *ConnectionInfo) { info.State = ConnectionStateDisconnected })
+
+				return
+			}
+
+			failures := 0
+
+			s.update(func(info *ConnectionInfo) {
+				info.Failures++
+				info.LastError = err
+				info.State = ConnectionStateReconnecting
+
+				if s.config.MaxRetries > 0 && info.Failures > s.config.MaxRetries {
+					info.State = ConnectionStateError
+				}
+
+				failures = info.Failures
+			})
+
+			if s.config.MaxRetries > 0 && failures > s.config.MaxRetries {
+				return
+			}
+
+			if !s.sleep(delay) {
+				s.update(func(info *ConnectionInfo) { info.State = ConnectionStateDisconnected })
+
+				return
+			}
+
+			delay = min(2*delay, s.config.MaxRestartDelay)
+
+			continue
+		}
+
+		restoreCtx, cancel := context.WithTimeout(s.ctx, s.config.ConnectTimeout)
+		restoreConnection(restoreCtx, client, state)
+		cancel()
+
+		if !s.manager.addClient(s.name, client, s) {
+			stop()
+
+			return
+		}
+
+		connectedAt := time.Now()
+
+		s.update(func(info *ConnectionInfo) {
+			info.State = ConnectionStateConnected
+			info.ServerInfo = client.ServerInfo()
+			info.Capabilities = client.ServerCapabilities()
+			info.ConnectedAt = connectedAt
+			info.Failures = 0
+		})
+
+		err = s.monitor(client)
+
+		state = captureConnection(client)
+		stop()
+
+		if s.ctx.Err() != nil {
+			s.update(func(info *ConnectionInfo) { info.State = ConnectionStateDisconnected })
+
+			return
+		}
+
+		// Connections that fail right away keep backing off
+		if time.Since(connectedAt) > s.config.MaxRestartDelay {
+			delay = s.config.RestartDelay
+		}
+
+		s.update(func(info *ConnectionInfo) {
+			info.State = ConnectionStateReconnecting
+			info.Restarts++
+			info.LastError = err
+		})
+
+		if !s.sleep(delay) {
+			s.update(func(info *ConnectionInfo) { info.State = ConnectionStateDisconnected })
+
+			return
+		}
+
+		delay = min(2*delay, s.config.MaxRestartDelay)
+	}
+}
+
+// connect starts or reaches the server and initializes a session. stop
+// closes the client, killing stdio servers that don't exit in time.
+func (s *supervisor) connect(state connectionState) (client *Client, stop func(), err error) {
+	ctx, cancel := context.WithTimeout(s.ctx, s.config.ConnectTimeout)
+	defer cancel()
+
+	config := s.config.Client
+	config.Roots = state.roots
+
+	if s.def.Command == "" {
+		httpConfig := s.config.HTTP
+		httpConfig.URL = s.def.URL
+		httpConfig.Headers = make(map[string]string, len(s.config.HTTP.Headers)+len(s.def.Headers))
+		maps.Copy(httpConfig.Headers, s.config.HTTP.Headers)
+		maps.Copy(httpConfig.Headers, s.def.Headers)
+
+		switch s.def.Type {
+		case "sse":
+			client, err = connectSSE(ctx, httpConfig, config)
+		case "http", "streamable-http":
+			client = NewClient(NewStreamableHTTPTransport(httpConfig), config)
+			if err = client.Connect(ctx, config.ClientInfo); err != nil {
+				_ = client.Close()
+			}
+		default:
+			client, err = connectHTTP(ctx, httpConfig, config)
+		}
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return client, func() { _ = client.Close() }, nil
+	}
+
+	processCtx, kill := context.WithCancel(s.ctx)
+
+	cmd := exec.CommandContext(processCtx, s.def.Command, s.def.Args...)
+	cmd.Dir = s.def.Cwd
+
+	if len(s.def.Env) > 0 {
+		cmd.Env = os.Environ()
+		for _, key := range slices.Sorted(maps.Keys(s.def.Env)) {
+			cmd.Env = append(cmd.Env, key+"="+s.def.Env[key])
+		}
+	}
+
+	transport, err := NewStdioTransport(cmd)
+	if err != nil {
+		kill()
+
+		return nil, nil, err
+	}
+
+	client = NewClient(transport, config)
+
+	stop = func() {
+		timer := time.AfterFunc(stdioStopTimeout, kill)
+		_ = client.Close()
+
+		timer.Stop()
+		kill()
+	}
+
+	if err := client.Connect(ctx, config.ClientInfo); err != nil {
+		kill()
+		_ = client.Close()
+
+		return nil, nil, err
+	}
+
+	return client, stop, nil
+}
+
+// monitor returns when the connection is lost, a ping fails or the
+// supervisor stops.
+func (s *supervisor) monitor(client *Client) error {
+	var ticks <-chan time.Time
+
+	if s.config.PingInterval > 0 {
+		ticker := time.NewTicker(s.config.PingInterval)
+		defer ticker.Stop()
+
+		ticks = ticker.C
+	}
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return s.ctx.Err()
+		case <-client.Done():
+			return ErrConnectionLost
+		case <-ticks:
+			ctx, cancel := context.WithTimeout(s.ctx, s.config.PingTimeout)
+			start := time.Now()
+			err := client.Ping(ctx)
+
+			cancel()
+
+			if err != nil {
+				return fmt.Errorf("ping: %w", err)
+			}
+
+			s.update(func(info *ConnectionInfo) {
+				info.LastPing = time.Now()
+				info.PingLatency = time.Since(start)
+			})
+		}
+	}
+}
+
+func (s *supervisor) sleep(d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-s.ctx.Done():
+		return false
+	}
+}
+
+// update changes the connection info, reporting state changes.
+func (s *supervisor) update(fn func(info *ConnectionInfo)) {
+	s.mu.Lock()
+	previous := s.info.State
+	fn(&s.info)
+	info := s.info
+	s.mu.Unlock()
+
+	if info.State != previous && s.config.OnStateChange != nil {
+		s.config.OnStateChange(info)
+	}
+}
+
+func (s *supervisor) connectionInfo() ConnectionInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.info
+}
+
+// captureConnection returns the state of a client to carry over to its
+// replacement.
+func captureConnection(client *Client) connectionState {
+	client.mu.RLock()
+	defer client.mu.RUnlock()
+
+	state := connectionState{
+		subscriptions: make(map[string][]func(ResourceContent), len(client.subscriptions)),
+		roots:         client.roots,
+	}
+
+	for uri, handlers := range client.subscriptions {
+		state.subscriptions[uri] = slices.Clone(handlers)
+	}
+
+	return state
+}
+
+// restoreConnection resubscribes a reconnected client to its predecessor's
+// resources. Subscriptions the server refuses keep their handlers, so that a
+// later reconnection tries again.
+func restoreConnection(ctx context.Context, client *Client, state connectionState) {
+	for _, uri := range slices.Sorted(maps.Keys(state.subscriptions)) {
+		_, _ = client.request(ctx, MethodSubscribeResource, SubscribeRequest{URI: uri})
+
+		client.mu.Lock()
+		client.subscriptions[uri] = append(client.subscriptions[uri], state.subscriptions[uri]...)
+		client.mu.Unlock()
+	}
+}
@@ -0,0 +1,221 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseServersConfig(t *testing.T) {
+	servers, err := ParseServersConfig([]byte(`{
+		"mcpServers": {
+			"files": {"command": "mcp-files", "args": ["/tmp"], "env": {"DEBUG": "1"}},
+			"search": {"url": "https://search.example.com/mcp", "headers": {"Authorization": "Bearer token"}},
+			"legacy": {"type": "sse", "url": "https://legacy.example.com/sse", "disabled": true}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	if len(servers) != 3 || servers["files"].Args[0] != "/tmp" || servers["search"].Headers["Authorization"] != "Bearer token" || !servers["legacy"].Disabled {
+		t.Errorf("unexpected servers %+v", servers)
+	}
+
+	for _, invalid := range []string{
+		`{"mcpServers": {"empty": {}}}`,
+		`{"mcpServers": {"both": {"command": "a", "url": "http://b"}}}`,
+		`{"mcpServers": {"mismatch": {"type": "sse", "command": "a"}}}`,
+		`{"mcpServers": {"unknown": {"type": "ws", "url": "ws://a"}}}`,
+	} {
+		if _, err := ParseServersConfig([]byte(invalid)); err == nil {
+			t.Errorf("expected %s to be rejected", invalid)
+		}
+	}
+}
+
+func TestSupervise_ReconnectsHTTPServer(t *testing.T) {
+	newServer := func() (*Server, http.Handler) {
+		transport := NewHTTPServerTransport(HTTPServerConfig{})
+		server := NewServer(transport, ServerConfig{Info: Implementation{Name: "test"}})
+		server.RegisterResource(Resource{URI: "test://status", Name: "status"}, TextResourceHandler("ok", "text/plain"))
+
+		go func() { _ = server.Start() }()
+
+		t.Cleanup(func() { _ = server.Stop() })
+
+		return server, transport
+	}
+
+	first, handler := newServer()
+
+	var current atomic.Value
+	current.Store(handler)
+
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current.Load().(http.Handler).ServeHTTP(w, r)
+	}))
+	defer httpServer.Close()
+
+	states := make(chan ConnectionInfo, 16)
+	manager := NewMCPClientManager()
+
+	defer manager.Close()
+
+	err := manager.Supervise("remote", ServerDefinition{URL: httpServer.URL}, SupervisorConfig{
+		PingInterval:  20 * time.Millisecond,
+		RestartDelay:  10 * time.Millisecond,
+		OnStateChange: func(info ConnectionInfo) { states <- info },
+	})
+	if err != nil {
+		t.Fatalf("supervise: %v", err)
+	}
+
+	waitForState(t, states, ConnectionStateConnected)
+
+	client, _ := manager.GetClient("remote")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.SubscribeResource(ctx, "test://status", func(ResourceContent) {}); err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+
+	// A restarted server no longer knows the session
+	second, handler := newServer()
+	current.Store(handler)
+
+	_ = first.Stop()
+
+	waitForState(t, states, ConnectionStateReconnecting)
+	info := waitForState(t, states, ConnectionStateConnected)
+
+	if info.Restarts != 1 || info.ServerInfo == nil || info.ServerInfo.Name != "test" {
+		t.Errorf("unexpected connection info %+v", info)
+	}
+
+	reconnected, _ := manager.GetClient("remote")
+	if reconnected == client {
+		t.Fatal("expected the client to be replaced")
+	}
+
+	if !second.subscribed("test://status") {
+		t.Error("expected the subscription to be restored")
+	}
+
+	if err := reconnected.Ping(ctx); err != nil {
+		t.Errorf("ping: %v", err)
+	}
+
+	manager.RemoveClient("remote")
+
+	if _, ok := manager.Connection("remote"); ok {
+		t.Error("expected the removed server to be gone")
+	}
+}
+
+func TestSupervise_RestartsStdioServer(t *testing.T) {
+	states := make(chan ConnectionInfo, 16)
+	manager := NewMCPClientManager()
+
+	defer manager.Close()
+
+	err := manager.Supervise("local", ServerDefinition{
+		Command: os.Args[0],
+		Args:    []string{"-test.run=^TestSupervisorHelperServer$"},
+		Env:     map[string]string{"MCP_SUPERVISOR_HELPER": "1"},
+	}, SupervisorConfig{
+		PingInterval:  -1,
+		RestartDelay:  10 * time.Millisecond,
+		OnStateChange: func(info ConnectionInfo) { states <- info },
+	})
+	if err != nil {
+		t.Fatalf("supervise: %v", err)
+	}
+
+	waitForState(t, states, ConnectionStateConnected)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client, _ := manager.GetClient("local")
+
+	if _, err := client.CallTool(ctx, "exit", nil); !errors.Is(err, ErrConnectionLost) {
+		t.Fatalf("expected the connection to be lost, got %v", err)
+	}
+
+	waitForState(t, states, ConnectionStateReconnecting)
+	waitForState(t, states, ConnectionStateConnected)
+
+	client, _ = manager.GetClient("local")
+	if err := client.Ping(ctx); err != nil {
+		t.Errorf("ping restarted server: %v", err)
+	}
+
+	if info, _ := manager.Connection("local"); info.Restarts != 1 || !info.Supervised {
+		t.Errorf("unexpected connection info %+v", info)
+	}
+}
+
+func TestSupervise_GivesUp(t *testing.T) {
+	states := make(chan ConnectionInfo, 16)
+	manager := NewMCPClientManager()
+
+	defer manager.Close()
+
+	err := manager.Supervise("missing", ServerDefinition{Command: "/nonexistent/mcp-server"}, SupervisorConfig{
+		RestartDelay:  time.Millisecond,
+		MaxRetries:    2,
+		OnStateChange: func(info ConnectionInfo) { states <- info },
+	})
+	if err != nil {
+		t.Fatalf("supervise: %v", err)
+	}
+
+	info := waitForState(t, states, ConnectionStateError)
+	if info.Failures != 3 || info.LastError == nil {
+		t.Errorf("unexpected connection info %+v", info)
+	}
+}
+
+// TestSupervisorHelperServer is the stdio server started by
+// TestSupervise_RestartsStdioServer.
+func TestSupervisorHelperServer(t *testing.T) {
+	if os.Getenv("MCP_SUPERVISOR_HELPER") != "1" {
+		t.Skip("helper process")
+	}
+
+	server := NewServer(NewStdioServerTransport(), ServerConfig{Info: Implementation{Name: "helper"}})
+	server.RegisterTool(Tool{Name: "exit", InputSchema: []byte(`{"type":"object"}`)}, func(context.Context, map[string]any) ([]ToolResultContent, error) {
+		os.Exit(1)
+
+		return nil, nil
+	})
+
+	_ = server.Start()
+
+	os.Exit(0)
+}
+
+func waitForState(t *testing.T, states <-chan ConnectionInfo, want ConnectionState) ConnectionInfo {
+	t.Helper()
+
+	timeout := time.After(5 * time.Second)
+
+	for {
+		select {
+		case info := <-states:
+			if info.State == want {
+				return info
+			}
+		case <-timeout:
+			t.Fatalf("timed out waiting for state %s", want)
+		}
+	}
+}
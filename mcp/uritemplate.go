@@ -0,0 +1,199 @@
+package mcp
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// URITemplate is a URI template of RFC 6570 levels 1 and 2: simple {var},
+// reserved {+var} and fragment {#var} expressions of one variable each.
+type URITemplate struct {
+	raw     string
+	parts   []templatePart
+	names   []string
+	pattern *regexp.Regexp
+	literal int
+}
+
+type templatePart struct {
+	literal string
+	name    string
+	op      byte // 0 for simple expressions, '+' or '#'
+	expr    bool
+}
+
+var templateVarName = regexp.MustCompile(`^[A-Za-z0-9_]+(\.[A-Za-z0-9_]+)*$`)
+
+const (
+	templateUnreserved = `[A-Za-z0-9\-._~]|%[0-9A-Fa-f]{2}`
+	templateReserved   = `[A-Za-z0-9\-._~:/?#\[\]@!$&'()*+,;=]|%[0-9A-Fa-f]{2}`
+)
+
+// ParseURITemplate parses a level 1 or 2 URI template, such as
+// "db://tables/{name}" or "file://{+path}".
+func ParseURITemplate(template string) (*URITemplate, error) {
+	t := &URITemplate{raw: template}
+
+	var pattern strings.Builder
+
+	pattern.WriteString("^")
+
+	for rest := template; rest != ""; {
+		start := strings.IndexByte(rest, '{')
+		if start < 0 {
+			start = len(rest)
+		}
+
+		if literal := rest[:start]; literal != "" {
+			if strings.ContainsRune(literal, '}') {
+				return nil, fmt.Errorf("uri template %q: unmatched }", template)
+			}
+
+			t.parts = append(t.parts, templatePart{literal: literal})
+			t.literal += len(literal)
+			pattern.WriteString(regexp.QuoteMeta(literal))
+		}
+
+		if start == len(rest) {
+			break
+		}
+
+		end := strings.IndexByte(rest[start:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("uri template %q: unclosed expression", template)
+		}
+
+		part := templatePart{name: rest[start+1 : start+end], expr: true}
+		rest = rest[start+end+1:]
+
+		if part.name != "" && (part.name[0] == '+' || part.name[0] == '#') {
+			part.op, part.name = part.name[0], part.name[1:]
+		}
+
+		if !templateVarName.MatchString(part.name) {
+			return nil, fmt.Errorf("uri template %q: unsupported expression {%s}", template, string(part.op)+part.name)
+		}
+
+		t.parts = append(t.parts, part)
+		t.names = append(t.names, part.name)
+
+		switch part.op {
+		case '+':
+			pattern.WriteString(`((?:` + templateReserved + `)*?)`)
+		case '#':
+			pattern.WriteString(`(?:#((?:` + templateReserved + `)*?))?`)
+		default:
+			pattern.WriteString(`((?:` + templateUnreserved + `)*)`)
+		}
+	}
+
+	pattern.WriteString("$")
+
+	re, err := regexp.Compile(pattern.String())
+	if err != nil {
+		return nil, fmt.Errorf("uri template %q: %w", template, err)
+	}
+
+	t.pattern = re
+
+	return t, nil
+}
+
+// String returns the template.
+func (t *URITemplate) String() string {
+	return t.raw
+}
+
+// Variables returns the names of the template's variables.
+func (t *URITemplate) Variables() []string {
+	return append([]string(nil), t.names...)
+}
+
+// Match reports whether uri is an expansion of the template and returns the
+// decoded values of its variables.
+func (t *URITemplate) Match(uri string) (map[string]string, bool) {
+	match := t.pattern.FindStringSubmatch(uri)
+	if match == nil {
+		return nil, false
+	}
+
+	vars := make(map[string]string, len(t.names))
+
+	for i, name := range t.names {
+		value, err := url.PathUnescape(match[i+1])
+		if err != nil {
+			return nil, false
+		}
+
+		// A variable used twice must have one value
+		if previous, ok := vars[name]; ok && previous != value {
+			return nil, false
+		}
+
+		vars[name] = value
+	}
+
+	return vars, true
+}
+
+// Expand substitutes vars into the template. Missing variables expand to
+// empty strings, and a missing fragment variable omits the fragment.
+func (t *URITemplate) Expand(vars map[string]string) string {
+	var b strings.Builder
+
+	for _, part := range t.parts {
+		if !part.expr {
+			b.WriteString(part.literal)
+
+			continue
+		}
+
+		value, ok := vars[part.name]
+
+		switch part.op {
+		case '+':
+			b.WriteString(encodeTemplateValue(value, true))
+		case '#':
+			if ok {
+				b.WriteString("#" + encodeTemplateValue(value, true))
+			}
+		default:
+			b.WriteString(encodeTemplateValue(value, false))
+		}
+	}
+
+	return b.String()
+}
+
+// encodeTemplateValue percent-encodes value, keeping unreserved characters
+// and, for reserved expansions, reserved characters and existing escapes.
+func encodeTemplateValue(value string, allowReserved bool) string {
+	const hex = "0123456789ABCDEF"
+
+	var b strings.Builder
+
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+
+		switch {
+		case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c >= '0' && c <= '9', strings.IndexByte("-._~", c) >= 0:
+			b.WriteByte(c)
+		case allowReserved && strings.IndexByte(":/?#[]@!$&'()*+,;=", c) >= 0:
+			b.WriteByte(c)
+		case allowReserved && c == '%' && i+2 < len(value) && isHex(value[i+1]) && isHex(value[i+2]):
+			b.WriteByte(c)
+		default:
+			b.WriteByte('%')
+			b.WriteByte(hex[c>>4])
+			b.WriteByte(hex[c&0x0F])
+		}
+	}
+
+	return b.String()
+}
+
+func isHex(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'
+}
@@ -344,6 +344,7 @@ func (b *MultiModalGenerator) Execute() (*MultiModalResult, error) {
 			result.ToolCalls = make([]ToolCallResult, len(choice.Message.ToolCalls))
 			for i, tc := range choice.Message.ToolCalls {
 				result.ToolCalls[i] = ToolCallResult{
+					ID:        tc.ID,
 					Name:      tc.Function.Name,
 					Arguments: tc.Function.Parsed,
 				}
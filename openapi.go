@@ -0,0 +1,976 @@
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// OpenAPIOperation describes an operation of an OpenAPI document. It is passed
+// to filters to decide which operations become tools.
+type OpenAPIOperation struct {
+	ID      string
+	Method  string
+	Path    string
+	Summary string
+	Tags    []string
+}
+
+// OpenAPIOption configures how OpenAPI operations are turned into tools.
+type OpenAPIOption func(*openAPIOptions)
+
+type openAPIOptions struct {
+	baseURL          string
+	client           *http.Client
+	auth             []func(*http.Request) error
+	maxResponseBytes int64
+	timeout          time.Duration
+	category         string
+	namePrefix       string
+	filter           func(OpenAPIOperation) bool
+}
+
+const (
+	defaultOpenAPIMaxResponseBytes = 64 * 1024
+	openAPIMaxSchemaDepth          = 8
+)
+
+// WithOpenAPIBaseURL sets the URL requests are sent to, overriding the
+// servers listed in the document.
+func WithOpenAPIBaseURL(baseURL string) OpenAPIOption {
+	return func(o *openAPIOptions) {
+		o.baseURL = baseURL
+	}
+}
+
+// WithOpenAPIHTTPClient sets the HTTP client used to call the API.
+func WithOpenAPIHTTPClient(client *http.Client) OpenAPIOption {
+	return func(o *openAPIOptions) {
+		o.client = client
+	}
+}
+
+// WithOpenAPIAuth adds a function that authenticates each outgoing request,
+// for example by signing it or attaching a freshly minted token.
+func WithOpenAPIAuth(auth func(*http.Request) error) OpenAPIOption {
+	return func(o *openAPIOptions) {
+		o.auth = append(o.auth, auth)
+	}
+}
+
+// WithOpenAPIBearerToken sends a static bearer token with each request.
+func WithOpenAPIBearerToken(token string) OpenAPIOption {
+	return WithOpenAPIHeader("Authorization", "Bearer "+token)
+}
+
+// WithOpenAPIHeader sends a static header with each request, such as an API key.
+func WithOpenAPIHeader(name, value string) OpenAPIOption {
+	return WithOpenAPIAuth(func(req *http.Request) error {
+		req.Header.Set(name, value)
+
+		return nil
+	})
+}
+
+// WithOpenAPIMaxResponseBytes limits how much of a response body is returned
+// to the model. Longer bodies are truncated. Defaults to 64 KiB.
+func WithOpenAPIMaxResponseBytes(n int64) OpenAPIOption {
+	return func(o *openAPIOptions) {
+		o.maxResponseBytes = n
+	}
+}
+
+// WithOpenAPITimeout sets the timeout of the generated tools.
+func WithOpenAPITimeout(timeout time.Duration) OpenAPIOption {
+	return func(o *openAPIOptions) {
+		o.timeout = timeout
+	}
+}
+
+// WithOpenAPICategory sets the category of every generated tool. By default
+// the first tag of each operation is used, falling back to the document title.
+func WithOpenAPICategory(category string) OpenAPIOption {
+	return func(o *openAPIOptions) {
+		o.category = category
+	}
+}
+
+// WithOpenAPINamePrefix prefixes every tool name, which keeps tools from
+// several services apart in a single registry.
+func WithOpenAPINamePrefix(prefix string) OpenAPIOption {
+	return func(o *openAPIOptions) {
+		o.namePrefix = prefix
+	}
+}
+
+// WithOpenAPIFilter only generates tools for operations the filter accepts.
+func WithOpenAPIFilter(filter func(OpenAPIOperation) bool) OpenAPIOption {
+	return func(o *openAPIOptions) {
+		o.filter = filter
+	}
+}
+
+// RegisterOpenAPI loads an OpenAPI document from a file and registers one tool
+// per operation. Either all tools are registered or none are.
+func (tr *ToolRegistry) RegisterOpenAPI(path string, opts ...OpenAPIOption) ([]*ToolDefinition, error) {
+	tools, err := LoadOpenAPITools(path, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, tool := range tools {
+		if err := tr.RegisterTool(tool); err != nil {
+			for _, registered := range tools[:i] {
+				_ = tr.UnregisterTool(registered.Name, registered.Version)
+			}
+
+			return nil, err
+		}
+	}
+
+	return tools, nil
+}
+
+// LoadOpenAPITools reads an OpenAPI 3 document (JSON or YAML) from a file and
+// generates one tool per operation.
+func LoadOpenAPITools(path string, opts ...OpenAPIOption) ([]*ToolDefinition, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read OpenAPI spec: %w", err)
+	}
+
+	return ParseOpenAPITools(data, opts...)
+}
+
+// ParseOpenAPITools generates one tool per operation of an OpenAPI 3 document.
+//
+// Path, query, header and cookie parameters become tool parameters under their
+// own names. The properties of a JSON or form request body are added alongside
+// them; a body that is not an object, or whose properties clash with a
+// parameter, is exposed as a single "body" parameter instead. Handlers send the
+// request to the API and return the status code and the decoded body, or an
+// error wrapping ErrToolExecutionFailed for 4xx and 5xx responses.
+func ParseOpenAPITools(data []byte, opts ...OpenAPIOption) ([]*ToolDefinition, error) {
+	options := &openAPIOptions{
+		client:           http.DefaultClient,
+		maxResponseBytes: defaultOpenAPIMaxResponseBytes,
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	var raw any
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidOpenAPISpec, err)
+	}
+
+	doc, ok := normalizeYAMLValue(raw).(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%w: document must be an object", ErrInvalidOpenAPISpec)
+	}
+
+	if version, _ := doc["openapi"].(string); !strings.HasPrefix(version, "3.") {
+		return nil, fmt.Errorf("%w: unsupported version %v, expected OpenAPI 3", ErrInvalidOpenAPISpec, doc["openapi"])
+	}
+
+	baseURL := options.baseURL
+	if baseURL == "" {
+		baseURL = openAPIServerURL(doc)
+	}
+
+	if u, err := url.Parse(baseURL); err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("%w: no absolute server URL, use WithOpenAPIBaseURL", ErrInvalidOpenAPISpec)
+	}
+
+	c := &openAPIConverter{doc: doc, options: options, baseURL: strings.TrimSuffix(baseURL, "/")}
+
+	paths, _ := doc["paths"].(map[string]any)
+
+	var tools []*ToolDefinition
+
+	names := make(map[string]string)
+
+	for _, path := range slices.Sorted(maps.Keys(paths)) {
+		item, _ := c.resolve(paths[path])
+
+		for _, method := range openAPIMethods {
+			op, ok := item[method].(map[string]any)
+			if !ok {
+				continue
+			}
+
+			tool, err := c.tool(strings.ToUpper(method), path, item, op)
+			if err != nil {
+				return nil, err
+			}
+
+			if tool == nil {
+				continue
+			}
+
+			where := strings.ToUpper(method) + " " + path
+			if other, exists := names[tool.Name]; exists {
+				return nil, fmt.Errorf("%w: %s and %s both map to tool %s", ErrInvalidOpenAPISpec, other, where, tool.Name)
+			}
+
+			names[tool.Name] = where
+			tools = append(tools, tool)
+		}
+	}
+
+	return tools, nil
+}
+
+var openAPIMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// openAPIConverter turns the operations of a normalized document into tools.
+type openAPIConverter struct {
+	doc     map[string]any
+	options *openAPIOptions
+	baseURL string
+}
+
+// openAPIParam maps a tool argument to a request parameter.
+type openAPIParam struct {
+	arg  string
+	name string
+	in   string
+}
+
+// openAPIBinding describes how tool arguments build an HTTP request.
+type openAPIBinding struct {
+	method      string
+	path        string
+	params      []openAPIParam
+	bodyFields  []string
+	bodyArg     string
+	contentType string
+}
+
+func (c *openAPIConverter) tool(method, path string, item, op map[string]any) (*ToolDefinition, error) {
+	id, _ := op["operationId"].(string)
+	summary, _ := op["summary"].(string)
+	tags := stringList(op["tags"])
+
+	operation := OpenAPIOperation{ID: id, Method: method, Path: path, Summary: summary, Tags: tags}
+	if c.options.filter != nil && !c.options.filter(operation) {
+		return nil, nil
+	}
+
+	name := id
+	if name == "" {
+		name = method + "_" + path
+	}
+
+	name = c.options.namePrefix + sanitizeToolName(name)
+
+	where := method + " " + path
+	binding := &openAPIBinding{method: method, path: path}
+	schema := ToolParameterSchema{Type: "object", Properties: make(map[string]ToolParameterProperty)}
+
+	params, err := c.parameters(item, op)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidOpenAPISpec, where, err)
+	}
+
+	for _, param := range params {
+		paramName, _ := param["name"].(string)
+		in, _ := param["in"].(string)
+
+		if paramName == "" || !slices.Contains([]string{"path", "query", "header", "cookie"}, in) {
+			return nil, fmt.Errorf("%w: %s: invalid parameter %q in %q", ErrInvalidOpenAPISpec, where, paramName, in)
+		}
+
+		arg := paramName
+		if _, exists := schema.Properties[arg]; exists {
+			arg = in + "_" + paramName
+		}
+
+		prop := ToolParameterProperty{Type: "string"}
+		if s, ok := param["schema"].(map[string]any); ok {
+			prop = c.property(s, 0)
+		}
+
+		if description, _ := param["description"].(string); description != "" {
+			prop.Description = description
+		}
+
+		schema.Properties[arg] = prop
+		if required, _ := param["required"].(bool); required || in == "path" {
+			schema.Required = append(schema.Required, arg)
+		}
+
+		binding.params = append(binding.params, openAPIParam{arg: arg, name: paramName, in: in})
+	}
+
+	if err := c.requestBody(op, binding, &schema); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidOpenAPISpec, where, err)
+	}
+
+	category := c.options.category
+	if category == "" && len(tags) > 0 {
+		category = tags[0]
+	}
+
+	info, _ := c.doc["info"].(map[string]any)
+	title, _ := info["title"].(string)
+
+	if category == "" {
+		category = title
+	}
+
+	description := summary
+	if text, _ := op["description"].(string); text != "" && text != summary {
+		description = strings.TrimSpace(description + "\n\n" + text)
+	}
+
+	if description == "" {
+		description = where
+	}
+
+	if deprecated, _ := op["deprecated"].(bool); deprecated {
+		description += " (deprecated)"
+	}
+
+	return &ToolDefinition{
+		Name:        name,
+		Description: description,
+		Category:    category,
+		Tags:        tags,
+		Parameters:  schema,
+		Handler:     c.handler(binding),
+		Timeout:     c.options.timeout,
+		Metadata: map[string]any{
+			"openapi.method":       method,
+			"openapi.path":         path,
+			"openapi.operation_id": id,
+			"openapi.spec":         title,
+		},
+	}, nil
+}
+
+// parameters merges path-level and operation-level parameters; operation
+// parameters override path parameters with the same name and location.
+func (c *openAPIConverter) parameters(item, op map[string]any) ([]map[string]any, error) {
+	var params []map[string]any
+
+	index := make(map[string]int)
+
+	for _, list := range []any{item["parameters"], op["parameters"]} {
+		entries, _ := list.([]any)
+		for _, entry := range entries {
+			param, err := c.resolve(entry)
+			if err != nil {
+				return nil, err
+			}
+
+			key := fmt.Sprintf("%v:%v", param["in"], param["name"])
+			if i, exists := index[key]; exists {
+				params[i] = param
+
+				continue
+			}
+
+			index[key] = len(params)
+			params = append(params, param)
+		}
+	}
+
+	return params, nil
+}
+
+// requestBody adds the request body of an operation to the tool schema.
+func (c *openAPIConverter) requestBody(op map[string]any, binding *openAPIBinding, schema *ToolParameterSchema) error {
+	if op["requestBody"] == nil {
+		return nil
+	}
+
+	body, err := c.resolve(op["requestBody"])
+	if err != nil {
+		return err
+	}
+
+	content, _ := body["content"].(map[string]any)
+	if len(content) == 0 {
+		return nil
+	}
+
+	binding.contentType = selectOpenAPIContentType(content)
+	media, _ := content[binding.contentType].(map[string]any)
+	required, _ := body["required"].(bool)
+
+	bodySchema, _ := media["schema"].(map[string]any)
+
+	prop := ToolParameterProperty{Type: "string"}
+	if bodySchema != nil {
+		prop = c.property(bodySchema, 0)
+	}
+
+	structured := isJSONContentType(binding.contentType) || binding.contentType == "application/x-www-form-urlencoded"
+
+	flatten := structured && prop.Type == "object" && len(prop.Properties) > 0
+	for field := range prop.Properties {
+		if _, exists := schema.Properties[field]; exists {
+			flatten = false
+		}
+	}
+
+	if flatten {
+		for _, field := range slices.Sorted(maps.Keys(prop.Properties)) {
+			schema.Properties[field] = prop.Properties[field]
+			binding.bodyFields = append(binding.bodyFields, field)
+		}
+
+		if required {
+			schema.Required = append(schema.Required, prop.Required...)
+		}
+
+		return nil
+	}
+
+	if !structured {
+		prop = ToolParameterProperty{Type: "string"}
+	}
+
+	if description, _ := body["description"].(string); description != "" {
+		prop.Description = description
+	} else if prop.Description == "" {
+		prop.Description = "Request body (" + binding.contentType + ")"
+	}
+
+	binding.bodyArg = "body"
+	schema.Properties["body"] = prop
+
+	if required {
+		schema.Required = append(schema.Required, "body")
+	}
+
+	return nil
+}
+
+// property converts an OpenAPI schema object into a tool parameter property.
+func (c *openAPIConverter) property(s map[string]any, depth int) ToolParameterProperty {
+	if depth > openAPIMaxSchemaDepth {
+		return ToolParameterProperty{Description: "Nested value"}
+	}
+
+	s, err := c.resolve(s)
+	if err != nil {
+		return ToolParameterProperty{}
+	}
+
+	if allOf, ok := s["allOf"].([]any); ok {
+		s = c.mergeAllOf(s, allOf)
+	}
+
+	var prop ToolParameterProperty
+
+	switch typ := s["type"].(type) {
+	case string:
+		prop.Type = typ
+	case []any:
+		// OpenAPI 3.1 expresses nullable values as a type list
+		for _, t := range typ {
+			if name, _ := t.(string); name != "null" {
+				prop.Type = name
+
+				break
+			}
+		}
+	}
+
+	if prop.Type == "" {
+		if _, ok := s["properties"]; ok {
+			prop.Type = "object"
+		} else if _, ok := s["items"]; ok {
+			prop.Type = "array"
+		}
+	}
+
+	prop.Description, _ = s["description"].(string)
+	if prop.Description == "" {
+		prop.Description, _ = s["title"].(string)
+	}
+
+	prop.Default = s["default"]
+	prop.Format, _ = s["format"].(string)
+	prop.Pattern, _ = s["pattern"].(string)
+	prop.UniqueItems, _ = s["uniqueItems"].(bool)
+
+	if values, ok := s["enum"].([]any); ok {
+		if enum := stringList(values); len(enum) == len(values) {
+			prop.Enum = enum
+		} else {
+			prop.Description = strings.TrimSpace(prop.Description + " Allowed values: " + formatEnum(values) + ".")
+		}
+	}
+
+	prop.Minimum = schemaFloat(s["minimum"])
+	prop.Maximum = schemaFloat(s["maximum"])
+	prop.MultipleOf = schemaFloat(s["multipleOf"])
+	prop.MinLength = schemaInt(s["minLength"])
+	prop.MaxLength = schemaInt(s["maxLength"])
+	prop.MinItems = schemaInt(s["minItems"])
+	prop.MaxItems = schemaInt(s["maxItems"])
+
+	// OpenAPI 3.0 marks bounds exclusive with booleans, 3.1 uses numbers
+	if exclusive, ok := s["exclusiveMinimum"].(bool); ok {
+		if exclusive {
+			prop.ExclusiveMinimum, prop.Minimum = prop.Minimum, nil
+		}
+	} else {
+		prop.ExclusiveMinimum = schemaFloat(s["exclusiveMinimum"])
+	}
+
+	if exclusive, ok := s["exclusiveMaximum"].(bool); ok {
+		if exclusive {
+			prop.ExclusiveMaximum, prop.Maximum = prop.Maximum, nil
+		}
+	} else {
+		prop.ExclusiveMaximum = schemaFloat(s["exclusiveMaximum"])
+	}
+
+	if items, ok := s["items"].(map[string]any); ok {
+		item := c.property(items, depth+1)
+		prop.Items = &item
+	}
+
+	if properties, ok := s["properties"].(map[string]any); ok {
+		prop.Properties = make(map[string]ToolParameterProperty, len(properties))
+
+		required := stringList(s["required"])
+
+		for _, name := range slices.Sorted(maps.Keys(properties)) {
+			field, _ := c.resolve(properties[name])
+
+			// Read-only properties are set by the server and never sent
+			if readOnly, _ := field["readOnly"].(bool); readOnly {
+				continue
+			}
+
+			prop.Properties[name] = c.property(field, depth+1)
+			if slices.Contains(required, name) {
+				prop.Required = append(prop.Required, name)
+			}
+		}
+	}
+
+	if additional, ok := s["additionalProperties"].(bool); ok {
+		prop.AdditionalProperties = &additional
+	}
+
+	for _, keyword := range []string{"oneOf", "anyOf"} {
+		alternatives, _ := s[keyword].([]any)
+		for _, alternative := range alternatives {
+			if alt, ok := alternative.(map[string]any); ok {
+				converted := c.property(alt, depth+1)
+				if keyword == "oneOf" {
+					prop.OneOf = append(prop.OneOf, converted)
+				} else {
+					prop.AnyOf = append(prop.AnyOf, converted)
+				}
+			}
+		}
+	}
+
+	return prop
+}
+
+// mergeAllOf folds allOf subschemas into a single object schema, which is how
+// OpenAPI documents usually express composition.
+func (c *openAPIConverter) mergeAllOf(s map[string]any, allOf []any) map[string]any {
+	merged := make(map[string]any, len(s))
+	properties := make(map[string]any)
+
+	var required []any
+
+	for _, part := range append([]any{s}, allOf...) {
+		schema, err := c.resolve(part)
+		if err != nil {
+			continue
+		}
+
+		for key, value := range schema {
+			switch key {
+			case "allOf", "$ref":
+			case "properties":
+				fields, _ := value.(map[string]any)
+				maps.Copy(properties, fields)
+			case "required":
+				list, _ := value.([]any)
+				required = append(required, list...)
+			default:
+				if _, exists := merged[key]; !exists {
+					merged[key] = value
+				}
+			}
+		}
+	}
+
+	if len(properties) > 0 {
+		merged["properties"] = properties
+	}
+
+	if len(required) > 0 {
+		merged["required"] = required
+	}
+
+	return merged
+}
+
+// resolve follows local $ref pointers such as "#/components/schemas/Pet".
+func (c *openAPIConverter) resolve(value any) (map[string]any, error) {
+	object, _ := value.(map[string]any)
+
+	for range openAPIMaxSchemaDepth {
+		ref, ok := object["$ref"].(string)
+		if !ok {
+			return object, nil
+		}
+
+		if !strings.HasPrefix(ref, "#/") {
+			return nil, fmt.Errorf("unsupported reference %q", ref)
+		}
+
+		var current any = c.doc
+
+		for _, token := range strings.Split(ref[2:], "/") {
+			token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+
+			parent, _ := current.(map[string]any)
+			if current, ok = parent[token]; !ok {
+				return nil, fmt.Errorf("unresolved reference %q", ref)
+			}
+		}
+
+		object, _ = current.(map[string]any)
+	}
+
+	return nil, fmt.Errorf("reference chain too deep at %v", object["$ref"])
+}
+
+// handler builds the ToolHandler that sends the HTTP request for an operation.
+func (c *openAPIConverter) handler(binding *openAPIBinding) ToolHandler {
+	options := c.options
+	baseURL := c.baseURL
+
+	return func(ctx context.Context, params map[string]any) (any, error) {
+		path := binding.path
+		query := url.Values{}
+		headers := http.Header{}
+
+		var cookies []*http.Cookie
+
+		for _, param := range binding.params {
+			value, ok := params[param.arg]
+			if !ok || value == nil {
+				continue
+			}
+
+			switch param.in {
+			case "path":
+				path = strings.ReplaceAll(path, "{"+param.name+"}", url.PathEscape(formatOpenAPIValue(value)))
+			case "query":
+				if list, isList := value.([]any); isList {
+					for _, item := range list {
+						query.Add(param.name, formatOpenAPIValue(item))
+					}
+				} else {
+					query.Set(param.name, formatOpenAPIValue(value))
+				}
+			case "header":
+				headers.Set(param.name, formatOpenAPIValue(value))
+			case "cookie":
+				cookies = append(cookies, &http.Cookie{Name: param.name, Value: formatOpenAPIValue(value)})
+			}
+		}
+
+		target := baseURL + path
+		if len(query) > 0 {
+			target += "?" + query.Encode()
+		}
+
+		body, err := binding.encodeBody(params)
+		if err != nil {
+			return nil, err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, binding.method, target, body)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrToolExecutionFailed, err)
+		}
+
+		maps.Copy(req.Header, headers)
+
+		for _, cookie := range cookies {
+			req.AddCookie(cookie)
+		}
+
+		req.Header.Set("Accept", "application/json")
+
+		if body != nil {
+			req.Header.Set("Content-Type", binding.contentType)
+		}
+
+		for _, auth := range options.auth {
+			if err := auth(req); err != nil {
+				return nil, fmt.Errorf("%w: authentication failed: %v", ErrToolExecutionFailed, err)
+			}
+		}
+
+		resp, err := options.client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrToolExecutionFailed, err)
+		}
+		defer resp.Body.Close()
+
+		data, err := io.ReadAll(io.LimitReader(resp.Body, options.maxResponseBytes+1))
+		if err != nil {
+			return nil, fmt.Errorf("%w: failed to read response: %v", ErrToolExecutionFailed, err)
+		}
+
+		truncated := int64(len(data)) > options.maxResponseBytes
+		if truncated {
+			data = data[:options.maxResponseBytes]
+		}
+
+		if resp.StatusCode >= http.StatusBadRequest {
+			return nil, fmt.Errorf("%w: %s %s returned %d: %s", ErrToolExecutionFailed, binding.method, path, resp.StatusCode, strings.TrimSpace(string(data)))
+		}
+
+		result := map[string]any{"status": resp.StatusCode}
+
+		var decoded any
+		if !truncated && isJSONContentType(resp.Header.Get("Content-Type")) && json.Unmarshal(data, &decoded) == nil {
+			result["body"] = decoded
+		} else if len(data) > 0 {
+			result["body"] = string(data)
+		}
+
+		if truncated {
+			result["truncated"] = true
+		}
+
+		return result, nil
+	}
+}
+
+// encodeBody builds the request body from the tool arguments, or returns nil
+// when the operation has no body or no body arguments were given.
+func (b *openAPIBinding) encodeBody(params map[string]any) (io.Reader, error) {
+	var value any
+
+	switch {
+	case b.bodyArg != "":
+		v, ok := params[b.bodyArg]
+		if !ok || v == nil {
+			return nil, nil
+		}
+
+		value = v
+	case len(b.bodyFields) > 0:
+		fields := make(map[string]any)
+
+		for _, field := range b.bodyFields {
+			if v, ok := params[field]; ok && v != nil {
+				fields[field] = v
+			}
+		}
+
+		if len(fields) == 0 {
+			return nil, nil
+		}
+
+		value = fields
+	default:
+		return nil, nil
+	}
+
+	switch {
+	case isJSONContentType(b.contentType):
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: failed to encode body: %v", ErrToolExecutionFailed, err)
+		}
+
+		return bytes.NewReader(data), nil
+	case b.contentType == "application/x-www-form-urlencoded":
+		form := url.Values{}
+		if fields, ok := value.(map[string]any); ok {
+			for key, v := range fields {
+				form.Set(key, formatOpenAPIValue(v))
+			}
+		}
+
+		return strings.NewReader(form.Encode()), nil
+	default:
+		return strings.NewReader(formatOpenAPIValue(value)), nil
+	}
+}
+
+// openAPIServerURL returns the first server URL with its variables replaced
+// by their defaults.
+func openAPIServerURL(doc map[string]any) string {
+	servers, _ := doc["servers"].([]any)
+	if len(servers) == 0 {
+		return ""
+	}
+
+	server, _ := servers[0].(map[string]any)
+	serverURL, _ := server["url"].(string)
+
+	variables, _ := server["variables"].(map[string]any)
+	for name, variable := range variables {
+		v, _ := variable.(map[string]any)
+		serverURL = strings.ReplaceAll(serverURL, "{"+name+"}", fmt.Sprint(v["default"]))
+	}
+
+	return serverURL
+}
+
+// selectOpenAPIContentType prefers JSON request bodies, then form bodies.
+func selectOpenAPIContentType(content map[string]any) string {
+	types := slices.Sorted(maps.Keys(content))
+
+	for _, contentType := range types {
+		if isJSONContentType(contentType) {
+			return contentType
+		}
+	}
+
+	if slices.Contains(types, "application/x-www-form-urlencoded") {
+		return "application/x-www-form-urlencoded"
+	}
+
+	return types[0]
+}
+
+func isJSONContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// formatOpenAPIValue renders an argument for a path, query, header or form value.
+func formatOpenAPIValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+
+	if f, ok := jsonNumber(value); ok {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+
+	return string(data)
+}
+
+// sanitizeToolName keeps letters, digits, '_' and '-', which every provider
+// accepts in function names.
+func sanitizeToolName(name string) string {
+	var b strings.Builder
+
+	underscore := false
+
+	for _, r := range name {
+		if r < 128 && (r == '-' || r == '_' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z') {
+			b.WriteRune(r)
+
+			underscore = r == '_'
+		} else if !underscore {
+			b.WriteByte('_')
+
+			underscore = true
+		}
+	}
+
+	return strings.Trim(b.String(), "_")
+}
+
+// normalizeYAMLValue converts decoded YAML into the types encoding/json
+// produces, turning non-string keys such as response codes into strings.
+func normalizeYAMLValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		object := make(map[string]any, len(v))
+		for key, item := range v {
+			object[key] = normalizeYAMLValue(item)
+		}
+
+		return object
+	case map[any]any:
+		object := make(map[string]any, len(v))
+		for key, item := range v {
+			object[fmt.Sprint(key)] = normalizeYAMLValue(item)
+		}
+
+		return object
+	case []any:
+		items := make([]any, len(v))
+		for i, item := range v {
+			items[i] = normalizeYAMLValue(item)
+		}
+
+		return items
+	}
+
+	return normalizeJSONValue(value)
+}
+
+// stringList returns the string elements of a decoded list.
+func stringList(value any) []string {
+	list, _ := value.([]any)
+
+	var out []string
+
+	for _, item := range list {
+		if s, ok := item.(string); ok {
+			out = append(out, s)
+		}
+	}
+
+	return out
+}
+
+func schemaFloat(value any) *float64 {
+	if f, ok := value.(float64); ok {
+		return &f
+	}
+
+	return nil
+}
+
+func schemaInt(value any) *int {
+	if f, ok := value.(float64); ok {
+		n := int(f)
+
+		return &n
+	}
+
+	return nil
+}
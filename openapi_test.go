@@ -0,0 +1,271 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const petStoreSpec = `
+openapi: 3.0.3
+info:
+  title: Pet Store
+  version: 2.1.0
+servers:
+  - url: https://{region}.pets.example.com/v1
+    variables:
+      region:
+        default: eu
+paths:
+  /pets/{petId}:
+    parameters:
+      - name: petId
+        in: path
+        schema: {type: integer, minimum: 1}
+    get:
+      operationId: getPet
+      summary: Get a pet
+      tags: [pets]
+      parameters:
+        - name: fields
+          in: query
+          schema: {type: array, items: {type: string}}
+        - name: X-Trace
+          in: header
+          schema: {type: string}
+      responses:
+        200:
+          description: OK
+  /pets:
+    post:
+      summary: Create a pet
+      tags: [pets, write]
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema: {$ref: '#/components/schemas/NewPet'}
+      responses:
+        201:
+          description: Created
+  /logs:
+    get:
+      operationId: getLogs
+      responses:
+        200:
+          description: OK
+components:
+  schemas:
+    NewPet:
+      allOf:
+        - $ref: '#/components/schemas/Base'
+        - type: object
+          required: [name]
+          properties:
+            name: {type: string, minLength: 1}
+            kind: {type: string, enum: [cat, dog]}
+    Base:
+      type: object
+      properties:
+        id: {type: integer, readOnly: true}
+        age: {type: integer, minimum: 0, exclusiveMinimum: true}
+`
+
+func writePetStoreSpec(t *testing.T) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "petstore.yaml")
+	if err := os.WriteFile(path, []byte(petStoreSpec), 0o600); err != nil {
+		t.Fatalf("failed to write spec: %v", err)
+	}
+
+	return path
+}
+
+func TestParseOpenAPITools_Schemas(t *testing.T) {
+	tools, err := ParseOpenAPITools([]byte(petStoreSpec))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	byName := make(map[string]*ToolDefinition)
+	for _, tool := range tools {
+		byName[tool.Name] = tool
+	}
+
+	if len(tools) != 3 || byName["getPet"] == nil || byName["POST_pets"] == nil || byName["getLogs"] == nil {
+		t.Fatalf("unexpected tools: %v", byName)
+	}
+
+	getPet := byName["getPet"]
+	if getPet.Category != "pets" || !slices.Equal(getPet.Tags, []string{"pets"}) || getPet.Description != "Get a pet" {
+		t.Errorf("unexpected metadata: %+v", getPet)
+	}
+
+	if !slices.Equal(getPet.Parameters.Required, []string{"petId"}) {
+		t.Errorf("expected path parameter required, got %v", getPet.Parameters.Required)
+	}
+
+	if petID := getPet.Parameters.Properties["petId"]; petID.Type != "integer" || *petID.Minimum != 1 {
+		t.Errorf("unexpected petId schema: %+v", petID)
+	}
+
+	if fields := getPet.Parameters.Properties["fields"]; fields.Type != "array" || fields.Items.Type != "string" {
+		t.Errorf("unexpected fields schema: %+v", fields)
+	}
+
+	create := byName["POST_pets"].Parameters
+	if !slices.Equal(create.Required, []string{"name"}) {
+		t.Errorf("expected body field required, got %v", create.Required)
+	}
+
+	if _, ok := create.Properties["id"]; ok {
+		t.Error("expected read-only property to be skipped")
+	}
+
+	if age := create.Properties["age"]; age.ExclusiveMinimum == nil || age.Minimum != nil {
+		t.Errorf("expected exclusive minimum, got %+v", age)
+	}
+
+	if kind := create.Properties["kind"]; !slices.Equal(kind.Enum, []string{"cat", "dog"}) {
+		t.Errorf("unexpected kind schema: %+v", kind)
+	}
+
+	if logs := byName["getLogs"]; logs.Category != "Pet Store" {
+		t.Errorf("expected category from title, got %q", logs.Category)
+	}
+}
+
+func TestRegisterOpenAPI_CallsAPI(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.Method {
+		case http.MethodGet:
+			if r.URL.Path == "/pets/7" {
+				_ = json.NewEncoder(w).Encode(map[string]any{
+					"fields": r.URL.Query()["fields"],
+					"trace":  r.Header.Get("X-Trace"),
+				})
+
+				return
+			}
+
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":"no such pet"}`))
+		case http.MethodPost:
+			var body map[string]any
+
+			_ = json.NewDecoder(r.Body).Decode(&body)
+
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(body)
+		}
+	}))
+	defer server.Close()
+
+	registry := NewToolRegistry(nil, nil)
+
+	tools, err := registry.RegisterOpenAPI(writePetStoreSpec(t), WithOpenAPIBaseURL(server.URL), WithOpenAPIBearerToken("secret"))
+	if err != nil || len(tools) != 3 {
+		t.Fatalf("expected 3 tools, got %d (%v)", len(tools), err)
+	}
+
+	result, err := registry.ExecuteTool(context.Background(), "getPet", "", map[string]any{
+		"petId":   float64(7),
+		"fields":  []any{"name", "kind"},
+		"X-Trace": "abc",
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	output := result.Result.(map[string]any)
+	body := output["body"].(map[string]any)
+
+	if output["status"] != http.StatusOK || body["trace"] != "abc" || len(body["fields"].([]any)) != 2 {
+		t.Errorf("unexpected output: %v", output)
+	}
+
+	result, err = registry.ExecuteTool(context.Background(), "POST_pets", "", map[string]any{"name": "Rex", "kind": "dog"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if output := result.Result.(map[string]any); output["status"] != http.StatusCreated || output["body"].(map[string]any)["name"] != "Rex" {
+		t.Errorf("unexpected output: %v", output)
+	}
+
+	_, err = registry.ExecuteTool(context.Background(), "getPet", "", map[string]any{"petId": float64(8)})
+	if !errors.Is(err, ErrToolExecutionFailed) || !strings.Contains(err.Error(), "404") {
+		t.Errorf("expected 404 failure, got %v", err)
+	}
+
+	if _, err := registry.ExecuteTool(context.Background(), "getPet", "", map[string]any{"petId": float64(0)}); !errors.Is(err, ErrInvalidToolArguments) {
+		t.Errorf("expected schema validation failure, got %v", err)
+	}
+}
+
+func TestOpenAPI_TruncatesResponses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(strings.Repeat("x", 100)))
+	}))
+	defer server.Close()
+
+	tools, err := ParseOpenAPITools([]byte(petStoreSpec),
+		WithOpenAPIBaseURL(server.URL),
+		WithOpenAPIMaxResponseBytes(10),
+		WithOpenAPINamePrefix("store_"),
+		WithOpenAPIFilter(func(op OpenAPIOperation) bool { return op.ID == "getLogs" }),
+	)
+	if err != nil || len(tools) != 1 || tools[0].Name != "store_getLogs" {
+		t.Fatalf("expected one filtered tool, got %v (%v)", tools, err)
+	}
+
+	result, err := tools[0].Handler(context.Background(), map[string]any{})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	output := result.(map[string]any)
+	if output["body"] != "xxxxxxxxxx" || output["truncated"] != true {
+		t.Errorf("expected truncated body, got %v", output)
+	}
+}
+
+func TestParseOpenAPITools_Invalid(t *testing.T) {
+	if _, err := ParseOpenAPITools([]byte(`swagger: "2.0"`)); !errors.Is(err, ErrInvalidOpenAPISpec) {
+		t.Errorf("expected ErrInvalidOpenAPISpec for Swagger 2, got %v", err)
+	}
+
+	noServer := `{"openapi": "3.1.0", "info": {"title": "x"}, "paths": {}}`
+	if _, err := ParseOpenAPITools([]byte(noServer)); !errors.Is(err, ErrInvalidOpenAPISpec) {
+		t.Errorf("expected ErrInvalidOpenAPISpec without a server URL, got %v", err)
+	}
+
+	tools, err := ParseOpenAPITools([]byte(petStoreSpec))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if tools[0].Metadata["openapi.path"] == nil {
+		t.Error("expected OpenAPI metadata on tools")
+	}
+
+	if _, err := ParseOpenAPITools([]byte(petStoreSpec), WithOpenAPIBaseURL("not a url")); !errors.Is(err, ErrInvalidOpenAPISpec) {
+		t.Errorf("expected ErrInvalidOpenAPISpec for relative base URL, got %v", err)
+	}
+}
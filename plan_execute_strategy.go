@@ -226,7 +226,7 @@ func (s *PlanExecuteStrategy) Execute(ctx context.Context, agent *Agent, input s
 // createPlan generates a plan for the given task.
 func (s *PlanExecuteStrategy) createPlan(ctx context.Context, agent *Agent, task string) (*Plan, error) {
 	// Build planning prompt
-	prompt := s.buildPlanningPrompt(task, agent.tools)
+	prompt := s.buildPlanningPrompt(task, agent.availableTools())
 
 	// Call LLM for planning
 	request := llm.ChatRequest{
@@ -386,8 +386,9 @@ func (s *PlanExecuteStrategy) executeStep(
 	// Add tools if step needs them
 	if len(step.ToolsNeeded) > 0 {
 		llmTools := make([]llm.Tool, 0)
+		tools := agent.availableTools()
 		for _, toolName := range step.ToolsNeeded {
-			for _, tool := range agent.tools {
+			for _, tool := range tools {
 				if tool.Name == toolName {
 					llmTools = append(llmTools, llm.Tool{
 						Type: "function",
@@ -472,13 +473,14 @@ func (s *PlanExecuteStrategy) executeStep(
 // executeToolCalls executes tool calls from LLM response.
 func (s *PlanExecuteStrategy) executeToolCalls(ctx context.Context, agent *Agent, toolCalls []llm.ToolCall) (any, error) {
 	results := make([]any, len(toolCalls))
+	tools := agent.availableTools()
 
 	for i, tc := range toolCalls {
 		// Find tool
 		var tool *Tool
-		for j := range agent.tools {
-			if agent.tools[j].Name == tc.Function.Name {
-				tool = &agent.tools[j]
+		for j := range tools {
+			if tools[j].Name == tc.Function.Name {
+				tool = &tools[j]
 				break
 			}
 		}
@@ -495,6 +497,10 @@ func (s *PlanExecuteStrategy) executeToolCalls(ctx context.Context, agent *Agent
 			}
 		}
 
+		if err := AuthorizeTool(ctx, ToolOriginAgent, tool.Name, tool.RequiredScopes, agent.toolAuditHook); err != nil {
+			return nil, err
+		}
+
 		// Execute tool
 		result, err := tool.Handler(ctx, args)
 		if err != nil {
@@ -26,6 +26,7 @@ type RAG struct {
 	includeMetadata  bool
 	chunkSize        int
 	chunkOverlap     int
+	chunker          Chunker
 	maxContextTokens int
 
 	// Citation support
@@ -52,6 +53,10 @@ type RAGOptions struct {
 	ChunkSize        int
 	ChunkOverlap     int
 	MaxContextTokens int
+
+	// Chunker splits documents without chunks for indexing. Defaults to
+	// windows of ChunkSize characters overlapping by ChunkOverlap.
+	Chunker Chunker
 }
 
 // Document represents a document for RAG.
@@ -129,6 +134,8 @@ func NewRAG(
 		if opts.MaxContextTokens > 0 {
 			rag.maxContextTokens = opts.MaxContextTokens
 		}
+
+		rag.chunker = opts.Chunker
 	}
 
 	return rag
@@ -148,7 +155,20 @@ func (r *RAG) IndexDocument(ctx context.Context, doc Document) error {
 	// Chunk the document if needed
 	chunks := doc.Chunks
 	if len(chunks) == 0 {
-		chunks = r.chunkDocument(doc)
+		if r.chunker == nil {
+			chunks = r.chunkDocument(doc)
+		} else {
+			var err error
+
+			chunks, err = r.chunker.Chunk(ctx, doc)
+			if err != nil {
+				if r.metrics != nil {
+					r.metrics.Counter("forge.ai.sdk.rag.index.errors").Inc()
+				}
+
+				return fmt.Errorf("failed to chunk document: %w", err)
+			}
+		}
 	}
 
 	// Generate embeddings for chunks
@@ -177,8 +197,9 @@ func (r *RAG) IndexDocument(ctx context.Context, doc Document) error {
 		metadata["chunk_index"] = i
 		metadata["content"] = chunk.Content
 
-		if r.includeMetadata && doc.Metadata != nil {
+		if r.includeMetadata {
 			maps.Copy(metadata, doc.Metadata)
+			maps.Copy(metadata, chunk.Metadata)
 		}
 
 		vectors[i] = Vector{
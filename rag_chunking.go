@@ -0,0 +1,609 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"math"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// Chunker splits documents into chunks for indexing, see RAGOptions.Chunker.
+type Chunker interface {
+	Chunk(ctx context.Context, doc Document) ([]DocumentChunk, error)
+}
+
+// ChunkerFunc adapts a function to a Chunker.
+type ChunkerFunc func(ctx context.Context, doc Document) ([]DocumentChunk, error)
+
+// Chunk implements Chunker.
+func (f ChunkerFunc) Chunk(ctx context.Context, doc Document) ([]DocumentChunk, error) {
+	return f(ctx, doc)
+}
+
+// DefaultSeparators are the separators of RecursiveChunker: paragraphs, lines,
+// sentences, words and finally characters.
+var DefaultSeparators = []string{"\n\n", "\n", ". ", " ", ""}
+
+const defaultChunkSize = 512
+
+// RecursiveChunker splits text on the first separator that occurs in it and
+// splits pieces that are still too long on the following separators, so that
+// chunks end at paragraph, line, sentence and word boundaries where possible.
+// Pieces are merged into chunks of up to ChunkSize, each repeating up to
+// ChunkOverlap of the end of the previous chunk.
+type RecursiveChunker struct {
+	ChunkSize    int // 512 by default
+	ChunkOverlap int
+	Separators   []string // DefaultSeparators by default
+
+	// Length measures text, in runes by default. Counting tokens sizes chunks
+	// in tokens while keeping the separator boundaries.
+	Length func(string) int
+}
+
+// Chunk implements Chunker.
+func (c *RecursiveChunker) Chunk(ctx context.Context, doc Document) ([]DocumentChunk, error) {
+	return newChunks(doc, c.SplitText(doc.Content), nil), nil
+}
+
+// SplitText splits text into chunk contents.
+func (c *RecursiveChunker) SplitText(text string) []string {
+	size, overlap := chunkBounds(c.ChunkSize, c.ChunkOverlap)
+
+	separators := c.Separators
+	if len(separators) == 0 {
+		separators = DefaultSeparators
+	}
+
+	return splitRecursive(text, separators, size, overlap, chunkLength(c.Length))
+}
+
+func splitRecursive(text string, separators []string, size, overlap int, length func(string) int) []string {
+	separator, rest := separators[len(separators)-1], []string(nil)
+
+	for i, sep := range separators {
+		if sep == "" || strings.Contains(text, sep) {
+			separator, rest = sep, separators[i+1:]
+
+			break
+		}
+	}
+
+	var chunks, pieces []string
+
+	// Separators stay at the end of their piece, so that chunks keep the
+	// original text
+	for _, piece := range strings.SplitAfter(text, separator) {
+		if length(piece) <= size {
+			pieces = append(pieces, piece)
+
+			continue
+		}
+
+		chunks = append(chunks, mergePieces(pieces, size, overlap, length)...)
+		pieces = nil
+
+		if len(rest) == 0 {
+			if piece = strings.TrimSpace(piece); piece != "" {
+				chunks = append(chunks, piece)
+			}
+
+			continue
+		}
+
+		chunks = append(chunks, splitRecursive(piece, rest, size, overlap, length)...)
+	}
+
+	return append(chunks, mergePieces(pieces, size, overlap, length)...)
+}
+
+// mergePieces joins consecutive pieces into chunks of up to size, starting
+// each chunk with the last pieces of the previous one up to overlap.
+func mergePieces(pieces []string, size, overlap int, length func(string) int) []string {
+	var (
+		chunks []string
+		window []string
+		total  int
+	)
+
+	flush := func() {
+		if chunk := strings.TrimSpace(strings.Join(window, "")); chunk != "" {
+			chunks = append(chunks, chunk)
+		}
+	}
+
+	for _, piece := range pieces {
+		n := length(piece)
+
+		if total+n > size && len(window) > 0 {
+			flush()
+
+			for len(window) > 0 && (total > overlap || total+n > size) {
+				total -= length(window[0])
+				window = window[1:]
+			}
+		}
+
+		window = append(window, piece)
+		total += n
+	}
+
+	if len(window) > 0 {
+		flush()
+	}
+
+	return chunks
+}
+
+// MarkdownChunker splits markdown at its headings, keeping each section with
+// its heading; headings inside fenced code blocks are ignored. Sections longer
+// than ChunkSize are split further like RecursiveChunker. The metadata of
+// each chunk holds the headings enclosing it as "heading_path", e.g.
+// []string{"Install", "From source"}.
+type MarkdownChunker struct {
+	ChunkSize    int // 512 by default
+	ChunkOverlap int
+
+	// MaxHeadingLevel is the deepest heading level starting a section, 6 by
+	// default.
+	MaxHeadingLevel int
+
+	Length func(string) int
+}
+
+var markdownHeading = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+
+// Chunk implements Chunker.
+func (c *MarkdownChunker) Chunk(ctx context.Context, doc Document) ([]DocumentChunk, error) {
+	size, overlap := chunkBounds(c.ChunkSize, c.ChunkOverlap)
+	length := chunkLength(c.Length)
+
+	maxLevel := c.MaxHeadingLevel
+	if maxLevel <= 0 || maxLevel > 6 {
+		maxLevel = 6
+	}
+
+	var (
+		contents []string
+		metadata []map[string]any
+		levels   []int
+		path     []string
+		section  strings.Builder
+		hasBody  bool
+		fence    string
+	)
+
+	flush := func() {
+		if hasBody {
+			var meta map[string]any
+			if len(path) > 0 {
+				meta = map[string]any{"heading_path": slices.Clone(path)}
+			}
+
+			text := section.String()
+			if length(text) <= size {
+				contents = append(contents, text)
+				metadata = append(metadata, meta)
+			} else {
+				for _, part := range splitRecursive(text, DefaultSeparators, size, overlap, length) {
+					contents = append(contents, part)
+					metadata = append(metadata, meta)
+				}
+			}
+		}
+
+		section.Reset()
+
+		hasBody = false
+	}
+
+	for line := range strings.Lines(doc.Content) {
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case fence != "":
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+		case strings.HasPrefix(trimmed, "```"), strings.HasPrefix(trimmed, "~~~"):
+			fence = trimmed[:3]
+		default:
+			match := markdownHeading.FindStringSubmatch(strings.TrimRight(line, "\r\n"))
+			if match == nil || len(match[1]) > maxLevel {
+				break
+			}
+
+			flush()
+
+			level := len(match[1])
+			for len(levels) > 0 && levels[len(levels)-1] >= level {
+				levels, path = levels[:len(levels)-1], path[:len(path)-1]
+			}
+
+			levels, path = append(levels, level), append(path, match[2])
+			section.WriteString(line)
+
+			continue
+		}
+
+		section.WriteString(line)
+
+		hasBody = hasBody || trimmed != ""
+	}
+
+	flush()
+
+	return newChunks(doc, contents, metadata), nil
+}
+
+// Tokenizer converts text to tokens and back, e.g. the BPE tokenizer of the
+// embedding model.
+type Tokenizer interface {
+	Encode(text string) []int
+	Decode(tokens []int) string
+}
+
+// TokenChunker splits documents into windows of ChunkSize tokens, each
+// starting ChunkOverlap tokens before the end of the previous one. To keep
+// paragraph and sentence boundaries instead, count tokens in
+// RecursiveChunker.Length.
+type TokenChunker struct {
+	Tokenizer    Tokenizer
+	ChunkSize    int // 512 by default
+	ChunkOverlap int
+}
+
+// Chunk implements Chunker.
+func (c *TokenChunker) Chunk(ctx context.Context, doc Document) ([]DocumentChunk, error) {
+	if c.Tokenizer == nil {
+		return nil, fmt.Errorf("%w: token chunker needs a tokenizer", ErrInvalidChunker)
+	}
+
+	size, overlap := chunkBounds(c.ChunkSize, c.ChunkOverlap)
+	tokens := c.Tokenizer.Encode(doc.Content)
+
+	var contents []string
+
+	for start := 0; start < len(tokens); start += size - overlap {
+		end := min(start+size, len(tokens))
+		contents = append(contents, strings.TrimSpace(c.Tokenizer.Decode(tokens[start:end])))
+
+		if end == len(tokens) {
+			break
+		}
+	}
+
+	return newChunks(doc, contents, nil), nil
+}
+
+// CodeChunker splits source code at top-level declarations such as
+// functions, methods, classes and types, keeping their leading comments and
+// decorators. Consecutive declarations are merged up to ChunkSize, and longer
+// ones are split at line boundaries. The metadata of each chunk holds the
+// "language" and its first and last lines as "start_line" and "end_line".
+type CodeChunker struct {
+	ChunkSize int // 512 by default
+
+	// Language selects the declaration patterns, e.g. "go", "python",
+	// "typescript" or "rust". Defaults to the document's "language" metadata;
+	// other languages use patterns common to many languages.
+	Language string
+
+	Length func(string) int
+}
+
+var (
+	codeDeclarations = map[string]*regexp.Regexp{
+		"go":         regexp.MustCompile(`^(func|type|var|const)\b`),
+		"python":     regexp.MustCompile(`^(async\s+def|def|class)\s`),
+		"javascript": regexp.MustCompile(`^(export\s+)?(default\s+)?(async\s+)?(function\b|class\b|(const|let|var)\s+\w+\s*=\s*(async\s*)?(\(|function\b|\w+\s*=>))`),
+		"typescript": regexp.MustCompile(`^(export\s+)?(default\s+)?(declare\s+)?(async\s+)?(function\b|(abstract\s+)?class\b|interface\b|type\b|enum\b|namespace\b|(const|let|var)\s+\w+(\s*:[^=]+)?\s*=\s*(async\s*)?(\(|function\b|\w+\s*=>))`),
+		"java":       regexp.MustCompile(`^\s{0,4}((public|protected|private|internal|static|final|abstract|sealed|override|virtual|async|synchronized)\s+)*(class|interface|enum|record|struct|fun|[\w<>\[\],.?]+\s+\w+\s*\()`),
+		"rust":       regexp.MustCompile(`^(pub(\([^)]*\))?\s+)?(async\s+)?(unsafe\s+)?(fn|struct|enum|impl|trait|mod|type)\b`),
+		"ruby":       regexp.MustCompile(`^\s{0,2}(def|class|module)\s`),
+	}
+	codeLanguageAliases = map[string]string{
+		"golang": "go", "py": "python", "js": "javascript", "jsx": "javascript", "ts": "typescript",
+		"tsx": "typescript", "kotlin": "java", "csharp": "java", "c#": "java", "rs": "rust", "rb": "ruby",
+	}
+	genericDeclaration = regexp.MustCompile(`^(export\s+)?(pub\s+)?(async\s+)?(func|function|def|class|fn|impl|struct|interface|type|module)\b`)
+	codeComment        = regexp.MustCompile(`^\s*(//|#|/\*|\*|--|@|///)`)
+)
+
+// Chunk implements Chunker.
+func (c *CodeChunker) Chunk(ctx context.Context, doc Document) ([]DocumentChunk, error) {
+	size, _ := chunkBounds(c.ChunkSize, 0)
+	length := chunkLength(c.Length)
+
+	language := c.Language
+	if language == "" {
+		language, _ = doc.Metadata["language"].(string)
+	}
+
+	language = strings.ToLower(language)
+	if alias, ok := codeLanguageAliases[language]; ok {
+		language = alias
+	}
+
+	declaration, ok := codeDeclarations[language]
+	if !ok {
+		declaration = genericDeclaration
+	}
+
+	lines := slices.Collect(strings.Lines(doc.Content))
+
+	// Blocks start at declarations, or at the comments and decorators
+	// preceding them
+	starts := []int{0}
+
+	for i := 1; i < len(lines); i++ {
+		if !declaration.MatchString(lines[i]) {
+			continue
+		}
+
+		start := i
+		for start-1 > starts[len(starts)-1] && strings.TrimSpace(lines[start-1]) != "" && codeComment.MatchString(lines[start-1]) {
+			start--
+		}
+
+		starts = append(starts, start)
+	}
+
+	var (
+		contents []string
+		metadata []map[string]any
+		first    int
+		total    int
+	)
+
+	emit := func(end int) {
+		// Trim blank lines, keeping the indentation of the first line
+		for first < end && strings.TrimSpace(lines[first]) == "" {
+			first++
+		}
+
+		last := end
+		for last > first && strings.TrimSpace(lines[last-1]) == "" {
+			last--
+		}
+
+		if first < last {
+			contents = append(contents, strings.TrimRight(strings.Join(lines[first:last], ""), " \t\r\n"))
+			metadata = append(metadata, map[string]any{
+				"language":   language,
+				"start_line": first + 1,
+				"end_line":   last,
+			})
+		}
+
+		first, total = end, 0
+	}
+
+	for i, start := range starts {
+		end := len(lines)
+		if i+1 < len(starts) {
+			end = starts[i+1]
+		}
+
+		block := length(strings.Join(lines[start:end], ""))
+
+		if total > 0 && total+block > size {
+			emit(start)
+		}
+
+		if block <= size {
+			total += block
+
+			continue
+		}
+
+		// Split long blocks at line boundaries
+		for line := start; line < end; line++ {
+			n := length(lines[line])
+			if total > 0 && total+n > size {
+				emit(line)
+			}
+
+			total += n
+		}
+
+		emit(end)
+	}
+
+	emit(len(lines))
+
+	return newChunks(doc, contents, metadata), nil
+}
+
+// SemanticChunker splits documents into sentences and starts a new chunk
+// where the embedding similarity of adjacent sentences drops below Threshold.
+// Without a Threshold, the document's own similarities set it at their
+// Percentile-th percentile, so that the least related sentences are split.
+type SemanticChunker struct {
+	Embedder EmbeddingModel
+
+	// Threshold is the cosine similarity below which chunks are split.
+	Threshold float64
+
+	// Percentile applies when Threshold is zero, 5 by default.
+	Percentile float64
+
+	// MaxChunkSize splits longer chunks like RecursiveChunker; zero leaves
+	// chunks whole.
+	MaxChunkSize int
+
+	Length func(string) int
+}
+
+var sentenceEnd = regexp.MustCompile(`[.!?]["')\]]*\s+|\n\s*\n`)
+
+// Chunk implements Chunker.
+func (c *SemanticChunker) Chunk(ctx context.Context, doc Document) ([]DocumentChunk, error) {
+	if c.Embedder == nil {
+		return nil, fmt.Errorf("%w: semantic chunker needs an embedder", ErrInvalidChunker)
+	}
+
+	sentences := splitSentences(doc.Content)
+	if len(sentences) == 0 {
+		return nil, nil
+	}
+
+	texts := make([]string, len(sentences))
+	for i, sentence := range sentences {
+		texts[i] = strings.TrimSpace(sentence)
+	}
+
+	embeddings, err := c.Embedder.Embed(ctx, texts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed sentences: %w", err)
+	}
+
+	if len(embeddings) != len(sentences) {
+		return nil, fmt.Errorf("embedder returned %d embeddings for %d sentences", len(embeddings), len(sentences))
+	}
+
+	similarities := make([]float64, len(sentences)-1)
+	for i := range similarities {
+		similarities[i] = cosineSimilarity(embeddings[i].Values, embeddings[i+1].Values)
+	}
+
+	threshold := c.Threshold
+	if threshold == 0 && len(similarities) > 0 {
+		percentile := c.Percentile
+		if percentile <= 0 {
+			percentile = 5
+		}
+
+		threshold = percentileOf(similarities, percentile)
+	}
+
+	var (
+		contents []string
+		current  strings.Builder
+	)
+
+	flush := func() {
+		text := strings.TrimSpace(current.String())
+		current.Reset()
+
+		if c.MaxChunkSize > 0 && chunkLength(c.Length)(text) > c.MaxChunkSize {
+			contents = append(contents, splitRecursive(text, DefaultSeparators, c.MaxChunkSize, 0, chunkLength(c.Length))...)
+
+			return
+		}
+
+		contents = append(contents, text)
+	}
+
+	for i, sentence := range sentences {
+		current.WriteString(sentence)
+
+		if i < len(similarities) && similarities[i] < threshold {
+			flush()
+		}
+	}
+
+	flush()
+
+	return newChunks(doc, contents, nil), nil
+}
+
+// splitSentences splits text after sentence ends and blank lines, keeping the
+// whitespace so that the sentences join back into text.
+func splitSentences(text string) []string {
+	var (
+		sentences []string
+		start     int
+	)
+
+	for _, loc := range sentenceEnd.FindAllStringIndex(text, -1) {
+		sentences = append(sentences, text[start:loc[1]])
+		start = loc[1]
+	}
+
+	sentences = append(sentences, text[start:])
+
+	return slices.DeleteFunc(sentences, func(s string) bool { return strings.TrimSpace(s) == "" })
+}
+
+func cosineSimilarity(a, b []float64) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+
+	var dot, normA, normB float64
+
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// percentileOf interpolates the p-th percentile of values.
+func percentileOf(values []float64, p float64) float64 {
+	sorted := slices.Sorted(slices.Values(values))
+	rank := min(p, 100) / 100 * float64(len(sorted)-1)
+	lower := int(rank)
+
+	if lower+1 >= len(sorted) {
+		return sorted[lower]
+	}
+
+	return sorted[lower] + (rank-float64(lower))*(sorted[lower+1]-sorted[lower])
+}
+
+// newChunks numbers chunk contents like RAG's default chunking, merging each
+// chunk's metadata over the document's. Blank contents are dropped.
+func newChunks(doc Document, contents []string, metadata []map[string]any) []DocumentChunk {
+	chunks := make([]DocumentChunk, 0, len(contents))
+
+	for i, content := range contents {
+		if strings.TrimSpace(content) == "" {
+			continue
+		}
+
+		meta := doc.Metadata
+		if metadata != nil && len(metadata[i]) > 0 {
+			meta = make(map[string]any, len(doc.Metadata)+len(metadata[i]))
+			maps.Copy(meta, doc.Metadata)
+			maps.Copy(meta, metadata[i])
+		}
+
+		chunks = append(chunks, DocumentChunk{
+			ID:       fmt.Sprintf("%s_chunk_%d", doc.ID, len(chunks)),
+			Content:  content,
+			Index:    len(chunks),
+			Metadata: meta,
+		})
+	}
+
+	return chunks
+}
+
+// chunkBounds applies the default chunk size and keeps the overlap below it.
+func chunkBounds(size, overlap int) (int, int) {
+	if size <= 0 {
+		size = defaultChunkSize
+	}
+
+	return size, min(max(overlap, 0), size-1)
+}
+
+func chunkLength(length func(string) int) func(string) int {
+	if length == nil {
+		return utf8.RuneCountInString
+	}
+
+	return length
+}
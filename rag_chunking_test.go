@@ -0,0 +1,245 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestRecursiveChunker(t *testing.T) {
+	text := "First paragraph is short.\n\nSecond paragraph is a little longer than the first one, so it gets split on words.\n\nThird."
+
+	chunker := &RecursiveChunker{ChunkSize: 40}
+
+	chunks, err := chunker.Chunk(context.Background(), Document{ID: "doc", Content: text})
+	if err != nil {
+		t.Fatalf("chunk: %v", err)
+	}
+
+	if chunks[0].Content != "First paragraph is short." {
+		t.Errorf("expected the first paragraph to stay whole, got %q", chunks[0].Content)
+	}
+
+	for i, chunk := range chunks {
+		if utf8.RuneCountInString(chunk.Content) > 40 {
+			t.Errorf("chunk %d exceeds the chunk size: %q", i, chunk.Content)
+		}
+
+		if chunk.Index != i || chunk.ID != fmt.Sprintf("doc_chunk_%d", i) {
+			t.Errorf("unexpected chunk numbering %s/%d", chunk.ID, chunk.Index)
+		}
+	}
+
+	overlapping := (&RecursiveChunker{ChunkSize: 20, ChunkOverlap: 8}).SplitText("one two three four five six seven eight")
+	if len(overlapping) < 2 {
+		t.Fatalf("expected several chunks, got %q", overlapping)
+	}
+
+	last := strings.Fields(overlapping[0])
+	if !strings.HasPrefix(overlapping[1], last[len(last)-1]) {
+		t.Errorf("expected %q to overlap %q", overlapping[1], overlapping[0])
+	}
+}
+
+func TestMarkdownChunker(t *testing.T) {
+	content := strings.Join([]string{
+		"# Guide",
+		"Welcome.",
+		"## Install",
+		"```sh",
+		"# not a heading",
+		"make install",
+		"```",
+		"### From source",
+		"Build it.",
+		"# API",
+		"Calls.",
+	}, "\n")
+
+	chunks, err := (&MarkdownChunker{}).Chunk(context.Background(), Document{
+		ID:       "readme",
+		Content:  content,
+		Metadata: map[string]any{"source": "README.md"},
+	})
+	if err != nil {
+		t.Fatalf("chunk: %v", err)
+	}
+
+	want := [][]string{{"Guide"}, {"Guide", "Install"}, {"Guide", "Install", "From source"}, {"API"}}
+	if len(chunks) != len(want) {
+		t.Fatalf("expected %d sections, got %d: %+v", len(want), len(chunks), chunks)
+	}
+
+	for i, chunk := range chunks {
+		path, _ := chunk.Metadata["heading_path"].([]string)
+		if !slices.Equal(path, want[i]) {
+			t.Errorf("chunk %d: expected heading path %v, got %v", i, want[i], path)
+		}
+
+		if chunk.Metadata["source"] != "README.md" {
+			t.Errorf("chunk %d: expected the document metadata", i)
+		}
+	}
+
+	if !strings.Contains(chunks[1].Content, "# not a heading") {
+		t.Errorf("expected the code block to stay in its section, got %q", chunks[1].Content)
+	}
+}
+
+type wordTokenizer struct {
+	words []string
+}
+
+func (w *wordTokenizer) Encode(text string) []int {
+	tokens := make([]int, 0)
+
+	for _, word := range strings.Fields(text) {
+		w.words = append(w.words, word)
+		tokens = append(tokens, len(w.words)-1)
+	}
+
+	return tokens
+}
+
+func (w *wordTokenizer) Decode(tokens []int) string {
+	words := make([]string, len(tokens))
+	for i, token := range tokens {
+		words[i] = w.words[token]
+	}
+
+	return strings.Join(words, " ")
+}
+
+func TestTokenChunker(t *testing.T) {
+	chunker := &TokenChunker{Tokenizer: &wordTokenizer{}, ChunkSize: 4, ChunkOverlap: 1}
+
+	chunks, err := chunker.Chunk(context.Background(), Document{ID: "doc", Content: "a b c d e f g"})
+	if err != nil {
+		t.Fatalf("chunk: %v", err)
+	}
+
+	if len(chunks) != 2 || chunks[0].Content != "a b c d" || chunks[1].Content != "d e f g" {
+		t.Errorf("unexpected chunks %+v", chunks)
+	}
+
+	if _, err := (&TokenChunker{}).Chunk(context.Background(), Document{Content: "a"}); !errors.Is(err, ErrInvalidChunker) {
+		t.Errorf("expected ErrInvalidChunker, got %v", err)
+	}
+}
+
+func TestCodeChunker(t *testing.T) {
+	source := `package main
+
+import "fmt"
+
+// Hello greets someone
+// by name.
+func Hello(name string) string {
+	return fmt.Sprintf("hello %s", name)
+}
+
+// Bye says goodbye.
+func Bye() string {
+	return "bye"
+}
+`
+
+	chunks, err := (&CodeChunker{ChunkSize: 120}).Chunk(context.Background(), Document{
+		ID:       "main.go",
+		Content:  source,
+		Metadata: map[string]any{"language": "golang"},
+	})
+	if err != nil {
+		t.Fatalf("chunk: %v", err)
+	}
+
+	if len(chunks) != 3 {
+		t.Fatalf("expected the preamble and two functions, got %d: %+v", len(chunks), chunks)
+	}
+
+	if !strings.HasPrefix(chunks[1].Content, "// Hello greets someone") || !strings.HasSuffix(chunks[1].Content, "}") {
+		t.Errorf("expected the function with its comment, got %q", chunks[1].Content)
+	}
+
+	if chunks[1].Metadata["start_line"] != 5 || chunks[1].Metadata["end_line"] != 9 || chunks[1].Metadata["language"] != "go" {
+		t.Errorf("unexpected metadata %v", chunks[1].Metadata)
+	}
+
+	merged, _ := (&CodeChunker{ChunkSize: 1000, Language: "go"}).Chunk(context.Background(), Document{Content: source})
+	if len(merged) != 1 {
+		t.Errorf("expected small functions to be merged, got %d chunks", len(merged))
+	}
+}
+
+func TestSemanticChunker(t *testing.T) {
+	embedder := &MockEmbeddingModel{
+		EmbedFunc: func(ctx context.Context, texts []string) ([]Vector, error) {
+			vectors := make([]Vector, len(texts))
+			for i, text := range texts {
+				vectors[i] = Vector{Values: []float64{1, 0}}
+				if strings.Contains(text, "dog") {
+					vectors[i] = Vector{Values: []float64{0, 1}}
+				}
+			}
+
+			return vectors, nil
+		},
+	}
+
+	chunker := &SemanticChunker{Embedder: embedder}
+
+	chunks, err := chunker.Chunk(context.Background(), Document{
+		ID:      "pets",
+		Content: "Cats purr. Cats nap all day. A dog barks. The dog fetches.",
+	})
+	if err != nil {
+		t.Fatalf("chunk: %v", err)
+	}
+
+	if len(chunks) != 2 || chunks[0].Content != "Cats purr. Cats nap all day." || chunks[1].Content != "A dog barks. The dog fetches." {
+		t.Errorf("expected a split between the topics, got %+v", chunks)
+	}
+
+	if _, err := (&SemanticChunker{}).Chunk(context.Background(), Document{Content: "a"}); !errors.Is(err, ErrInvalidChunker) {
+		t.Errorf("expected ErrInvalidChunker, got %v", err)
+	}
+}
+
+func TestRAG_IndexDocument_WithChunker(t *testing.T) {
+	var vectors []Vector
+
+	vectorStore := &MockVectorStore{
+		UpsertFunc: func(ctx context.Context, v []Vector) error {
+			vectors = v
+
+			return nil
+		},
+	}
+
+	rag := NewRAG(vectorStore, &MockEmbeddingModel{}, nil, nil, &RAGOptions{
+		IncludeMetadata: true,
+		Chunker:         &MarkdownChunker{},
+	})
+
+	err := rag.IndexDocument(context.Background(), Document{ID: "doc", Content: "# One\nFirst.\n# Two\nSecond."})
+	if err != nil {
+		t.Fatalf("index: %v", err)
+	}
+
+	if len(vectors) != 2 {
+		t.Fatalf("expected 2 vectors, got %d", len(vectors))
+	}
+
+	if path, _ := vectors[1].Metadata["heading_path"].([]string); !slices.Equal(path, []string{"Two"}) {
+		t.Errorf("expected the chunk metadata to be stored, got %v", vectors[1].Metadata)
+	}
+
+	failing := NewRAG(vectorStore, &MockEmbeddingModel{}, nil, nil, &RAGOptions{Chunker: &TokenChunker{}})
+	if err := failing.IndexDocument(context.Background(), Document{ID: "doc", Content: "text"}); !errors.Is(err, ErrInvalidChunker) {
+		t.Errorf("expected the chunker error, got %v", err)
+	}
+}
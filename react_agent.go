@@ -314,9 +314,9 @@ func (a *ReactAgent) getToolDescription() string {
 		desc = fmt.Sprintf("Use ReAct reasoning to solve: %s", a.Name)
 	}
 
-	if len(a.tools) > 0 {
-		toolNames := make([]string, len(a.tools))
-		for i, tool := range a.tools {
+	if tools := a.availableTools(); len(tools) > 0 {
+		toolNames := make([]string, len(tools))
+		for i, tool := range tools {
 			toolNames[i] = tool.Name
 		}
 		desc += fmt.Sprintf(" Available tools: %v", toolNames)
@@ -184,11 +184,13 @@ func (s *ReactStrategy) Execute(ctx context.Context, agent *Agent, input string)
 
 		// Step 2: Execute action if specified
 		if trace.Action != "" {
-			observation, err := s.act(execCtx, agent, trace)
+			actCtx, cacheHit := withToolCacheRecorder(execCtx)
+
+			observation, err := s.act(actCtx, agent, trace)
 			if err != nil {
 				step.Error = err.Error()
 				step.State = StepStateFailed
-				trace.Observation = fmt.Sprintf("Error: %s", err.Error())
+				trace.Observation = "Error: " + toolErrorContent(err)
 				trace.Confidence = 0.3 // Low confidence on error
 			} else {
 				trace.Observation = observation
@@ -197,6 +199,7 @@ func (s *ReactStrategy) Execute(ctx context.Context, agent *Agent, input string)
 						Name:     trace.Action,
 						Result:   observation,
 						Duration: time.Since(step.StartTime),
+						Cached:   cacheHit.Load(),
 					},
 				}
 			}
@@ -302,9 +305,9 @@ func (s *ReactStrategy) think(
 	}
 
 	// Add tools if available
-	if len(agent.tools) > 0 {
-		llmTools := make([]llm.Tool, len(agent.tools))
-		for i, tool := range agent.tools {
+	if tools := agent.availableTools(); len(tools) > 0 {
+		llmTools := make([]llm.Tool, len(tools))
+		for i, tool := range tools {
 			llmTools[i] = llm.Tool{
 				Type: "function",
 				Function: &llm.FunctionDefinition{
@@ -350,9 +353,10 @@ func (s *ReactStrategy) think(
 func (s *ReactStrategy) act(ctx context.Context, agent *Agent, trace ReasoningTrace) (string, error) {
 	// Find the tool
 	var tool *Tool
-	for i := range agent.tools {
-		if agent.tools[i].Name == trace.Action {
-			tool = &agent.tools[i]
+	tools := agent.availableTools()
+	for i := range tools {
+		if tools[i].Name == trace.Action {
+			tool = &tools[i]
 			break
 		}
 	}
@@ -366,6 +370,10 @@ func (s *ReactStrategy) act(ctx context.Context, agent *Agent, trace ReasoningTr
 		return "", fmt.Errorf("tool %s has no handler", trace.Action)
 	}
 
+	if err := AuthorizeTool(ctx, ToolOriginAgent, tool.Name, tool.RequiredScopes, agent.toolAuditHook); err != nil {
+		return "", err
+	}
+
 	result, err := tool.Handler(ctx, trace.ActionInput)
 	if err != nil {
 		return "", fmt.Errorf("tool execution failed: %w", err)
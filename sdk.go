@@ -183,6 +183,8 @@ type Result = GenerateResult
 // ToolOutput represents a tool call from the LLM.
 // Renamed from ToolCallResult for clarity.
 type ToolOutput struct {
+	// ID is the provider's tool call ID, used to correlate the call with its result
+	ID        string
 	Name      string
 	Arguments map[string]any
 }
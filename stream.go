@@ -268,6 +268,7 @@ type StreamResult = StreamResponse
 // ToolInvocation represents a function/tool call made by the LLM.
 // Renamed from ToolCall for clarity (duplicated from llm.ToolInvocation for SDK-specific use).
 type ToolInvocation struct {
+	ID        string
 	Name      string
 	Arguments map[string]any
 	Result    any
@@ -1014,6 +1015,7 @@ func (b *StreamingGenerator) Stream() (*StreamResponse, error) {
 				}
 
 				toolCall := ToolInvocation{
+					ID:        tc.ID,
 					Name:      tc.Function.Name,
 					Arguments: make(map[string]any),
 				}
@@ -1607,6 +1609,7 @@ func (b *StreamingGenerator) handleBlockEvent(
 			// Store completed tool call
 			if *currentToolID != "" {
 				toolCall := ToolInvocation{
+					ID:   *currentToolID,
 					Name: *currentToolName,
 					Arguments: map[string]any{
 						"raw": currentToolArgs.String(),
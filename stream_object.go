@@ -434,6 +434,17 @@ func (b *StreamObjectBuilder[T]) Stream() (*StreamObjectResult[T], error) {
 		return nil, err
 	}
 
+	// Validate the final JSON against the schema when strict
+	if b.schemaStrict {
+		if validator, compileErr := CompileJSONSchema(schema); compileErr == nil {
+			if err := validator.ValidateJSON([]byte(finalState.RawJSON)); err != nil {
+				b.handleError(err)
+
+				return nil, err
+			}
+		}
+	}
+
 	// Run validators on final object
 	for i, validator := range b.validators {
 		if err := validator(finalState.Value); err != nil {
@@ -622,6 +633,14 @@ func generatePropertySchemaFromType(t reflect.Type, description string) map[stri
 		t = t.Elem()
 	}
 
+	// time.Time marshals as an RFC 3339 string
+	if t == timeType {
+		schema["type"] = "string"
+		schema["format"] = "date-time"
+
+		return schema
+	}
+
 	switch t.Kind() {
 	case reflect.String:
 		schema["type"] = "string"
@@ -0,0 +1,157 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Principal is the identity a tool call is made on behalf of, with the
+// scopes it has been granted.
+type Principal struct {
+	ID     string         `json:"id"`
+	Scopes []string       `json:"scopes,omitempty"`
+	Claims map[string]any `json:"claims,omitempty"`
+}
+
+// HasScope reports whether the principal was granted a scope. A granted scope
+// ending in ":*" covers every scope with that prefix, and "*" covers all scopes.
+func (p *Principal) HasScope(scope string) bool {
+	if p == nil {
+		return false
+	}
+
+	for _, granted := range p.Scopes {
+		if granted == scope || granted == "*" {
+			return true
+		}
+
+		if prefix, ok := strings.CutSuffix(granted, "*"); ok && strings.HasSuffix(prefix, ":") && strings.HasPrefix(scope, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// principalKey carries the calling principal through a context.
+type principalKey struct{}
+
+// toolOriginKey carries the component executing a tool through a context.
+type toolOriginKey struct{}
+
+// WithPrincipal returns a context whose tool calls are authorized for principal.
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext returns the principal of ctx, or nil for anonymous calls.
+func PrincipalFromContext(ctx context.Context) *Principal {
+	principal, _ := ctx.Value(principalKey{}).(*Principal)
+
+	return principal
+}
+
+// Tool call origins reported in audit events.
+const (
+	ToolOriginRegistry = "registry"
+	ToolOriginAgent    = "agent"
+	ToolOriginWorkflow = "workflow"
+	ToolOriginMCP      = "mcp"
+)
+
+// WithToolOrigin marks tool calls made under ctx as coming from origin, unless
+// an outer component already did.
+func WithToolOrigin(ctx context.Context, origin string) context.Context {
+	if _, ok := ctx.Value(toolOriginKey{}).(string); ok {
+		return ctx
+	}
+
+	return context.WithValue(ctx, toolOriginKey{}, origin)
+}
+
+// ToolAuditEvent records an authorization decision for a tool that requires scopes.
+type ToolAuditEvent struct {
+	Tool           string    `json:"tool"`
+	Origin         string    `json:"origin"`
+	PrincipalID    string    `json:"principal_id,omitempty"`
+	RequiredScopes []string  `json:"required_scopes"`
+	MissingScopes  []string  `json:"missing_scopes,omitempty"`
+	Allowed        bool      `json:"allowed"`
+	Timestamp      time.Time `json:"timestamp"`
+}
+
+// ToolAuditHook receives authorization decisions. It is called synchronously
+// and may be called concurrently.
+type ToolAuditHook func(ctx context.Context, event ToolAuditEvent)
+
+// ToolPermissionError reports a tool call denied because the principal lacks
+// required scopes.
+type ToolPermissionError struct {
+	Tool        string
+	PrincipalID string
+	Missing     []string
+}
+
+func (e *ToolPermissionError) Error() string {
+	who := "anonymous caller"
+	if e.PrincipalID != "" {
+		who = "principal " + e.PrincipalID
+	}
+
+	return fmt.Sprintf("permission denied: %s lacks scopes %s required by tool %s", who, strings.Join(e.Missing, ", "), e.Tool)
+}
+
+// Unwrap allows errors.Is(err, ErrToolPermissionDenied).
+func (e *ToolPermissionError) Unwrap() error {
+	return ErrToolPermissionDenied
+}
+
+// AuthorizeTool checks that the principal of ctx holds every scope a tool
+// requires and reports the decision to hook. Tools without required scopes are
+// open to every caller and are not audited. origin names the component making
+// the call; an origin recorded in ctx by an outer component takes precedence.
+func AuthorizeTool(ctx context.Context, origin, tool string, required []string, hook ToolAuditHook) error {
+	if len(required) == 0 {
+		return nil
+	}
+
+	if recorded, ok := ctx.Value(toolOriginKey{}).(string); ok {
+		origin = recorded
+	}
+
+	principal := PrincipalFromContext(ctx)
+
+	var missing []string
+
+	for _, scope := range required {
+		if !principal.HasScope(scope) {
+			missing = append(missing, scope)
+		}
+	}
+
+	event := ToolAuditEvent{
+		Tool:           tool,
+		Origin:         origin,
+		RequiredScopes: slices.Clone(required),
+		MissingScopes:  missing,
+		Allowed:        len(missing) == 0,
+		Timestamp:      time.Now(),
+	}
+
+	if principal != nil {
+		event.PrincipalID = principal.ID
+	}
+
+	if hook != nil {
+		hook(ctx, event)
+	}
+
+	if !event.Allowed {
+		return &ToolPermissionError{Tool: tool, PrincipalID: event.PrincipalID, Missing: missing}
+	}
+
+	return nil
+}
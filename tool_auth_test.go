@@ -0,0 +1,209 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/xraph/ai-sdk/testhelpers"
+)
+
+func TestPrincipal_HasScope(t *testing.T) {
+	p := &Principal{ID: "user-1", Scopes: []string{"billing:read", "reports:*"}}
+
+	tests := []struct {
+		scope string
+		want  bool
+	}{
+		{"billing:read", true},
+		{"billing:write", false},
+		{"reports:export", true},
+		{"reports:export:pdf", true},
+		{"reportsx", false},
+	}
+
+	for _, tt := range tests {
+		if got := p.HasScope(tt.scope); got != tt.want {
+			t.Errorf("HasScope(%q) = %v, want %v", tt.scope, got, tt.want)
+		}
+	}
+
+	if !(&Principal{Scopes: []string{"*"}}).HasScope("anything") {
+		t.Error("expected * to grant every scope")
+	}
+
+	var nilPrincipal *Principal
+	if nilPrincipal.HasScope("billing:read") {
+		t.Error("expected nil principal to have no scopes")
+	}
+}
+
+// auditRecorder collects audit events from concurrent tool calls.
+type auditRecorder struct {
+	mu     sync.Mutex
+	events []ToolAuditEvent
+}
+
+func (r *auditRecorder) hook(_ context.Context, event ToolAuditEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.events = append(r.events, event)
+}
+
+func newScopedRegistry(t *testing.T, recorder *auditRecorder) *ToolRegistry {
+	t.Helper()
+
+	registry := NewToolRegistry(nil, nil)
+	registry.SetAuditHook(recorder.hook)
+
+	err := registry.RegisterTool(&ToolDefinition{
+		Name:           "refund",
+		Version:        "1.0.0",
+		RequiredScopes: []string{"billing:write"},
+		Handler: func(ctx context.Context, params map[string]any) (any, error) {
+			return "refunded", nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to register tool: %v", err)
+	}
+
+	return registry
+}
+
+func TestToolRegistry_ExecuteTool_RequiredScopes(t *testing.T) {
+	recorder := &auditRecorder{}
+	registry := newScopedRegistry(t, recorder)
+
+	_, err := registry.ExecuteTool(context.Background(), "refund", "1.0.0", nil)
+	if !errors.Is(err, ErrToolPermissionDenied) {
+		t.Fatalf("expected permission denied for anonymous caller, got %v", err)
+	}
+
+	ctx := WithPrincipal(context.Background(), &Principal{ID: "support", Scopes: []string{"billing:read"}})
+
+	_, err = registry.ExecuteTool(ctx, "refund", "1.0.0", nil)
+
+	var permErr *ToolPermissionError
+	if !errors.As(err, &permErr) {
+		t.Fatalf("expected ToolPermissionError, got %v", err)
+	}
+
+	if permErr.PrincipalID != "support" || !slices.Equal(permErr.Missing, []string{"billing:write"}) {
+		t.Errorf("unexpected permission error: %+v", permErr)
+	}
+
+	ctx = WithPrincipal(context.Background(), &Principal{ID: "admin", Scopes: []string{"billing:*"}})
+
+	result, err := registry.ExecuteTool(ctx, "refund", "1.0.0", nil)
+	if err != nil {
+		t.Fatalf("expected admin call to succeed, got %v", err)
+	}
+
+	if result.Result != "refunded" {
+		t.Errorf("expected handler result, got %v", result.Result)
+	}
+
+	if len(recorder.events) != 3 {
+		t.Fatalf("expected 3 audit events, got %d", len(recorder.events))
+	}
+
+	if recorder.events[0].Allowed || recorder.events[1].Allowed || !recorder.events[2].Allowed {
+		t.Errorf("unexpected audit decisions: %+v", recorder.events)
+	}
+
+	if recorder.events[2].PrincipalID != "admin" || recorder.events[2].Origin != ToolOriginRegistry {
+		t.Errorf("unexpected audit event: %+v", recorder.events[2])
+	}
+}
+
+func TestToolRegistry_ExecuteTool_NoScopesNotAudited(t *testing.T) {
+	recorder := &auditRecorder{}
+
+	registry := NewToolRegistry(nil, nil)
+	registry.SetAuditHook(recorder.hook)
+
+	_ = registry.RegisterTool(&ToolDefinition{
+		Name:    "echo",
+		Version: "1.0.0",
+		Handler: func(ctx context.Context, params map[string]any) (any, error) {
+			return "ok", nil
+		},
+	})
+
+	if _, err := registry.ExecuteTool(context.Background(), "echo", "1.0.0", nil); err != nil {
+		t.Fatalf("expected open tool to run, got %v", err)
+	}
+
+	if len(recorder.events) != 0 {
+		t.Errorf("expected no audit events, got %d", len(recorder.events))
+	}
+}
+
+func TestWorkflow_ToolNode_RequiredScopes(t *testing.T) {
+	recorder := &auditRecorder{}
+
+	wf := NewWorkflow("billing", "Billing", nil, nil)
+	wf.SetToolRegistry(newScopedRegistry(t, recorder))
+
+	node := &WorkflowNode{ID: "refund", Type: NodeTypeTool, ToolName: "refund"}
+	execution := &WorkflowExecution{Input: map[string]any{}, NodeExecutions: map[string]*NodeExecution{}}
+
+	_, err := wf.executeToolNode(context.Background(), node, execution)
+	if !errors.Is(err, ErrToolPermissionDenied) {
+		t.Fatalf("expected permission denied, got %v", err)
+	}
+
+	if len(recorder.events) != 1 || recorder.events[0].Origin != ToolOriginWorkflow {
+		t.Errorf("expected one workflow audit event, got %+v", recorder.events)
+	}
+}
+
+func TestAgent_ExecuteTool_PermissionDenied(t *testing.T) {
+	recorder := &auditRecorder{}
+	called := false
+
+	agent, err := NewAgent("agent", "Agent", &testhelpers.MockLLMManager{}, &MockStateStore{}, nil, nil, &AgentOptions{
+		ToolAuditHook: recorder.hook,
+		Tools: []Tool{{
+			Name:           "refund",
+			RequiredScopes: []string{"billing:write"},
+			Handler: func(ctx context.Context, params map[string]any) (any, error) {
+				called = true
+
+				return "refunded", nil
+			},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("failed to create agent: %v", err)
+	}
+
+	ctx := WithPrincipal(context.Background(), &Principal{ID: "viewer"})
+
+	execution := agent.executeTool(ctx, ToolCallResult{Name: "refund", Arguments: map[string]any{}})
+	if !errors.Is(execution.Error, ErrToolPermissionDenied) {
+		t.Fatalf("expected permission denied, got %v", execution.Error)
+	}
+
+	if called {
+		t.Error("expected handler not to run")
+	}
+
+	var content map[string]any
+	if err := json.Unmarshal([]byte(toolErrorContent(execution.Error)), &content); err != nil {
+		t.Fatalf("expected JSON tool error, got %v", err)
+	}
+
+	if content["error"] != "permission_denied" || content["tool"] != "refund" {
+		t.Errorf("unexpected tool error content: %v", content)
+	}
+
+	if len(recorder.events) != 1 || recorder.events[0].Origin != ToolOriginAgent || recorder.events[0].PrincipalID != "viewer" {
+		t.Errorf("unexpected audit events: %+v", recorder.events)
+	}
+}
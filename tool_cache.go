@@ -0,0 +1,238 @@
+package sdk
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/xraph/go-utils/metrics"
+)
+
+// ToolCacheKeyFunc derives the cache key of a tool call. Calls with equal keys
+// share a cached result. Include the principal from ctx in the key when results
+// differ per caller.
+type ToolCacheKeyFunc func(ctx context.Context, params map[string]any) (string, error)
+
+// ToolCachePolicy marks a tool's results as cacheable. Only cache tools without
+// side effects. Results are stored as JSON, so a cached Result is the decoded
+// JSON value rather than the type the handler returned.
+type ToolCachePolicy struct {
+	// TTL is how long a result stays cached; zero keeps it until invalidated
+	TTL time.Duration `json:"ttl"`
+
+	// Key derives the cache key; defaults to DefaultToolCacheKey
+	Key ToolCacheKeyFunc `json:"-"`
+
+	// Resources names what the result depends on, e.g. "customers". Calls to
+	// tools that invalidate one of them drop the result. Defaults to the tool name.
+	Resources []string `json:"resources,omitempty"`
+}
+
+// ToolInvalidationHook returns resources whose cached results become stale
+// after a successful call, in addition to ToolDefinition.Invalidates.
+type ToolInvalidationHook func(ctx context.Context, params map[string]any, result any) []string
+
+// cachedToolResult is the stored form of a successful tool call.
+type cachedToolResult struct {
+	Result   json.RawMessage `json:"result"`
+	CachedAt time.Time       `json:"cached_at"`
+}
+
+// DefaultToolCacheKey keys a call on its arguments. Maps are encoded with
+// sorted keys, so argument order does not matter.
+func DefaultToolCacheKey(_ context.Context, params map[string]any) (string, error) {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode tool arguments: %w", err)
+	}
+
+	return fmt.Sprintf("%x", sha256.Sum256(data)), nil
+}
+
+// SetCacheStore sets the store that cacheable tools keep their results in.
+// Tool results are not cached while no store is set.
+func (tr *ToolRegistry) SetCacheStore(store CacheStore) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	tr.cacheStore = store
+}
+
+// InvalidateToolCache drops the cached results of every tool that depends on
+// one of the resources.
+func (tr *ToolRegistry) InvalidateToolCache(ctx context.Context, resources ...string) error {
+	tr.mu.RLock()
+	store := tr.cacheStore
+	tr.mu.RUnlock()
+
+	if store == nil {
+		return nil
+	}
+
+	// Cache stores cannot delete by prefix, so each resource carries a
+	// generation that is part of every dependent key. Bumping it orphans
+	// the old entries until their TTL expires.
+	generation := []byte(strconv.FormatInt(time.Now().UnixNano(), 36))
+
+	for _, resource := range resources {
+		if err := store.Set(ctx, toolCacheGenerationKey(resource), generation, 0); err != nil {
+			return fmt.Errorf("failed to invalidate tool cache for %s: %w", resource, err)
+		}
+	}
+
+	if tr.logger != nil && len(resources) > 0 {
+		tr.logger.Debug("Tool cache invalidated", F("resources", resources))
+	}
+
+	return nil
+}
+
+// toolCacheKey returns the store key of a call to a cacheable tool.
+func (tr *ToolRegistry) toolCacheKey(ctx context.Context, store CacheStore, tool *ToolDefinition, params map[string]any) (string, error) {
+	keyFunc := tool.Cache.Key
+	if keyFunc == nil {
+		keyFunc = DefaultToolCacheKey
+	}
+
+	key, err := keyFunc(ctx, params)
+	if err != nil {
+		return "", err
+	}
+
+	resources := tool.Cache.Resources
+	if len(resources) == 0 {
+		resources = []string{tool.Name}
+	}
+
+	var b strings.Builder
+
+	for _, resource := range slices.Sorted(slices.Values(resources)) {
+		generation, found, err := store.Get(ctx, toolCacheGenerationKey(resource))
+		if err != nil {
+			return "", err
+		}
+
+		if !found {
+			generation = []byte("0")
+		}
+
+		fmt.Fprintf(&b, "%s=%s\x00", resource, generation)
+	}
+
+	b.WriteString(key)
+
+	return fmt.Sprintf("tool-cache:%s@%s:%x", tool.Name, tool.Version, sha256.Sum256([]byte(b.String()))), nil
+}
+
+// lookupToolResult returns the key and cached result of a call, if any. Cache
+// failures are logged and treated as misses.
+func (tr *ToolRegistry) lookupToolResult(ctx context.Context, store CacheStore, tool *ToolDefinition, params map[string]any) (string, *cachedToolResult) {
+	key, err := tr.toolCacheKey(ctx, store, tool, params)
+	if err != nil {
+		tr.logCacheError(tool.Name, err)
+
+		return "", nil
+	}
+
+	data, found, err := store.Get(ctx, key)
+	if err != nil {
+		tr.logCacheError(tool.Name, err)
+
+		return key, nil
+	}
+
+	if found {
+		var entry cachedToolResult
+		if err := json.Unmarshal(data, &entry); err == nil {
+			tr.recordCacheLookup(tool.Name, true)
+
+			return key, &entry
+		}
+	}
+
+	tr.recordCacheLookup(tool.Name, false)
+
+	return key, nil
+}
+
+// storeToolResult caches a successful result under key.
+func (tr *ToolRegistry) storeToolResult(ctx context.Context, store CacheStore, tool *ToolDefinition, key string, result any) {
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		tr.logCacheError(tool.Name, err)
+
+		return
+	}
+
+	data, err := json.Marshal(cachedToolResult{Result: encoded, CachedAt: time.Now()})
+	if err == nil {
+		err = store.Set(ctx, key, data, tool.Cache.TTL)
+	}
+
+	if err != nil {
+		tr.logCacheError(tool.Name, err)
+	}
+}
+
+// invalidateAfter drops cached results made stale by a successful call.
+func (tr *ToolRegistry) invalidateAfter(ctx context.Context, tool *ToolDefinition, params map[string]any, result any) {
+	resources := slices.Clone(tool.Invalidates)
+	if tool.InvalidateHook != nil {
+		resources = append(resources, tool.InvalidateHook(ctx, params, result)...)
+	}
+
+	if len(resources) == 0 {
+		return
+	}
+
+	if err := tr.InvalidateToolCache(ctx, resources...); err != nil {
+		tr.logCacheError(tool.Name, err)
+	}
+}
+
+func (tr *ToolRegistry) recordCacheLookup(tool string, hit bool) {
+	if tr.metrics == nil {
+		return
+	}
+
+	name := "forge.ai.sdk.tools.cache_misses"
+	if hit {
+		name = "forge.ai.sdk.tools.cache_hits"
+	}
+
+	tr.metrics.Counter(name, metrics.WithLabel("tool", tool)).Inc()
+}
+
+func (tr *ToolRegistry) logCacheError(tool string, err error) {
+	if tr.logger != nil {
+		tr.logger.Warn("Tool cache unavailable", F("tool", tool), F("error", err.Error()))
+	}
+}
+
+func toolCacheGenerationKey(resource string) string {
+	return "tool-cache-generation:" + resource
+}
+
+// toolCacheHitKey carries a flag set when a tool call under the context was
+// served from the cache.
+type toolCacheHitKey struct{}
+
+// withToolCacheRecorder returns a context that records cache hits of the tool
+// calls made under it, including registry calls made by agent tool handlers.
+func withToolCacheRecorder(ctx context.Context) (context.Context, *atomic.Bool) {
+	hit := &atomic.Bool{}
+
+	return context.WithValue(ctx, toolCacheHitKey{}, hit), hit
+}
+
+func recordToolCacheHit(ctx context.Context) {
+	if hit, ok := ctx.Value(toolCacheHitKey{}).(*atomic.Bool); ok {
+		hit.Store(true)
+	}
+}
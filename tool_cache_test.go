@@ -0,0 +1,252 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/xraph/ai-sdk/testhelpers"
+)
+
+// mapCacheStore is an in-memory CacheStore without expiry.
+type mapCacheStore struct {
+	mu   sync.Mutex
+	data map[string][]byte
+}
+
+func newMapCacheStore() *mapCacheStore {
+	return &mapCacheStore{data: make(map[string][]byte)}
+}
+
+func (m *mapCacheStore) Get(_ context.Context, key string) ([]byte, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	value, ok := m.data[key]
+
+	return value, ok, nil
+}
+
+func (m *mapCacheStore) Set(_ context.Context, key string, value []byte, _ time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.data[key] = value
+
+	return nil
+}
+
+func (m *mapCacheStore) Delete(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.data, key)
+
+	return nil
+}
+
+func (m *mapCacheStore) Clear(_ context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.data = make(map[string][]byte)
+
+	return nil
+}
+
+// newCachedRegistry registers a cached get_customer tool and an
+// update_customer tool that invalidates it.
+func newCachedRegistry(t *testing.T, calls *int) *ToolRegistry {
+	t.Helper()
+
+	registry := NewToolRegistry(nil, nil)
+	registry.SetCacheStore(newMapCacheStore())
+
+	tools := []*ToolDefinition{
+		{
+			Name:    "get_customer",
+			Version: "1.0.0",
+			Parameters: ToolParameterSchema{
+				Type:       "object",
+				Properties: map[string]ToolParameterProperty{"id": {Type: "string"}},
+			},
+			Cache: &ToolCachePolicy{TTL: time.Minute, Resources: []string{"customers"}},
+			Handler: func(ctx context.Context, params map[string]any) (any, error) {
+				*calls++
+
+				return map[string]any{"id": params["id"], "version": *calls}, nil
+			},
+		},
+		{
+			Name:    "update_customer",
+			Version: "1.0.0",
+			Parameters: ToolParameterSchema{
+				Type:       "object",
+				Properties: map[string]ToolParameterProperty{"id": {Type: "string"}},
+			},
+			Invalidates: []string{"customers"},
+			Handler: func(ctx context.Context, params map[string]any) (any, error) {
+				return "updated", nil
+			},
+		},
+	}
+
+	for _, tool := range tools {
+		if err := registry.RegisterTool(tool); err != nil {
+			t.Fatalf("failed to register %s: %v", tool.Name, err)
+		}
+	}
+
+	return registry
+}
+
+func TestToolRegistry_ExecuteTool_Cache(t *testing.T) {
+	calls := 0
+	registry := newCachedRegistry(t, &calls)
+	ctx := context.Background()
+
+	first, err := registry.ExecuteTool(ctx, "get_customer", "1.0.0", map[string]any{"id": "c1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if first.Metadata["cache_hit"] != nil {
+		t.Error("expected first call to miss the cache")
+	}
+
+	second, err := registry.ExecuteTool(ctx, "get_customer", "1.0.0", map[string]any{"id": "c1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if calls != 1 {
+		t.Errorf("expected handler to run once, ran %d times", calls)
+	}
+
+	if second.Metadata["cache_hit"] != true || !second.Success {
+		t.Errorf("expected cached success, got %+v", second)
+	}
+
+	if got := second.Result.(map[string]any)["version"]; got != float64(1) {
+		t.Errorf("expected cached version 1, got %v", got)
+	}
+
+	if _, err := registry.ExecuteTool(ctx, "get_customer", "1.0.0", map[string]any{"id": "c2"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if calls != 2 {
+		t.Errorf("expected different arguments to miss the cache, handler ran %d times", calls)
+	}
+}
+
+func TestToolRegistry_ExecuteTool_CacheInvalidation(t *testing.T) {
+	calls := 0
+	registry := newCachedRegistry(t, &calls)
+	ctx := context.Background()
+	params := map[string]any{"id": "c1"}
+
+	_, _ = registry.ExecuteTool(ctx, "get_customer", "1.0.0", params)
+
+	if _, err := registry.ExecuteTool(ctx, "update_customer", "1.0.0", params); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	result, _ := registry.ExecuteTool(ctx, "get_customer", "1.0.0", params)
+	if result.Metadata["cache_hit"] != nil || calls != 2 {
+		t.Errorf("expected update to invalidate the cache, handler ran %d times", calls)
+	}
+
+	if err := registry.InvalidateToolCache(ctx, "customers"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, _ = registry.ExecuteTool(ctx, "get_customer", "1.0.0", params)
+	if calls != 3 {
+		t.Errorf("expected explicit invalidation to drop the result, handler ran %d times", calls)
+	}
+}
+
+func TestToolRegistry_ExecuteTool_InvalidateHook(t *testing.T) {
+	calls := 0
+	registry := newCachedRegistry(t, &calls)
+	ctx := context.Background()
+
+	_ = registry.RegisterTool(&ToolDefinition{
+		Name:    "touch",
+		Version: "1.0.0",
+		Parameters: ToolParameterSchema{
+			Type:       "object",
+			Properties: map[string]ToolParameterProperty{"resource": {Type: "string"}},
+		},
+		InvalidateHook: func(ctx context.Context, params map[string]any, result any) []string {
+			return []string{fmt.Sprint(params["resource"])}
+		},
+		Handler: func(ctx context.Context, params map[string]any) (any, error) {
+			return nil, nil
+		},
+	})
+
+	_, _ = registry.ExecuteTool(ctx, "get_customer", "1.0.0", map[string]any{"id": "c1"})
+	_, _ = registry.ExecuteTool(ctx, "touch", "1.0.0", map[string]any{"resource": "orders"})
+	_, _ = registry.ExecuteTool(ctx, "get_customer", "1.0.0", map[string]any{"id": "c1"})
+
+	if calls != 1 {
+		t.Errorf("expected unrelated invalidation to keep the cache, handler ran %d times", calls)
+	}
+
+	_, _ = registry.ExecuteTool(ctx, "touch", "1.0.0", map[string]any{"resource": "customers"})
+	_, _ = registry.ExecuteTool(ctx, "get_customer", "1.0.0", map[string]any{"id": "c1"})
+
+	if calls != 2 {
+		t.Errorf("expected hook invalidation to drop the result, handler ran %d times", calls)
+	}
+}
+
+func TestToolRegistry_ExecuteTool_NoCacheStore(t *testing.T) {
+	calls := 0
+	registry := newCachedRegistry(t, &calls)
+	registry.SetCacheStore(nil)
+
+	for range 2 {
+		_, _ = registry.ExecuteTool(context.Background(), "get_customer", "1.0.0", map[string]any{"id": "c1"})
+	}
+
+	if calls != 2 {
+		t.Errorf("expected no caching without a store, handler ran %d times", calls)
+	}
+}
+
+func TestAgent_ExecuteTool_CacheHit(t *testing.T) {
+	calls := 0
+	registry := newCachedRegistry(t, &calls)
+
+	agent, err := NewAgent("agent", "Agent", &testhelpers.MockLLMManager{}, &MockStateStore{}, nil, nil, &AgentOptions{
+		Tools: []Tool{{
+			Name: "get_customer",
+			Handler: func(ctx context.Context, params map[string]any) (any, error) {
+				result, err := registry.ExecuteTool(ctx, "get_customer", "1.0.0", params)
+				if err != nil {
+					return nil, err
+				}
+
+				return result.Result, nil
+			},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("failed to create agent: %v", err)
+	}
+
+	call := ToolCallResult{Name: "get_customer", Arguments: map[string]any{"id": "c1"}}
+
+	if first := agent.executeTool(context.Background(), call); first.Cached {
+		t.Error("expected first call not to be cached")
+	}
+
+	if second := agent.executeTool(context.Background(), call); !second.Cached {
+		t.Error("expected second call to be served from the cache")
+	}
+}
@@ -53,6 +53,11 @@ type ToolChain struct {
 	// Configuration
 	timeout         time.Duration
 	continueOnError bool
+
+	// DAG mode
+	dag       bool
+	output    string
+	evaluator *ExpressionEvaluator
 }
 
 // ChainStep represents a single step in the tool chain.
@@ -79,6 +84,10 @@ type ChainStep struct {
 
 	// Parallel execution
 	Parallel []ChainStep // Steps to run in parallel
+
+	// DAG mode: extra dependencies and a condition expression
+	DependsOn []string
+	When      string
 }
 
 // ChainContext provides shared state across chain steps.
@@ -132,7 +141,8 @@ func NewToolChain(registry *ToolRegistry) *ToolChain {
 			results: make(map[string]any),
 			errors:  make(map[string]error),
 		},
-		timeout: 5 * time.Minute,
+		timeout:   5 * time.Minute,
+		evaluator: NewExpressionEvaluator(),
 	}
 }
 
@@ -292,6 +302,20 @@ func (c *ToolChain) Execute(ctx context.Context) (*ChainResult, error) {
 		Success:       true,
 	}
 
+	if c.dag {
+		if err := c.executeDAG(execCtx, result); err != nil {
+			result.Success = false
+
+			if c.logger != nil {
+				c.logger.Error("Tool chain failed", logger.String("error", err.Error()))
+			}
+
+			return result, err
+		}
+
+		return c.complete(result, startTime), nil
+	}
+
 	// Execute steps
 	for i, step := range c.steps {
 		select {
@@ -409,6 +433,12 @@ func (c *ToolChain) Execute(ctx context.Context) (*ChainResult, error) {
 	}
 
 	result.FinalResult = c.context.lastResult
+
+	return c.complete(result, startTime), nil
+}
+
+// complete records a finished chain execution and notifies the callbacks.
+func (c *ToolChain) complete(result *ChainResult, startTime time.Time) *ChainResult {
 	result.TotalDuration = time.Since(startTime)
 
 	if c.onChainComplete != nil {
@@ -443,7 +473,7 @@ func (c *ToolChain) Execute(ctx context.Context) (*ChainResult, error) {
 		)
 	}
 
-	return result, nil
+	return result
 }
 
 // executeParallelSteps executes steps in parallel.
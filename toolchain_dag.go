@@ -0,0 +1,915 @@
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	logger "github.com/xraph/go-utils/log"
+)
+
+// chainInputVariable is the expression variable holding the chain input.
+const chainInputVariable = "input"
+
+// ChainSpec is the JSON form of a tool chain whose steps run as a DAG. Step
+// inputs reference the outputs of other steps with ${...} expressions such as
+// "${search.results[0].url}", and each step starts as soon as the steps it
+// references have finished. Specs can be written by hand or proposed by a
+// model, then checked with Validate before they run.
+type ChainSpec struct {
+	Name        string          `json:"name,omitempty"`
+	Description string          `json:"description,omitempty"`
+	Steps       []ChainSpecStep `json:"steps"`
+
+	// Output selects the final result, e.g. "${summarize.text}". Defaults to
+	// the result of the last step that ran.
+	Output string `json:"output,omitempty"`
+}
+
+// ChainSpecStep is one tool call of a ChainSpec.
+type ChainSpecStep struct {
+	// ID names the step; other steps read its result as ${id}, so it must be
+	// an identifier of letters, digits and underscores
+	ID      string `json:"id"`
+	Tool    string `json:"tool"`
+	Version string `json:"version,omitempty"`
+
+	// Input holds the tool arguments. Strings may contain ${expr}; a string
+	// that is a single ${expr} takes the expression's value unchanged, and
+	// $${ escapes a literal ${.
+	Input map[string]any `json:"input,omitempty"`
+
+	// DependsOn lists steps that must finish first without being referenced
+	DependsOn []string `json:"depends_on,omitempty"`
+
+	// When is a condition expression; the step, and every step depending on
+	// it, is skipped when it is false
+	When string `json:"when,omitempty"`
+}
+
+// ChainSpecError describes one problem in a chain definition.
+type ChainSpecError struct {
+	Step    string
+	Message string
+}
+
+func (e *ChainSpecError) Error() string {
+	if e.Step == "" {
+		return e.Message
+	}
+
+	return fmt.Sprintf("step %s: %s", e.Step, e.Message)
+}
+
+// Unwrap allows errors.Is(err, ErrInvalidChainSpec).
+func (e *ChainSpecError) Unwrap() error {
+	return ErrInvalidChainSpec
+}
+
+// ChainSpecErrors collects every problem found in a chain definition.
+type ChainSpecErrors []*ChainSpecError
+
+func (e ChainSpecErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+
+	return "invalid tool chain spec:\n  " + strings.Join(msgs, "\n  ")
+}
+
+// Unwrap allows errors.Is/As to match individual spec errors.
+func (e ChainSpecErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+
+	return errs
+}
+
+// ParseChainSpec decodes a JSON chain definition. Unknown fields are rejected
+// so that typos in model-written specs are reported rather than ignored.
+func ParseChainSpec(data []byte) (*ChainSpec, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	var spec ChainSpec
+	if err := decoder.Decode(&spec); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidChainSpec, err)
+	}
+
+	return &spec, nil
+}
+
+// Validate checks step IDs, references, conditions and the dependency graph.
+// Tools are also checked against registry when it is not nil.
+func (s *ChainSpec) Validate(registry *ToolRegistry) error {
+	if _, errs := planChainDAG(s.chainSteps(), s.Output, NewExpressionEvaluator(), registry); len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+func (s *ChainSpec) chainSteps() []ChainStep {
+	steps := make([]ChainStep, len(s.Steps))
+
+	for i, step := range s.Steps {
+		steps[i] = ChainStep{
+			Name:      step.ID,
+			ToolName:  step.Tool,
+			Version:   step.Version,
+			Input:     step.Input,
+			DependsOn: step.DependsOn,
+			When:      step.When,
+		}
+	}
+
+	return steps
+}
+
+// NewToolChainFromSpec validates a spec and returns a chain that runs it as a DAG.
+func NewToolChainFromSpec(registry *ToolRegistry, spec *ChainSpec) (*ToolChain, error) {
+	if err := spec.Validate(registry); err != nil {
+		return nil, err
+	}
+
+	chain := NewToolChain(registry).AsDAG().WithOutput(spec.Output)
+	chain.steps = spec.chainSteps()
+
+	return chain, nil
+}
+
+// Spec returns the JSON form of the chain. Chains whose steps use Go callbacks
+// or parallel groups cannot be serialized.
+func (c *ToolChain) Spec() (*ChainSpec, error) {
+	spec := &ChainSpec{Output: c.output, Steps: make([]ChainSpecStep, 0, len(c.steps))}
+
+	var errs ChainSpecErrors
+
+	for _, step := range c.steps {
+		if step.InputMapper != nil || step.Transformer != nil || step.Condition != nil || len(step.Parallel) > 0 {
+			errs = append(errs, &ChainSpecError{Step: step.Name, Message: "uses Go callbacks or parallel groups and cannot be serialized"})
+
+			continue
+		}
+
+		spec.Steps = append(spec.Steps, ChainSpecStep{
+			ID:        step.Name,
+			Tool:      step.ToolName,
+			Version:   step.Version,
+			Input:     step.Input,
+			DependsOn: step.DependsOn,
+			When:      step.When,
+		})
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	return spec, nil
+}
+
+// AsDAG runs the chain as a dependency graph instead of in order. Steps start
+// as soon as the steps they reference or depend on have finished, so
+// independent steps run in parallel. Steps whose dependencies failed or were
+// skipped are skipped too, and steps without Input or an InputMapper get no
+// arguments rather than the previous result.
+func (c *ToolChain) AsDAG() *ToolChain {
+	c.dag = true
+
+	return c
+}
+
+// WithOutput selects the final result of a DAG chain with a template such as
+// "${summarize.text}".
+func (c *ToolChain) WithOutput(template string) *ToolChain {
+	c.output = template
+
+	return c
+}
+
+// WithChainInput sets values that step inputs can read as ${input.name}. The
+// values are also available through ChainContext.Get.
+func (c *ToolChain) WithChainInput(input map[string]any) *ToolChain {
+	for k, v := range input {
+		c.context.Set(k, v)
+	}
+
+	return c
+}
+
+// WithDependsOn makes a DAG step wait for steps it does not reference in its input.
+func WithDependsOn(steps ...string) ChainOption {
+	return func(s *ChainStep) {
+		s.DependsOn = append(s.DependsOn, steps...)
+	}
+}
+
+// WithWhen skips a DAG step, and the steps depending on it, when the
+// condition expression is false, e.g. "len(search.results) > 0".
+func WithWhen(expr string) ChainOption {
+	return func(s *ChainStep) {
+		s.When = expr
+	}
+}
+
+// chainPlan is the validated dependency graph of a DAG chain.
+type chainPlan struct {
+	steps      []*ChainStep
+	byName     map[string]*ChainStep
+	deps       map[string][]string
+	dependents map[string][]string
+}
+
+// planChainDAG validates steps and resolves their dependencies.
+func planChainDAG(steps []ChainStep, output string, evaluator *ExpressionEvaluator, registry *ToolRegistry) (*chainPlan, ChainSpecErrors) {
+	var errs ChainSpecErrors
+
+	fail := func(step, format string, args ...any) {
+		errs = append(errs, &ChainSpecError{Step: step, Message: fmt.Sprintf(format, args...)})
+	}
+
+	plan := &chainPlan{
+		byName:     make(map[string]*ChainStep),
+		deps:       make(map[string][]string),
+		dependents: make(map[string][]string),
+	}
+
+	if len(steps) == 0 {
+		fail("", "chain has no steps")
+	}
+
+	for i := range steps {
+		step := &steps[i]
+
+		switch {
+		case step.Name == "":
+			fail("", "step %d has no id", i+1)
+
+			continue
+		case step.Name == chainInputVariable:
+			fail(step.Name, "%q is reserved for the chain input", chainInputVariable)
+		case !isChainStepID(step.Name):
+			fail(step.Name, "step id must be an identifier of letters, digits and underscores")
+		case plan.byName[step.Name] != nil:
+			fail(step.Name, "duplicate step id")
+
+			continue
+		}
+
+		plan.steps = append(plan.steps, step)
+		plan.byName[step.Name] = step
+
+		if len(step.Parallel) > 0 {
+			fail(step.Name, "parallel groups are not supported in DAG mode; independent steps already run in parallel")
+		}
+
+		if step.ToolName == "" {
+			fail(step.Name, "tool is required")
+		} else if registry != nil {
+			if _, err := registry.GetTool(step.ToolName, step.Version); err != nil {
+				fail(step.Name, "unknown tool %s", step.ToolName)
+			}
+		}
+	}
+
+	for _, step := range plan.steps {
+		var refs []string
+
+		if err := collectTemplateReferences(step.Input, evaluator, &refs); err != nil {
+			fail(step.Name, "invalid input: %v", err)
+		}
+
+		if step.When != "" {
+			names, err := evaluator.References(step.When)
+			if err != nil {
+				fail(step.Name, "invalid when condition: %v", err)
+			}
+
+			refs = append(refs, names...)
+		}
+
+		for _, ref := range refs {
+			switch {
+			case ref == chainInputVariable:
+			case ref == step.Name:
+				fail(step.Name, "references its own output")
+			case plan.byName[ref] == nil:
+				fail(step.Name, "references unknown step %q", ref)
+			default:
+				plan.addDependency(step.Name, ref)
+			}
+		}
+
+		for _, dep := range step.DependsOn {
+			if plan.byName[dep] == nil || dep == step.Name {
+				fail(step.Name, "depends on unknown step %q", dep)
+
+				continue
+			}
+
+			plan.addDependency(step.Name, dep)
+		}
+	}
+
+	if output != "" {
+		var refs []string
+		if err := collectTemplateReferences(output, evaluator, &refs); err != nil {
+			fail("", "invalid output: %v", err)
+		}
+
+		for _, ref := range refs {
+			if ref != chainInputVariable && plan.byName[ref] == nil {
+				fail("", "output references unknown step %q", ref)
+			}
+		}
+	}
+
+	if cycle := plan.cycle(); len(cycle) > 0 {
+		fail("", "dependency cycle: %s", strings.Join(cycle, " -> "))
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	return plan, nil
+}
+
+// isChainStepID reports whether a step ID can be referenced from expressions.
+func isChainStepID(id string) bool {
+	switch strings.ToLower(id) {
+	case "and", "or", "not", "true", "false", "null", "nil":
+		return false
+	}
+
+	for i, r := range id {
+		if r != '_' && !unicode.IsLetter(r) && (i == 0 || !unicode.IsDigit(r)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (p *chainPlan) addDependency(step, dep string) {
+	if !slices.Contains(p.deps[step], dep) {
+		p.deps[step] = append(p.deps[step], dep)
+		p.dependents[dep] = append(p.dependents[dep], step)
+	}
+}
+
+// cycle returns the steps of a dependency cycle, if there is one.
+func (p *chainPlan) cycle() []string {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	state := make(map[string]int)
+
+	var (
+		path  []string
+		found []string
+		visit func(name string) bool
+	)
+
+	visit = func(name string) bool {
+		switch state[name] {
+		case visiting:
+			start := slices.Index(path, name)
+			found = append(slices.Clone(path[start:]), name)
+
+			return true
+		case visited:
+			return false
+		}
+
+		state[name] = visiting
+		path = append(path, name)
+
+		for _, dep := range p.deps[name] {
+			if visit(dep) {
+				return true
+			}
+		}
+
+		path = path[:len(path)-1]
+		state[name] = visited
+
+		return false
+	}
+
+	for _, step := range p.steps {
+		if visit(step.Name) {
+			slices.Reverse(found)
+
+			return found
+		}
+	}
+
+	return nil
+}
+
+// chainTemplatePart is literal text or a ${...} expression of a template.
+type chainTemplatePart struct {
+	text   string
+	isExpr bool
+}
+
+// parseChainTemplate splits a string into literal text and ${...} expressions.
+func parseChainTemplate(s string) ([]chainTemplatePart, error) {
+	var (
+		parts   []chainTemplatePart
+		literal strings.Builder
+	)
+
+	for i := 0; i < len(s); {
+		switch {
+		case strings.HasPrefix(s[i:], "$${"):
+			literal.WriteString("${")
+			i += 3
+		case strings.HasPrefix(s[i:], "${"):
+			end := templateExpressionEnd(s, i+2)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated ${ in %q", s)
+			}
+
+			expr := strings.TrimSpace(s[i+2 : end])
+			if expr == "" {
+				return nil, fmt.Errorf("empty ${} in %q", s)
+			}
+
+			if literal.Len() > 0 {
+				parts = append(parts, chainTemplatePart{text: literal.String()})
+				literal.Reset()
+			}
+
+			parts = append(parts, chainTemplatePart{text: expr, isExpr: true})
+			i = end + 1
+		default:
+			literal.WriteByte(s[i])
+			i++
+		}
+	}
+
+	if literal.Len() > 0 {
+		parts = append(parts, chainTemplatePart{text: literal.String()})
+	}
+
+	return parts, nil
+}
+
+// templateExpressionEnd returns the index of the '}' closing an expression
+// that starts at start, skipping quoted strings.
+func templateExpressionEnd(s string, start int) int {
+	var quote byte
+
+	for i := start; i < len(s); i++ {
+		c := s[i]
+
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '}':
+			return i
+		}
+	}
+
+	return -1
+}
+
+// collectTemplateReferences appends the variables read by the templates in value.
+func collectTemplateReferences(value any, evaluator *ExpressionEvaluator, refs *[]string) error {
+	switch v := value.(type) {
+	case string:
+		parts, err := parseChainTemplate(v)
+		if err != nil {
+			return err
+		}
+
+		for _, part := range parts {
+			if !part.isExpr {
+				continue
+			}
+
+			names, err := evaluator.References(part.text)
+			if err != nil {
+				return err
+			}
+
+			*refs = append(*refs, names...)
+		}
+	case map[string]any:
+		for _, key := range slices.Sorted(maps.Keys(v)) {
+			if err := collectTemplateReferences(v[key], evaluator, refs); err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+		}
+	case []any:
+		for i, item := range v {
+			if err := collectTemplateReferences(item, evaluator, refs); err != nil {
+				return fmt.Errorf("[%d]: %w", i, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// resolveChainTemplates evaluates the templates in value against data.
+func resolveChainTemplates(value any, evaluator *ExpressionEvaluator, data map[string]any) (any, error) {
+	switch v := value.(type) {
+	case string:
+		parts, err := parseChainTemplate(v)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(parts) == 1 && parts[0].isExpr {
+			return evaluator.EvaluateTransform(parts[0].text, data)
+		}
+
+		var b strings.Builder
+
+		for _, part := range parts {
+			if !part.isExpr {
+				b.WriteString(part.text)
+
+				continue
+			}
+
+			resolved, err := evaluator.EvaluateTransform(part.text, data)
+			if err != nil {
+				return nil, err
+			}
+
+			if s, ok := resolved.(string); ok {
+				b.WriteString(s)
+			} else if encoded, err := json.Marshal(resolved); err == nil {
+				b.Write(encoded)
+			} else {
+				fmt.Fprint(&b, resolved)
+			}
+		}
+
+		return b.String(), nil
+	case map[string]any:
+		out := make(map[string]any, len(v))
+
+		for key, item := range v {
+			resolved, err := resolveChainTemplates(item, evaluator, data)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", key, err)
+			}
+
+			out[key] = resolved
+		}
+
+		return out, nil
+	case []any:
+		out := make([]any, len(v))
+
+		for i, item := range v {
+			resolved, err := resolveChainTemplates(item, evaluator, data)
+			if err != nil {
+				return nil, fmt.Errorf("[%d]: %w", i, err)
+			}
+
+			out[i] = resolved
+		}
+
+		return out, nil
+	}
+
+	return value, nil
+}
+
+// chainStepOutcome is the result of running one DAG step.
+type chainStepOutcome struct {
+	step     *ChainStep
+	value    any
+	err      error
+	skipped  bool
+	duration time.Duration
+}
+
+// executeDAG runs the chain's steps as a dependency graph.
+func (c *ToolChain) executeDAG(ctx context.Context, result *ChainResult) error {
+	plan, errs := planChainDAG(c.steps, c.output, c.evaluator, c.registry)
+	if len(errs) > 0 {
+		return errs
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		done      = make(chan chainStepOutcome)
+		remaining = make(map[string]int, len(plan.steps))
+		blocked   = make(map[string]bool)
+		running   int
+		abortErr  error
+		schedule  func(step *ChainStep)
+		finish    func(step *ChainStep, block bool)
+	)
+
+	schedule = func(step *ChainStep) {
+		if blocked[step.Name] {
+			if c.logger != nil {
+				c.logger.Debug("Skipping step (dependency failed or skipped)", logger.String("step", step.Name))
+			}
+
+			result.StepsSkipped++
+			finish(step, true)
+
+			return
+		}
+
+		running++
+
+		go func() {
+			done <- c.runDAGStep(ctx, step)
+		}()
+	}
+
+	finish = func(step *ChainStep, block bool) {
+		for _, name := range plan.dependents[step.Name] {
+			if block {
+				blocked[name] = true
+			}
+
+			remaining[name]--
+			if remaining[name] == 0 && abortErr == nil {
+				schedule(plan.byName[name])
+			}
+		}
+	}
+
+	for _, step := range plan.steps {
+		remaining[step.Name] = len(plan.deps[step.Name])
+	}
+
+	for _, step := range plan.steps {
+		if remaining[step.Name] == 0 {
+			schedule(step)
+		}
+	}
+
+	for running > 0 {
+		outcome := <-done
+		running--
+
+		name := outcome.step.Name
+		result.StepDurations[name] = outcome.duration
+
+		switch {
+		case outcome.skipped:
+			result.StepsSkipped++
+			finish(outcome.step, true)
+		case outcome.err != nil:
+			result.Errors[name] = outcome.err
+
+			c.context.mu.Lock()
+			c.context.errors[name] = outcome.err
+			c.context.mu.Unlock()
+
+			err := outcome.err
+			if c.errorHandler != nil {
+				err = c.errorHandler(name, err)
+			}
+
+			if err != nil && !c.continueOnError {
+				if abortErr == nil {
+					abortErr = fmt.Errorf("step %s failed: %w", name, err)
+
+					cancel()
+				}
+
+				continue
+			}
+
+			if c.logger != nil {
+				c.logger.Warn("Tool chain step failed (continuing)",
+					logger.String("step", name),
+					logger.String("tool", outcome.step.ToolName),
+					logger.String("error", outcome.err.Error()),
+				)
+			}
+
+			result.StepsExecuted++
+			finish(outcome.step, true)
+		default:
+			c.context.mu.Lock()
+			c.context.results[name] = outcome.value
+			c.context.lastResult = outcome.value
+			c.context.mu.Unlock()
+
+			result.StepResults[name] = outcome.value
+			result.StepsExecuted++
+
+			if c.onStepComplete != nil {
+				c.onStepComplete(name, outcome.value)
+			}
+
+			finish(outcome.step, false)
+		}
+	}
+
+	if abortErr != nil {
+		return abortErr
+	}
+
+	if c.output != "" {
+		output, err := resolveChainTemplates(c.output, c.evaluator, c.dagData())
+		if err != nil {
+			return fmt.Errorf("chain output: %w", err)
+		}
+
+		result.FinalResult = output
+	} else {
+		for _, step := range slices.Backward(plan.steps) {
+			if value, ok := result.StepResults[step.Name]; ok {
+				result.FinalResult = value
+
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+// runDAGStep evaluates a step's condition and input and calls its tool.
+func (c *ToolChain) runDAGStep(ctx context.Context, step *ChainStep) chainStepOutcome {
+	start := time.Now()
+	outcome := chainStepOutcome{step: step}
+
+	defer func() {
+		outcome.duration = time.Since(start)
+	}()
+
+	data := c.dagData()
+
+	if step.When != "" {
+		ok, err := c.evaluator.EvaluateCondition(step.When, data)
+		if err != nil {
+			outcome.err = fmt.Errorf("when condition: %w", err)
+
+			return outcome
+		}
+
+		if !ok {
+			outcome.skipped = true
+
+			return outcome
+		}
+	}
+
+	if step.Condition != nil && !step.Condition(c.context) {
+		outcome.skipped = true
+
+		return outcome
+	}
+
+	var input map[string]any
+
+	if step.InputMapper != nil {
+		input = step.InputMapper(c.context)
+	} else {
+		resolved, err := resolveChainTemplates(step.Input, c.evaluator, data)
+		if err != nil {
+			outcome.err = fmt.Errorf("input: %w", err)
+
+			return outcome
+		}
+
+		input, _ = resolved.(map[string]any)
+	}
+
+	if input == nil {
+		input = make(map[string]any)
+	}
+
+	if c.onStepStart != nil {
+		c.onStepStart(step.Name, input)
+	}
+
+	toolResult, err := c.registry.ExecuteTool(ctx, step.ToolName, step.Version, input)
+	if err != nil {
+		outcome.err = err
+
+		return outcome
+	}
+
+	outcome.value = toolResult.Result
+	if step.Transformer != nil {
+		outcome.value = step.Transformer(c.context, outcome.value)
+	}
+
+	return outcome
+}
+
+// dagData returns the variables visible to step expressions: the results of
+// finished steps and the chain input.
+func (c *ToolChain) dagData() map[string]any {
+	c.context.mu.RLock()
+	defer c.context.mu.RUnlock()
+
+	data := maps.Clone(c.context.results)
+	data[chainInputVariable] = maps.Clone(c.context.data)
+
+	return data
+}
+
+// ToolChainToolName is the name of the tool returned by NewToolChainTool.
+const ToolChainToolName = "run_tool_chain"
+
+// NewToolChainTool returns a tool that lets a model propose a ChainSpec over
+// the tools in registry. The chain is validated before it runs, and problems
+// are returned as a tool error the model can correct. Chains run with the
+// caller's context, so tool scopes still apply to every step.
+func NewToolChainTool(registry *ToolRegistry) *ToolDefinition {
+	stringProp := func(description string) ToolParameterProperty {
+		return ToolParameterProperty{Type: "string", Description: description}
+	}
+
+	step := ToolParameterProperty{
+		Type: "object",
+		Properties: map[string]ToolParameterProperty{
+			"id":         stringProp("Unique step id; later steps read its result as ${id}"),
+			"tool":       stringProp("Name of the tool to call"),
+			"version":    stringProp("Tool version, defaults to 1.0.0"),
+			"input":      {Type: "object", Description: "Tool arguments; strings may reference earlier results, e.g. \"${search.results[0].url}\""},
+			"depends_on": {Type: "array", Description: "Steps that must finish first", Items: &ToolParameterProperty{Type: "string"}},
+			"when":       stringProp("Condition expression; the step and the steps depending on it are skipped when false"),
+		},
+		Required: []string{"id", "tool"},
+	}
+
+	return &ToolDefinition{
+		Name:    ToolChainToolName,
+		Version: "1.0.0",
+		Description: "Runs several tool calls as a dependency graph in one call. Steps run in parallel unless " +
+			"their input references another step's result with ${step_id...}.",
+		Category: "tool_chain",
+		Parameters: ToolParameterSchema{
+			Type: "object",
+			Properties: map[string]ToolParameterProperty{
+				"steps":  {Type: "array", Description: "Steps of the chain", Items: &step},
+				"output": stringProp("Optional template selecting the final result, e.g. \"${summarize}\""),
+			},
+			Required: []string{"steps"},
+		},
+		Handler: func(ctx context.Context, params map[string]any) (any, error) {
+			data, err := json.Marshal(params)
+			if err != nil {
+				return nil, err
+			}
+
+			spec, err := ParseChainSpec(data)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, s := range spec.Steps {
+				if s.Tool == ToolChainToolName {
+					return nil, &ChainSpecError{Step: s.ID, Message: "chains cannot run other chains"}
+				}
+			}
+
+			chain, err := NewToolChainFromSpec(registry, spec)
+			if err != nil {
+				return nil, err
+			}
+
+			result, err := chain.Execute(ctx)
+			if err != nil {
+				return nil, err
+			}
+
+			errs := make(map[string]string, len(result.Errors))
+			for name, stepErr := range result.Errors {
+				errs[name] = stepErr.Error()
+			}
+
+			return map[string]any{"output": result.FinalResult, "steps": result.StepResults, "errors": errs}, nil
+		},
+	}
+}
@@ -0,0 +1,305 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newDAGRegistry registers a search tool, a slow fetch tool that records peak
+// concurrency, and a summarize tool.
+func newDAGRegistry(t *testing.T, peak *int) *ToolRegistry {
+	t.Helper()
+
+	registry := NewToolRegistry(nil, nil)
+
+	var (
+		mu      sync.Mutex
+		running int
+	)
+
+	open := func(names ...string) ToolParameterSchema {
+		props := make(map[string]ToolParameterProperty, len(names))
+		for _, name := range names {
+			props[name] = ToolParameterProperty{}
+		}
+
+		return ToolParameterSchema{Type: "object", Properties: props}
+	}
+
+	tools := []*ToolDefinition{
+		{
+			Name:       "search",
+			Parameters: open("query"),
+			Handler: func(ctx context.Context, params map[string]any) (any, error) {
+				query := params["query"].(string)
+
+				return map[string]any{"results": []any{
+					map[string]any{"url": "https://example.com/" + query},
+					map[string]any{"url": "https://example.org/" + query},
+				}}, nil
+			},
+		},
+		{
+			Name:       "fetch",
+			Parameters: open("url"),
+			Handler: func(ctx context.Context, params map[string]any) (any, error) {
+				mu.Lock()
+				running++
+				*peak = max(*peak, running)
+				mu.Unlock()
+
+				time.Sleep(20 * time.Millisecond)
+
+				mu.Lock()
+				running--
+				mu.Unlock()
+
+				return "page " + params["url"].(string), nil
+			},
+		},
+		{
+			Name:       "summarize",
+			Parameters: open("texts", "title"),
+			Handler: func(ctx context.Context, params map[string]any) (any, error) {
+				return map[string]any{"title": params["title"], "count": len(params["texts"].([]any))}, nil
+			},
+		},
+		{
+			Name:       "fail",
+			Parameters: open(),
+			Handler: func(ctx context.Context, params map[string]any) (any, error) {
+				return nil, errors.New("boom")
+			},
+		},
+	}
+
+	for _, tool := range tools {
+		tool.Version = "1.0.0"
+		if err := registry.RegisterTool(tool); err != nil {
+			t.Fatalf("failed to register %s: %v", tool.Name, err)
+		}
+	}
+
+	return registry
+}
+
+func TestToolChainDAG_Execute(t *testing.T) {
+	peak := 0
+	registry := newDAGRegistry(t, &peak)
+
+	chain := NewToolChain(registry).AsDAG().
+		WithChainInput(map[string]any{"topic": "go"}).
+		NamedStep("search", "search", WithInput(map[string]any{"query": "${input.topic}"})).
+		NamedStep("first", "fetch", WithInput(map[string]any{"url": "${search.results[0].url}"})).
+		NamedStep("second", "fetch", WithInput(map[string]any{"url": "${search.results[1].url}"})).
+		NamedStep("summary", "summarize", WithInput(map[string]any{
+			"texts": []any{"${first}", "${second}"},
+			"title": "About ${input.topic}: ${len(search.results)} results",
+		})).
+		WithOutput("${summary.title}")
+
+	result, err := chain.Execute(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if peak != 2 {
+		t.Errorf("expected both fetches to run in parallel, peak concurrency %d", peak)
+	}
+
+	if result.StepResults["first"] != "page https://example.com/go" {
+		t.Errorf("unexpected fetch result: %v", result.StepResults["first"])
+	}
+
+	if result.FinalResult != "About go: 2 results" {
+		t.Errorf("unexpected final result: %v", result.FinalResult)
+	}
+
+	if summary := result.StepResults["summary"].(map[string]any); summary["count"] != 2 {
+		t.Errorf("expected list values to be passed through, got %v", summary)
+	}
+
+	if result.StepsExecuted != 4 {
+		t.Errorf("expected 4 executed steps, got %d", result.StepsExecuted)
+	}
+}
+
+func TestToolChainDAG_WhenAndFailures(t *testing.T) {
+	peak := 0
+	registry := newDAGRegistry(t, &peak)
+
+	chain := NewToolChain(registry).AsDAG().ContinueOnError(true).
+		NamedStep("search", "search", WithInput(map[string]any{"query": "x"})).
+		NamedStep("skipped", "fetch", WithInput(map[string]any{"url": "u"}), WithWhen("len(search.results) > 5")).
+		NamedStep("after_skipped", "fetch", WithInput(map[string]any{"url": "${skipped}"})).
+		NamedStep("broken", "fail").
+		NamedStep("after_broken", "fetch", WithInput(map[string]any{"url": "u"}), WithDependsOn("broken"))
+
+	result, err := chain.Execute(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, ok := result.StepResults["skipped"]; ok {
+		t.Error("expected step with false condition to be skipped")
+	}
+
+	if _, ok := result.StepResults["after_skipped"]; ok {
+		t.Error("expected dependent of a skipped step to be skipped")
+	}
+
+	if _, ok := result.StepResults["after_broken"]; ok {
+		t.Error("expected dependent of a failed step to be skipped")
+	}
+
+	if result.StepsSkipped != 3 || result.Errors["broken"] == nil {
+		t.Errorf("unexpected result: skipped=%d errors=%v", result.StepsSkipped, result.Errors)
+	}
+
+	_, err = NewToolChain(registry).AsDAG().
+		NamedStep("broken", "fail").
+		NamedStep("after", "fetch", WithDependsOn("broken")).
+		Execute(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "step broken failed") {
+		t.Errorf("expected chain to stop on failure, got %v", err)
+	}
+}
+
+func TestChainSpec_Validate(t *testing.T) {
+	peak := 0
+	registry := newDAGRegistry(t, &peak)
+
+	spec, err := ParseChainSpec([]byte(`{
+		"steps": [
+			{"id": "a", "tool": "search", "input": {"query": "${b}"}},
+			{"id": "b", "tool": "fetch", "input": {"url": "${a.results[0].url}"}},
+			{"id": "c", "tool": "missing"},
+			{"id": "d", "tool": "fetch", "input": {"url": "${nope}"}},
+			{"id": "a", "tool": "fetch"},
+			{"id": "fetch-page", "tool": "fetch"},
+			{"id": "null", "tool": "fetch"}
+		]
+	}`))
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+
+	err = spec.Validate(registry)
+	if !errors.Is(err, ErrInvalidChainSpec) {
+		t.Fatalf("expected ErrInvalidChainSpec, got %v", err)
+	}
+
+	for _, want := range []string{"dependency cycle", "unknown tool missing", `unknown step "nope"`, "duplicate step id", "fetch-page: step id must be an identifier", "null: step id must be an identifier"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in %v", want, err)
+		}
+	}
+
+	if _, err := ParseChainSpec([]byte(`{"steps": [{"id": "a", "tool": "search", "args": {}}]}`)); !errors.Is(err, ErrInvalidChainSpec) {
+		t.Errorf("expected unknown fields to be rejected, got %v", err)
+	}
+}
+
+func TestChainSpec_RoundTrip(t *testing.T) {
+	peak := 0
+	registry := newDAGRegistry(t, &peak)
+
+	chain := NewToolChain(registry).
+		NamedStep("search", "search", WithInput(map[string]any{"query": "go"})).
+		NamedStep("fetch", "fetch", WithInput(map[string]any{"url": "${search.results[0].url}"})).
+		WithOutput("${fetch}")
+
+	spec, err := chain.Spec()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	loaded, err := NewToolChainFromSpec(registry, spec)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	result, err := loaded.Execute(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.FinalResult != "page https://example.com/go" {
+		t.Errorf("unexpected final result: %v", result.FinalResult)
+	}
+
+	withCallback := NewToolChain(registry).Step("search").Transform(func(ctx *ChainContext, result any) any { return result })
+	if _, err := withCallback.Spec(); err == nil {
+		t.Error("expected chains with callbacks not to serialize")
+	}
+}
+
+func TestToolChainTool(t *testing.T) {
+	peak := 0
+	registry := newDAGRegistry(t, &peak)
+	_ = registry.RegisterTool(NewToolChainTool(registry))
+
+	result, err := registry.ExecuteTool(context.Background(), ToolChainToolName, "1.0.0", map[string]any{
+		"steps": []any{
+			map[string]any{"id": "s", "tool": "search", "input": map[string]any{"query": "dag"}},
+			map[string]any{"id": "f", "tool": "fetch", "input": map[string]any{"url": "${s.results[1].url}"}},
+		},
+		"output": "${f}",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if output := result.Result.(map[string]any)["output"]; output != "page https://example.org/dag" {
+		t.Errorf("unexpected output: %v", output)
+	}
+
+	_, err = registry.ExecuteTool(context.Background(), ToolChainToolName, "1.0.0", map[string]any{
+		"steps": []any{map[string]any{"id": "s", "tool": "unknown"}},
+	})
+	if !errors.Is(err, ErrInvalidChainSpec) {
+		t.Errorf("expected invalid chain to be reported, got %v", err)
+	}
+}
+
+func TestParseChainTemplate(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"plain", "plain"},
+		{"${a.b}", "{a.b}"},
+		{"x ${a['}']} y", "x |{a['}']}| y"},
+		{"cost $${price}", "cost ${price}"},
+	}
+
+	for _, tt := range tests {
+		parts, err := parseChainTemplate(tt.in)
+		if err != nil {
+			t.Fatalf("parseChainTemplate(%q): %v", tt.in, err)
+		}
+
+		var got []string
+
+		for _, part := range parts {
+			if part.isExpr {
+				got = append(got, fmt.Sprintf("{%s}", part.text))
+			} else {
+				got = append(got, part.text)
+			}
+		}
+
+		if joined := strings.Join(got, "|"); joined != tt.want {
+			t.Errorf("parseChainTemplate(%q) = %q, want %q", tt.in, joined, tt.want)
+		}
+	}
+
+	if _, err := parseChainTemplate("${unterminated"); err == nil {
+		t.Error("expected unterminated template to fail")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
 	"reflect"
 	"slices"
 	"strconv"
@@ -21,8 +22,12 @@ type ToolRegistry struct {
 	logger  logger.Logger
 	metrics metrics.Metrics
 
-	mu    sync.RWMutex
-	tools map[string]*ToolDefinition
+	mu      sync.RWMutex
+	tools   map[string]*ToolDefinition
+	schemas map[string]*JSONSchema
+
+	auditHook  ToolAuditHook
+	cacheStore CacheStore
 }
 
 // ToolDefinition defines a tool that can be used by agents.
@@ -39,6 +44,21 @@ type ToolDefinition struct {
 	RetryConfig *RetryConfig        `json:"retry_config,omitempty"`
 	Metadata    map[string]any      `json:"metadata,omitempty"`
 	CreatedAt   time.Time           `json:"created_at"`
+
+	// RequiredScopes lists the scopes the calling principal must hold,
+	// e.g. "billing:write". Tools without scopes can be called by anyone.
+	RequiredScopes []string `json:"required_scopes,omitempty"`
+
+	// Cache makes successful results reusable for calls with the same
+	// arguments. See ToolRegistry.SetCacheStore.
+	Cache *ToolCachePolicy `json:"cache,omitempty"`
+
+	// Invalidates lists resources whose cached results are dropped after a
+	// successful call, for tools that modify them.
+	Invalidates []string `json:"invalidates,omitempty"`
+
+	// InvalidateHook computes further resources to invalidate from a call
+	InvalidateHook ToolInvalidationHook `json:"-"`
 }
 
 // ToolParameterSchema defines the JSON schema for tool parameters.
@@ -46,16 +66,48 @@ type ToolParameterSchema struct {
 	Type       string                           `json:"type"`
 	Properties map[string]ToolParameterProperty `json:"properties"`
 	Required   []string                         `json:"required"`
+
+	// AdditionalProperties allows parameters not listed in Properties.
+	// Unknown parameters are rejected when nil.
+	AdditionalProperties *bool `json:"additionalProperties,omitempty"`
 }
 
 // ToolParameterProperty defines a single parameter.
 type ToolParameterProperty struct {
-	Type        string   `json:"type"`
+	Type        string   `json:"type,omitempty"`
 	Description string   `json:"description"`
 	Enum        []string `json:"enum,omitempty"`
 	Default     any      `json:"default,omitempty"`
 	Minimum     *float64 `json:"minimum,omitempty"`
 	Maximum     *float64 `json:"maximum,omitempty"`
+	Format      string   `json:"format,omitempty"`
+
+	// String constraints
+	Pattern   string `json:"pattern,omitempty"`
+	MinLength *int   `json:"minLength,omitempty"`
+	MaxLength *int   `json:"maxLength,omitempty"`
+
+	// Numeric constraints
+	ExclusiveMinimum *float64 `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum *float64 `json:"exclusiveMaximum,omitempty"`
+	MultipleOf       *float64 `json:"multipleOf,omitempty"`
+
+	// Items and its constraints describe array elements
+	Items       *ToolParameterProperty `json:"items,omitempty"`
+	MinItems    *int                   `json:"minItems,omitempty"`
+	MaxItems    *int                   `json:"maxItems,omitempty"`
+	UniqueItems bool                   `json:"uniqueItems,omitempty"`
+
+	// Properties, Required and AdditionalProperties describe nested objects.
+	// Unlike the top-level schema, nested objects accept unknown properties
+	// unless AdditionalProperties is false.
+	Properties           map[string]ToolParameterProperty `json:"properties,omitempty"`
+	Required             []string                         `json:"required,omitempty"`
+	AdditionalProperties *bool                            `json:"additionalProperties,omitempty"`
+
+	// OneOf and AnyOf list alternative schemas for the value
+	OneOf []ToolParameterProperty `json:"oneOf,omitempty"`
+	AnyOf []ToolParameterProperty `json:"anyOf,omitempty"`
 }
 
 // ToolHandler is the function signature for tool implementations.
@@ -73,6 +125,25 @@ type ToolRunResult struct {
 	Metadata  map[string]any
 }
 
+// ToolFieldError describes one invalid tool argument.
+type ToolFieldError = SchemaViolation
+
+// ToolArgumentError reports every invalid argument of a tool call, with
+// machine-readable paths the model can use to correct its call.
+type ToolArgumentError struct {
+	Tool   string
+	Fields []ToolFieldError
+}
+
+func (e *ToolArgumentError) Error() string {
+	return fmt.Sprintf("invalid arguments for tool %s: %s", e.Tool, formatViolations(e.Fields))
+}
+
+// Unwrap allows errors.Is(err, ErrInvalidToolArguments).
+func (e *ToolArgumentError) Unwrap() error {
+	return ErrInvalidToolArguments
+}
+
 // ToolExecutionResult is an alias for ToolRunResult for backward compatibility.
 // Deprecated: Use ToolRunResult instead.
 type ToolExecutionResult = ToolRunResult
@@ -83,6 +154,7 @@ func NewToolRegistry(logger logger.Logger, metrics metrics.Metrics) *ToolRegistr
 		logger:  logger,
 		metrics: metrics,
 		tools:   make(map[string]*ToolDefinition),
+		schemas: make(map[string]*JSONSchema),
 	}
 }
 
@@ -104,6 +176,11 @@ func (tr *ToolRegistry) RegisterTool(tool *ToolDefinition) error {
 		tool.Timeout = 30 * time.Second
 	}
 
+	schema, err := tool.Parameters.Compile()
+	if err != nil {
+		return fmt.Errorf("tool %s: %w", tool.Name, err)
+	}
+
 	tool.CreatedAt = time.Now()
 
 	tr.mu.Lock()
@@ -116,6 +193,7 @@ func (tr *ToolRegistry) RegisterTool(tool *ToolDefinition) error {
 	}
 
 	tr.tools[key] = tool
+	tr.schemas[key] = schema
 
 	if tr.logger != nil {
 		tr.logger.Info("Tool registered",
@@ -132,6 +210,15 @@ func (tr *ToolRegistry) RegisterTool(tool *ToolDefinition) error {
 	return nil
 }
 
+// SetAuditHook sets the hook receiving authorization decisions for tools
+// that require scopes.
+func (tr *ToolRegistry) SetAuditHook(hook ToolAuditHook) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	tr.auditHook = hook
+}
+
 // RegisterFunc is a convenience method to register a function as a tool.
 func (tr *ToolRegistry) RegisterFunc(name, description string, fn any) error {
 	handler, schema, err := tr.wrapFunction(fn)
@@ -312,6 +399,47 @@ func (tr *ToolRegistry) ListTools() []*ToolDefinition {
 	return tools
 }
 
+// AgentTools returns the registered tools in the form accepted by agents,
+// sorted by name. Calls go through ExecuteTool, so validation, authorization
+// and caching apply. Of several versions of a tool, the latest registered is
+// used.
+func (tr *ToolRegistry) AgentTools() []Tool {
+	tr.mu.RLock()
+
+	latest := make(map[string]*ToolDefinition, len(tr.tools))
+	for _, tool := range tr.tools {
+		if current, ok := latest[tool.Name]; !ok || tool.CreatedAt.After(current.CreatedAt) {
+			latest[tool.Name] = tool
+		}
+	}
+
+	tr.mu.RUnlock()
+
+	tools := make([]Tool, 0, len(latest))
+
+	for _, name := range slices.Sorted(maps.Keys(latest)) {
+		tool := latest[name]
+		version := tool.Version
+
+		tools = append(tools, Tool{
+			Name:        tool.Name,
+			Description: tool.Description,
+			Parameters:  tool.Parameters.Map(),
+			Handler: func(ctx context.Context, params map[string]any) (any, error) {
+				result, err := tr.ExecuteTool(ctx, name, version, params)
+				if err != nil {
+					return nil, err
+				}
+
+				return result.Result, nil
+			},
+			selfValidating: true,
+		})
+	}
+
+	return tools
+}
+
 // ListToolsByCategory returns tools in a specific category.
 func (tr *ToolRegistry) ListToolsByCategory(category string) []*ToolDefinition {
 	tr.mu.RLock()
@@ -382,6 +510,25 @@ func (tr *ToolRegistry) ExecuteTool(ctx context.Context, name, version string, p
 		return result, err
 	}
 
+	tr.mu.RLock()
+	auditHook := tr.auditHook
+	tr.mu.RUnlock()
+
+	if err := AuthorizeTool(ctx, ToolOriginRegistry, tool.Name, tool.RequiredScopes, auditHook); err != nil {
+		result.Error = err
+		result.Duration = time.Since(startTime)
+
+		if tr.logger != nil {
+			tr.logger.Warn("Tool call denied", F("tool", name), F("error", err.Error()))
+		}
+
+		if tr.metrics != nil {
+			tr.metrics.Counter("forge.ai.sdk.tools.denied", metrics.WithLabel("tool", name)).Inc()
+		}
+
+		return result, err
+	}
+
 	// Validate parameters
 	if err := tr.validateParameters(tool, params); err != nil {
 		result.Error = fmt.Errorf("parameter validation failed: %w", err)
@@ -390,6 +537,32 @@ func (tr *ToolRegistry) ExecuteTool(ctx context.Context, name, version string, p
 		return result, result.Error
 	}
 
+	tr.mu.RLock()
+	cacheStore := tr.cacheStore
+	tr.mu.RUnlock()
+
+	var cacheKey string
+
+	if tool.Cache != nil && cacheStore != nil {
+		var cached *cachedToolResult
+
+		cacheKey, cached = tr.lookupToolResult(ctx, cacheStore, tool, params)
+		if cached != nil {
+			var value any
+			if err := json.Unmarshal(cached.Result, &value); err == nil {
+				result.Result = value
+				result.Success = true
+				result.Duration = time.Since(startTime)
+				result.Metadata["cache_hit"] = true
+				result.Metadata["cached_at"] = cached.CachedAt
+
+				recordToolCacheHit(ctx)
+
+				return result, nil
+			}
+		}
+	}
+
 	// Create context with timeout
 	execCtx, cancel := context.WithTimeout(ctx, tool.Timeout)
 	defer cancel()
@@ -419,6 +592,14 @@ func (tr *ToolRegistry) ExecuteTool(ctx context.Context, name, version string, p
 	result.Error = execErr
 	result.Success = execErr == nil
 
+	if result.Success {
+		if cacheKey != "" {
+			tr.storeToolResult(ctx, cacheStore, tool, cacheKey, execResult)
+		}
+
+		tr.invalidateAfter(ctx, tool, params, execResult)
+	}
+
 	// Record metrics
 	if tr.metrics != nil {
 		tr.metrics.Counter("forge.ai.sdk.tools.executions", metrics.WithLabel("tool", name), metrics.WithLabel("version", version), metrics.WithLabel("success", strconv.FormatBool(result.Success))).Inc()
@@ -447,96 +628,89 @@ func (tr *ToolRegistry) ExecuteTool(ctx context.Context, name, version string, p
 
 // validateParameters validates tool parameters against the schema.
 func (tr *ToolRegistry) validateParameters(tool *ToolDefinition, params map[string]any) error {
-	// Check required parameters
-	for _, required := range tool.Parameters.Required {
-		if _, exists := params[required]; !exists {
-			return fmt.Errorf("missing required parameter: %s", required)
-		}
-	}
-
-	// Validate parameter types
-	for paramName, paramValue := range params {
-		prop, exists := tool.Parameters.Properties[paramName]
-		if !exists {
-			return fmt.Errorf("unknown parameter: %s", paramName)
-		}
+	tr.mu.RLock()
+	schema := tr.schemas[tr.toolKey(tool.Name, tool.Version)]
+	tr.mu.RUnlock()
 
-		// Basic type validation
-		if err := tr.validateParameterType(paramValue, prop); err != nil {
-			return fmt.Errorf("parameter %s: %w", paramName, err)
+	if schema == nil {
+		var err error
+		if schema, err = tool.Parameters.Compile(); err != nil {
+			return err
 		}
 	}
 
-	return nil
+	return validateToolArguments(tool.Name, schema, params)
 }
 
-// validateParameterType validates a parameter value against its schema.
-func (tr *ToolRegistry) validateParameterType(value any, prop ToolParameterProperty) error {
-	if value == nil {
-		return nil
+// Compile compiles the parameter schema for validation.
+func (s ToolParameterSchema) Compile() (*JSONSchema, error) {
+	return CompileJSONSchema(s.Map())
+}
+
+// Map returns the parameter schema as a JSON Schema document.
+func (s ToolParameterSchema) Map() map[string]any {
+	schema := map[string]any{"type": "object"}
+
+	if s.Type != "" {
+		schema["type"] = s.Type
 	}
 
-	valueType := reflect.TypeOf(value)
-	expectedType := prop.Type
+	properties := make(map[string]any, len(s.Properties))
 
-	switch expectedType {
-	case "string":
-		if valueType.Kind() != reflect.String {
-			return fmt.Errorf("expected string, got %s", valueType.Kind())
+	for name, prop := range s.Properties {
+		var value any
+		if data, err := json.Marshal(prop); err == nil {
+			_ = json.Unmarshal(data, &value)
 		}
 
-		// Check enum
-		if len(prop.Enum) > 0 {
-			strValue := value.(string)
-			found := slices.Contains(prop.Enum, strValue)
+		properties[name] = value
+	}
 
-			if !found {
-				return fmt.Errorf("value must be one of: %v", prop.Enum)
-			}
-		}
+	schema["properties"] = properties
 
-	case "integer":
-		switch valueType.Kind() {
-		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
-			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-			// Valid integer type
-		case reflect.Float32, reflect.Float64:
-			// Allow floats that are whole numbers
-			floatValue := reflect.ValueOf(value).Float()
-			if floatValue != float64(int64(floatValue)) {
-				return errors.New("expected integer, got float with decimals")
-			}
-		default:
-			return fmt.Errorf("expected integer, got %s", valueType.Kind())
+	if len(s.Required) > 0 {
+		required := make([]any, len(s.Required))
+		for i, name := range s.Required {
+			required[i] = name
 		}
 
-	case "number":
-		switch valueType.Kind() {
-		case reflect.Float32, reflect.Float64,
-			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
-			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-			// Valid numeric type
-		default:
-			return fmt.Errorf("expected number, got %s", valueType.Kind())
-		}
+		schema["required"] = required
+	}
 
-	case "boolean":
-		if valueType.Kind() != reflect.Bool {
-			return fmt.Errorf("expected boolean, got %s", valueType.Kind())
-		}
+	schema["additionalProperties"] = s.AdditionalProperties != nil && *s.AdditionalProperties
 
-	case "array":
-		if valueType.Kind() != reflect.Array && valueType.Kind() != reflect.Slice {
-			return fmt.Errorf("expected array, got %s", valueType.Kind())
-		}
+	return schema
+}
+
+// validateToolArguments checks tool call arguments against a compiled schema.
+// Null arguments are treated as omitted, as models often send null for
+// optional parameters.
+func validateToolArguments(toolName string, schema *JSONSchema, params map[string]any) error {
+	violations := schema.Violations(dropNullProperties(params))
+	if len(violations) == 0 {
+		return nil
+	}
+
+	return &ToolArgumentError{Tool: toolName, Fields: violations}
+}
 
-	case "object":
-		if valueType.Kind() != reflect.Map && valueType.Kind() != reflect.Struct {
-			return fmt.Errorf("expected object, got %s", valueType.Kind())
+// dropNullProperties returns a copy of an object without null properties,
+// recursing into nested objects.
+func dropNullProperties(object map[string]any) map[string]any {
+	result := make(map[string]any, len(object))
+
+	for key, value := range object {
+		switch v := value.(type) {
+		case nil:
+			continue
+		case map[string]any:
+			result[key] = dropNullProperties(v)
+		default:
+			result[key] = value
 		}
 	}
 
-	return nil
+	return result
 }
 
 // UnregisterTool removes a tool from the registry.
@@ -554,6 +728,7 @@ func (tr *ToolRegistry) UnregisterTool(name, version string) error {
 	}
 
 	delete(tr.tools, key)
+	delete(tr.schemas, key)
 
 	if tr.logger != nil {
 		tr.logger.Info("Tool unregistered",
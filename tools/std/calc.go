@@ -0,0 +1,204 @@
+package std
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	sdk "github.com/xraph/ai-sdk"
+)
+
+// CalculatorConfig configures the calculator tool.
+type CalculatorConfig struct {
+	// MaxSteps bounds the work done per expression
+	MaxSteps int
+}
+
+// NewCalculatorTool returns a tool that evaluates arithmetic expressions with
+// the workflow expression engine. Expressions cannot call anything but the
+// math functions below and are bounded in size and evaluation steps.
+//
+// Supported functions: abs, ceil, floor, round, sqrt, pow, exp, log, log10,
+// log2, sin, cos, tan, asin, acos, atan, min and max. The constants pi and e
+// are predefined.
+func NewCalculatorTool(config CalculatorConfig) *sdk.ToolDefinition {
+	if config.MaxSteps <= 0 {
+		config.MaxSteps = 1000
+	}
+
+	opts := []sdk.ExpressionOption{
+		sdk.WithExpressionMaxSteps(config.MaxSteps),
+		sdk.WithExpressionMaxDepth(32),
+		sdk.WithoutExpressionBuiltins(),
+	}
+	for name, fn := range calculatorFunctions() {
+		opts = append(opts, sdk.WithExpressionFunction(name, fn))
+	}
+
+	evaluator := sdk.NewExpressionEvaluator(opts...)
+
+	params := sdk.ToolParameterSchema{
+		Type: "object",
+		Properties: map[string]sdk.ToolParameterProperty{
+			"expression": {Type: "string", Description: "Arithmetic expression, e.g. \"round(pow(1.05, 10) * 1000)\"", MaxLength: ptr(1000)},
+			"variables":  {Type: "object", Description: "Named numeric values the expression can use"},
+		},
+		Required: []string{"expression"},
+	}
+
+	hints := sdk.UIToolHints{
+		PreferredPartType: sdk.PartTypeMetric,
+		RenderOptions:     map[string]any{"valueField": "result", "labelField": "expression"},
+	}
+
+	description := "Evaluates an arithmetic expression exactly. Supports + - * / %, comparisons, parentheses, " +
+		"pi, e and the functions abs, ceil, floor, round, sqrt, pow, exp, log, log10, log2, sin, cos, tan, asin, acos, atan, min and max."
+
+	return newTool("calculator", description, params, hints, func(ctx context.Context, params map[string]any) (any, error) {
+		expression, _ := params["expression"].(string)
+
+		data := map[string]any{"pi": math.Pi, "e": math.E}
+
+		if variables, ok := params["variables"].(map[string]any); ok {
+			for name, value := range variables {
+				if _, isNumber := toFloat(value); !isNumber {
+					return nil, fmt.Errorf("variable %s must be a number", name)
+				}
+
+				data[name] = value
+			}
+		}
+
+		value, err := evaluator.EvaluateTransform(expression, data)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, isBool := value.(bool); !isBool {
+			f, ok := toFloat(value)
+			if !ok {
+				return nil, fmt.Errorf("expression did not evaluate to a number: %v", value)
+			}
+
+			if math.IsNaN(f) || math.IsInf(f, 0) {
+				return nil, errors.New("expression result is not a finite number")
+			}
+
+			value = f
+		}
+
+		return map[string]any{"expression": expression, "result": value}, nil
+	})
+}
+
+// calculatorFunctions returns the math functions exposed to expressions.
+func calculatorFunctions() map[string]sdk.ExpressionFunc {
+	unary := func(name string, fn func(float64) float64) sdk.ExpressionFunc {
+		return func(args ...any) (any, error) {
+			values, err := floatArgs(name, args, 1)
+			if err != nil {
+				return nil, err
+			}
+
+			return fn(values[0]), nil
+		}
+	}
+
+	variadic := func(name string, fn func(a, b float64) float64) sdk.ExpressionFunc {
+		return func(args ...any) (any, error) {
+			if len(args) == 0 {
+				return nil, fmt.Errorf("%s expects at least 1 argument", name)
+			}
+
+			values, err := floatArgs(name, args, len(args))
+			if err != nil {
+				return nil, err
+			}
+
+			result := values[0]
+			for _, v := range values[1:] {
+				result = fn(result, v)
+			}
+
+			return result, nil
+		}
+	}
+
+	return map[string]sdk.ExpressionFunc{
+		"abs":   unary("abs", math.Abs),
+		"ceil":  unary("ceil", math.Ceil),
+		"floor": unary("floor", math.Floor),
+		"round": func(args ...any) (any, error) {
+			if len(args) == 2 {
+				values, err := floatArgs("round", args, 2)
+				if err != nil {
+					return nil, err
+				}
+
+				scale := math.Pow(10, math.Trunc(values[1]))
+
+				return math.Round(values[0]*scale) / scale, nil
+			}
+
+			return unary("round", math.Round)(args...)
+		},
+		"sqrt": unary("sqrt", math.Sqrt),
+		"pow": func(args ...any) (any, error) {
+			values, err := floatArgs("pow", args, 2)
+			if err != nil {
+				return nil, err
+			}
+
+			return math.Pow(values[0], values[1]), nil
+		},
+		"exp":   unary("exp", math.Exp),
+		"log":   unary("log", math.Log),
+		"log10": unary("log10", math.Log10),
+		"log2":  unary("log2", math.Log2),
+		"sin":   unary("sin", math.Sin),
+		"cos":   unary("cos", math.Cos),
+		"tan":   unary("tan", math.Tan),
+		"asin":  unary("asin", math.Asin),
+		"acos":  unary("acos", math.Acos),
+		"atan":  unary("atan", math.Atan),
+		"min":   variadic("min", math.Min),
+		"max":   variadic("max", math.Max),
+	}
+}
+
+func floatArgs(name string, args []any, n int) ([]float64, error) {
+	if len(args) != n {
+		return nil, fmt.Errorf("%s expects %d argument(s), got %d", name, n, len(args))
+	}
+
+	values := make([]float64, n)
+
+	for i, arg := range args {
+		f, ok := toFloat(arg)
+		if !ok {
+			return nil, fmt.Errorf("%s expects numbers, got %v", name, arg)
+		}
+
+		values[i] = f
+	}
+
+	return values, nil
+}
+
+func toFloat(value any) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	}
+
+	return 0, false
+}
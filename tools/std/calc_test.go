@@ -0,0 +1,44 @@
+package std
+
+import (
+	"context"
+	"testing"
+
+	sdk "github.com/xraph/ai-sdk"
+)
+
+func TestCalculatorTool(t *testing.T) {
+	tool := NewCalculatorTool(CalculatorConfig{})
+
+	cases := map[string]any{
+		"(2 + 3) * 4":             float64(20),
+		"round(pow(1.05, 2), 4)":  1.1025,
+		"max(1, x, 3) + sqrt(16)": float64(11),
+		"round(pi * 100)":         float64(314),
+		"10 > 3":                  true,
+	}
+
+	for expression, expected := range cases {
+		result, err := tool.Handler(context.Background(), map[string]any{"expression": expression, "variables": map[string]any{"x": float64(7)}})
+		if err != nil {
+			t.Errorf("%s: expected no error, got %v", expression, err)
+
+			continue
+		}
+
+		if got := result.(map[string]any)["result"]; got != expected {
+			t.Errorf("%s: expected %v, got %v", expression, expected, got)
+		}
+	}
+
+	for _, expression := range []string{"1 / 0", "'a' + 'b'", "sqrt()", "os.exit(1)", "len('abc')", "upper('a')"} {
+		if _, err := tool.Handler(context.Background(), map[string]any{"expression": expression}); err == nil {
+			t.Errorf("%s: expected error", expression)
+		}
+	}
+
+	ui := UITool(tool)
+	if ui.Name() != "calculator" || ui.GetUIHints().PreferredPartType != sdk.PartTypeMetric {
+		t.Errorf("unexpected UI tool: %s %+v", ui.Name(), ui.GetUIHints())
+	}
+}
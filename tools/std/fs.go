@@ -0,0 +1,288 @@
+package std
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	"unicode/utf8"
+
+	sdk "github.com/xraph/ai-sdk"
+)
+
+// FileSystemConfig configures the filesystem tools.
+type FileSystemConfig struct {
+	// Root is the directory the tools are confined to. Paths are resolved
+	// relative to it and cannot escape it, including through symlinks.
+	Root string
+
+	// AllowWrite adds the fs_write tool
+	AllowWrite bool
+
+	// MaxReadBytes caps the content returned by fs_read
+	MaxReadBytes int64
+
+	// MaxWriteBytes caps the content accepted by fs_write
+	MaxWriteBytes int
+
+	// MaxEntries caps the entries returned by fs_list
+	MaxEntries int
+}
+
+// DefaultFileSystemConfig returns the default filesystem configuration for a root directory.
+func DefaultFileSystemConfig(root string) FileSystemConfig {
+	return FileSystemConfig{
+		Root:          root,
+		MaxReadBytes:  256 * 1024,
+		MaxWriteBytes: 1 << 20,
+		MaxEntries:    500,
+	}
+}
+
+// NewFileSystemTools returns fs_read and fs_list tools, plus fs_write when
+// writing is allowed, all scoped to the root directory.
+func NewFileSystemTools(config FileSystemConfig) ([]*sdk.ToolDefinition, error) {
+	defaults := DefaultFileSystemConfig(config.Root)
+
+	if config.MaxReadBytes <= 0 {
+		config.MaxReadBytes = defaults.MaxReadBytes
+	}
+
+	if config.MaxWriteBytes <= 0 {
+		config.MaxWriteBytes = defaults.MaxWriteBytes
+	}
+
+	if config.MaxEntries <= 0 {
+		config.MaxEntries = defaults.MaxEntries
+	}
+
+	if config.Root == "" {
+		return nil, fmt.Errorf("%w: filesystem root is required", sdk.ErrInvalidConfig)
+	}
+
+	root, err := os.OpenRoot(config.Root)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", sdk.ErrInvalidConfig, err)
+	}
+
+	f := &fileSystem{root: root, config: config}
+
+	tools := []*sdk.ToolDefinition{f.readTool(), f.listTool()}
+	if config.AllowWrite {
+		tools = append(tools, f.writeTool())
+	}
+
+	return tools, nil
+}
+
+type fileSystem struct {
+	root   *os.Root
+	config FileSystemConfig
+}
+
+func pathParam(description string) sdk.ToolParameterProperty {
+	return sdk.ToolParameterProperty{Type: "string", Description: description}
+}
+
+func (f *fileSystem) readTool() *sdk.ToolDefinition {
+	params := sdk.ToolParameterSchema{
+		Type: "object",
+		Properties: map[string]sdk.ToolParameterProperty{
+			"path": pathParam("File path relative to the root directory"),
+		},
+		Required: []string{"path"},
+	}
+
+	hints := sdk.UIToolHints{
+		PreferredPartType: sdk.PartTypeCode,
+		Collapsible:       true,
+		RenderOptions:     map[string]any{"titleField": "path", "contentField": "content"},
+	}
+
+	return newTool("fs_read", "Reads a text file from the workspace.", params, hints, func(ctx context.Context, params map[string]any) (any, error) {
+		name, err := cleanRootPath(params["path"])
+		if err != nil {
+			return nil, err
+		}
+
+		file, err := f.root.Open(name)
+		if err != nil {
+			return nil, rootError(err)
+		}
+		defer file.Close()
+
+		info, err := file.Stat()
+		if err != nil {
+			return nil, err
+		}
+
+		if info.IsDir() {
+			return nil, fmt.Errorf("%s is a directory", name)
+		}
+
+		data, err := io.ReadAll(io.LimitReader(file, f.config.MaxReadBytes))
+		if err != nil {
+			return nil, err
+		}
+
+		if !utf8.Valid(data) && len(data) < int(f.config.MaxReadBytes) {
+			return nil, fmt.Errorf("%s is not a text file", name)
+		}
+
+		result := map[string]any{"path": name, "size": info.Size(), "content": strings.ToValidUTF8(string(data), "")}
+		if info.Size() > int64(len(data)) {
+			result["truncated"] = true
+		}
+
+		return result, nil
+	})
+}
+
+func (f *fileSystem) listTool() *sdk.ToolDefinition {
+	params := sdk.ToolParameterSchema{
+		Type: "object",
+		Properties: map[string]sdk.ToolParameterProperty{
+			"path": pathParam("Directory path relative to the root directory; defaults to the root"),
+		},
+	}
+
+	hints := sdk.UIToolHints{
+		PreferredPartType: sdk.PartTypeTable,
+		RenderOptions:     map[string]any{"rowsField": "entries", "columns": []string{"name", "type", "size"}},
+	}
+
+	return newTool("fs_list", "Lists the files and directories in a workspace directory.", params, hints, func(ctx context.Context, params map[string]any) (any, error) {
+		name := "."
+		if params["path"] != nil {
+			var err error
+			if name, err = cleanRootPath(params["path"]); err != nil {
+				return nil, err
+			}
+		}
+
+		entries, err := fs.ReadDir(f.root.FS(), name)
+		if err != nil {
+			return nil, rootError(err)
+		}
+
+		out := make([]map[string]any, 0, min(len(entries), f.config.MaxEntries))
+
+		for _, entry := range entries[:min(len(entries), f.config.MaxEntries)] {
+			item := map[string]any{"name": entry.Name(), "type": "file"}
+
+			switch {
+			case entry.IsDir():
+				item["type"] = "directory"
+			case entry.Type()&fs.ModeSymlink != 0:
+				item["type"] = "symlink"
+			default:
+				if info, err := entry.Info(); err == nil {
+					item["size"] = info.Size()
+				}
+			}
+
+			out = append(out, item)
+		}
+
+		result := map[string]any{"path": name, "entries": out}
+		if len(entries) > len(out) {
+			result["truncated"] = true
+		}
+
+		return result, nil
+	})
+}
+
+func (f *fileSystem) writeTool() *sdk.ToolDefinition {
+	params := sdk.ToolParameterSchema{
+		Type: "object",
+		Properties: map[string]sdk.ToolParameterProperty{
+			"path":    pathParam("File path relative to the root directory"),
+			"content": {Type: "string", Description: "Text content to write", MaxLength: ptr(f.config.MaxWriteBytes)},
+			"append":  {Type: "boolean", Description: "Append to the file instead of replacing it"},
+		},
+		Required: []string{"path", "content"},
+	}
+
+	hints := sdk.UIToolHints{
+		PreferredPartType: sdk.PartTypeAlert,
+		RenderOptions:     map[string]any{"severity": "info", "messageField": "path"},
+	}
+
+	return newTool("fs_write", "Writes a text file in the workspace, creating parent directories as needed.", params, hints, func(ctx context.Context, params map[string]any) (any, error) {
+		name, err := cleanRootPath(params["path"])
+		if err != nil {
+			return nil, err
+		}
+
+		if name == "." {
+			return nil, errors.New("path must name a file")
+		}
+
+		content, _ := params["content"].(string)
+		if len(content) > f.config.MaxWriteBytes {
+			return nil, fmt.Errorf("content exceeds %d bytes", f.config.MaxWriteBytes)
+		}
+
+		if dir := path.Dir(name); dir != "." {
+			if err := f.root.MkdirAll(dir, 0o755); err != nil {
+				return nil, rootError(err)
+			}
+		}
+
+		flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+		if appendMode, _ := params["append"].(bool); appendMode {
+			flags = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+		}
+
+		file, err := f.root.OpenFile(name, flags, 0o644)
+		if err != nil {
+			return nil, rootError(err)
+		}
+
+		if _, err := file.WriteString(content); err != nil {
+			file.Close()
+
+			return nil, err
+		}
+
+		if err := file.Close(); err != nil {
+			return nil, err
+		}
+
+		return map[string]any{"path": name, "bytes_written": len(content)}, nil
+	})
+}
+
+// cleanRootPath turns a model-supplied path into a path relative to the root.
+// Leading slashes are treated as the root itself.
+func cleanRootPath(value any) (string, error) {
+	name, _ := value.(string)
+
+	name = path.Clean("/" + strings.ReplaceAll(name, "\\", "/"))
+	name = strings.TrimPrefix(name, "/")
+
+	if name == "" {
+		return ".", nil
+	}
+
+	if !fs.ValidPath(name) {
+		return "", fmt.Errorf("%w: %s", ErrPathOutsideRoot, name)
+	}
+
+	return name, nil
+}
+
+// rootError reports escapes through symlinks as ErrPathOutsideRoot.
+func rootError(err error) error {
+	var pathErr *os.PathError
+	if errors.As(err, &pathErr) && strings.Contains(pathErr.Err.Error(), "escapes") {
+		return fmt.Errorf("%w: %s", ErrPathOutsideRoot, pathErr.Path)
+	}
+
+	return err
+}
@@ -0,0 +1,81 @@
+package std
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	sdk "github.com/xraph/ai-sdk"
+)
+
+func TestFileSystemTools(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+
+	if err := os.WriteFile(filepath.Join(outside, "secret.txt"), []byte("secret"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Symlink(filepath.Join(outside, "secret.txt"), filepath.Join(root, "link.txt")); err != nil {
+		t.Fatal(err)
+	}
+
+	config := DefaultFileSystemConfig(root)
+	config.AllowWrite = true
+
+	tools, err := NewFileSystemTools(config)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	registry := sdk.NewToolRegistry(nil, nil)
+	if err := Register(registry, tools...); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	ctx := context.Background()
+
+	if _, err := registry.ExecuteTool(ctx, "fs_write", "", map[string]any{"path": "notes/today.md", "content": "# Today"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	result, err := registry.ExecuteTool(ctx, "fs_read", "", map[string]any{"path": "/notes/today.md"})
+	if err != nil || result.Result.(map[string]any)["content"] != "# Today" {
+		t.Fatalf("expected written content, got %v (%v)", result, err)
+	}
+
+	result, err = registry.ExecuteTool(ctx, "fs_list", "", map[string]any{})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if entries := result.Result.(map[string]any)["entries"].([]map[string]any); len(entries) != 2 || entries[1]["type"] != "directory" {
+		t.Errorf("unexpected entries: %v", entries)
+	}
+
+	// ".." is resolved against the root, so it cannot climb out of it
+	if _, err := registry.ExecuteTool(ctx, "fs_read", "", map[string]any{"path": "../" + filepath.Base(outside) + "/secret.txt"}); err == nil {
+		t.Error("expected path outside root to fail")
+	}
+
+	if _, err := registry.ExecuteTool(ctx, "fs_read", "", map[string]any{"path": "link.txt"}); !errors.Is(err, ErrPathOutsideRoot) {
+		t.Errorf("expected symlink escape to fail, got %v", err)
+	}
+
+	if hints, ok := registry.GetUIHints("fs_list", ""); !ok || hints.PreferredPartType != sdk.PartTypeTable {
+		t.Errorf("expected table UI hints, got %+v", hints)
+	}
+}
+
+func TestFileSystemTools_ReadOnlyByDefault(t *testing.T) {
+	tools, err := NewFileSystemTools(DefaultFileSystemConfig(t.TempDir()))
+	if err != nil || len(tools) != 2 {
+		t.Fatalf("expected read and list tools, got %d (%v)", len(tools), err)
+	}
+
+	if _, err := NewFileSystemTools(DefaultFileSystemConfig(filepath.Join(t.TempDir(), "missing"))); !errors.Is(err, sdk.ErrInvalidConfig) {
+		t.Errorf("expected ErrInvalidConfig for missing root, got %v", err)
+	}
+}
@@ -0,0 +1,263 @@
+package std
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+
+	sdk "github.com/xraph/ai-sdk"
+)
+
+// HTTPFetchConfig configures the HTTP fetch tool.
+type HTTPFetchConfig struct {
+	// AllowedHosts lists hosts that may be fetched. "*.example.com" matches
+	// subdomains. Any public host is allowed when empty.
+	AllowedHosts []string
+
+	// AllowPrivateNetworks allows loopback, private and link-local addresses,
+	// which are blocked by default to prevent requests to internal services.
+	// The proxy settings of the environment are only honored when set.
+	AllowPrivateNetworks bool
+
+	// MaxBytes caps the response body returned to the model
+	MaxBytes int64
+
+	// Timeout bounds each request
+	Timeout time.Duration
+
+	// UserAgent is sent with each request
+	UserAgent string
+
+	// Transport overrides the HTTP transport, mainly for tests. The private
+	// network check only applies to the default transport.
+	Transport http.RoundTripper
+}
+
+// DefaultHTTPFetchConfig returns the default HTTP fetch configuration.
+func DefaultHTTPFetchConfig() HTTPFetchConfig {
+	return HTTPFetchConfig{
+		MaxBytes:  512 * 1024,
+		Timeout:   20 * time.Second,
+		UserAgent: "ai-sdk-fetch/1.0",
+	}
+}
+
+// newFetchTransport returns the default transport of the fetch tool.
+func newFetchTransport(allowPrivateNetworks bool) *http.Transport {
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+
+	transport := &http.Transport{
+		DialContext:         dialer.DialContext,
+		TLSHandshakeTimeout: 10 * time.Second,
+	}
+
+	if allowPrivateNetworks {
+		transport.Proxy = http.ProxyFromEnvironment
+	} else {
+		// Checking the resolved address, not the host name, also covers DNS
+		// names pointing at internal addresses. A proxy would resolve and
+		// dial the target itself, bypassing the check, so none is used.
+		dialer.Control = rejectPrivateAddress
+	}
+
+	return transport
+}
+
+// NewHTTPFetchTool returns a tool that fetches a URL with GET and returns its
+// body, converting HTML to plain text unless raw output is requested.
+func NewHTTPFetchTool(config HTTPFetchConfig) *sdk.ToolDefinition {
+	defaults := DefaultHTTPFetchConfig()
+
+	if config.MaxBytes <= 0 {
+		config.MaxBytes = defaults.MaxBytes
+	}
+
+	if config.Timeout <= 0 {
+		config.Timeout = defaults.Timeout
+	}
+
+	if config.UserAgent == "" {
+		config.UserAgent = defaults.UserAgent
+	}
+
+	transport := config.Transport
+	if transport == nil {
+		transport = newFetchTransport(config.AllowPrivateNetworks)
+	}
+
+	client := &http.Client{
+		Transport: transport,
+		Timeout:   config.Timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 5 {
+				return errors.New("too many redirects")
+			}
+
+			return checkFetchURL(req.URL, config.AllowedHosts)
+		},
+	}
+
+	description := "Fetches a web page or API response over HTTP GET and returns its content. HTML is converted to plain text."
+	if len(config.AllowedHosts) > 0 {
+		description += " Allowed hosts: " + strings.Join(config.AllowedHosts, ", ") + "."
+	}
+
+	params := sdk.ToolParameterSchema{
+		Type: "object",
+		Properties: map[string]sdk.ToolParameterProperty{
+			"url": {Type: "string", Description: "Absolute http or https URL to fetch", Format: "uri"},
+			"raw": {Type: "boolean", Description: "Return HTML unchanged instead of converting it to text"},
+		},
+		Required: []string{"url"},
+	}
+
+	hints := sdk.UIToolHints{
+		PreferredPartType: sdk.PartTypeText,
+		Collapsible:       true,
+		DefaultCollapsed:  true,
+		RenderOptions:     map[string]any{"sourceField": "url", "contentField": "content"},
+	}
+
+	return newTool("http_fetch", description, params, hints, func(ctx context.Context, params map[string]any) (any, error) {
+		rawURL, _ := params["url"].(string)
+		raw, _ := params["raw"].(bool)
+
+		target, err := url.Parse(rawURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid URL: %w", err)
+		}
+
+		if err := checkFetchURL(target, config.AllowedHosts); err != nil {
+			return nil, err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		req.Header.Set("User-Agent", config.UserAgent)
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("fetch failed: %w", err)
+		}
+		defer resp.Body.Close()
+
+		data, err := io.ReadAll(io.LimitReader(resp.Body, config.MaxBytes+1))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response: %w", err)
+		}
+
+		truncated := int64(len(data)) > config.MaxBytes
+		if truncated {
+			data = data[:config.MaxBytes]
+		}
+
+		contentType := resp.Header.Get("Content-Type")
+		content := string(data)
+
+		if mediaType, _, _ := mime.ParseMediaType(contentType); mediaType == "text/html" && !raw {
+			content = HTMLToText(content)
+		}
+
+		result := map[string]any{
+			"url":          resp.Request.URL.String(),
+			"status":       resp.StatusCode,
+			"content_type": contentType,
+			"content":      content,
+		}
+
+		if truncated {
+			result["truncated"] = true
+		}
+
+		return result, nil
+	})
+}
+
+// checkFetchURL verifies the scheme and host of a URL against the allowlist.
+func checkFetchURL(u *url.URL, allowed []string) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%w: unsupported scheme %q", ErrHostNotAllowed, u.Scheme)
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if host == "" {
+		return fmt.Errorf("%w: URL has no host", ErrHostNotAllowed)
+	}
+
+	if len(allowed) == 0 {
+		return nil
+	}
+
+	for _, pattern := range allowed {
+		pattern = strings.ToLower(pattern)
+
+		if suffix, ok := strings.CutPrefix(pattern, "*."); ok {
+			if strings.HasSuffix(host, "."+suffix) {
+				return nil
+			}
+		} else if host == pattern {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %s", ErrHostNotAllowed, host)
+}
+
+// rejectPrivateAddress is a dialer control function refusing connections to
+// loopback, private, link-local and unspecified addresses.
+func rejectPrivateAddress(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified() {
+		return fmt.Errorf("%w: %s is a private address", ErrHostNotAllowed, host)
+	}
+
+	return nil
+}
+
+var (
+	htmlHiddenElements = regexp.MustCompile(`(?is)<(script|style|noscript|head|template|svg)\b.*?</\s*(script|style|noscript|head|template|svg)\s*>`)
+	htmlComments       = regexp.MustCompile(`(?s)<!--.*?-->`)
+	htmlBlockTags      = regexp.MustCompile(`(?i)</?(p|div|br|hr|h[1-6]|li|ul|ol|tr|table|section|article|header|footer|nav|main|aside|blockquote|pre|dt|dd|figure|figcaption)\b[^>]*>`)
+	htmlTags           = regexp.MustCompile(`(?s)<[^>]*>`)
+	htmlSpaces         = regexp.MustCompile(`[ \t\f\r\v\x{00a0}]+`)
+	htmlBlankLines     = regexp.MustCompile(`\n\s*\n+`)
+)
+
+// HTMLToText converts an HTML document to readable plain text: scripts,
+// styles and markup are removed, block elements become line breaks and
+// entities are decoded.
+func HTMLToText(document string) string {
+	text := htmlComments.ReplaceAllString(document, "")
+	text = htmlHiddenElements.ReplaceAllString(text, "")
+	text = htmlBlockTags.ReplaceAllString(text, "\n")
+	text = htmlTags.ReplaceAllString(text, "")
+	text = html.UnescapeString(text)
+	text = htmlSpaces.ReplaceAllString(text, " ")
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+
+	text = htmlBlankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")
+
+	return strings.TrimSpace(text)
+}
@@ -0,0 +1,94 @@
+package std
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHTMLToText(t *testing.T) {
+	page := `<html><head><title>T</title><style>p{}</style></head><body>
+<h1>Title</h1><script>alert(1)</script><p>Fish &amp; chips</p><!-- hidden --><ul><li>one</li><li>two</li></ul></body></html>`
+
+	expected := "Title\n\nFish & chips\n\none\n\ntwo"
+	if text := HTMLToText(page); text != expected {
+		t.Errorf("expected %q, got %q", expected, text)
+	}
+}
+
+func TestHTTPFetchTool(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/page":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_, _ = w.Write([]byte("<p>Hello <b>world</b></p>"))
+		case "/away":
+			http.Redirect(w, r, "https://evil.example.com/", http.StatusFound)
+		default:
+			_, _ = w.Write([]byte(strings.Repeat("a", 100)))
+		}
+	}))
+	defer server.Close()
+
+	config := DefaultHTTPFetchConfig()
+	config.AllowPrivateNetworks = true
+	config.AllowedHosts = []string{"127.0.0.1"}
+	config.MaxBytes = 64
+
+	tool := NewHTTPFetchTool(config)
+
+	result, err := tool.Handler(context.Background(), map[string]any{"url": server.URL + "/page"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if output := result.(map[string]any); output["content"] != "Hello world" || output["status"] != http.StatusOK {
+		t.Errorf("unexpected output: %v", output)
+	}
+
+	result, err = tool.Handler(context.Background(), map[string]any{"url": server.URL + "/big"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if output := result.(map[string]any); output["content"] != strings.Repeat("a", 64) || output["truncated"] != true {
+		t.Errorf("expected truncated content, got %v", output)
+	}
+
+	if _, err := tool.Handler(context.Background(), map[string]any{"url": server.URL + "/away"}); !errors.Is(err, ErrHostNotAllowed) {
+		t.Errorf("expected redirect off the allowlist to fail, got %v", err)
+	}
+
+	if _, err := tool.Handler(context.Background(), map[string]any{"url": "https://example.org/"}); !errors.Is(err, ErrHostNotAllowed) {
+		t.Errorf("expected host outside allowlist to fail, got %v", err)
+	}
+
+	if _, err := tool.Handler(context.Background(), map[string]any{"url": "file:///etc/passwd"}); !errors.Is(err, ErrHostNotAllowed) {
+		t.Errorf("expected file scheme to fail, got %v", err)
+	}
+}
+
+func TestHTTPFetchTool_BlocksPrivateNetworks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	tool := NewHTTPFetchTool(DefaultHTTPFetchConfig())
+
+	if _, err := tool.Handler(context.Background(), map[string]any{"url": server.URL}); !errors.Is(err, ErrHostNotAllowed) {
+		t.Errorf("expected loopback address to be blocked, got %v", err)
+	}
+}
+
+func TestHTTPFetchTool_IgnoresProxyByDefault(t *testing.T) {
+	// A proxy would dial private targets itself, past the address check
+	if transport := newFetchTransport(false); transport.Proxy != nil {
+		t.Error("expected no proxy when private networks are blocked")
+	}
+
+	if transport := newFetchTransport(true); transport.Proxy == nil {
+		t.Error("expected the environment proxy when private networks are allowed")
+	}
+}
@@ -0,0 +1,481 @@
+package std
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	sdk "github.com/xraph/ai-sdk"
+)
+
+// JSONQueryConfig configures the JSON query tool.
+type JSONQueryConfig struct {
+	// MaxResults caps the number of matches returned
+	MaxResults int
+}
+
+// NewJSONQueryTool returns a tool that selects values from a JSON document
+// with a JSONPath query.
+func NewJSONQueryTool(config JSONQueryConfig) *sdk.ToolDefinition {
+	if config.MaxResults <= 0 {
+		config.MaxResults = 100
+	}
+
+	params := sdk.ToolParameterSchema{
+		Type: "object",
+		Properties: map[string]sdk.ToolParameterProperty{
+			"document": {Description: "JSON document, either as a value or as a JSON-encoded string"},
+			"query":    {Type: "string", Description: "JSONPath query, e.g. \"$.items[?(@.price < 10)].name\""},
+		},
+		Required: []string{"document", "query"},
+	}
+
+	hints := sdk.UIToolHints{
+		PreferredPartType: sdk.PartTypeJSON,
+		Collapsible:       true,
+		RenderOptions:     map[string]any{"dataField": "matches"},
+	}
+
+	description := "Selects values from a JSON document with JSONPath. Supports $, .name, ['name'], [0], [-1], [*], .., " +
+		"slices [start:end:step], unions [0,2] and filters [?(@.field > 1 && @.tag == 'x')]."
+
+	return newTool("json_query", description, params, hints, func(ctx context.Context, params map[string]any) (any, error) {
+		document := params["document"]
+		if text, ok := document.(string); ok {
+			if err := json.Unmarshal([]byte(text), &document); err != nil {
+				return nil, fmt.Errorf("document is not valid JSON: %w", err)
+			}
+		}
+
+		query, _ := params["query"].(string)
+
+		matches, err := QueryJSON(document, query)
+		if err != nil {
+			return nil, err
+		}
+
+		result := map[string]any{"count": len(matches), "matches": matches[:min(len(matches), config.MaxResults)]}
+		if len(matches) > config.MaxResults {
+			result["truncated"] = true
+		}
+
+		return result, nil
+	})
+}
+
+// QueryJSON evaluates a JSONPath query against a decoded JSON document and
+// returns the matching values in document order.
+func QueryJSON(document any, query string) ([]any, error) {
+	segments, err := parseJSONPath(query)
+	if err != nil {
+		return nil, err
+	}
+
+	q := &jsonPathQuery{root: document, evaluator: sdk.NewExpressionEvaluator(sdk.WithExpressionMaxSteps(10000))}
+
+	nodes := []any{document}
+
+	for _, segment := range segments {
+		if segment.recursive {
+			var all []any
+			for _, node := range nodes {
+				all = appendDescendants(all, node)
+			}
+
+			nodes = all
+		}
+
+		var next []any
+
+		for _, node := range nodes {
+			for _, sel := range segment.selectors {
+				matched, err := q.apply(sel, node)
+				if err != nil {
+					return nil, err
+				}
+
+				next = append(next, matched...)
+			}
+		}
+
+		nodes = next
+	}
+
+	if nodes == nil {
+		nodes = []any{}
+	}
+
+	return nodes, nil
+}
+
+type jsonPathSegment struct {
+	recursive bool
+	selectors []jsonPathSelector
+}
+
+type jsonPathSelectorKind int
+
+const (
+	selectName jsonPathSelectorKind = iota
+	selectIndex
+	selectWildcard
+	selectSlice
+	selectFilter
+)
+
+type jsonPathSelector struct {
+	kind   jsonPathSelectorKind
+	name   string
+	index  int
+	slice  [3]*int
+	filter string
+}
+
+// parseJSONPath splits a query into segments of selectors.
+func parseJSONPath(query string) ([]jsonPathSegment, error) {
+	query = strings.TrimSpace(query)
+	if !strings.HasPrefix(query, "$") {
+		return nil, fmt.Errorf("invalid JSONPath %q: must start with $", query)
+	}
+
+	var segments []jsonPathSegment
+
+	for i := 1; i < len(query); {
+		var segment jsonPathSegment
+
+		switch {
+		case strings.HasPrefix(query[i:], ".."):
+			segment.recursive = true
+			i += 2
+		case query[i] == '.':
+			i++
+		case query[i] == '[':
+		default:
+			return nil, fmt.Errorf("invalid JSONPath %q: unexpected %q at %d", query, query[i], i)
+		}
+
+		if i >= len(query) {
+			return nil, fmt.Errorf("invalid JSONPath %q: ends with a dot", query)
+		}
+
+		if query[i] == '[' {
+			end, err := matchingBracket(query, i)
+			if err != nil {
+				return nil, err
+			}
+
+			if segment.selectors, err = parseBracket(query[i+1 : end]); err != nil {
+				return nil, fmt.Errorf("invalid JSONPath %q: %w", query, err)
+			}
+
+			i = end + 1
+		} else {
+			end := i
+			for end < len(query) && query[end] != '.' && query[end] != '[' {
+				end++
+			}
+
+			name := query[i:end]
+			if name == "" {
+				return nil, fmt.Errorf("invalid JSONPath %q: empty name at %d", query, i)
+			}
+
+			if name == "*" {
+				segment.selectors = []jsonPathSelector{{kind: selectWildcard}}
+			} else {
+				segment.selectors = []jsonPathSelector{{kind: selectName, name: name}}
+			}
+
+			i = end
+		}
+
+		segments = append(segments, segment)
+	}
+
+	return segments, nil
+}
+
+// matchingBracket finds the ']' closing the '[' at start, skipping quoted
+// strings and nested brackets inside filters.
+func matchingBracket(query string, start int) (int, error) {
+	depth := 0
+
+	var quote byte
+
+	for i := start; i < len(query); i++ {
+		c := query[i]
+
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+			if depth == 0 {
+				return i, nil
+			}
+		}
+	}
+
+	return 0, fmt.Errorf("invalid JSONPath %q: unclosed bracket at %d", query, start)
+}
+
+func parseBracket(content string) ([]jsonPathSelector, error) {
+	content = strings.TrimSpace(content)
+
+	if filter, ok := strings.CutPrefix(content, "?"); ok {
+		filter = strings.TrimSpace(filter)
+		if strings.HasPrefix(filter, "(") && strings.HasSuffix(filter, ")") {
+			filter = filter[1 : len(filter)-1]
+		}
+
+		return []jsonPathSelector{{kind: selectFilter, filter: rewriteFilter(filter)}}, nil
+	}
+
+	var selectors []jsonPathSelector
+
+	for _, part := range splitUnion(content) {
+		part = strings.TrimSpace(part)
+
+		switch {
+		case part == "*":
+			selectors = append(selectors, jsonPathSelector{kind: selectWildcard})
+		case len(part) >= 2 && (part[0] == '\'' || part[0] == '"') && part[len(part)-1] == part[0]:
+			name := part[1 : len(part)-1]
+			if part[0] == '\'' {
+				name = strings.ReplaceAll(name, `\'`, `'`)
+			} else if unquoted, err := strconv.Unquote(part); err == nil {
+				name = unquoted
+			}
+
+			selectors = append(selectors, jsonPathSelector{kind: selectName, name: name})
+		case strings.Contains(part, ":"):
+			sel := jsonPathSelector{kind: selectSlice}
+
+			bounds := strings.Split(part, ":")
+			if len(bounds) > 3 {
+				return nil, fmt.Errorf("invalid slice %q", part)
+			}
+
+			for i, bound := range bounds {
+				if bound = strings.TrimSpace(bound); bound == "" {
+					continue
+				}
+
+				n, err := strconv.Atoi(bound)
+				if err != nil {
+					return nil, fmt.Errorf("invalid slice %q", part)
+				}
+
+				sel.slice[i] = &n
+			}
+
+			selectors = append(selectors, sel)
+		default:
+			n, err := strconv.Atoi(part)
+			if err != nil {
+				return nil, fmt.Errorf("invalid selector %q", part)
+			}
+
+			selectors = append(selectors, jsonPathSelector{kind: selectIndex, index: n})
+		}
+	}
+
+	return selectors, nil
+}
+
+// splitUnion splits a bracket on commas outside quotes.
+func splitUnion(content string) []string {
+	var (
+		parts []string
+		quote byte
+		start int
+	)
+
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == ',':
+			parts = append(parts, content[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(parts, content[start:])
+}
+
+// rewriteFilter maps the JSONPath names @ and $ to variables of the
+// expression language.
+func rewriteFilter(filter string) string {
+	var (
+		b     strings.Builder
+		quote rune
+	)
+
+	for _, r := range filter {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+
+			b.WriteRune(r)
+		case r == '\'' || r == '"':
+			quote = r
+
+			b.WriteRune(r)
+		case r == '@':
+			b.WriteString("current")
+		case r == '$':
+			b.WriteString("root")
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
+
+type jsonPathQuery struct {
+	root      any
+	evaluator *sdk.ExpressionEvaluator
+}
+
+func (q *jsonPathQuery) apply(sel jsonPathSelector, node any) ([]any, error) {
+	switch sel.kind {
+	case selectName:
+		if object, ok := node.(map[string]any); ok {
+			if value, exists := object[sel.name]; exists {
+				return []any{value}, nil
+			}
+		}
+	case selectIndex:
+		if list, ok := node.([]any); ok {
+			i := sel.index
+			if i < 0 {
+				i += len(list)
+			}
+
+			if i >= 0 && i < len(list) {
+				return []any{list[i]}, nil
+			}
+		}
+	case selectWildcard:
+		return children(node), nil
+	case selectSlice:
+		if list, ok := node.([]any); ok {
+			return sliceList(list, sel.slice), nil
+		}
+	case selectFilter:
+		var matched []any
+
+		for _, child := range children(node) {
+			ok, err := q.evaluator.EvaluateCondition(sel.filter, map[string]any{"current": child, "root": q.root})
+			if err != nil {
+				return nil, fmt.Errorf("invalid filter %q: %w", sel.filter, err)
+			}
+
+			if ok {
+				matched = append(matched, child)
+			}
+		}
+
+		return matched, nil
+	}
+
+	return nil, nil
+}
+
+// children returns the elements of a list or the values of an object in key order.
+func children(node any) []any {
+	switch v := node.(type) {
+	case []any:
+		return v
+	case map[string]any:
+		out := make([]any, 0, len(v))
+		for _, key := range slices.Sorted(maps.Keys(v)) {
+			out = append(out, v[key])
+		}
+
+		return out
+	}
+
+	return nil
+}
+
+// appendDescendants appends a node and all nodes below it.
+func appendDescendants(out []any, node any) []any {
+	out = append(out, node)
+
+	for _, child := range children(node) {
+		out = appendDescendants(out, child)
+	}
+
+	return out
+}
+
+func sliceList(list []any, bounds [3]*int) []any {
+	n := len(list)
+
+	step := 1
+	if bounds[2] != nil {
+		step = *bounds[2]
+	}
+
+	if step == 0 {
+		return nil
+	}
+
+	normalize := func(bound *int, fallback int) int {
+		if bound == nil {
+			return fallback
+		}
+
+		i := *bound
+		if i < 0 {
+			i += n
+		}
+
+		return i
+	}
+
+	var out []any
+
+	if step > 0 {
+		start := max(normalize(bounds[0], 0), 0)
+		end := min(normalize(bounds[1], n), n)
+
+		for i := start; i < end; i += step {
+			out = append(out, list[i])
+		}
+	} else {
+		start := min(normalize(bounds[0], n-1), n-1)
+		end := max(normalize(bounds[1], -n-1), -1)
+
+		for i := start; i > end; i += step {
+			out = append(out, list[i])
+		}
+	}
+
+	return out
+}
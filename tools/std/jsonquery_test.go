@@ -0,0 +1,73 @@
+package std
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+const storeJSON = `{
+  "store": {
+    "books": [
+      {"title": "Dune", "price": 9.5, "tags": ["scifi"]},
+      {"title": "Emma", "price": 12, "tags": ["classic"]},
+      {"title": "Ubik", "price": 7, "tags": ["scifi", "short"]}
+    ],
+    "bicycle": {"color": "red", "price": 120}
+  }
+}`
+
+func TestQueryJSON(t *testing.T) {
+	var doc any
+	if err := json.Unmarshal([]byte(storeJSON), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string][]any{
+		"$.store.books[0].title":                            {"Dune"},
+		"$.store.books[-1].title":                           {"Ubik"},
+		"$['store']['bicycle'].color":                       {"red"},
+		"$.store.books[*].title":                            {"Dune", "Emma", "Ubik"},
+		"$.store.books[0,2].title":                          {"Dune", "Ubik"},
+		"$.store.books[1:].title":                           {"Emma", "Ubik"},
+		"$.store.books[::-1].title":                         {"Ubik", "Emma", "Dune"},
+		"$.store.books[?(@.price < 10)].title":              {"Dune", "Ubik"},
+		"$.store.books[?(contains(@.tags, 'short'))].title": {"Ubik"},
+		"$..price":        {120.0, 9.5, 12.0, 7.0},
+		"$.store.missing": {},
+	}
+
+	for query, expected := range cases {
+		matches, err := QueryJSON(doc, query)
+		if err != nil {
+			t.Errorf("%s: expected no error, got %v", query, err)
+
+			continue
+		}
+
+		if !reflect.DeepEqual(matches, expected) {
+			t.Errorf("%s: expected %v, got %v", query, expected, matches)
+		}
+	}
+
+	for _, query := range []string{"store.books", "$.store[", "$.books[abc]", "$."} {
+		if _, err := QueryJSON(doc, query); err == nil {
+			t.Errorf("%s: expected error", query)
+		}
+	}
+}
+
+func TestJSONQueryTool(t *testing.T) {
+	tool := NewJSONQueryTool(JSONQueryConfig{MaxResults: 2})
+
+	result, err := tool.Handler(context.Background(), map[string]any{"document": storeJSON, "query": "$.store.books[*].price"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	output := result.(map[string]any)
+	if output["count"] != 3 || len(output["matches"].([]any)) != 2 || output["truncated"] != true {
+		t.Errorf("unexpected output: %v", output)
+	}
+}
@@ -0,0 +1,255 @@
+package std
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	sdk "github.com/xraph/ai-sdk"
+)
+
+// SQLConfig configures the SQL query tool.
+type SQLConfig struct {
+	// DB is the database to query
+	DB *sql.DB
+
+	// Schema describes the tables the model may query; it is added to the
+	// tool description
+	Schema string
+
+	// MaxRows caps the rows returned
+	MaxRows int
+
+	// Timeout bounds each query
+	Timeout time.Duration
+}
+
+// sqlWriteKeywords are rejected anywhere in a statement outside string literals
+// and quoted identifiers.
+var sqlWriteKeywords = map[string]bool{
+	"INSERT": true, "UPDATE": true, "DELETE": true, "MERGE": true, "UPSERT": true,
+	"CREATE": true, "ALTER": true, "DROP": true, "TRUNCATE": true, "RENAME": true,
+	"GRANT": true, "REVOKE": true, "COPY": true, "ATTACH": true, "DETACH": true,
+	"PRAGMA": true, "VACUUM": true, "CALL": true, "EXEC": true, "EXECUTE": true,
+	"LOCK": true, "SET": true, "INTO": true,
+}
+
+// sqlReadKeywords are the statements a query may start with.
+var sqlReadKeywords = map[string]bool{"SELECT": true, "WITH": true, "VALUES": true, "EXPLAIN": true, "SHOW": true, "DESCRIBE": true}
+
+// NewSQLQueryTool returns a tool that runs read-only SQL queries. Statements
+// must be a single SELECT-like query without write keywords, and they run in a
+// read-only transaction that is always rolled back. Use a database user
+// without write privileges as well when the database holds important data.
+func NewSQLQueryTool(config SQLConfig) (*sdk.ToolDefinition, error) {
+	if config.DB == nil {
+		return nil, fmt.Errorf("%w: database handle is required", sdk.ErrInvalidConfig)
+	}
+
+	if config.MaxRows <= 0 {
+		config.MaxRows = 200
+	}
+
+	if config.Timeout <= 0 {
+		config.Timeout = 30 * time.Second
+	}
+
+	description := "Runs a read-only SQL query and returns the resulting rows."
+	if config.Schema != "" {
+		description += "\n\nSchema:\n" + config.Schema
+	}
+
+	params := sdk.ToolParameterSchema{
+		Type: "object",
+		Properties: map[string]sdk.ToolParameterProperty{
+			"query": {Type: "string", Description: "A single SELECT statement"},
+			"args":  {Type: "array", Description: "Values for the query placeholders"},
+		},
+		Required: []string{"query"},
+	}
+
+	hints := sdk.UIToolHints{
+		PreferredPartType: sdk.PartTypeTable,
+		Collapsible:       true,
+		RenderOptions:     map[string]any{"columnsField": "columns", "rowsField": "rows"},
+	}
+
+	tool := newTool("sql_query", description, params, hints, func(ctx context.Context, params map[string]any) (any, error) {
+		query, _ := params["query"].(string)
+		args, _ := params["args"].([]any)
+
+		query, err := checkReadOnlySQL(query)
+		if err != nil {
+			return nil, err
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, config.Timeout)
+		defer cancel()
+
+		tx, err := config.DB.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+		if err != nil {
+			return nil, fmt.Errorf("failed to begin read-only transaction: %w", err)
+		}
+
+		defer func() { _ = tx.Rollback() }()
+
+		rows, err := tx.QueryContext(ctx, query, args...)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+
+		return collectRows(rows, config.MaxRows)
+	})
+
+	tool.Timeout = config.Timeout + 5*time.Second
+
+	return tool, nil
+}
+
+func collectRows(rows *sql.Rows, maxRows int) (map[string]any, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]map[string]any, 0)
+	truncated := false
+
+	for rows.Next() {
+		if len(out) >= maxRows {
+			truncated = true
+
+			break
+		}
+
+		values := make([]any, len(columns))
+		pointers := make([]any, len(columns))
+
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, err
+		}
+
+		row := make(map[string]any, len(columns))
+
+		for i, column := range columns {
+			switch v := values[i].(type) {
+			case []byte:
+				if utf8.Valid(v) {
+					row[column] = string(v)
+				} else {
+					row[column] = v
+				}
+			case time.Time:
+				row[column] = v.Format(time.RFC3339Nano)
+			default:
+				row[column] = v
+			}
+		}
+
+		out = append(out, row)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	result := map[string]any{"columns": columns, "rows": out, "row_count": len(out)}
+	if truncated {
+		result["truncated"] = true
+	}
+
+	return result, nil
+}
+
+// checkReadOnlySQL accepts a single read-only statement and returns it
+// without a trailing semicolon.
+func checkReadOnlySQL(query string) (string, error) {
+	query = strings.TrimSpace(query)
+	query = strings.TrimSpace(strings.TrimSuffix(query, ";"))
+
+	words, err := sqlKeywords(query)
+	if err != nil {
+		return "", err
+	}
+
+	if len(words) == 0 || !sqlReadKeywords[words[0]] {
+		return "", fmt.Errorf("%w: only SELECT queries are allowed", ErrWriteNotAllowed)
+	}
+
+	for _, word := range words {
+		if word == ";" {
+			return "", fmt.Errorf("%w: only a single statement is allowed", ErrWriteNotAllowed)
+		}
+
+		if sqlWriteKeywords[word] {
+			return "", fmt.Errorf("%w: %s is not allowed", ErrWriteNotAllowed, word)
+		}
+	}
+
+	return query, nil
+}
+
+// sqlKeywords returns the upper-cased words of a statement outside string
+// literals, quoted identifiers and comments, plus any semicolons.
+func sqlKeywords(query string) ([]string, error) {
+	var words []string
+
+	runes := []rune(query)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+
+		switch {
+		case r == '\'' || r == '"' || r == '`' || r == '[':
+			closing := r
+			if r == '[' {
+				closing = ']'
+			}
+
+			i++
+			for i < len(runes) && runes[i] != closing {
+				i++
+			}
+
+			if i >= len(runes) {
+				return nil, fmt.Errorf("%w: unterminated quote", ErrWriteNotAllowed)
+			}
+
+			i++
+		case r == '-' && i+1 < len(runes) && runes[i+1] == '-':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+		case r == '/' && i+1 < len(runes) && runes[i+1] == '*':
+			end := strings.Index(string(runes[i+2:]), "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("%w: unterminated comment", ErrWriteNotAllowed)
+			}
+
+			i += 2 + len([]rune(string(runes[i+2:])[:end])) + 2
+		case r == ';':
+			words = append(words, ";")
+			i++
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+
+			words = append(words, strings.ToUpper(string(runes[start:i])))
+		default:
+			i++
+		}
+	}
+
+	return words, nil
+}
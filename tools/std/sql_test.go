@@ -0,0 +1,122 @@
+package std
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"testing"
+
+	sdk "github.com/xraph/ai-sdk"
+)
+
+// fakeDriver serves fixed rows and records how transactions were opened.
+type fakeDriver struct {
+	readOnly  bool
+	committed bool
+	queries   []string
+}
+
+func (d *fakeDriver) Open(string) (driver.Conn, error) { return &fakeConn{d: d}, nil }
+
+type fakeConn struct{ d *fakeDriver }
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c *fakeConn) Close() error                        { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error)           { return nil, errors.New("use BeginTx") }
+
+func (c *fakeConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	c.d.readOnly = opts.ReadOnly
+
+	return &fakeTx{d: c.d}, nil
+}
+
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.d.queries = append(c.d.queries, query)
+
+	return &fakeRows{rows: [][]driver.Value{{int64(1), []byte("Ada")}, {int64(2), []byte("Grace")}, {int64(3), []byte("Linus")}}}, nil
+}
+
+type fakeTx struct{ d *fakeDriver }
+
+func (t *fakeTx) Commit() error   { t.d.committed = true; return nil }
+func (t *fakeTx) Rollback() error { return nil }
+
+type fakeRows struct {
+	rows [][]driver.Value
+	i    int
+}
+
+func (r *fakeRows) Columns() []string { return []string{"id", "name"} }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.i >= len(r.rows) {
+		return io.EOF
+	}
+
+	copy(dest, r.rows[r.i])
+	r.i++
+
+	return nil
+}
+
+func TestSQLQueryTool(t *testing.T) {
+	fake := &fakeDriver{}
+	sql.Register("std-fake", fake)
+
+	db, err := sql.Open("std-fake", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	tool, err := NewSQLQueryTool(SQLConfig{DB: db, MaxRows: 2, Schema: "users(id, name)"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	result, err := tool.Handler(context.Background(), map[string]any{"query": "SELECT id, name FROM users -- all of them\n;"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	output := result.(map[string]any)
+	rows := output["rows"].([]map[string]any)
+
+	if len(rows) != 2 || rows[1]["name"] != "Grace" || output["truncated"] != true {
+		t.Errorf("unexpected output: %v", output)
+	}
+
+	if !fake.readOnly || fake.committed {
+		t.Errorf("expected a read-only transaction that is rolled back, got readOnly=%v committed=%v", fake.readOnly, fake.committed)
+	}
+
+	rejected := []string{
+		"DELETE FROM users",
+		"SELECT 1; DROP TABLE users",
+		"WITH gone AS (DELETE FROM users RETURNING *) SELECT * FROM gone",
+		"SELECT * INTO backup FROM users",
+		"select 1 /* unterminated",
+	}
+
+	for _, query := range rejected {
+		if _, err := tool.Handler(context.Background(), map[string]any{"query": query}); !errors.Is(err, ErrWriteNotAllowed) {
+			t.Errorf("%q: expected ErrWriteNotAllowed, got %v", query, err)
+		}
+	}
+
+	allowed := "SELECT name FROM users WHERE note = 'drop table; delete'"
+	if _, err := tool.Handler(context.Background(), map[string]any{"query": allowed}); err != nil {
+		t.Errorf("expected keywords in strings to be ignored, got %v", err)
+	}
+
+	if len(fake.queries) != 2 {
+		t.Errorf("expected rejected queries never to reach the database, got %v", fake.queries)
+	}
+
+	if _, err := NewSQLQueryTool(SQLConfig{}); !errors.Is(err, sdk.ErrInvalidConfig) {
+		t.Errorf("expected ErrInvalidConfig without a database, got %v", err)
+	}
+}
@@ -0,0 +1,85 @@
+// Package std provides ready-made tools for common agent tasks: fetching web
+// pages, reading and writing files under a root directory, evaluating
+// arithmetic, converting time zones, querying JSON and running read-only SQL.
+//
+// Every constructor returns an *sdk.ToolDefinition carrying UI hints in its
+// metadata, so the tools can be registered in an sdk.ToolRegistry directly or
+// wrapped with UITool for an sdk.UIToolRegistry.
+package std
+
+import (
+	"errors"
+
+	sdk "github.com/xraph/ai-sdk"
+)
+
+var (
+	// ErrHostNotAllowed is returned when a URL's host is not on the allowlist
+	// or resolves to a private address.
+	ErrHostNotAllowed = errors.New("host not allowed")
+
+	// ErrPathOutsideRoot is returned when a path escapes the configured root directory.
+	ErrPathOutsideRoot = errors.New("path outside root directory")
+
+	// ErrWriteNotAllowed is returned when a SQL statement is not read-only.
+	ErrWriteNotAllowed = errors.New("statement is not read-only")
+)
+
+// Register adds tools to a registry, keeping their UI hints.
+func Register(registry *sdk.ToolRegistry, tools ...*sdk.ToolDefinition) error {
+	for _, tool := range tools {
+		if err := registry.RegisterTool(tool); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// UITool wraps a tool so it can be registered in an sdk.UIToolRegistry.
+func UITool(tool *sdk.ToolDefinition) sdk.UITool {
+	hints, _ := tool.Metadata["ui_hints"].(sdk.UIToolHints)
+
+	return sdk.NewBaseUITool(sdk.BaseUIToolConfig{
+		Name:        tool.Name,
+		Description: tool.Description,
+		Version:     tool.Version,
+		Parameters:  tool.Parameters,
+		Hints:       hints,
+		Handler:     tool.Handler,
+	})
+}
+
+// newTool builds a tool definition marked as a UI tool.
+func newTool(name, description string, params sdk.ToolParameterSchema, hints sdk.UIToolHints, handler sdk.ToolHandler) *sdk.ToolDefinition {
+	return &sdk.ToolDefinition{
+		Name:        name,
+		Description: description,
+		Category:    "std",
+		Tags:        []string{"std"},
+		Parameters:  params,
+		Handler:     handler,
+		Metadata: map[string]any{
+			"ui_tool":  true,
+			"ui_hints": hints,
+		},
+	}
+}
+
+// intParam reads an optional integer argument, which arrives as float64 from JSON.
+func intParam(params map[string]any, name string, fallback int) int {
+	switch v := params[name].(type) {
+	case float64:
+		return int(v)
+	case int:
+		return v
+	case int64:
+		return int(v)
+	}
+
+	return fallback
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,134 @@
+package std
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	sdk "github.com/xraph/ai-sdk"
+)
+
+// TimeConfig configures the time tool.
+type TimeConfig struct {
+	// DefaultTimezone is used when the model does not name one; defaults to UTC
+	DefaultTimezone string
+
+	// Now returns the current time; defaults to time.Now
+	Now func() time.Time
+}
+
+// timeLayouts are the input formats accepted for conversions.
+var timeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04",
+	time.DateOnly,
+}
+
+// NewTimeTool returns a tool that reports the current time in a timezone and
+// converts times between IANA timezones.
+func NewTimeTool(config TimeConfig) *sdk.ToolDefinition {
+	if config.DefaultTimezone == "" {
+		config.DefaultTimezone = "UTC"
+	}
+
+	if config.Now == nil {
+		config.Now = time.Now
+	}
+
+	params := sdk.ToolParameterSchema{
+		Type: "object",
+		Properties: map[string]sdk.ToolParameterProperty{
+			"operation": {Type: "string", Description: "\"now\" for the current time, \"convert\" to convert a time between timezones", Enum: []string{"now", "convert"}, Default: "now"},
+			"timezone":  {Type: "string", Description: "IANA timezone of the result, e.g. \"Europe/Paris\""},
+			"time":      {Type: "string", Description: "Time to convert, e.g. \"2024-05-01 14:30\" or RFC 3339"},
+			"from_timezone": {
+				Type:        "string",
+				Description: "IANA timezone of the input time when it has no UTC offset",
+			},
+		},
+	}
+
+	hints := sdk.UIToolHints{
+		PreferredPartType: sdk.PartTypeCard,
+		RenderOptions:     map[string]any{"titleField": "time", "subtitleField": "timezone"},
+	}
+
+	return newTool("time", "Returns the current date and time, or converts a time between timezones.", params, hints, func(ctx context.Context, params map[string]any) (any, error) {
+		operation, _ := params["operation"].(string)
+
+		target, err := loadLocation(params["timezone"], config.DefaultTimezone)
+		if err != nil {
+			return nil, err
+		}
+
+		var t time.Time
+
+		switch operation {
+		case "", "now":
+			t = config.Now()
+		case "convert":
+			source, err := loadLocation(params["from_timezone"], config.DefaultTimezone)
+			if err != nil {
+				return nil, err
+			}
+
+			input, _ := params["time"].(string)
+			if t, err = parseTime(strings.TrimSpace(input), source); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("unknown operation %q", operation)
+		}
+
+		return describeTime(t.In(target)), nil
+	})
+}
+
+func loadLocation(value any, fallback string) (*time.Location, error) {
+	name, _ := value.(string)
+	if name == "" {
+		name = fallback
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown timezone %q", name)
+	}
+
+	return loc, nil
+}
+
+func parseTime(input string, loc *time.Location) (time.Time, error) {
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, input, loc); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("cannot parse time %q, use RFC 3339 or \"2006-01-02 15:04\"", input)
+}
+
+func describeTime(t time.Time) map[string]any {
+	name, offset := t.Zone()
+
+	return map[string]any{
+		"time":       t.Format(time.RFC3339),
+		"timezone":   t.Location().String(),
+		"abbrev":     name,
+		"utc_offset": fmt.Sprintf("%+03d:%02d", offset/3600, abs(offset%3600)/60),
+		"weekday":    t.Weekday().String(),
+		"unix":       t.Unix(),
+	}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+
+	return n
+}
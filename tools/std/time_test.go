@@ -0,0 +1,39 @@
+package std
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestTimeTool(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	tool := NewTimeTool(TimeConfig{Now: func() time.Time { return now }})
+
+	result, err := tool.Handler(context.Background(), map[string]any{"timezone": "Asia/Kolkata"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if output := result.(map[string]any); output["time"] != "2024-03-10T17:30:00+05:30" || output["utc_offset"] != "+05:30" || output["weekday"] != "Sunday" {
+		t.Errorf("unexpected output: %v", output)
+	}
+
+	result, err = tool.Handler(context.Background(), map[string]any{
+		"operation":     "convert",
+		"time":          "2024-07-01 09:00",
+		"from_timezone": "America/New_York",
+		"timezone":      "Europe/Paris",
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if output := result.(map[string]any); output["time"] != "2024-07-01T15:00:00+02:00" {
+		t.Errorf("unexpected conversion: %v", output)
+	}
+
+	if _, err := tool.Handler(context.Background(), map[string]any{"timezone": "Mars/Olympus"}); err == nil {
+		t.Error("expected unknown timezone to fail")
+	}
+}
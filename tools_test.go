@@ -519,84 +519,85 @@ func TestToolRegistry_ExportSchema(t *testing.T) {
 
 // Test Parameter Validation
 
-func TestToolRegistry_ValidateParameters_String(t *testing.T) {
-	registry := NewToolRegistry(nil, nil)
+// validateProperty validates a value as the only argument of a tool, through
+// the registry's argument validator.
+func validateProperty(value any, prop ToolParameterProperty) error {
+	tool := &ToolDefinition{
+		Name:       "test_tool",
+		Parameters: ToolParameterSchema{Type: "object", Properties: map[string]ToolParameterProperty{"value": prop}},
+	}
 
+	return NewToolRegistry(nil, nil).validateParameters(tool, map[string]any{"value": value})
+}
+
+func TestToolRegistry_ValidateParameters_String(t *testing.T) {
 	prop := ToolParameterProperty{Type: "string"}
 
-	err := registry.validateParameterType("hello", prop)
+	err := validateProperty("hello", prop)
 	if err != nil {
 		t.Errorf("expected no error for valid string, got %v", err)
 	}
 
-	err = registry.validateParameterType(123, prop)
+	err = validateProperty(123, prop)
 	if err == nil {
 		t.Error("expected error for integer when string expected")
 	}
 }
 
 func TestToolRegistry_ValidateParameters_Integer(t *testing.T) {
-	registry := NewToolRegistry(nil, nil)
-
 	prop := ToolParameterProperty{Type: "integer"}
 
-	err := registry.validateParameterType(42, prop)
+	err := validateProperty(42, prop)
 	if err != nil {
 		t.Errorf("expected no error for valid integer, got %v", err)
 	}
 
-	err = registry.validateParameterType("not an int", prop)
+	err = validateProperty("not an int", prop)
 	if err == nil {
 		t.Error("expected error for string when integer expected")
 	}
 }
 
 func TestToolRegistry_ValidateParameters_Number(t *testing.T) {
-	registry := NewToolRegistry(nil, nil)
-
 	prop := ToolParameterProperty{Type: "number"}
 
-	err := registry.validateParameterType(3.14, prop)
+	err := validateProperty(3.14, prop)
 	if err != nil {
 		t.Errorf("expected no error for valid number, got %v", err)
 	}
 
-	err = registry.validateParameterType(42, prop)
+	err = validateProperty(42, prop)
 	if err != nil {
 		t.Errorf("expected no error for integer as number, got %v", err)
 	}
 }
 
 func TestToolRegistry_ValidateParameters_Boolean(t *testing.T) {
-	registry := NewToolRegistry(nil, nil)
-
 	prop := ToolParameterProperty{Type: "boolean"}
 
-	err := registry.validateParameterType(true, prop)
+	err := validateProperty(true, prop)
 	if err != nil {
 		t.Errorf("expected no error for valid boolean, got %v", err)
 	}
 
-	err = registry.validateParameterType("true", prop)
+	err = validateProperty("true", prop)
 	if err == nil {
 		t.Error("expected error for string when boolean expected")
 	}
 }
 
 func TestToolRegistry_ValidateParameters_Enum(t *testing.T) {
-	registry := NewToolRegistry(nil, nil)
-
 	prop := ToolParameterProperty{
 		Type: "string",
 		Enum: []string{"red", "green", "blue"},
 	}
 
-	err := registry.validateParameterType("red", prop)
+	err := validateProperty("red", prop)
 	if err != nil {
 		t.Errorf("expected no error for valid enum value, got %v", err)
 	}
 
-	err = registry.validateParameterType("yellow", prop)
+	err = validateProperty("yellow", prop)
 	if err == nil {
 		t.Error("expected error for invalid enum value")
 	}
@@ -0,0 +1,488 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TypedTool is a tool backed by a Go function that takes a struct of arguments.
+//
+// The parameter schema is derived from the fields of In:
+//   - the json tag sets the property name; fields tagged "-" and unexported
+//     fields are skipped, and embedded structs are flattened
+//   - the description tag sets the property description
+//   - the jsonschema tag takes comma-separated options: description=...,
+//     enum=... (repeatable, strings only), default=..., format=...,
+//     pattern=..., minimum=..., maximum=..., exclusiveMinimum=...,
+//     exclusiveMaximum=..., multipleOf=..., minLength=..., maxLength=...,
+//     minItems=..., maxItems=..., uniqueItems, required and optional; values
+//     containing commas are single-quoted, doubling any quote inside, e.g.
+//     pattern='^[a-z]{1,3}$'
+//
+// Fields are required unless they are pointers, tagged omitempty, have a
+// default or are marked optional. Arguments from the model are validated
+// against the schema and decoded into In; invalid arguments are reported as a
+// *ToolArgumentError listing every offending field, so the model can correct
+// its call. Results are serialized through encoding/json, so structs become
+// maps keyed by their json names.
+type TypedTool[In, Out any] struct {
+	name        string
+	description string
+	fn          func(context.Context, In) (Out, error)
+	schema      ToolParameterSchema
+	validator   *JSONSchema
+}
+
+// NewTypedTool creates a tool from a function taking a struct of arguments.
+func NewTypedTool[In, Out any](name, description string, fn func(ctx context.Context, in In) (Out, error)) (*TypedTool[In, Out], error) {
+	if name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidToolName)
+	}
+
+	if fn == nil {
+		return nil, errors.New("tool handler is required")
+	}
+
+	inType := reflect.TypeFor[In]()
+	for inType.Kind() == reflect.Pointer {
+		inType = inType.Elem()
+	}
+
+	if inType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: tool %s input must be a struct, got %s", ErrInvalidConfig, name, inType)
+	}
+
+	prop, err := typedSchema(inType, make(map[reflect.Type]bool))
+	if err != nil {
+		return nil, fmt.Errorf("%w: tool %s: %w", ErrInvalidConfig, name, err)
+	}
+
+	schema := ToolParameterSchema{
+		Type:       "object",
+		Properties: prop.Properties,
+		Required:   prop.Required,
+	}
+
+	validator, err := schema.Compile()
+	if err != nil {
+		return nil, fmt.Errorf("%w: tool %s: %w", ErrInvalidConfig, name, err)
+	}
+
+	return &TypedTool[In, Out]{
+		name:        name,
+		description: description,
+		fn:          fn,
+		schema:      schema,
+		validator:   validator,
+	}, nil
+}
+
+// Name returns the tool name.
+func (t *TypedTool[In, Out]) Name() string {
+	return t.name
+}
+
+// Description returns the tool description.
+func (t *TypedTool[In, Out]) Description() string {
+	return t.description
+}
+
+// Schema returns the parameter schema derived from In.
+func (t *TypedTool[In, Out]) Schema() ToolParameterSchema {
+	return t.schema
+}
+
+// Decode validates model arguments against the schema, applies defaults and
+// decodes them into In.
+func (t *TypedTool[In, Out]) Decode(args map[string]any) (In, error) {
+	var in In
+
+	object := ToolParameterProperty{Type: "object", Properties: t.schema.Properties, Required: t.schema.Required}
+	value := applyToolDefaults(dropNullProperties(args), object)
+
+	if err := validateToolArguments(t.name, t.validator, value); err != nil {
+		return in, err
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return in, &ToolArgumentError{Tool: t.name, Fields: []ToolFieldError{{Message: err.Error()}}}
+	}
+
+	if err := json.Unmarshal(data, &in); err != nil {
+		field := ToolFieldError{Message: err.Error()}
+
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			field = ToolFieldError{Path: typeErr.Field, Message: fmt.Sprintf("cannot use %s as %s", typeErr.Value, typeErr.Type)}
+		}
+
+		return in, &ToolArgumentError{Tool: t.name, Fields: []ToolFieldError{field}}
+	}
+
+	return in, nil
+}
+
+// Call decodes args, runs the function and serializes its result.
+func (t *TypedTool[In, Out]) Call(ctx context.Context, args map[string]any) (any, error) {
+	in, err := t.Decode(args)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := t.fn(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+
+	return serializeToolOutput(out)
+}
+
+// Definition returns a ToolDefinition for registration in a ToolRegistry.
+func (t *TypedTool[In, Out]) Definition() *ToolDefinition {
+	return &ToolDefinition{
+		Name:        t.name,
+		Version:     "1.0.0",
+		Description: t.description,
+		Parameters:  t.schema,
+		Handler:     t.Call,
+	}
+}
+
+// AgentTool returns the tool in the form accepted by AgentOptions.Tools.
+func (t *TypedTool[In, Out]) AgentTool() Tool {
+	return Tool{
+		Name:           t.name,
+		Description:    t.description,
+		Parameters:     t.schema.Map(),
+		Handler:        t.Call,
+		selfValidating: true,
+	}
+}
+
+// serializeToolOutput converts a result into its JSON form.
+func serializeToolOutput(out any) (any, error) {
+	data, err := json.Marshal(out)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to serialize result: %w", ErrToolExecutionFailed, err)
+	}
+
+	var result any
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("%w: failed to serialize result: %w", ErrToolExecutionFailed, err)
+	}
+
+	return result, nil
+}
+
+var timeType = reflect.TypeFor[time.Time]()
+
+// typedSchema derives the schema of a Go type.
+func typedSchema(t reflect.Type, seen map[reflect.Type]bool) (ToolParameterProperty, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t == timeType {
+		return ToolParameterProperty{Type: "string", Format: "date-time"}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return ToolParameterProperty{Type: "string"}, nil
+	case reflect.Bool:
+		return ToolParameterProperty{Type: "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return ToolParameterProperty{Type: "integer"}, nil
+	case reflect.Float32, reflect.Float64:
+		return ToolParameterProperty{Type: "number"}, nil
+	case reflect.Interface:
+		return ToolParameterProperty{}, nil
+	case reflect.Slice, reflect.Array:
+		// encoding/json encodes byte slices as base64 strings
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			return ToolParameterProperty{Type: "string", Format: "byte"}, nil
+		}
+
+		items, err := typedSchema(t.Elem(), seen)
+		if err != nil {
+			return ToolParameterProperty{}, err
+		}
+
+		return ToolParameterProperty{Type: "array", Items: &items}, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return ToolParameterProperty{}, fmt.Errorf("map key type %s is not supported", t.Key())
+		}
+
+		return ToolParameterProperty{Type: "object"}, nil
+	case reflect.Struct:
+		if seen[t] {
+			return ToolParameterProperty{}, fmt.Errorf("recursive type %s is not supported", t)
+		}
+
+		seen[t] = true
+		defer delete(seen, t)
+
+		closed := false
+		prop := ToolParameterProperty{
+			Type:                 "object",
+			Properties:           make(map[string]ToolParameterProperty),
+			Required:             make([]string, 0),
+			AdditionalProperties: &closed,
+		}
+
+		if err := addStructFields(&prop, t, seen); err != nil {
+			return ToolParameterProperty{}, err
+		}
+
+		return prop, nil
+	default:
+		return ToolParameterProperty{}, fmt.Errorf("type %s is not supported", t)
+	}
+}
+
+// addStructFields adds the exported fields of a struct to an object schema.
+func addStructFields(prop *ToolParameterProperty, t reflect.Type, seen map[reflect.Type]bool) error {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name, jsonOpts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" && jsonOpts == "" {
+			continue
+		}
+
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+
+		// Embedded structs without a json name are flattened, as in encoding/json
+		if field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+			if err := addStructFields(prop, fieldType, seen); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		fieldProp, err := typedSchema(field.Type, seen)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", field.Name, err)
+		}
+
+		fieldProp.Description = field.Tag.Get("description")
+
+		required := field.Type.Kind() != reflect.Pointer && !slices.Contains(strings.Split(jsonOpts, ","), "omitempty")
+
+		if tag, ok := field.Tag.Lookup("jsonschema"); ok {
+			if required, err = applySchemaTag(&fieldProp, tag, required); err != nil {
+				return fmt.Errorf("field %s: %w", field.Name, err)
+			}
+		}
+
+		prop.Properties[name] = fieldProp
+
+		if required {
+			prop.Required = append(prop.Required, name)
+		}
+	}
+
+	return nil
+}
+
+// applySchemaTag applies jsonschema tag options to a property and returns
+// whether the field is required.
+func applySchemaTag(prop *ToolParameterProperty, tag string, required bool) (bool, error) {
+	options, err := splitSchemaTag(tag)
+	if err != nil {
+		return required, err
+	}
+
+	for _, option := range options {
+		key, value, _ := strings.Cut(strings.TrimSpace(option), "=")
+
+		if strings.HasPrefix(value, "'") {
+			if len(value) < 2 || !strings.HasSuffix(value, "'") {
+				return required, fmt.Errorf("invalid quoted %s %s", key, value)
+			}
+
+			value = strings.ReplaceAll(value[1:len(value)-1], "''", "'")
+		}
+
+		switch key {
+		case "":
+		case "required":
+			required = true
+		case "optional":
+			required = false
+		case "description":
+			prop.Description = value
+		case "format":
+			prop.Format = value
+		case "enum":
+			if prop.Type != "string" {
+				return required, fmt.Errorf("enum is only supported on strings, got %s", prop.Type)
+			}
+
+			prop.Enum = append(prop.Enum, value)
+		case "pattern":
+			prop.Pattern = value
+		case "uniqueItems":
+			prop.UniqueItems = true
+		case "minimum", "maximum", "exclusiveMinimum", "exclusiveMaximum", "multipleOf":
+			bound, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return required, fmt.Errorf("invalid %s %q", key, value)
+			}
+
+			switch key {
+			case "minimum":
+				prop.Minimum = &bound
+			case "maximum":
+				prop.Maximum = &bound
+			case "exclusiveMinimum":
+				prop.ExclusiveMinimum = &bound
+			case "exclusiveMaximum":
+				prop.ExclusiveMaximum = &bound
+			default:
+				prop.MultipleOf = &bound
+			}
+		case "minLength", "maxLength", "minItems", "maxItems":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return required, fmt.Errorf("invalid %s %q", key, value)
+			}
+
+			switch key {
+			case "minLength":
+				prop.MinLength = &n
+			case "maxLength":
+				prop.MaxLength = &n
+			case "minItems":
+				prop.MinItems = &n
+			default:
+				prop.MaxItems = &n
+			}
+		case "default":
+			def, err := parseSchemaDefault(prop.Type, value)
+			if err != nil {
+				return required, fmt.Errorf("invalid default %q: %w", value, err)
+			}
+
+			prop.Default = def
+			// The default fills in the value, so the model may omit it
+			required = false
+		default:
+			return required, fmt.Errorf("unknown jsonschema option %q", key)
+		}
+	}
+
+	return required, nil
+}
+
+// splitSchemaTag splits a jsonschema tag on the commas outside single quotes.
+func splitSchemaTag(tag string) ([]string, error) {
+	var (
+		options []string
+		quoted  bool
+		start   int
+	)
+
+	for i, r := range tag {
+		switch {
+		case r == '\'':
+			quoted = !quoted
+		case r == ',' && !quoted:
+			options = append(options, tag[start:i])
+			start = i + 1
+		}
+	}
+
+	if quoted {
+		return nil, fmt.Errorf("unterminated quote in jsonschema tag %q", tag)
+	}
+
+	return append(options, tag[start:]), nil
+}
+
+// parseSchemaDefault converts a tag default to the property type.
+func parseSchemaDefault(typ, value string) (any, error) {
+	switch typ {
+	case "integer":
+		return strconv.ParseInt(value, 10, 64)
+	case "number":
+		return strconv.ParseFloat(value, 64)
+	case "boolean":
+		return strconv.ParseBool(value)
+	case "array", "object", "":
+		var def any
+		err := json.Unmarshal([]byte(value), &def)
+
+		return def, err
+	default:
+		return value, nil
+	}
+}
+
+// applyToolDefaults returns a copy of an object with defaults filled in for
+// missing properties, including those of nested objects.
+func applyToolDefaults(value map[string]any, prop ToolParameterProperty) map[string]any {
+	result := maps.Clone(value)
+
+	for name, child := range prop.Properties {
+		current, exists := result[name]
+		if !exists && child.Default != nil {
+			result[name] = child.Default
+
+			continue
+		}
+
+		if nested, ok := current.(map[string]any); ok && child.Properties != nil {
+			result[name] = applyToolDefaults(nested, child)
+		}
+	}
+
+	return result
+}
+
+// jsonNumber returns the numeric value of v, if it is a number.
+func jsonNumber(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case json.Number:
+		f, err := n.Float64()
+
+		return f, err == nil
+	}
+
+	rv := reflect.ValueOf(v)
+
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	default:
+		return 0, false
+	}
+}
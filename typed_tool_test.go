@@ -0,0 +1,216 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+type weatherAddress struct {
+	City    string `json:"city"              description:"City name"`
+	Country string `json:"country,omitempty" jsonschema:"enum=FR,enum=US"`
+}
+
+type weatherPaging struct {
+	Page int `json:"page" jsonschema:"minimum=1,default=1"`
+}
+
+type weatherArgs struct {
+	weatherPaging
+
+	Address weatherAddress `json:"address"`
+	Days    int            `json:"days"              jsonschema:"description=Forecast length,minimum=1,maximum=7"`
+	Units   string         `json:"units"             jsonschema:"enum=metric,enum=imperial,default=metric"`
+	Tags    []string       `json:"tags,omitempty"`
+	Verbose *bool          `json:"verbose"`
+	Ignored string         `json:"-"`
+}
+
+type weatherResult struct {
+	Summary string `json:"summary"`
+	Days    int    `json:"days"`
+	Units   string `json:"units"`
+	Page    int    `json:"page"`
+}
+
+func newWeatherTool(t *testing.T) *TypedTool[weatherArgs, weatherResult] {
+	t.Helper()
+
+	tool, err := NewTypedTool("forecast", "Get a forecast", func(ctx context.Context, in weatherArgs) (weatherResult, error) {
+		return weatherResult{Summary: "Sunny in " + in.Address.City, Days: in.Days, Units: in.Units, Page: in.Page}, nil
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	return tool
+}
+
+func TestTypedTool_Schema(t *testing.T) {
+	schema := newWeatherTool(t).Schema()
+
+	if !slices.Equal(schema.Required, []string{"address", "days"}) {
+		t.Errorf("expected address and days required, got %v", schema.Required)
+	}
+
+	days := schema.Properties["days"]
+	if days.Type != "integer" || days.Description != "Forecast length" || *days.Minimum != 1 || *days.Maximum != 7 {
+		t.Errorf("unexpected days schema: %+v", days)
+	}
+
+	if units := schema.Properties["units"]; units.Default != "metric" || len(units.Enum) != 2 {
+		t.Errorf("unexpected units schema: %+v", units)
+	}
+
+	if page := schema.Properties["page"]; page.Type != "integer" || page.Default != int64(1) {
+		t.Errorf("expected embedded page field, got %+v", page)
+	}
+
+	address := schema.Properties["address"]
+	if address.Type != "object" || address.Properties["city"].Description != "City name" || !slices.Equal(address.Required, []string{"city"}) {
+		t.Errorf("unexpected nested schema: %+v", address)
+	}
+
+	if tags := schema.Properties["tags"]; tags.Type != "array" || tags.Items == nil || tags.Items.Type != "string" {
+		t.Errorf("unexpected tags schema: %+v", tags)
+	}
+
+	if _, ok := schema.Properties["Ignored"]; ok {
+		t.Error("expected json:\"-\" field to be skipped")
+	}
+}
+
+func TestTypedTool_Call(t *testing.T) {
+	tool := newWeatherTool(t)
+
+	result, err := tool.Call(context.Background(), map[string]any{
+		"address": map[string]any{"city": "Paris"},
+		"days":    float64(3),
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	output, ok := result.(map[string]any)
+	if !ok {
+		t.Fatalf("expected serialized map, got %T", result)
+	}
+
+	if output["summary"] != "Sunny in Paris" || output["days"] != float64(3) || output["units"] != "metric" || output["page"] != float64(1) {
+		t.Errorf("unexpected output: %v", output)
+	}
+}
+
+func TestTypedTool_FieldErrors(t *testing.T) {
+	tool := newWeatherTool(t)
+
+	_, err := tool.Call(context.Background(), map[string]any{
+		"address": map[string]any{"country": "DE"},
+		"days":    float64(10),
+		"units":   "kelvin",
+		"tags":    []any{"ok", float64(1)},
+		"extra":   true,
+	})
+
+	var argErr *ToolArgumentError
+	if !errors.As(err, &argErr) || !errors.Is(err, ErrInvalidToolArguments) {
+		t.Fatalf("expected ToolArgumentError, got %v", err)
+	}
+
+	paths := make([]string, len(argErr.Fields))
+	for i, field := range argErr.Fields {
+		paths[i] = field.Path
+	}
+
+	for _, expected := range []string{"address.city", "address.country", "days", "units", "tags[1]", "extra"} {
+		if !slices.Contains(paths, expected) {
+			t.Errorf("expected error at %s, got %v", expected, paths)
+		}
+	}
+
+	if !strings.Contains(err.Error(), "days: must be <= 7") {
+		t.Errorf("expected readable message, got %q", err.Error())
+	}
+}
+
+func TestTypedTool_Registration(t *testing.T) {
+	tool := newWeatherTool(t)
+
+	registry := NewToolRegistry(nil, nil)
+	if err := registry.RegisterTool(tool.Definition()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	result, err := registry.ExecuteTool(context.Background(), "forecast", "", map[string]any{
+		"address": map[string]any{"city": "Lyon"},
+		"days":    float64(2),
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if output := result.Result.(map[string]any); output["summary"] != "Sunny in Lyon" {
+		t.Errorf("unexpected output: %v", output)
+	}
+
+	agentTool := tool.AgentTool()
+
+	properties, _ := agentTool.Parameters["properties"].(map[string]any)
+	if agentTool.Parameters["type"] != "object" || properties["days"] == nil {
+		t.Errorf("expected JSON schema parameters, got %v", agentTool.Parameters)
+	}
+}
+
+func TestTypedTool_QuotedTagValues(t *testing.T) {
+	type invoiceArgs struct {
+		Code string `json:"code" jsonschema:"pattern='^[a-z]{1,3}$',maxLength=3"`
+		Name string `json:"name" jsonschema:"description='Name, as shown on the invoice',default='O''Brien, Ltd'"`
+	}
+
+	tool, err := NewTypedTool("invoice", "", func(ctx context.Context, in invoiceArgs) (string, error) { return in.Name, nil })
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	schema := tool.Schema()
+
+	if code := schema.Properties["code"]; code.Pattern != "^[a-z]{1,3}$" || code.MaxLength == nil || *code.MaxLength != 3 {
+		t.Errorf("unexpected code schema: %+v", code)
+	}
+
+	if name := schema.Properties["name"]; name.Description != "Name, as shown on the invoice" || name.Default != "O'Brien, Ltd" {
+		t.Errorf("unexpected name schema: %+v", name)
+	}
+
+	type unterminated struct {
+		S string `json:"s" jsonschema:"pattern='a,b"`
+	}
+
+	if _, err := NewTypedTool("bad", "", func(ctx context.Context, in unterminated) (string, error) { return in.S, nil }); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected ErrInvalidConfig for an unterminated quote, got %v", err)
+	}
+}
+
+func TestNewTypedTool_InvalidInput(t *testing.T) {
+	if _, err := NewTypedTool("bad", "", func(ctx context.Context, in string) (string, error) { return in, nil }); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected ErrInvalidConfig for non-struct input, got %v", err)
+	}
+
+	type badEnum struct {
+		N int `json:"n" jsonschema:"enum=1"`
+	}
+
+	if _, err := NewTypedTool("bad", "", func(ctx context.Context, in badEnum) (int, error) { return in.N, nil }); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected ErrInvalidConfig for enum on integer, got %v", err)
+	}
+
+	type node struct {
+		Children []node `json:"children"`
+	}
+
+	if _, err := NewTypedTool("bad", "", func(ctx context.Context, in node) (int, error) { return 0, nil }); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected ErrInvalidConfig for recursive type, got %v", err)
+	}
+}
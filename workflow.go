@@ -6,9 +6,12 @@ import (
 	"fmt"
 	"maps"
 	"slices"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/google/uuid"
 	logger "github.com/xraph/go-utils/log"
 	"github.com/xraph/go-utils/metrics"
 )
@@ -20,14 +23,26 @@ type Workflow struct {
 	Description string
 	Nodes       map[string]*WorkflowNode
 	Edges       map[string][]string // node_id -> []dependent_node_ids
+	ErrorEdges  map[string][]string // node_id -> []error_handler_node_ids
 	StartNodes  []string
 	Version     string
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
 
 	// External dependencies for node execution
-	toolRegistry  *ToolRegistry
-	agentRegistry *AgentRegistry
+	toolRegistry     *ToolRegistry
+	agentRegistry    *AgentRegistry
+	workflowRegistry *WorkflowRegistry
+
+	// Optional persistence for durable executions
+	store WorkflowStore
+
+	// Evaluates condition, transform and mapping expressions
+	evaluator *ExpressionEvaluator
+
+	// Signal and approval nodes currently waiting in this process
+	signals   map[string]*PendingSignal
+	signalsMu sync.Mutex
 
 	logger  logger.Logger
 	metrics metrics.Metrics
@@ -41,8 +56,13 @@ type WorkflowNode struct {
 	Name        string
 	Description string
 	Config      map[string]any
-	Retry       *RetryConfig
-	Timeout     time.Duration
+	// Retry re-runs the node with backoff on failure; Timeout applies to each attempt
+	Retry   *RetryConfig
+	Timeout time.Duration
+
+	// Compensation is the ID of a node that undoes this node's effects. It runs,
+	// in reverse completion order, when a later step fails the workflow.
+	Compensation string
 
 	// For agent nodes
 	AgentID string
@@ -62,6 +82,15 @@ type WorkflowNode struct {
 	// TransformHandler takes priority over Transform expression if set
 	TransformHandler func(ctx context.Context, data map[string]any) (any, error)
 
+	// For sub-workflow nodes
+	WorkflowID string
+	// InputMapping maps child input keys to expressions evaluated against the parent's data.
+	// If empty, the child receives the parent's data unchanged.
+	InputMapping map[string]string
+	// OutputMapping maps result keys to expressions evaluated against the child's data.
+	// If empty, the result contains the outputs of every completed child node.
+	OutputMapping map[string]string
+
 	// Execution state
 	Status    NodeStatus
 	StartTime time.Time
@@ -75,13 +104,16 @@ type WorkflowNode struct {
 type NodeType string
 
 const (
-	NodeTypeAgent     NodeType = "agent"
-	NodeTypeTool      NodeType = "tool"
-	NodeTypeCondition NodeType = "condition"
-	NodeTypeTransform NodeType = "transform"
-	NodeTypeParallel  NodeType = "parallel"
-	NodeTypeSequence  NodeType = "sequence"
-	NodeTypeWait      NodeType = "wait"
+	NodeTypeAgent       NodeType = "agent"
+	NodeTypeTool        NodeType = "tool"
+	NodeTypeCondition   NodeType = "condition"
+	NodeTypeTransform   NodeType = "transform"
+	NodeTypeParallel    NodeType = "parallel"
+	NodeTypeSequence    NodeType = "sequence"
+	NodeTypeWait        NodeType = "wait"
+	NodeTypeSubWorkflow NodeType = "subworkflow"
+	NodeTypeApproval    NodeType = "approval"
+	NodeTypeSignal      NodeType = "signal"
 )
 
 // NodeStatus represents the execution status of a node.
@@ -93,6 +125,7 @@ const (
 	NodeStatusCompleted NodeStatus = "completed"
 	NodeStatusFailed    NodeStatus = "failed"
 	NodeStatusSkipped   NodeStatus = "skipped"
+	NodeStatusWaiting   NodeStatus = "waiting"
 )
 
 // WorkflowExecution represents an execution instance of a workflow.
@@ -108,6 +141,8 @@ type WorkflowExecution struct {
 	Error          error
 
 	mu sync.RWMutex
+	// persistMu serializes snapshots so an older one never overwrites a newer one
+	persistMu sync.Mutex
 }
 
 // NodeExecution represents the execution of a single node.
@@ -120,6 +155,15 @@ type NodeExecution struct {
 	Output    any
 	Error     error
 	Attempts  int
+
+	// SubExecution holds the child execution for sub-workflow nodes.
+	SubExecution *WorkflowExecution
+
+	// WakeAt is the durable deadline of a wait, signal or approval node, kept across resumes.
+	WakeAt time.Time
+
+	// SignalPayload is the payload delivered to a signal or approval node.
+	SignalPayload map[string]any
 }
 
 // WorkflowStatus represents the overall workflow execution status.
@@ -131,6 +175,9 @@ const (
 	WorkflowStatusCompleted WorkflowStatus = "completed"
 	WorkflowStatusFailed    WorkflowStatus = "failed"
 	WorkflowStatusCancelled WorkflowStatus = "cancelled"
+	WorkflowStatusWaiting   WorkflowStatus = "waiting"
+	// WorkflowStatusCompensated means the workflow failed and all compensations succeeded.
+	WorkflowStatusCompensated WorkflowStatus = "compensated"
 )
 
 // NewWorkflow creates a new workflow.
@@ -140,10 +187,12 @@ func NewWorkflow(id, name string, logger logger.Logger, metrics metrics.Metrics)
 		Name:       name,
 		Nodes:      make(map[string]*WorkflowNode),
 		Edges:      make(map[string][]string),
+		ErrorEdges: make(map[string][]string),
 		StartNodes: make([]string, 0),
 		Version:    "1.0.0",
 		CreatedAt:  time.Now(),
 		UpdatedAt:  time.Now(),
+		evaluator:  NewExpressionEvaluator(),
 		logger:     logger,
 		metrics:    metrics,
 	}
@@ -165,6 +214,43 @@ func (w *Workflow) SetAgentRegistry(ar *AgentRegistry) {
 	w.agentRegistry = ar
 }
 
+// SetWorkflowRegistry sets the workflow registry for sub-workflow node execution.
+func (w *Workflow) SetWorkflowRegistry(wr *WorkflowRegistry) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.workflowRegistry = wr
+}
+
+// SetStore sets the store used to persist executions after each node completes.
+func (w *Workflow) SetStore(store WorkflowStore) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.store = store
+}
+
+// SetExpressionEvaluator sets the evaluator used for condition, transform and
+// mapping expressions, e.g. to register custom functions or tighten limits.
+func (w *Workflow) SetExpressionEvaluator(evaluator *ExpressionEvaluator) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.evaluator = evaluator
+}
+
+// expressions returns the workflow's expression evaluator.
+func (w *Workflow) expressions() *ExpressionEvaluator {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.evaluator == nil {
+		w.evaluator = NewExpressionEvaluator()
+	}
+
+	return w.evaluator
+}
+
 // AddNode adds a node to the workflow.
 func (w *Workflow) AddNode(node *WorkflowNode) error {
 	w.mu.Lock()
@@ -223,6 +309,38 @@ func (w *Workflow) AddEdge(fromNode, toNode string) error {
 	return nil
 }
 
+// AddErrorEdge routes failures of fromNode to the handler node toNode. When a
+// node with error edges fails, its handlers run instead of failing the
+// workflow, and its regular dependents are skipped.
+func (w *Workflow) AddErrorEdge(fromNode, toNode string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, exists := w.Nodes[fromNode]; !exists {
+		return fmt.Errorf("source node %s not found", fromNode)
+	}
+
+	if _, exists := w.Nodes[toNode]; !exists {
+		return fmt.Errorf("handler node %s not found", toNode)
+	}
+
+	if w.ErrorEdges == nil {
+		w.ErrorEdges = make(map[string][]string)
+	}
+
+	w.ErrorEdges[fromNode] = append(w.ErrorEdges[fromNode], toNode)
+
+	if w.hasCycle() {
+		w.ErrorEdges[fromNode] = w.ErrorEdges[fromNode][:len(w.ErrorEdges[fromNode])-1]
+
+		return fmt.Errorf("adding error edge %s -> %s would create a cycle", fromNode, toNode)
+	}
+
+	w.UpdatedAt = time.Now()
+
+	return nil
+}
+
 // SetStartNode marks a node as a starting point.
 func (w *Workflow) SetStartNode(nodeID string) error {
 	w.mu.Lock()
@@ -261,7 +379,7 @@ func (w *Workflow) Execute(ctx context.Context, input map[string]any) (*Workflow
 	w.mu.RUnlock()
 
 	execution := &WorkflowExecution{
-		ID:             fmt.Sprintf("%s_%d", w.ID, time.Now().Unix()),
+		ID:             fmt.Sprintf("%s_%s", w.ID, uuid.New().String()),
 		WorkflowID:     w.ID,
 		Status:         WorkflowStatusRunning,
 		StartTime:      time.Now(),
@@ -276,17 +394,106 @@ func (w *Workflow) Execute(ctx context.Context, input map[string]any) (*Workflow
 		)
 	}
 
+	return w.run(ctx, execution)
+}
+
+// Resume continues a persisted execution. Completed nodes are skipped and
+// nodes that were in flight or failed when the execution stopped are re-run.
+// Branches that were skipped, or whose failure was already routed to an
+// error handler, stay blocked. Compensated and cancelled executions cannot be
+// resumed, since their completed nodes may have been undone.
+func (w *Workflow) Resume(ctx context.Context, executionID string) (*WorkflowExecution, error) {
+	w.mu.RLock()
+	store := w.store
+
+	if store == nil {
+		w.mu.RUnlock()
+
+		return nil, fmt.Errorf("%w: workflow store not configured", ErrInvalidConfig)
+	}
+
+	if err := w.validate(); err != nil {
+		w.mu.RUnlock()
+
+		return nil, fmt.Errorf("workflow validation failed: %w", err)
+	}
+
+	w.mu.RUnlock()
+
+	execution, err := store.Load(ctx, executionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if execution.WorkflowID != w.ID {
+		return nil, fmt.Errorf("%w: execution %s belongs to workflow %s", ErrInvalidConfig, executionID, execution.WorkflowID)
+	}
+
+	switch execution.Status {
+	case WorkflowStatusCompleted:
+		return execution, nil
+	case WorkflowStatusCompensated, WorkflowStatusCancelled:
+		return execution, fmt.Errorf("%w: execution %s is %s", ErrWorkflowNotResumable, executionID, execution.Status)
+	}
+
+	execution.Status = WorkflowStatusRunning
+	execution.EndTime = time.Time{}
+	execution.Error = nil
+
+	if w.logger != nil {
+		w.logger.Info("Resuming workflow execution",
+			F("workflow", w.ID),
+			F("execution", execution.ID),
+		)
+	}
+
+	return w.run(ctx, execution)
+}
+
+// run executes the DAG for an execution and records its final state.
+func (w *Workflow) run(ctx context.Context, execution *WorkflowExecution) (*WorkflowExecution, error) {
+	w.saveExecution(ctx, execution)
+
 	// Execute workflow DAG
 	err := w.executeDAG(ctx, execution)
 
+	status := WorkflowStatusCompleted
+
+	if err != nil {
+		status = WorkflowStatusFailed
+
+		// Roll back completed steps even if the caller's context is done
+		compensated, compErr := w.compensate(context.WithoutCancel(ctx), execution)
+		if compErr != nil {
+			err = errors.Join(err, compErr)
+		} else if compensated {
+			status = WorkflowStatusCompensated
+		}
+	}
+
+	execution.mu.Lock()
+
 	execution.EndTime = time.Now()
+	execution.Status = status
+	execution.Error = err
+
+	execution.mu.Unlock()
+
+	// Persist the final state even if the caller's context is done
+	w.saveExecution(context.WithoutCancel(ctx), execution)
+
+	finished := WorkflowEvent{
+		Type:     WorkflowEventFinished,
+		Status:   status,
+		Duration: execution.EndTime.Sub(execution.StartTime),
+	}
+
 	if err != nil {
-		execution.Status = WorkflowStatusFailed
-		execution.Error = err
-	} else {
-		execution.Status = WorkflowStatusCompleted
+		finished.Error = err.Error()
 	}
 
+	w.emit(ctx, execution, finished)
+
 	if w.metrics != nil {
 		w.metrics.Counter("forge.ai.sdk.workflow.executions",
 			metrics.WithLabel("workflow", w.ID),
@@ -303,13 +510,60 @@ func (w *Workflow) Execute(ctx context.Context, input map[string]any) (*Workflow
 
 // executeDAG executes the workflow DAG.
 func (w *Workflow) executeDAG(ctx context.Context, execution *WorkflowExecution) error {
-	// Track completed nodes
+	// Track completed nodes, including those restored from a previous run
 	completed := make(map[string]bool)
 	executing := make(map[string]bool)
 
-	// Start with start nodes
-	toExecute := make([]string, len(w.StartNodes))
-	copy(toExecute, w.StartNodes)
+	// Nodes whose dependents must not run: skipped nodes and failures routed to error handlers
+	blocked := make(map[string]bool)
+
+	statuses := make(map[string]NodeStatus)
+
+	execution.mu.RLock()
+
+	for nodeID, nodeExec := range execution.NodeExecutions {
+		statuses[nodeID] = nodeExec.Status
+	}
+
+	execution.mu.RUnlock()
+
+	// A failure whose error handlers already started was routed before the
+	// execution stopped: keep it blocked instead of re-running it
+	handlers := make([]string, 0)
+
+	w.mu.RLock()
+
+	for nodeID, status := range statuses {
+		switch status {
+		case NodeStatusCompleted:
+			completed[nodeID] = true
+		case NodeStatusSkipped:
+			completed[nodeID] = true
+			blocked[nodeID] = true
+		case NodeStatusFailed:
+			routed := slices.ContainsFunc(w.ErrorEdges[nodeID], func(handler string) bool {
+				_, ok := statuses[handler]
+
+				return ok
+			})
+
+			if routed {
+				completed[nodeID] = true
+				blocked[nodeID] = true
+				handlers = append(handlers, w.ErrorEdges[nodeID]...)
+			}
+		}
+	}
+
+	w.mu.RUnlock()
+
+	toExecute := w.pendingNodes(completed)
+
+	for _, handler := range handlers {
+		if !completed[handler] && !slices.Contains(toExecute, handler) {
+			toExecute = append(toExecute, handler)
+		}
+	}
 
 	for len(toExecute) > 0 {
 		// Check context cancellation
@@ -322,10 +576,35 @@ func (w *Workflow) executeDAG(ctx context.Context, execution *WorkflowExecution)
 		// Find nodes ready to execute (all dependencies completed)
 		readyNodes := make([]string, 0)
 
+		skippedAny := false
+
 		for _, nodeID := range toExecute {
-			if w.isNodeReady(nodeID, completed, executing) {
-				readyNodes = append(readyNodes, nodeID)
+			if !w.isNodeReady(nodeID, completed, executing) || slices.Contains(readyNodes, nodeID) {
+				continue
 			}
+
+			// Skip nodes downstream of a skipped or handled failure
+			if w.hasBlockedParent(nodeID, blocked) {
+				w.skipNode(ctx, execution, nodeID)
+
+				completed[nodeID] = true
+				blocked[nodeID] = true
+				skippedAny = true
+
+				w.mu.RLock()
+				toExecute = append(toExecute, w.Edges[nodeID]...)
+				w.mu.RUnlock()
+
+				continue
+			}
+
+			readyNodes = append(readyNodes, nodeID)
+		}
+
+		if skippedAny && len(readyNodes) == 0 {
+			toExecute = slices.DeleteFunc(toExecute, func(id string) bool { return completed[id] })
+
+			continue
 		}
 
 		if len(readyNodes) == 0 {
@@ -351,20 +630,43 @@ func (w *Workflow) executeDAG(ctx context.Context, execution *WorkflowExecution)
 				defer wg.Done()
 
 				err := w.executeNode(ctx, execution, nid)
+
+				w.mu.RLock()
+				dependents := w.Edges[nid]
+				handlers := w.ErrorEdges[nid]
+				w.mu.RUnlock()
+
 				if err != nil {
-					errors <- fmt.Errorf("node %s failed: %w", nid, err)
+					if len(handlers) == 0 {
+						errors <- fmt.Errorf("node %s failed: %w", nid, err)
+					} else {
+						// Route the failure to its handlers instead of the regular dependents
+						if w.logger != nil {
+							w.logger.Info("Routing node failure to error handlers",
+								F("workflow", w.ID),
+								F("node", nid),
+								F("handlers", handlers),
+							)
+						}
+
+						dependents = append(slices.Clone(dependents), handlers...)
+					}
 				}
 
+				w.saveExecution(ctx, execution)
+
 				execution.mu.Lock()
 
 				completed[nid] = true
 				delete(executing, nid)
+
+				if err != nil {
+					blocked[nid] = true
+				}
+
 				execution.mu.Unlock()
 
 				// Add dependent nodes to execution queue
-				w.mu.RLock()
-				dependents := w.Edges[nid]
-				w.mu.RUnlock()
 
 				execution.mu.Lock()
 
@@ -404,6 +706,155 @@ func (w *Workflow) executeDAG(ctx context.Context, execution *WorkflowExecution)
 	return nil
 }
 
+// hasBlockedParent reports whether any regular parent of the node is blocked.
+func (w *Workflow) hasBlockedParent(nodeID string, blocked map[string]bool) bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	for parentID, children := range w.Edges {
+		if blocked[parentID] && slices.Contains(children, nodeID) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// skipNode records a node as skipped without running it.
+func (w *Workflow) skipNode(ctx context.Context, execution *WorkflowExecution, nodeID string) {
+	now := time.Now()
+
+	execution.mu.Lock()
+	execution.NodeExecutions[nodeID] = &NodeExecution{
+		NodeID:    nodeID,
+		Status:    NodeStatusSkipped,
+		StartTime: now,
+		EndTime:   now,
+		Input:     make(map[string]any),
+	}
+	execution.mu.Unlock()
+
+	w.mu.RLock()
+	nodeType := w.Nodes[nodeID].Type
+	w.mu.RUnlock()
+
+	w.emit(ctx, execution, WorkflowEvent{Type: WorkflowEventNodeSkipped, NodeID: nodeID, NodeType: nodeType})
+
+	if w.logger != nil {
+		w.logger.Debug("Node skipped",
+			F("workflow", w.ID),
+			F("node", nodeID),
+		)
+	}
+}
+
+// compensate runs the compensation nodes of completed steps in reverse
+// completion order. It reports whether any compensation ran.
+func (w *Workflow) compensate(ctx context.Context, execution *WorkflowExecution) (bool, error) {
+	type step struct {
+		nodeID       string
+		compensation string
+		endTime      time.Time
+	}
+
+	steps := make([]step, 0)
+
+	w.mu.RLock()
+	execution.mu.RLock()
+
+	for nodeID, nodeExec := range execution.NodeExecutions {
+		node, ok := w.Nodes[nodeID]
+		if ok && node.Compensation != "" && nodeExec.Status == NodeStatusCompleted {
+			steps = append(steps, step{nodeID: nodeID, compensation: node.Compensation, endTime: nodeExec.EndTime})
+		}
+	}
+
+	execution.mu.RUnlock()
+	w.mu.RUnlock()
+
+	if len(steps) == 0 {
+		return false, nil
+	}
+
+	sort.Slice(steps, func(i, j int) bool {
+		return steps[i].endTime.After(steps[j].endTime)
+	})
+
+	var errs []error
+
+	for _, s := range steps {
+		if w.logger != nil {
+			w.logger.Info("Running compensation",
+				F("workflow", w.ID),
+				F("node", s.nodeID),
+				F("compensation", s.compensation),
+			)
+		}
+
+		if err := w.executeNode(ctx, execution, s.compensation); err != nil {
+			errs = append(errs, fmt.Errorf("compensation %s for node %s failed: %w", s.compensation, s.nodeID, err))
+		}
+
+		w.saveExecution(ctx, execution)
+	}
+
+	if w.metrics != nil {
+		w.metrics.Counter("forge.ai.sdk.workflow.compensations",
+			metrics.WithLabel("workflow", w.ID),
+		).Add(float64(len(steps)))
+	}
+
+	return true, errors.Join(errs...)
+}
+
+// pendingNodes returns the nodes to schedule first: start nodes that have not
+// completed, plus the unfinished dependents of completed nodes.
+func (w *Workflow) pendingNodes(completed map[string]bool) []string {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	pending := make([]string, 0, len(w.StartNodes))
+
+	for _, nodeID := range w.StartNodes {
+		if !completed[nodeID] {
+			pending = append(pending, nodeID)
+		}
+	}
+
+	for nodeID := range completed {
+		for _, dep := range w.Edges[nodeID] {
+			if !completed[dep] && !slices.Contains(pending, dep) {
+				pending = append(pending, dep)
+			}
+		}
+	}
+
+	return pending
+}
+
+// saveExecution persists a snapshot of the execution if a store is configured.
+// Persistence failures are logged but do not fail the workflow.
+func (w *Workflow) saveExecution(ctx context.Context, execution *WorkflowExecution) {
+	w.mu.RLock()
+	store := w.store
+	w.mu.RUnlock()
+
+	if store == nil {
+		return
+	}
+
+	execution.persistMu.Lock()
+	defer execution.persistMu.Unlock()
+
+	if err := store.Save(ctx, execution); err != nil && w.logger != nil {
+		w.logger.Warn("Failed to persist workflow execution",
+			F("workflow", w.ID),
+			F("execution", execution.ID),
+			F("error", err.Error()),
+		)
+	}
+}
+
 // executeNode executes a single workflow node.
 func (w *Workflow) executeNode(ctx context.Context, execution *WorkflowExecution, nodeID string) error {
 	w.mu.RLock()
@@ -419,46 +870,73 @@ func (w *Workflow) executeNode(ctx context.Context, execution *WorkflowExecution
 	}
 
 	execution.mu.Lock()
+
+	// Carry over state from a previous attempt when resuming
+	if prev, ok := execution.NodeExecutions[nodeID]; ok {
+		nodeExec.Attempts = prev.Attempts + 1
+		nodeExec.WakeAt = prev.WakeAt
+		nodeExec.SignalPayload = prev.SignalPayload
+	}
+
 	execution.NodeExecutions[nodeID] = nodeExec
+	attempts := nodeExec.Attempts
 	execution.mu.Unlock()
 
-	// Create context with timeout
-	nodeCtx, cancel := context.WithTimeout(ctx, node.Timeout)
-	defer cancel()
+	w.emit(ctx, execution, WorkflowEvent{
+		Type:     WorkflowEventNodeStarted,
+		NodeID:   nodeID,
+		NodeType: node.Type,
+		Attempt:  attempts,
+	})
 
 	var (
 		err    error
 		result any
 	)
 
-	// Execute based on node type
+	if node.Retry != nil && node.Retry.MaxAttempts > 1 {
+		attempt := 0
 
-	switch node.Type {
-	case NodeTypeTool:
-		result, err = w.executeToolNode(nodeCtx, node, execution)
-	case NodeTypeAgent:
-		result, err = w.executeAgentNode(nodeCtx, node, execution)
-	case NodeTypeCondition:
-		result, err = w.executeConditionNode(nodeCtx, node, execution)
-	case NodeTypeTransform:
-		result, err = w.executeTransformNode(nodeCtx, node, execution)
-	case NodeTypeWait:
-		result, err = w.executeWaitNode(nodeCtx, node)
-	default:
-		err = fmt.Errorf("unsupported node type: %s", node.Type)
+		err = Retry(ctx, *node.Retry, w.logger, func(ctx context.Context) error {
+			attempt++
+			if attempt > 1 {
+				execution.mu.Lock()
+				nodeExec.Attempts++
+				execution.mu.Unlock()
+			}
+
+			var attemptErr error
+
+			result, attemptErr = w.runNodeAttempt(ctx, node, execution, nodeExec)
+
+			return attemptErr
+		})
+	} else {
+		result, err = w.runNodeAttempt(ctx, node, execution, nodeExec)
 	}
 
+	execution.mu.Lock()
+
 	nodeExec.EndTime = time.Now()
 	nodeExec.Output = result
 	nodeExec.Error = err
 
 	if err != nil {
 		nodeExec.Status = NodeStatusFailed
+	} else {
+		nodeExec.Status = NodeStatusCompleted
+	}
+
+	execution.mu.Unlock()
+
+	w.emitNodeResult(ctx, execution, node, nodeExec)
 
+	if err != nil {
 		if w.logger != nil {
 			w.logger.Warn("Node execution failed",
 				F("workflow", w.ID),
 				F("node", nodeID),
+				F("attempts", nodeExec.Attempts),
 				F("error", err.Error()),
 			)
 		}
@@ -466,8 +944,6 @@ func (w *Workflow) executeNode(ctx context.Context, execution *WorkflowExecution
 		return err
 	}
 
-	nodeExec.Status = NodeStatusCompleted
-
 	if w.logger != nil {
 		w.logger.Debug("Node execution completed",
 			F("workflow", w.ID),
@@ -479,6 +955,55 @@ func (w *Workflow) executeNode(ctx context.Context, execution *WorkflowExecution
 	return nil
 }
 
+// runNodeAttempt runs a single attempt of a node, bounded by the node's timeout.
+func (w *Workflow) runNodeAttempt(ctx context.Context, node *WorkflowNode, execution *WorkflowExecution, nodeExec *NodeExecution) (any, error) {
+	// Create context with timeout. Signal and approval nodes enforce their own
+	// persisted deadline, which the attempt timeout must not race.
+	var (
+		nodeCtx context.Context
+		cancel  context.CancelFunc
+	)
+
+	if node.Type == NodeTypeApproval || node.Type == NodeTypeSignal {
+		nodeCtx, cancel = context.WithCancel(ctx)
+	} else {
+		nodeCtx, cancel = context.WithTimeout(ctx, node.Timeout)
+	}
+
+	defer cancel()
+
+	// Forward agent and tool stream events when the execution is streamed
+	nodeCtx = w.nodeStreamContext(nodeCtx, execution, node)
+
+	var (
+		err    error
+		result any
+	)
+
+	// Execute based on node type
+
+	switch node.Type {
+	case NodeTypeTool:
+		result, err = w.executeToolNode(nodeCtx, node, execution)
+	case NodeTypeAgent:
+		result, err = w.executeAgentNode(nodeCtx, node, execution)
+	case NodeTypeCondition:
+		result, err = w.executeConditionNode(nodeCtx, node, execution)
+	case NodeTypeTransform:
+		result, err = w.executeTransformNode(nodeCtx, node, execution)
+	case NodeTypeWait:
+		result, err = w.executeDurableWaitNode(nodeCtx, node, execution, nodeExec)
+	case NodeTypeSubWorkflow:
+		result, err = w.executeSubWorkflowNode(nodeCtx, node, execution, nodeExec)
+	case NodeTypeApproval, NodeTypeSignal:
+		result, err = w.executeSignalNode(nodeCtx, node, execution, nodeExec)
+	default:
+		err = fmt.Errorf("unsupported node type: %s", node.Type)
+	}
+
+	return result, err
+}
+
 // executeToolNode executes a tool node.
 func (w *Workflow) executeToolNode(ctx context.Context, node *WorkflowNode, execution *WorkflowExecution) (any, error) {
 	w.mu.RLock()
@@ -527,7 +1052,7 @@ func (w *Workflow) executeToolNode(ctx context.Context, node *WorkflowNode, exec
 	execution.mu.RUnlock()
 
 	// Execute the tool
-	result, err := registry.ExecuteTool(ctx, node.ToolName, version, params)
+	result, err := registry.ExecuteTool(WithToolOrigin(ctx, ToolOriginWorkflow), node.ToolName, version, params)
 	if err != nil {
 		return nil, fmt.Errorf("tool %s execution failed: %w", node.ToolName, err)
 	}
@@ -659,7 +1184,7 @@ func (w *Workflow) executeConditionNode(ctx context.Context, node *WorkflowNode,
 
 	// Priority 2: Use expression evaluation if condition string is set
 	if node.Condition != "" {
-		evaluator := NewExpressionEvaluator()
+		evaluator := w.expressions()
 
 		result, err := evaluator.EvaluateCondition(node.Condition, data)
 		if err != nil {
@@ -696,7 +1221,7 @@ func (w *Workflow) executeTransformNode(ctx context.Context, node *WorkflowNode,
 
 	// Priority 2: Use expression evaluation if transform string is set
 	if node.Transform != "" {
-		evaluator := NewExpressionEvaluator()
+		evaluator := w.expressions()
 
 		result, err := evaluator.EvaluateTransform(node.Transform, data)
 		if err != nil {
@@ -713,6 +1238,143 @@ func (w *Workflow) executeTransformNode(ctx context.Context, node *WorkflowNode,
 	return nil, fmt.Errorf("%w: no transform handler or expression configured for node %s", ErrInvalidConfig, node.ID)
 }
 
+// subWorkflowStackKey is the context key holding the IDs of the workflows
+// currently executing on the sub-workflow call path.
+type subWorkflowStackKey struct{}
+
+// executeSubWorkflowNode executes a referenced workflow as a child of this execution.
+func (w *Workflow) executeSubWorkflowNode(ctx context.Context, node *WorkflowNode, execution *WorkflowExecution, nodeExec *NodeExecution) (any, error) {
+	w.mu.RLock()
+	registry := w.workflowRegistry
+	toolRegistry := w.toolRegistry
+	agentRegistry := w.agentRegistry
+	w.mu.RUnlock()
+
+	// Try to get registry from execution input if not set on workflow
+	if registry == nil {
+		if wr, ok := execution.Input["__workflow_registry"].(*WorkflowRegistry); ok {
+			registry = wr
+		}
+	}
+
+	if registry == nil {
+		return nil, fmt.Errorf("%w: workflow registry not configured for workflow", ErrWorkflowNotFound)
+	}
+
+	child, err := registry.Get(node.WorkflowID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workflow %s: %w", node.WorkflowID, err)
+	}
+
+	// Guard against a workflow invoking itself through its children
+	stack, _ := ctx.Value(subWorkflowStackKey{}).([]string)
+	if len(stack) == 0 {
+		stack = []string{w.ID}
+	}
+
+	if slices.Contains(stack, child.ID) {
+		return nil, fmt.Errorf("%w: %s -> %s", ErrWorkflowRecursion, strings.Join(stack, " -> "), child.ID)
+	}
+
+	childCtx := context.WithValue(ctx, subWorkflowStackKey{}, append(slices.Clone(stack), child.ID))
+
+	// Build child input from the parent's data
+	data := w.buildNodeExecutionData(execution)
+	evaluator := w.expressions()
+
+	childInput := make(map[string]any)
+
+	if len(node.InputMapping) == 0 {
+		maps.Copy(childInput, data)
+	} else {
+		for key, expr := range node.InputMapping {
+			value, err := evaluator.EvaluateTransform(expr, data)
+			if err != nil {
+				return nil, fmt.Errorf("input mapping %q failed: %w", key, err)
+			}
+
+			childInput[key] = value
+		}
+	}
+
+	execution.mu.Lock()
+	nodeExec.Input = childInput
+	execution.mu.Unlock()
+
+	// Pass registries down so nested nodes can resolve them
+	enrichedInput := maps.Clone(childInput)
+
+	for _, key := range []string{"__tool_registry", "__agent_registry", "__workflow_registry"} {
+		if v, ok := execution.Input[key]; ok {
+			enrichedInput[key] = v
+		}
+	}
+
+	if toolRegistry != nil {
+		enrichedInput["__tool_registry"] = toolRegistry
+	}
+
+	if agentRegistry != nil {
+		enrichedInput["__agent_registry"] = agentRegistry
+	}
+
+	enrichedInput["__workflow_registry"] = registry
+
+	childExec, err := child.Execute(childCtx, enrichedInput)
+
+	execution.mu.Lock()
+	nodeExec.SubExecution = childExec
+	execution.mu.Unlock()
+
+	if err != nil {
+		return nil, fmt.Errorf("sub-workflow %s execution failed: %w", node.WorkflowID, err)
+	}
+
+	// Build result from the child's node outputs
+	childData := make(map[string]any)
+
+	childExec.mu.RLock()
+
+	for nodeID, childNodeExec := range childExec.NodeExecutions {
+		if childNodeExec.Status == NodeStatusCompleted && childNodeExec.Output != nil {
+			childData[nodeID] = childNodeExec.Output
+		}
+	}
+
+	childExec.mu.RUnlock()
+
+	if w.metrics != nil {
+		w.metrics.Counter("forge.ai.sdk.workflow.sub_workflow_executions",
+			metrics.WithLabel("workflow", w.ID),
+			metrics.WithLabel("sub_workflow", node.WorkflowID),
+		).Inc()
+	}
+
+	if len(node.OutputMapping) == 0 {
+		return childData, nil
+	}
+
+	// Output expressions may reference both the child's input and node outputs
+	for k, v := range childInput {
+		if _, exists := childData[k]; !exists {
+			childData[k] = v
+		}
+	}
+
+	result := make(map[string]any, len(node.OutputMapping))
+
+	for key, expr := range node.OutputMapping {
+		value, err := evaluator.EvaluateTransform(expr, childData)
+		if err != nil {
+			return nil, fmt.Errorf("output mapping %q failed: %w", key, err)
+		}
+
+		result[key] = value
+	}
+
+	return result, nil
+}
+
 // buildNodeExecutionData builds a data map from previous node execution outputs.
 func (w *Workflow) buildNodeExecutionData(execution *WorkflowExecution) map[string]any {
 	data := make(map[string]any)
@@ -724,12 +1386,24 @@ func (w *Workflow) buildNodeExecutionData(execution *WorkflowExecution) map[stri
 		}
 	}
 
-	// Add outputs from completed nodes
 	execution.mu.RLock()
 
+	// Merge payloads delivered to signal and approval nodes
+	for _, nodeExec := range execution.NodeExecutions {
+		if nodeExec.Status == NodeStatusCompleted && nodeExec.SignalPayload != nil {
+			maps.Copy(data, nodeExec.SignalPayload)
+		}
+	}
+
+	// Add outputs from completed nodes, and errors from failed ones for error handlers
 	for nodeID, nodeExec := range execution.NodeExecutions {
 		if nodeExec.Status == NodeStatusCompleted && nodeExec.Output != nil {
 			data[nodeID] = nodeExec.Output
+		} else if nodeExec.Status == NodeStatusFailed && nodeExec.Error != nil {
+			data[nodeID] = map[string]any{
+				"status": string(NodeStatusFailed),
+				"error":  nodeExec.Error.Error(),
+			}
 		}
 	}
 
@@ -740,14 +1414,49 @@ func (w *Workflow) buildNodeExecutionData(execution *WorkflowExecution) map[stri
 
 // executeWaitNode executes a wait/delay node.
 func (w *Workflow) executeWaitNode(ctx context.Context, node *WorkflowNode) (any, error) {
+	waitTime := waitNodeDuration(node)
+
+	return waitUntil(ctx, time.Now().Add(waitTime), waitTime)
+}
+
+// executeDurableWaitNode executes a wait node as a durable timer. The wake-up
+// deadline is persisted before waiting, so a resumed execution only waits for
+// the remaining time.
+func (w *Workflow) executeDurableWaitNode(ctx context.Context, node *WorkflowNode, execution *WorkflowExecution, nodeExec *NodeExecution) (any, error) {
+	waitTime := waitNodeDuration(node)
+
+	execution.mu.Lock()
+
+	if nodeExec.WakeAt.IsZero() {
+		nodeExec.WakeAt = nodeExec.StartTime.Add(waitTime)
+	}
+
+	wakeAt := nodeExec.WakeAt
+	execution.mu.Unlock()
+
+	w.saveExecution(ctx, execution)
+
+	return waitUntil(ctx, wakeAt, waitTime)
+}
+
+// waitNodeDuration returns the configured wait time of a wait node.
+func waitNodeDuration(node *WorkflowNode) time.Duration {
 	// Default wait time
 	waitTime := 1 * time.Second
 	if duration, ok := node.Config["duration"].(time.Duration); ok {
 		waitTime = duration
 	}
 
+	return waitTime
+}
+
+// waitUntil blocks until the deadline passes or the context is done.
+func waitUntil(ctx context.Context, wakeAt time.Time, waitTime time.Duration) (any, error) {
+	timer := time.NewTimer(time.Until(wakeAt))
+	defer timer.Stop()
+
 	select {
-	case <-time.After(waitTime):
+	case <-timer.C:
 		return map[string]any{"waited": waitTime.String()}, nil
 	case <-ctx.Done():
 		return nil, ctx.Err()
@@ -793,6 +1502,20 @@ func (w *Workflow) validate() error {
 			if node.AgentID == "" {
 				return fmt.Errorf("agent node %s missing agent ID", node.ID)
 			}
+		case NodeTypeSubWorkflow:
+			if node.WorkflowID == "" {
+				return fmt.Errorf("sub-workflow node %s missing workflow ID", node.ID)
+			}
+		}
+
+		if node.Compensation != "" {
+			if node.Compensation == node.ID {
+				return fmt.Errorf("node %s cannot compensate itself", node.ID)
+			}
+
+			if _, exists := w.Nodes[node.Compensation]; !exists {
+				return fmt.Errorf("compensation node %s for node %s not found", node.Compensation, node.ID)
+			}
 		}
 	}
 
@@ -818,7 +1541,9 @@ func (w *Workflow) hasCycleUtil(nodeID string, visited, recStack map[string]bool
 	visited[nodeID] = true
 	recStack[nodeID] = true
 
-	for _, childID := range w.Edges[nodeID] {
+	children := append(slices.Clone(w.Edges[nodeID]), w.ErrorEdges[nodeID]...)
+
+	for _, childID := range children {
 		if !visited[childID] {
 			if w.hasCycleUtil(childID, visited, recStack) {
 				return true
@@ -893,6 +1618,13 @@ func (w *Workflow) RemoveNode(nodeID string) error {
 		w.Edges[parent] = newChildren
 	}
 
+	// Remove from error edges
+	delete(w.ErrorEdges, nodeID)
+
+	for parent, handlers := range w.ErrorEdges {
+		w.ErrorEdges[parent] = slices.DeleteFunc(handlers, func(id string) bool { return id == nodeID })
+	}
+
 	// Remove from start nodes
 	newStartNodes := make([]string, 0)
 
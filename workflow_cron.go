@@ -0,0 +1,236 @@
+package sdk
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CronSchedule is a parsed five-field cron expression
+// (minute, hour, day of month, month, day of week).
+//
+// Fields accept *, values, ranges (1-5), lists (1,15), steps (*/10, 0-30/5)
+// and month/day names (jan, mon). The descriptors @yearly, @annually,
+// @monthly, @weekly, @daily, @midnight and @hourly are also accepted.
+// As in standard cron, when both day of month and day of week are restricted
+// a time matches if either does.
+type CronSchedule struct {
+	expr     string
+	minute   uint64
+	hour     uint64
+	dom      uint64
+	month    uint64
+	dow      uint64
+	anyDom   bool
+	anyDow   bool
+	location *time.Location
+}
+
+// cronDescriptors maps descriptors to their five-field equivalents.
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var (
+	cronMonthNames = map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}
+	cronDayNames = map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}
+)
+
+// ParseCron parses a cron expression evaluated in the given location
+// (time.Local when nil).
+func ParseCron(expr string, location *time.Location) (*CronSchedule, error) {
+	if location == nil {
+		location = time.Local
+	}
+
+	spec := strings.TrimSpace(expr)
+	if d, ok := cronDescriptors[strings.ToLower(spec)]; ok {
+		spec = d
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("%w: cron expression %q must have 5 fields", ErrInvalidConfig, expr)
+	}
+
+	c := &CronSchedule{expr: expr, location: location}
+
+	var err error
+
+	if c.minute, err = parseCronField(fields[0], 0, 59, nil); err != nil {
+		return nil, fmt.Errorf("%w: cron minute: %w", ErrInvalidConfig, err)
+	}
+
+	if c.hour, err = parseCronField(fields[1], 0, 23, nil); err != nil {
+		return nil, fmt.Errorf("%w: cron hour: %w", ErrInvalidConfig, err)
+	}
+
+	if c.dom, err = parseCronField(fields[2], 1, 31, nil); err != nil {
+		return nil, fmt.Errorf("%w: cron day of month: %w", ErrInvalidConfig, err)
+	}
+
+	if c.month, err = parseCronField(fields[3], 1, 12, cronMonthNames); err != nil {
+		return nil, fmt.Errorf("%w: cron month: %w", ErrInvalidConfig, err)
+	}
+
+	// Day of week accepts 7 as an alias for Sunday
+	if c.dow, err = parseCronField(fields[4], 0, 7, cronDayNames); err != nil {
+		return nil, fmt.Errorf("%w: cron day of week: %w", ErrInvalidConfig, err)
+	}
+
+	if c.dow&(1<<7) != 0 {
+		c.dow |= 1
+	}
+
+	c.anyDom = fields[2] == "*" || fields[2] == "?"
+	c.anyDow = fields[4] == "*" || fields[4] == "?"
+
+	return c, nil
+}
+
+// String returns the original expression.
+func (c *CronSchedule) String() string {
+	return c.expr
+}
+
+// Next returns the first matching time strictly after t, or the zero time if
+// none exists within five years.
+func (c *CronSchedule) Next(t time.Time) time.Time {
+	t = t.In(c.location).Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if c.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, c.location)
+
+			continue
+		}
+
+		if !c.matchDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, c.location)
+
+			continue
+		}
+
+		if c.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, c.location)
+
+			continue
+		}
+
+		if c.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+
+			continue
+		}
+
+		return t
+	}
+
+	return time.Time{}
+}
+
+// matchDay applies the cron day-of-month/day-of-week rules.
+func (c *CronSchedule) matchDay(t time.Time) bool {
+	domMatch := c.dom&(1<<uint(t.Day())) != 0
+	dowMatch := c.dow&(1<<uint(t.Weekday())) != 0
+
+	switch {
+	case c.anyDom && c.anyDow:
+		return true
+	case c.anyDom:
+		return dowMatch
+	case c.anyDow:
+		return domMatch
+	default:
+		return domMatch || dowMatch
+	}
+}
+
+// parseCronField parses one comma-separated cron field into a bit set.
+func parseCronField(field string, lo, hi int, names map[string]int) (uint64, error) {
+	var bits uint64
+
+	for part := range strings.SplitSeq(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+
+		step := 1
+
+		if hasStep {
+			var err error
+
+			step, err = strconv.Atoi(stepPart)
+			if err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step %q", stepPart)
+			}
+		}
+
+		start, end := lo, hi
+
+		switch {
+		case rangePart == "*" || rangePart == "?":
+		case strings.Contains(rangePart, "-"):
+			from, to, _ := strings.Cut(rangePart, "-")
+
+			var err error
+
+			if start, err = parseCronValue(from, lo, hi, names); err != nil {
+				return 0, err
+			}
+
+			if end, err = parseCronValue(to, lo, hi, names); err != nil {
+				return 0, err
+			}
+
+			if start > end {
+				return 0, fmt.Errorf("invalid range %q", rangePart)
+			}
+		default:
+			value, err := parseCronValue(rangePart, lo, hi, names)
+			if err != nil {
+				return 0, err
+			}
+
+			start = value
+			if !hasStep {
+				end = value
+			}
+		}
+
+		for v := start; v <= end; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+
+	return bits, nil
+}
+
+// parseCronValue parses a number or name within bounds.
+func parseCronValue(s string, lo, hi int, names map[string]int) (int, error) {
+	if v, ok := names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", s)
+	}
+
+	if v < lo || v > hi {
+		return 0, fmt.Errorf("value %d out of range %d-%d", v, lo, hi)
+	}
+
+	return v, nil
+}
@@ -0,0 +1,374 @@
+package sdk
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// WorkflowDiagramOption configures workflow diagram rendering.
+type WorkflowDiagramOption func(*workflowDiagramOptions)
+
+type workflowDiagramOptions struct {
+	execution *WorkflowExecution
+	direction string
+}
+
+// WithExecutionOverlay colors each node by its status in the given execution
+// and annotates it with the node's duration.
+func WithExecutionOverlay(execution *WorkflowExecution) WorkflowDiagramOption {
+	return func(o *workflowDiagramOptions) {
+		o.execution = execution
+	}
+}
+
+// WithDiagramDirection sets the layout direction: "TB" (default) or "LR".
+func WithDiagramDirection(direction string) WorkflowDiagramOption {
+	return func(o *workflowDiagramOptions) {
+		o.direction = direction
+	}
+}
+
+// nodeStatusColors maps node statuses to fill and stroke colors.
+var nodeStatusColors = map[NodeStatus][2]string{
+	NodeStatusPending:   {"#f8f9fa", "#adb5bd"},
+	NodeStatusRunning:   {"#cfe2ff", "#0d6efd"},
+	NodeStatusWaiting:   {"#fff3cd", "#ffc107"},
+	NodeStatusCompleted: {"#d1e7dd", "#198754"},
+	NodeStatusFailed:    {"#f8d7da", "#dc3545"},
+	NodeStatusSkipped:   {"#e9ecef", "#6c757d"},
+}
+
+// diagramNode is a node prepared for rendering.
+type diagramNode struct {
+	node   *WorkflowNode
+	label  string
+	status NodeStatus
+}
+
+// diagramEdge is an edge prepared for rendering.
+type diagramEdge struct {
+	from, to string
+	label    string
+	kind     string // "", "error" or "compensation"
+}
+
+// ToMermaid renders the workflow as a Mermaid flowchart.
+func (w *Workflow) ToMermaid(opts ...WorkflowDiagramOption) string {
+	o := newWorkflowDiagramOptions(opts)
+	nodes, edges := w.diagramGraph(o)
+	ids := mermaidIDs(nodes)
+
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "flowchart %s\n", o.direction)
+
+	for _, n := range nodes {
+		open, closing := mermaidShape(n.node.Type)
+		fmt.Fprintf(&sb, "    %s%s\"%s\"%s\n", ids[n.node.ID], open, mermaidEscape(n.label), closing)
+	}
+
+	for _, e := range edges {
+		arrow := "-->"
+
+		switch e.kind {
+		case "error":
+			arrow = "-.->"
+		case "compensation":
+			arrow = "-.-"
+		}
+
+		if e.label != "" {
+			fmt.Fprintf(&sb, "    %s %s|\"%s\"| %s\n", ids[e.from], arrow, mermaidEscape(e.label), ids[e.to])
+		} else {
+			fmt.Fprintf(&sb, "    %s %s %s\n", ids[e.from], arrow, ids[e.to])
+		}
+	}
+
+	if o.execution != nil {
+		byStatus := make(map[NodeStatus][]string)
+		for _, n := range nodes {
+			byStatus[n.status] = append(byStatus[n.status], ids[n.node.ID])
+		}
+
+		for _, status := range sortedStatuses(byStatus) {
+			colors := nodeStatusColors[status]
+			fmt.Fprintf(&sb, "    classDef %s fill:%s,stroke:%s\n", status, colors[0], colors[1])
+			fmt.Fprintf(&sb, "    class %s %s\n", strings.Join(byStatus[status], ","), status)
+		}
+	}
+
+	return sb.String()
+}
+
+// ToDOT renders the workflow as a Graphviz DOT digraph.
+func (w *Workflow) ToDOT(opts ...WorkflowDiagramOption) string {
+	o := newWorkflowDiagramOptions(opts)
+	nodes, edges := w.diagramGraph(o)
+
+	var sb strings.Builder
+
+	w.mu.RLock()
+	fmt.Fprintf(&sb, "digraph %s {\n", dotQuote(w.ID))
+	w.mu.RUnlock()
+
+	fmt.Fprintf(&sb, "    rankdir=%s;\n", o.direction)
+	sb.WriteString("    node [fontname=\"Helvetica\"];\n")
+
+	for _, n := range nodes {
+		attrs := []string{
+			"label=" + dotQuote(n.label),
+			"shape=" + dotShape(n.node.Type),
+		}
+
+		styles := []string{}
+		if n.node.Type == NodeTypeAgent {
+			styles = append(styles, "rounded")
+		}
+
+		if o.execution != nil {
+			colors := nodeStatusColors[n.status]
+			styles = append(styles, "filled")
+			attrs = append(attrs, "fillcolor="+dotQuote(colors[0]), "color="+dotQuote(colors[1]))
+		}
+
+		if len(styles) > 0 {
+			attrs = append(attrs, "style="+dotQuote(strings.Join(styles, ",")))
+		}
+
+		fmt.Fprintf(&sb, "    %s [%s];\n", dotQuote(n.node.ID), strings.Join(attrs, ", "))
+	}
+
+	for _, e := range edges {
+		attrs := []string{}
+
+		if e.label != "" {
+			attrs = append(attrs, "label="+dotQuote(e.label))
+		}
+
+		switch e.kind {
+		case "error":
+			attrs = append(attrs, "style=dashed", "color=\"#dc3545\"")
+		case "compensation":
+			attrs = append(attrs, "style=dotted", "arrowhead=none")
+		}
+
+		if len(attrs) > 0 {
+			fmt.Fprintf(&sb, "    %s -> %s [%s];\n", dotQuote(e.from), dotQuote(e.to), strings.Join(attrs, ", "))
+		} else {
+			fmt.Fprintf(&sb, "    %s -> %s;\n", dotQuote(e.from), dotQuote(e.to))
+		}
+	}
+
+	sb.WriteString("}\n")
+
+	return sb.String()
+}
+
+// ToMermaidArtifact renders the workflow as a Mermaid diagram artifact.
+func (w *Workflow) ToMermaidArtifact(opts ...WorkflowDiagramOption) *Artifact {
+	w.mu.RLock()
+	name := w.Name
+	w.mu.RUnlock()
+
+	return NewMermaidArtifact(name, w.ToMermaid(opts...))
+}
+
+// newWorkflowDiagramOptions applies options over the defaults.
+func newWorkflowDiagramOptions(opts []WorkflowDiagramOption) *workflowDiagramOptions {
+	o := &workflowDiagramOptions{direction: "TB"}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// diagramGraph collects nodes and edges in a stable order.
+func (w *Workflow) diagramGraph(o *workflowDiagramOptions) ([]diagramNode, []diagramEdge) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	var nodeExecs map[string]*NodeExecution
+
+	if o.execution != nil {
+		o.execution.mu.RLock()
+		nodeExecs = make(map[string]*NodeExecution, len(o.execution.NodeExecutions))
+
+		for id, ne := range o.execution.NodeExecutions {
+			copied := *ne
+			nodeExecs[id] = &copied
+		}
+
+		o.execution.mu.RUnlock()
+	}
+
+	ids := make([]string, 0, len(w.Nodes))
+	for id := range w.Nodes {
+		ids = append(ids, id)
+	}
+
+	sort.Strings(ids)
+
+	nodes := make([]diagramNode, 0, len(ids))
+	edges := make([]diagramEdge, 0)
+
+	for _, id := range ids {
+		node := w.Nodes[id]
+
+		label := node.Name
+		if label == "" {
+			label = node.ID
+		}
+
+		label = fmt.Sprintf("%s\n(%s)", label, node.Type)
+		status := NodeStatusPending
+
+		if ne, ok := nodeExecs[id]; ok {
+			status = ne.Status
+
+			if !ne.EndTime.IsZero() {
+				label += "\n" + ne.EndTime.Sub(ne.StartTime).Round(time.Millisecond).String()
+			}
+		}
+
+		nodes = append(nodes, diagramNode{node: node, label: label, status: status})
+
+		for _, to := range w.Edges[id] {
+			edge := diagramEdge{from: id, to: to}
+			if node.Type == NodeTypeCondition && node.Condition != "" {
+				edge.label = node.Condition
+			}
+
+			edges = append(edges, edge)
+		}
+
+		for _, to := range w.ErrorEdges[id] {
+			edges = append(edges, diagramEdge{from: id, to: to, label: "on error", kind: "error"})
+		}
+
+		if node.Compensation != "" {
+			edges = append(edges, diagramEdge{from: id, to: node.Compensation, label: "compensate", kind: "compensation"})
+		}
+	}
+
+	return nodes, edges
+}
+
+// mermaidShape returns the opening and closing brackets for a node type.
+func mermaidShape(t NodeType) (string, string) {
+	switch t {
+	case NodeTypeAgent:
+		return "([", "])"
+	case NodeTypeCondition:
+		return "{", "}"
+	case NodeTypeTransform:
+		return "[/", "/]"
+	case NodeTypeWait:
+		return "((", "))"
+	case NodeTypeSubWorkflow:
+		return "[[", "]]"
+	case NodeTypeApproval, NodeTypeSignal:
+		return "{{", "}}"
+	default:
+		return "[", "]"
+	}
+}
+
+// dotShape returns the Graphviz shape for a node type.
+func dotShape(t NodeType) string {
+	switch t {
+	case NodeTypeCondition:
+		return "diamond"
+	case NodeTypeTransform:
+		return "parallelogram"
+	case NodeTypeWait:
+		return "circle"
+	case NodeTypeSubWorkflow:
+		return "component"
+	case NodeTypeApproval, NodeTypeSignal:
+		return "hexagon"
+	default:
+		return "box"
+	}
+}
+
+// mermaidReserved lists words Mermaid does not accept as node identifiers.
+var mermaidReserved = map[string]bool{
+	"end": true, "graph": true, "flowchart": true, "subgraph": true, "style": true,
+	"class": true, "classdef": true, "click": true, "linkstyle": true, "direction": true,
+}
+
+// mermaidIDs assigns each node a unique Mermaid identifier. Node IDs that are
+// already safe keep their ID; others are sanitized, reserved words get a "_"
+// suffix, and identifiers that would collide get a numeric suffix.
+func mermaidIDs(nodes []diagramNode) map[string]string {
+	ids := make(map[string]string, len(nodes))
+	used := make(map[string]bool, len(nodes))
+
+	for _, n := range nodes {
+		if id := n.node.ID; mermaidID(id) == id && !mermaidReserved[strings.ToLower(id)] {
+			ids[id] = id
+			used[id] = true
+		}
+	}
+
+	for _, n := range nodes {
+		if _, ok := ids[n.node.ID]; ok {
+			continue
+		}
+
+		base := mermaidID(n.node.ID)
+		if mermaidReserved[strings.ToLower(base)] {
+			base += "_"
+		}
+
+		id := base
+		for i := 2; used[id]; i++ {
+			id = fmt.Sprintf("%s_%d", base, i)
+		}
+
+		ids[n.node.ID] = id
+		used[id] = true
+	}
+
+	return ids
+}
+
+// mermaidID converts a node ID into a safe Mermaid identifier.
+func mermaidID(id string) string {
+	var sb strings.Builder
+
+	for _, r := range id {
+		if r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			sb.WriteRune(r)
+		} else {
+			sb.WriteRune('_')
+		}
+	}
+
+	return sb.String()
+}
+
+// mermaidEscape escapes text for use inside a quoted Mermaid label.
+func mermaidEscape(s string) string {
+	return strings.NewReplacer(`"`, "#quot;", "\n", "<br/>").Replace(s)
+}
+
+// dotQuote quotes a string as a DOT ID.
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+// sortedStatuses returns the statuses of a grouping in sorted order.
+func sortedStatuses(byStatus map[NodeStatus][]string) []NodeStatus {
+	statuses := make([]NodeStatus, 0, len(byStatus))
+	for status := range byStatus {
+		statuses = append(statuses, status)
+	}
+
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i] < statuses[j] })
+
+	return statuses
+}
@@ -0,0 +1,111 @@
+package sdk
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func newDiagramTestWorkflow() *Workflow {
+	wf := NewWorkflow("orders", "Orders", nil, nil)
+	_ = wf.AddNode(&WorkflowNode{ID: "fetch", Type: NodeTypeTool, ToolName: "fetch_order"})
+	_ = wf.AddNode(&WorkflowNode{ID: "check", Type: NodeTypeCondition, Condition: "fetch.total > 100"})
+	_ = wf.AddNode(&WorkflowNode{ID: "review", Name: "Manager \"review\"", Type: NodeTypeApproval})
+	_ = wf.AddNode(&WorkflowNode{ID: "notify-team", Type: NodeTypeAgent, AgentID: "notifier"})
+	_ = wf.AddEdge("fetch", "check")
+	_ = wf.AddEdge("check", "review")
+	_ = wf.AddErrorEdge("fetch", "notify-team")
+	_ = wf.SetStartNode("fetch")
+
+	return wf
+}
+
+func TestWorkflow_ToMermaid(t *testing.T) {
+	out := newDiagramTestWorkflow().ToMermaid(WithDiagramDirection("LR"))
+
+	for _, want := range []string{
+		"flowchart LR",
+		`fetch["fetch<br/>(tool)"]`,
+		`check{"check<br/>(condition)"}`,
+		`review{{"Manager #quot;review#quot;<br/>(approval)"}}`,
+		`notify_team(["notify-team<br/>(agent)"])`,
+		`check -->|"fetch.total > 100"| review`,
+		`fetch -.->|"on error"| notify_team`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected Mermaid output to contain %q, got:\n%s", want, out)
+		}
+	}
+
+	if strings.Contains(out, "classDef") {
+		t.Error("expected no status classes without an execution overlay")
+	}
+}
+
+func TestWorkflow_ToMermaid_UniqueIDs(t *testing.T) {
+	wf := NewWorkflow("ids", "IDs", nil, nil)
+	_ = wf.AddNode(&WorkflowNode{ID: "a-b", Type: NodeTypeTransform, Transform: "1"})
+	_ = wf.AddNode(&WorkflowNode{ID: "a_b", Type: NodeTypeTransform, Transform: "1"})
+	_ = wf.AddNode(&WorkflowNode{ID: "end", Type: NodeTypeTransform, Transform: "1"})
+	_ = wf.AddEdge("a-b", "a_b")
+	_ = wf.AddEdge("a_b", "end")
+	_ = wf.SetStartNode("a-b")
+
+	out := wf.ToMermaid()
+
+	for _, want := range []string{
+		`a_b[/"a_b<br/>(transform)"/]`,
+		`a_b_2[/"a-b<br/>(transform)"/]`,
+		`end_[/"end<br/>(transform)"/]`,
+		`a_b_2 --> a_b`,
+		`a_b --> end_`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected Mermaid output to contain %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestWorkflow_ToDOT(t *testing.T) {
+	out := newDiagramTestWorkflow().ToDOT()
+
+	for _, want := range []string{
+		`digraph "orders" {`,
+		`"check" [label="check\n(condition)", shape=diamond];`,
+		`"review" [label="Manager \"review\"\n(approval)", shape=hexagon];`,
+		`"check" -> "review" [label="fetch.total > 100"];`,
+		`"fetch" -> "notify-team" [label="on error", style=dashed`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected DOT output to contain %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestWorkflow_Diagram_ExecutionOverlay(t *testing.T) {
+	wf := newDiagramTestWorkflow()
+	start := time.Now()
+
+	execution := &WorkflowExecution{
+		NodeExecutions: map[string]*NodeExecution{
+			"fetch": {NodeID: "fetch", Status: NodeStatusCompleted, StartTime: start, EndTime: start.Add(1500 * time.Millisecond)},
+			"check": {NodeID: "check", Status: NodeStatusFailed, StartTime: start, EndTime: start.Add(2 * time.Millisecond)},
+		},
+	}
+
+	mermaid := wf.ToMermaid(WithExecutionOverlay(execution))
+	for _, want := range []string{"fetch<br/>(tool)<br/>1.5s", "class fetch completed", "class check failed", "class notify_team,review pending"} {
+		if !strings.Contains(mermaid, want) {
+			t.Errorf("expected Mermaid overlay to contain %q, got:\n%s", want, mermaid)
+		}
+	}
+
+	dot := wf.ToDOT(WithExecutionOverlay(execution))
+	if !strings.Contains(dot, `fillcolor="#f8d7da"`) {
+		t.Errorf("expected failed node to be filled red, got:\n%s", dot)
+	}
+
+	if artifact := wf.ToMermaidArtifact(WithExecutionOverlay(execution)); artifact.Type != ArtifactTypeMermaid {
+		t.Errorf("expected mermaid artifact, got %s", artifact.Type)
+	}
+}
@@ -3,336 +3,1489 @@ package sdk
 import (
 	"errors"
 	"fmt"
-	"regexp"
+	"maps"
+	"math"
+	"reflect"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"unicode"
 )
 
-// ExpressionEvaluator evaluates simple expressions for workflow condition and transform nodes.
-// It supports basic comparison operators, logical operators, and field access from context data.
+// ExpressionFunc is a Go function that can be called from workflow expressions.
+type ExpressionFunc func(args ...any) (any, error)
+
+// ExpressionOption configures an ExpressionEvaluator.
+type ExpressionOption func(*ExpressionEvaluator)
+
+// WithExpressionFunction registers a Go function callable from expressions.
+// Registered functions take precedence over built-ins of the same name.
+func WithExpressionFunction(name string, fn ExpressionFunc) ExpressionOption {
+	return func(e *ExpressionEvaluator) {
+		e.functions[name] = fn
+	}
+}
+
+// WithoutExpressionBuiltins disables the built-in functions, so expressions
+// can only call functions registered with WithExpressionFunction.
+func WithoutExpressionBuiltins() ExpressionOption {
+	return func(e *ExpressionEvaluator) {
+		e.noBuiltins = true
+	}
+}
+
+// WithExpressionMaxSteps limits the number of evaluation steps per expression,
+// including iterations of filter and map.
+func WithExpressionMaxSteps(steps int) ExpressionOption {
+	return func(e *ExpressionEvaluator) {
+		e.maxSteps = steps
+	}
+}
+
+// WithExpressionMaxDepth limits how deeply an expression may nest.
+func WithExpressionMaxDepth(depth int) ExpressionOption {
+	return func(e *ExpressionEvaluator) {
+		e.maxDepth = depth
+	}
+}
+
+// WithExpressionMaxSize limits the total size of the strings and lists an
+// expression may build, counted in bytes and list elements. Formatting a list
+// or map as a string counts its formatted length.
+func WithExpressionMaxSize(size int) ExpressionOption {
+	return func(e *ExpressionEvaluator) {
+		e.maxSize = size
+	}
+}
+
+const (
+	defaultExpressionMaxSteps = 10000
+	defaultExpressionMaxDepth = 64
+	defaultExpressionMaxSize  = 4 << 20
+	expressionCacheSize       = 1024
+)
+
+// ExpressionEvaluator evaluates expressions for workflow condition and transform nodes.
+// Expressions are parsed once into an AST and cached, and every evaluation is
+// bounded by step, depth and size limits so untrusted expressions cannot hang
+// a workflow or exhaust its memory.
 //
-// Supported operators:
+// Supported syntax, from lowest to highest precedence:
+//   - Ternary: cond ? a : b
+//   - Null-coalescing: a ?? b (b is used when a is null or missing)
+//   - Logical: or, ||, and, &&, not (keywords are case-insensitive)
 //   - Comparison: ==, !=, <, >, <=, >=
-//   - Logical: and, or, not, &&, ||, !
-//   - Arithmetic (for transforms): +, -, *, /
+//   - Arithmetic: +, -, *, /, % (+ concatenates non-numeric values)
+//   - Unary: !, -
+//   - Access: object.field, data["key"], items[0], fn(args), (expr)
+//
+// Literals are numbers, 'single' or "double" quoted strings, true, false,
+// null/nil and lists like [1, 2, 3].
 //
-// Field access:
-//   - Simple: field_name
-//   - Nested: object.field
-//   - Map access: data["key"]
+// Built-in functions: len, contains, startsWith, endsWith, lower, upper,
+// trim, keys, filter and map. filter and map take a lambda such as
+// filter(items, x => x.score > 0.5).
 //
 // Examples:
 //   - "status == 'completed'"
 //   - "count > 10 and active == true"
-//   - "result.score >= 0.8"
-//   - "value * 2"
-//   - "items.length > 0"
+//   - "(price + tax) * quantity"
+//   - "len(filter(results, r => r.ok)) > 0 ? 'pass' : 'fail'"
+//   - "user.nickname ?? user.name"
 type ExpressionEvaluator struct {
-	// Reserved for future options like custom functions
+	functions map[string]ExpressionFunc
+	maxSteps  int
+	maxDepth  int
+	maxSize   int
+
+	noBuiltins bool
+
+	cache map[string]exprNode
+	mu    sync.RWMutex
+}
+
+// NewExpressionEvaluator creates a new expression evaluator.
+func NewExpressionEvaluator(opts ...ExpressionOption) *ExpressionEvaluator {
+	e := &ExpressionEvaluator{
+		functions: make(map[string]ExpressionFunc),
+		maxSteps:  defaultExpressionMaxSteps,
+		maxDepth:  defaultExpressionMaxDepth,
+		maxSize:   defaultExpressionMaxSize,
+		cache:     make(map[string]exprNode),
+	}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	return e
+}
+
+// RegisterFunction registers a Go function callable from expressions.
+func (e *ExpressionEvaluator) RegisterFunction(name string, fn ExpressionFunc) error {
+	if name == "" || fn == nil {
+		return fmt.Errorf("%w: function name and implementation are required", ErrInvalidConfig)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.functions[name] = fn
+
+	return nil
+}
+
+// Validate parses an expression without evaluating it.
+func (e *ExpressionEvaluator) Validate(expr string) error {
+	_, err := e.compile(expr)
+
+	return err
+}
+
+// References returns the names of the variables an expression reads from its
+// data, in order of first use. Lambda parameters and functions are excluded.
+func (e *ExpressionEvaluator) References(expr string) ([]string, error) {
+	node, err := e.compile(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+
+	collectReferences(node, nil, &names)
+
+	return names, nil
+}
+
+// collectReferences appends the free identifiers of node to names.
+func collectReferences(node exprNode, bound []string, names *[]string) {
+	switch n := node.(type) {
+	case *identNode:
+		if !slices.Contains(bound, n.name) && !slices.Contains(*names, n.name) {
+			*names = append(*names, n.name)
+		}
+	case *memberNode:
+		collectReferences(n.target, bound, names)
+	case *indexNode:
+		collectReferences(n.target, bound, names)
+		collectReferences(n.index, bound, names)
+	case *unaryNode:
+		collectReferences(n.operand, bound, names)
+	case *binaryNode:
+		collectReferences(n.left, bound, names)
+		collectReferences(n.right, bound, names)
+	case *ternaryNode:
+		collectReferences(n.cond, bound, names)
+		collectReferences(n.then, bound, names)
+		collectReferences(n.otherwise, bound, names)
+	case *listNode:
+		for _, item := range n.items {
+			collectReferences(item, bound, names)
+		}
+	case *lambdaNode:
+		collectReferences(n.body, append(slices.Clone(bound), n.param), names)
+	case *callNode:
+		for _, arg := range n.args {
+			collectReferences(arg, bound, names)
+		}
+	}
+}
+
+// EvaluateCondition evaluates a condition expression and returns a boolean result.
+func (e *ExpressionEvaluator) EvaluateCondition(expr string, data map[string]any) (bool, error) {
+	value, err := e.evaluate(expr, data)
+	if err != nil {
+		return false, err
+	}
+
+	return e.toBool(value), nil
+}
+
+// EvaluateTransform evaluates a transform expression and returns the result.
+func (e *ExpressionEvaluator) EvaluateTransform(expr string, data map[string]any) (any, error) {
+	return e.evaluate(expr, data)
+}
+
+// evaluate compiles (or reuses) an expression and evaluates it against data.
+func (e *ExpressionEvaluator) evaluate(expr string, data map[string]any) (any, error) {
+	node, err := e.compile(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	env := &exprEnv{evaluator: e, data: data, steps: new(int), size: new(int)}
+
+	value, err := node.eval(env)
+	if err != nil {
+		return nil, &ExpressionError{Expression: strings.TrimSpace(expr), Message: "evaluation failed", Cause: err}
+	}
+
+	return value, nil
+}
+
+// compile parses an expression, caching the AST by source text.
+func (e *ExpressionEvaluator) compile(expr string) (exprNode, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return nil, errors.New("empty expression")
+	}
+
+	e.mu.RLock()
+	node, ok := e.cache[expr]
+	e.mu.RUnlock()
+
+	if ok {
+		return node, nil
+	}
+
+	tokens, err := lexExpression(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &exprParser{expr: expr, tokens: tokens, maxDepth: e.maxDepth}
+
+	node, err = p.parse()
+	if err != nil {
+		return nil, err
+	}
+
+	e.mu.Lock()
+
+	// Keep the cache bounded for callers that generate expressions dynamically
+	if len(e.cache) >= expressionCacheSize {
+		clear(e.cache)
+	}
+
+	e.cache[expr] = node
+	e.mu.Unlock()
+
+	return node, nil
+}
+
+// function looks up a registered or built-in function.
+func (e *ExpressionEvaluator) function(name string) (ExpressionFunc, bool) {
+	e.mu.RLock()
+	fn, ok := e.functions[name]
+	e.mu.RUnlock()
+
+	return fn, ok
+}
+
+// Lexer
+
+type exprTokenKind int
+
+const (
+	tokEOF exprTokenKind = iota
+	tokNumber
+	tokString
+	tokIdent
+	tokOp
+)
+
+type exprToken struct {
+	kind  exprTokenKind
+	text  string
+	value any
+	pos   int
+}
+
+// exprOperators lists operators longest first so that "==" wins over "=".
+var exprOperators = []string{
+	"==", "!=", "<=", ">=", "&&", "||", "??", "=>",
+	"<", ">", "!", "+", "-", "*", "/", "%", "(", ")", "[", "]", ",", ".", "?", ":",
+}
+
+// lexExpression splits an expression into tokens.
+func lexExpression(expr string) ([]exprToken, error) {
+	tokens := make([]exprToken, 0)
+	runes := []rune(expr)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case unicode.IsDigit(r):
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.' ||
+				((runes[i] == 'e' || runes[i] == 'E') && i+1 < len(runes)) ||
+				((runes[i] == '+' || runes[i] == '-') && (runes[i-1] == 'e' || runes[i-1] == 'E'))) {
+				i++
+			}
+
+			text := string(runes[start:i])
+
+			num, err := strconv.ParseFloat(text, 64)
+			if err != nil {
+				return nil, &ExpressionError{Expression: expr, Message: fmt.Sprintf("invalid number %q at position %d", text, start)}
+			}
+
+			tokens = append(tokens, exprToken{kind: tokNumber, text: text, value: num, pos: start})
+		case r == '\'' || r == '"':
+			start := i
+			quote := r
+
+			var sb strings.Builder
+
+			i++
+
+			for ; i < len(runes) && runes[i] != quote; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+
+					switch runes[i] {
+					case 'n':
+						sb.WriteRune('\n')
+					case 't':
+						sb.WriteRune('\t')
+					default:
+						sb.WriteRune(runes[i])
+					}
+
+					continue
+				}
+
+				sb.WriteRune(runes[i])
+			}
+
+			if i >= len(runes) {
+				return nil, &ExpressionError{Expression: expr, Message: fmt.Sprintf("unterminated string at position %d", start)}
+			}
+
+			i++
+
+			tokens = append(tokens, exprToken{kind: tokString, text: string(runes[start:i]), value: sb.String(), pos: start})
+		case r == '_' || unicode.IsLetter(r):
+			start := i
+			for i < len(runes) && (runes[i] == '_' || unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i])) {
+				i++
+			}
+
+			tokens = append(tokens, exprToken{kind: tokIdent, text: string(runes[start:i]), pos: start})
+		default:
+			matched := false
+
+			for _, op := range exprOperators {
+				if strings.HasPrefix(string(runes[i:]), op) {
+					tokens = append(tokens, exprToken{kind: tokOp, text: op, pos: i})
+					i += len([]rune(op))
+					matched = true
+
+					break
+				}
+			}
+
+			if !matched {
+				return nil, &ExpressionError{Expression: expr, Message: fmt.Sprintf("unexpected character %q at position %d", r, i)}
+			}
+		}
+	}
+
+	return append(tokens, exprToken{kind: tokEOF, pos: len(runes)}), nil
+}
+
+// Parser
+
+type exprParser struct {
+	expr     string
+	tokens   []exprToken
+	pos      int
+	depth    int
+	maxDepth int
+}
+
+// parse parses the whole expression.
+func (p *exprParser) parse() (exprNode, error) {
+	node, err := p.parseTernary()
+	if err != nil {
+		return nil, err
+	}
+
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, p.errorf(tok, "unexpected %q", tok.text)
+	}
+
+	return node, nil
+}
+
+func (p *exprParser) peek() exprToken {
+	return p.tokens[p.pos]
+}
+
+func (p *exprParser) next() exprToken {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+
+	return tok
+}
+
+// isOp reports whether the current token is one of the given operators.
+func (p *exprParser) isOp(ops ...string) bool {
+	tok := p.peek()
+
+	return tok.kind == tokOp && slices.Contains(ops, tok.text)
+}
+
+// isKeyword reports whether the current token is a case-insensitive keyword.
+func (p *exprParser) isKeyword(keyword string) bool {
+	tok := p.peek()
+
+	return tok.kind == tokIdent && strings.EqualFold(tok.text, keyword)
+}
+
+func (p *exprParser) expect(op string) error {
+	if !p.isOp(op) {
+		tok := p.peek()
+		if tok.kind == tokEOF {
+			return p.errorf(tok, "expected %q but reached end of expression", op)
+		}
+
+		return p.errorf(tok, "expected %q but found %q", op, tok.text)
+	}
+
+	p.next()
+
+	return nil
+}
+
+func (p *exprParser) errorf(tok exprToken, format string, args ...any) error {
+	return &ExpressionError{
+		Expression: p.expr,
+		Message:    fmt.Sprintf(format, args...) + fmt.Sprintf(" at position %d", tok.pos),
+	}
+}
+
+// enter guards against deeply nested expressions.
+func (p *exprParser) enter() error {
+	p.depth++
+	if p.maxDepth > 0 && p.depth > p.maxDepth {
+		return &ExpressionError{
+			Expression: p.expr,
+			Message:    fmt.Sprintf("nesting deeper than %d", p.maxDepth),
+			Cause:      ErrExpressionLimitExceeded,
+		}
+	}
+
+	return nil
+}
+
+func (p *exprParser) leave() {
+	p.depth--
+}
+
+func (p *exprParser) parseTernary() (exprNode, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer p.leave()
+
+	cond, err := p.parseCoalesce()
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.isOp("?") {
+		return cond, nil
+	}
+
+	p.next()
+
+	then, err := p.parseTernary()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+
+	otherwise, err := p.parseTernary()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ternaryNode{cond: cond, then: then, otherwise: otherwise}, nil
+}
+
+func (p *exprParser) parseCoalesce() (exprNode, error) {
+	left, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.isOp("??") {
+		p.next()
+
+		right, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		left = &binaryNode{op: "??", left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *exprParser) parseOr() (exprNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.isOp("||") || p.isKeyword("or") {
+		p.next()
+
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+
+		left = &binaryNode{op: "||", left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *exprParser) parseAnd() (exprNode, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.isOp("&&") || p.isKeyword("and") {
+		p.next()
+
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+
+		left = &binaryNode{op: "&&", left: left, right: right}
+	}
+
+	return left, nil
+}
+
+// parseNot handles the "not" keyword, which binds looser than comparisons
+// so that "not a == b" negates the comparison.
+func (p *exprParser) parseNot() (exprNode, error) {
+	if !p.isKeyword("not") {
+		return p.parseComparison()
+	}
+
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer p.leave()
+
+	p.next()
+
+	operand, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+
+	return &unaryNode{op: "!", operand: operand}, nil
+}
+
+func (p *exprParser) parseComparison() (exprNode, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.isOp("==", "!=", "<", ">", "<=", ">=") {
+		op := p.next().text
+
+		right, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+
+		left = &binaryNode{op: op, left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *exprParser) parseAdditive() (exprNode, error) {
+	left, err := p.parseMultiplicative()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.isOp("+", "-") {
+		op := p.next().text
+
+		right, err := p.parseMultiplicative()
+		if err != nil {
+			return nil, err
+		}
+
+		left = &binaryNode{op: op, left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *exprParser) parseMultiplicative() (exprNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.isOp("*", "/", "%") {
+		op := p.next().text
+
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		left = &binaryNode{op: op, left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *exprParser) parseUnary() (exprNode, error) {
+	if !p.isOp("!", "-") {
+		return p.parsePostfix()
+	}
+
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer p.leave()
+
+	op := p.next().text
+
+	operand, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	return &unaryNode{op: op, operand: operand}, nil
+}
+
+func (p *exprParser) parsePostfix() (exprNode, error) {
+	node, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		switch {
+		case p.isOp("."):
+			p.next()
+
+			tok := p.next()
+			if tok.kind != tokIdent {
+				return nil, p.errorf(tok, "expected field name after '.'")
+			}
+
+			node = &memberNode{target: node, name: tok.text}
+		case p.isOp("["):
+			p.next()
+
+			index, err := p.parseTernary()
+			if err != nil {
+				return nil, err
+			}
+
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+
+			node = &indexNode{target: node, index: index}
+		default:
+			return node, nil
+		}
+	}
+}
+
+func (p *exprParser) parsePrimary() (exprNode, error) {
+	tok := p.next()
+
+	switch tok.kind {
+	case tokNumber, tokString:
+		return &literalNode{value: tok.value}, nil
+	case tokIdent:
+		switch strings.ToLower(tok.text) {
+		case "true":
+			return &literalNode{value: true}, nil
+		case "false":
+			return &literalNode{value: false}, nil
+		case "null", "nil":
+			return &literalNode{value: nil}, nil
+		}
+
+		if p.isOp("=>") {
+			p.next()
+
+			body, err := p.parseTernary()
+			if err != nil {
+				return nil, err
+			}
+
+			return &lambdaNode{param: tok.text, body: body}, nil
+		}
+
+		if p.isOp("(") {
+			p.next()
+
+			args, err := p.parseList(")")
+			if err != nil {
+				return nil, err
+			}
+
+			return &callNode{name: tok.text, args: args}, nil
+		}
+
+		return &identNode{name: tok.text}, nil
+	case tokOp:
+		switch tok.text {
+		case "(":
+			node, err := p.parseTernary()
+			if err != nil {
+				return nil, err
+			}
+
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+
+			return node, nil
+		case "[":
+			items, err := p.parseList("]")
+			if err != nil {
+				return nil, err
+			}
+
+			return &listNode{items: items}, nil
+		}
+	case tokEOF:
+		return nil, p.errorf(tok, "unexpected end of expression")
+	}
+
+	return nil, p.errorf(tok, "unexpected %q", tok.text)
 }
 
-// NewExpressionEvaluator creates a new expression evaluator.
-func NewExpressionEvaluator() *ExpressionEvaluator {
-	return &ExpressionEvaluator{}
+// parseList parses comma-separated expressions up to the closing token.
+func (p *exprParser) parseList(closing string) ([]exprNode, error) {
+	items := make([]exprNode, 0)
+
+	if p.isOp(closing) {
+		p.next()
+
+		return items, nil
+	}
+
+	for {
+		item, err := p.parseTernary()
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, item)
+
+		if !p.isOp(",") {
+			break
+		}
+
+		p.next()
+	}
+
+	if err := p.expect(closing); err != nil {
+		return nil, err
+	}
+
+	return items, nil
 }
 
-// EvaluateCondition evaluates a condition expression and returns a boolean result.
-func (e *ExpressionEvaluator) EvaluateCondition(expr string, data map[string]any) (bool, error) {
-	if expr == "" {
-		return false, errors.New("empty expression")
+// AST
+
+// exprNode is a node of a parsed expression.
+type exprNode interface {
+	eval(env *exprEnv) (any, error)
+}
+
+// exprEnv holds the state of a single evaluation.
+type exprEnv struct {
+	evaluator *ExpressionEvaluator
+	data      map[string]any
+	vars      map[string]any // lambda parameters
+	steps     *int
+	size      *int // bytes and list elements built so far
+}
+
+// step counts an evaluation step and enforces the step limit.
+func (env *exprEnv) step() error {
+	*env.steps++
+
+	if limit := env.evaluator.maxSteps; limit > 0 && *env.steps > limit {
+		return fmt.Errorf("%w: more than %d steps", ErrExpressionLimitExceeded, limit)
 	}
 
-	// Normalize the expression
-	expr = strings.TrimSpace(expr)
+	return nil
+}
 
-	// Handle literal booleans
-	lowerExpr := strings.ToLower(expr)
-	if lowerExpr == "true" {
-		return true, nil
+// alloc charges the size of a built string or list and enforces the size limit.
+func (env *exprEnv) alloc(size int) error {
+	*env.size += size
+
+	if limit := env.evaluator.maxSize; limit > 0 && *env.size > limit {
+		return fmt.Errorf("%w: more than %d bytes", ErrExpressionLimitExceeded, limit)
 	}
 
-	if lowerExpr == "false" {
-		return false, nil
+	return nil
+}
+
+// chargeFormat charges the formatted length of a list or map before it is
+// rendered as a string. Lists may share elements, so their formatted form can
+// be far larger than what was built.
+func (env *exprEnv) chargeFormat(v any) error {
+	switch reflect.ValueOf(v).Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+	default:
+		return nil
+	}
+
+	budget := math.MaxInt
+	if limit := env.evaluator.maxSize; limit > 0 {
+		budget = limit - *env.size + 1
+	}
+
+	return env.alloc(formattedSize(reflect.ValueOf(v), budget))
+}
+
+// format renders a value as a string, charging lists and maps first.
+func (env *exprEnv) format(v any) (string, error) {
+	if err := env.chargeFormat(v); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprint(v), nil
+}
+
+// formattedSize approximates the length of a value formatted with %v,
+// stopping early once it exceeds budget.
+func formattedSize(rv reflect.Value, budget int) int {
+	for rv.Kind() == reflect.Interface || rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return 5
+		}
+
+		rv = rv.Elem()
+	}
+
+	switch rv.Kind() {
+	case reflect.String:
+		return rv.Len()
+	case reflect.Slice, reflect.Array:
+		size := 2
+
+		for i := 0; i < rv.Len() && size <= budget; i++ {
+			size += formattedSize(rv.Index(i), budget-size) + 1
+		}
+
+		return size
+	case reflect.Map:
+		size := 5
+
+		iter := rv.MapRange()
+		for size <= budget && iter.Next() {
+			size += formattedSize(iter.Key(), budget-size) + formattedSize(iter.Value(), budget-size) + 2
+		}
+
+		return size
+	default:
+		return 1
+	}
+}
+
+// missingError reports a missing field, which ?? treats as null.
+type missingError struct {
+	msg string
+}
+
+func (e *missingError) Error() string {
+	return e.msg
+}
+
+type literalNode struct {
+	value any
+}
+
+func (n *literalNode) eval(env *exprEnv) (any, error) {
+	return n.value, env.step()
+}
+
+type identNode struct {
+	name string
+}
+
+func (n *identNode) eval(env *exprEnv) (any, error) {
+	if err := env.step(); err != nil {
+		return nil, err
 	}
 
-	// Handle logical operators (lowest precedence)
-	if result, ok, err := e.evaluateLogicalExpression(expr, data); ok {
-		return result, err
+	if v, ok := env.vars[n.name]; ok {
+		return v, nil
 	}
 
-	// Handle comparison operators
-	if result, ok, err := e.evaluateComparisonExpression(expr, data); ok {
-		return result, err
+	if v, ok := env.data[n.name]; ok {
+		return v, nil
 	}
 
-	// Handle field access that returns boolean
-	value, err := e.resolveValue(expr, data)
+	return nil, &missingError{msg: fmt.Sprintf("field '%s' not found", n.name)}
+}
+
+type memberNode struct {
+	target exprNode
+	name   string
+}
+
+func (n *memberNode) eval(env *exprEnv) (any, error) {
+	target, err := n.target.eval(env)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
-	// Convert to boolean
-	return e.toBool(value), nil
+	if err := env.step(); err != nil {
+		return nil, err
+	}
+
+	switch v := target.(type) {
+	case map[string]any:
+		if val, ok := v[n.name]; ok {
+			return val, nil
+		}
+	case map[string]string:
+		if val, ok := v[n.name]; ok {
+			return val, nil
+		}
+	case nil:
+		return nil, &missingError{msg: fmt.Sprintf("cannot access field '%s' on null", n.name)}
+	default:
+		return nil, fmt.Errorf("cannot access field '%s' on non-map value", n.name)
+	}
+
+	return nil, &missingError{msg: fmt.Sprintf("field '%s' not found", n.name)}
 }
 
-// EvaluateTransform evaluates a transform expression and returns the result.
-func (e *ExpressionEvaluator) EvaluateTransform(expr string, data map[string]any) (any, error) {
-	if expr == "" {
-		return nil, errors.New("empty expression")
+type indexNode struct {
+	target exprNode
+	index  exprNode
+}
+
+func (n *indexNode) eval(env *exprEnv) (any, error) {
+	target, err := n.target.eval(env)
+	if err != nil {
+		return nil, err
 	}
 
-	// Normalize the expression
-	expr = strings.TrimSpace(expr)
+	index, err := n.index.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	switch v := target.(type) {
+	case map[string]any:
+		return v[fmt.Sprint(index)], nil
+	case map[string]string:
+		return v[fmt.Sprint(index)], nil
+	case nil:
+		return nil, &missingError{msg: "cannot index null"}
+	}
 
-	// Handle arithmetic expressions
-	if result, ok, err := e.evaluateArithmeticExpression(expr, data); ok {
-		return result, err
+	items, ok := toList(target)
+	if !ok {
+		return nil, fmt.Errorf("cannot index %T", target)
 	}
 
-	// Handle field access/extraction
-	return e.resolveValue(expr, data)
+	i, ok := env.evaluator.toFloat64(index)
+	if !ok || i != math.Trunc(i) {
+		return nil, fmt.Errorf("invalid list index %v", index)
+	}
+
+	if i < 0 {
+		i += float64(len(items))
+	}
+
+	if i < 0 || int(i) >= len(items) {
+		return nil, &missingError{msg: fmt.Sprintf("index %v out of range", index)}
+	}
+
+	return items[int(i)], nil
+}
+
+type unaryNode struct {
+	op      string
+	operand exprNode
 }
 
-// evaluateLogicalExpression handles and/or/not operators.
-func (e *ExpressionEvaluator) evaluateLogicalExpression(expr string, data map[string]any) (bool, bool, error) {
-	// Handle 'not' prefix
-	if strings.HasPrefix(strings.ToLower(expr), "not ") {
-		subExpr := strings.TrimPrefix(expr, "not ")
-		subExpr = strings.TrimPrefix(subExpr, "NOT ")
-		result, err := e.EvaluateCondition(subExpr, data)
+func (n *unaryNode) eval(env *exprEnv) (any, error) {
+	value, err := n.operand.eval(env)
+	if err != nil {
+		return nil, err
+	}
 
-		return !result, true, err
+	if err := env.step(); err != nil {
+		return nil, err
 	}
 
-	// Handle '!' prefix
-	if after, ok := strings.CutPrefix(expr, "!"); ok {
-		subExpr := after
-		result, err := e.EvaluateCondition(subExpr, data)
+	if n.op == "!" {
+		return !env.evaluator.toBool(value), nil
+	}
 
-		return !result, true, err
+	num, ok := env.evaluator.toFloat64(value)
+	if !ok {
+		return nil, fmt.Errorf("cannot negate non-numeric value %v", value)
 	}
 
-	// Find 'and' or '&&' (handle both)
-	andPatterns := []string{" and ", " AND ", " && ", "&&"}
-	for _, pattern := range andPatterns {
-		if idx := strings.Index(expr, pattern); idx > 0 {
-			left := strings.TrimSpace(expr[:idx])
-			right := strings.TrimSpace(expr[idx+len(pattern):])
+	return -num, nil
+}
 
-			leftResult, err := e.EvaluateCondition(left, data)
-			if err != nil {
-				return false, true, err
-			}
-			// Short-circuit evaluation
-			if !leftResult {
-				return false, true, nil
-			}
+type binaryNode struct {
+	op          string
+	left, right exprNode
+}
 
-			rightResult, err := e.EvaluateCondition(right, data)
+func (n *binaryNode) eval(env *exprEnv) (any, error) {
+	e := env.evaluator
 
-			return leftResult && rightResult, true, err
+	left, err := n.left.eval(env)
+
+	if n.op == "??" {
+		var missing *missingError
+		if errors.As(err, &missing) || (err == nil && left == nil) {
+			return n.right.eval(env)
 		}
+
+		return left, err
 	}
 
-	// Find 'or' or '||'
-	orPatterns := []string{" or ", " OR ", " || ", "||"}
-	for _, pattern := range orPatterns {
-		if idx := strings.Index(expr, pattern); idx > 0 {
-			left := strings.TrimSpace(expr[:idx])
-			right := strings.TrimSpace(expr[idx+len(pattern):])
+	if err != nil {
+		return nil, err
+	}
 
-			leftResult, err := e.EvaluateCondition(left, data)
-			if err != nil {
-				return false, true, err
-			}
-			// Short-circuit evaluation
-			if leftResult {
-				return true, true, nil
-			}
+	if err := env.step(); err != nil {
+		return nil, err
+	}
 
-			rightResult, err := e.EvaluateCondition(right, data)
+	// Short-circuit logical operators
+	switch n.op {
+	case "&&":
+		if !e.toBool(left) {
+			return false, nil
+		}
+
+		right, err := n.right.eval(env)
 
-			return leftResult || rightResult, true, err
+		return e.toBool(right), err
+	case "||":
+		if e.toBool(left) {
+			return true, nil
 		}
-	}
 
-	return false, false, nil
-}
+		right, err := n.right.eval(env)
 
-// evaluateComparisonExpression handles comparison operators.
-func (e *ExpressionEvaluator) evaluateComparisonExpression(expr string, data map[string]any) (bool, bool, error) {
-	// Order matters: check longer operators first
-	operators := []string{"==", "!=", ">=", "<=", ">", "<"}
+		return e.toBool(right), err
+	}
 
-	for _, op := range operators {
-		if idx := strings.Index(expr, op); idx > 0 {
-			left := strings.TrimSpace(expr[:idx])
-			right := strings.TrimSpace(expr[idx+len(op):])
+	right, err := n.right.eval(env)
+	if err != nil {
+		return nil, err
+	}
 
-			leftValue, err := e.resolveValue(left, data)
-			if err != nil {
-				return false, true, err
-			}
+	// Comparisons and concatenation may format both operands
+	if err := env.chargeFormat(left); err != nil {
+		return nil, err
+	}
 
-			rightValue, err := e.resolveValue(right, data)
-			if err != nil {
-				return false, true, err
-			}
+	if err := env.chargeFormat(right); err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "==", "!=", "<", ">", "<=", ">=":
+		return e.compare(left, right, n.op)
+	}
 
-			result, err := e.compare(leftValue, rightValue, op)
+	result, err := e.arithmetic(left, right, n.op)
+	if err != nil {
+		return nil, err
+	}
 
-			return result, true, err
+	if s, ok := result.(string); ok {
+		if err := env.alloc(len(s)); err != nil {
+			return nil, err
 		}
 	}
 
-	return false, false, nil
+	return result, nil
+}
+
+type ternaryNode struct {
+	cond, then, otherwise exprNode
 }
 
-// evaluateArithmeticExpression handles arithmetic operators.
-func (e *ExpressionEvaluator) evaluateArithmeticExpression(expr string, data map[string]any) (any, bool, error) {
-	// Handle addition/subtraction (lowest precedence in arithmetic)
-	for _, op := range []string{"+", "-"} {
-		// Find the operator (but not at the start for negative numbers)
-		idx := -1
+func (n *ternaryNode) eval(env *exprEnv) (any, error) {
+	cond, err := n.cond.eval(env)
+	if err != nil {
+		return nil, err
+	}
 
-		for i := 1; i < len(expr); i++ {
-			if string(expr[i]) == op && expr[i-1] != 'e' && expr[i-1] != 'E' { // Not scientific notation
-				idx = i
+	if env.evaluator.toBool(cond) {
+		return n.then.eval(env)
+	}
 
-				break
-			}
+	return n.otherwise.eval(env)
+}
+
+type listNode struct {
+	items []exprNode
+}
+
+func (n *listNode) eval(env *exprEnv) (any, error) {
+	items := make([]any, 0, len(n.items))
+
+	for _, item := range n.items {
+		v, err := item.eval(env)
+		if err != nil {
+			return nil, err
 		}
 
-		if idx > 0 {
-			left := strings.TrimSpace(expr[:idx])
-			right := strings.TrimSpace(expr[idx+1:])
+		items = append(items, v)
+	}
 
-			leftValue, err := e.resolveValue(left, data)
-			if err != nil {
-				return nil, true, err
-			}
+	if err := env.alloc(len(items)); err != nil {
+		return nil, err
+	}
 
-			rightValue, err := e.resolveValue(right, data)
-			if err != nil {
-				return nil, true, err
-			}
+	return items, env.step()
+}
+
+type lambdaNode struct {
+	param string
+	body  exprNode
+}
 
-			result, err := e.arithmetic(leftValue, rightValue, op)
+func (n *lambdaNode) eval(env *exprEnv) (any, error) {
+	return &exprLambda{node: n, env: env}, env.step()
+}
 
-			return result, true, err
-		}
+// exprLambda is a lambda bound to the environment it was created in.
+type exprLambda struct {
+	node *lambdaNode
+	env  *exprEnv
+}
+
+// call evaluates the lambda body with its parameter bound to arg.
+func (l *exprLambda) call(arg any) (any, error) {
+	vars := maps.Clone(l.env.vars)
+	if vars == nil {
+		vars = make(map[string]any)
 	}
 
-	// Handle multiplication/division (higher precedence)
-	for _, op := range []string{"*", "/"} {
-		if idx := strings.Index(expr, op); idx > 0 {
-			left := strings.TrimSpace(expr[:idx])
-			right := strings.TrimSpace(expr[idx+1:])
+	vars[l.node.param] = arg
 
-			leftValue, err := e.resolveValue(left, data)
-			if err != nil {
-				return nil, true, err
-			}
+	return l.node.body.eval(&exprEnv{
+		evaluator: l.env.evaluator,
+		data:      l.env.data,
+		vars:      vars,
+		steps:     l.env.steps,
+		size:      l.env.size,
+	})
+}
 
-			rightValue, err := e.resolveValue(right, data)
-			if err != nil {
-				return nil, true, err
-			}
+type callNode struct {
+	name string
+	args []exprNode
+}
 
-			result, err := e.arithmetic(leftValue, rightValue, op)
+func (n *callNode) eval(env *exprEnv) (any, error) {
+	args := make([]any, 0, len(n.args))
 
-			return result, true, err
+	for _, arg := range n.args {
+		v, err := arg.eval(env)
+		if err != nil {
+			return nil, err
 		}
+
+		args = append(args, v)
 	}
 
-	return nil, false, nil
+	if err := env.step(); err != nil {
+		return nil, err
+	}
+
+	if fn, ok := env.evaluator.function(n.name); ok {
+		return fn(args...)
+	}
+
+	builtin, ok := exprBuiltins[n.name]
+	if !ok || env.evaluator.noBuiltins {
+		return nil, fmt.Errorf("unknown function '%s'", n.name)
+	}
+
+	return builtin(env, args)
 }
 
-// resolveValue resolves a value from the expression - could be a literal or a field path.
-func (e *ExpressionEvaluator) resolveValue(expr string, data map[string]any) (any, error) {
-	expr = strings.TrimSpace(expr)
+// Built-in functions
+
+type exprBuiltin func(env *exprEnv, args []any) (any, error)
+
+var exprBuiltins map[string]exprBuiltin
+
+func init() {
+	// Assigned in init because filter and map evaluate lambdas that may call built-ins
+	exprBuiltins = map[string]exprBuiltin{
+		"len":        builtinLen,
+		"contains":   builtinContains,
+		"startsWith": stringBuiltin2("startsWith", strings.HasPrefix),
+		"endsWith":   stringBuiltin2("endsWith", strings.HasSuffix),
+		"lower":      stringBuiltin1("lower", strings.ToLower),
+		"upper":      stringBuiltin1("upper", strings.ToUpper),
+		"trim":       stringBuiltin1("trim", strings.TrimSpace),
+		"keys":       builtinKeys,
+		"filter":     builtinFilter,
+		"map":        builtinMap,
+	}
+}
 
-	// Check for string literals
-	if (strings.HasPrefix(expr, "'") && strings.HasSuffix(expr, "'")) ||
-		(strings.HasPrefix(expr, "\"") && strings.HasSuffix(expr, "\"")) {
-		return expr[1 : len(expr)-1], nil
+func builtinLen(env *exprEnv, args []any) (any, error) {
+	if len(args) != 1 {
+		return nil, errors.New("len expects 1 argument")
 	}
 
-	// Check for numeric literals
-	if num, err := strconv.ParseFloat(expr, 64); err == nil {
-		return num, nil
+	switch v := args[0].(type) {
+	case nil:
+		return 0, nil
+	case string:
+		return len([]rune(v)), nil
 	}
 
-	// Check for integer literals
-	if num, err := strconv.ParseInt(expr, 10, 64); err == nil {
-		return num, nil
+	rv := reflect.ValueOf(args[0])
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return rv.Len(), nil
+	default:
+		return nil, fmt.Errorf("len: unsupported type %T", args[0])
 	}
+}
 
-	// Check for boolean literals
-	lowerExpr := strings.ToLower(expr)
-	if lowerExpr == "true" {
-		return true, nil
+func builtinContains(env *exprEnv, args []any) (any, error) {
+	if len(args) != 2 {
+		return nil, errors.New("contains expects 2 arguments")
 	}
 
-	if lowerExpr == "false" {
+	switch v := args[0].(type) {
+	case nil:
 		return false, nil
+	case string:
+		substr, err := env.format(args[1])
+		if err != nil {
+			return nil, err
+		}
+
+		return strings.Contains(v, substr), nil
+	case map[string]any:
+		key, err := env.format(args[1])
+		if err != nil {
+			return nil, err
+		}
+
+		_, ok := v[key]
+
+		return ok, nil
+	}
+
+	items, ok := toList(args[0])
+	if !ok {
+		return nil, fmt.Errorf("contains: unsupported type %T", args[0])
+	}
+
+	for _, item := range items {
+		if err := env.step(); err != nil {
+			return nil, err
+		}
+
+		if err := env.chargeFormat(item); err != nil {
+			return nil, err
+		}
+
+		if equal, _ := env.evaluator.compare(item, args[1], "=="); equal {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func builtinKeys(env *exprEnv, args []any) (any, error) {
+	if len(args) != 1 {
+		return nil, errors.New("keys expects 1 argument")
+	}
+
+	rv := reflect.ValueOf(args[0])
+	if rv.Kind() != reflect.Map {
+		return nil, fmt.Errorf("keys: unsupported type %T", args[0])
+	}
+
+	names := make([]string, 0, rv.Len())
+	for _, k := range rv.MapKeys() {
+		names = append(names, fmt.Sprint(k.Interface()))
 	}
 
-	// Check for null/nil
-	if lowerExpr == "null" || lowerExpr == "nil" {
-		return nil, nil
+	sort.Strings(names)
+
+	keys := make([]any, len(names))
+	for i, name := range names {
+		keys[i] = name
 	}
 
-	// Resolve field path
-	return e.resolveFieldPath(expr, data)
+	return keys, env.alloc(len(keys))
 }
 
-// resolveFieldPath resolves a dotted field path like "object.field" or "data.nested.value".
-func (e *ExpressionEvaluator) resolveFieldPath(path string, data map[string]any) (any, error) {
-	// Handle map access syntax: data["key"]
-	if bracketMatch := regexp.MustCompile(`^(\w+)\["([^"]+)"\]$`).FindStringSubmatch(path); len(bracketMatch) == 3 {
-		mapName := bracketMatch[1]
-		key := bracketMatch[2]
+func builtinFilter(env *exprEnv, args []any) (any, error) {
+	items, fn, err := listAndLambda("filter", args)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]any, 0, len(items))
+
+	for _, item := range items {
+		keep, err := fn.call(item)
+		if err != nil {
+			return nil, err
+		}
 
-		mapValue, ok := data[mapName]
-		if !ok {
-			return nil, fmt.Errorf("field '%s' not found", mapName)
+		if env.evaluator.toBool(keep) {
+			out = append(out, item)
 		}
+	}
+
+	return out, env.alloc(len(out))
+}
+
+func builtinMap(env *exprEnv, args []any) (any, error) {
+	items, fn, err := listAndLambda("map", args)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]any, 0, len(items))
 
-		if m, ok := mapValue.(map[string]any); ok {
-			return m[key], nil
+	for _, item := range items {
+		v, err := fn.call(item)
+		if err != nil {
+			return nil, err
 		}
 
-		return nil, fmt.Errorf("'%s' is not a map", mapName)
+		out = append(out, v)
 	}
 
-	// Handle dotted path
-	parts := strings.Split(path, ".")
+	return out, env.alloc(len(out))
+}
 
-	var current any = data
+// listAndLambda validates the (list, lambda) arguments of filter and map.
+func listAndLambda(name string, args []any) ([]any, *exprLambda, error) {
+	if len(args) != 2 {
+		return nil, nil, fmt.Errorf("%s expects 2 arguments", name)
+	}
 
-	for _, part := range parts {
-		switch v := current.(type) {
-		case map[string]any:
-			val, ok := v[part]
-			if !ok {
-				return nil, fmt.Errorf("field '%s' not found in path '%s'", part, path)
-			}
+	fn, ok := args[1].(*exprLambda)
+	if !ok {
+		return nil, nil, fmt.Errorf("%s expects a lambda like x => x > 0 as its second argument", name)
+	}
 
-			current = val
-		case map[string]string:
-			val, ok := v[part]
-			if !ok {
-				return nil, fmt.Errorf("field '%s' not found in path '%s'", part, path)
-			}
+	if args[0] == nil {
+		return []any{}, fn, nil
+	}
 
-			current = val
-		default:
-			return nil, fmt.Errorf("cannot access field '%s' on non-map value", part)
+	items, ok := toList(args[0])
+	if !ok {
+		return nil, nil, fmt.Errorf("%s: unsupported type %T", name, args[0])
+	}
+
+	return items, fn, nil
+}
+
+// stringBuiltin1 adapts a string function of one argument.
+func stringBuiltin1(name string, fn func(string) string) exprBuiltin {
+	return func(env *exprEnv, args []any) (any, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("%s expects 1 argument", name)
+		}
+
+		if args[0] == nil {
+			return nil, nil
+		}
+
+		s, err := env.format(args[0])
+		if err != nil {
+			return nil, err
+		}
+
+		result := fn(s)
+
+		return result, env.alloc(len(result))
+	}
+}
+
+// stringBuiltin2 adapts a string predicate of two arguments.
+func stringBuiltin2(name string, fn func(string, string) bool) exprBuiltin {
+	return func(env *exprEnv, args []any) (any, error) {
+		if len(args) != 2 {
+			return nil, fmt.Errorf("%s expects 2 arguments", name)
+		}
+
+		if args[0] == nil {
+			return false, nil
+		}
+
+		s, err := env.format(args[0])
+		if err != nil {
+			return nil, err
+		}
+
+		other, err := env.format(args[1])
+		if err != nil {
+			return nil, err
 		}
+
+		return fn(s, other), nil
+	}
+}
+
+// toList converts any slice or array to []any.
+func toList(v any) ([]any, bool) {
+	if items, ok := v.([]any); ok {
+		return items, true
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, false
+	}
+
+	items := make([]any, rv.Len())
+	for i := range items {
+		items[i] = rv.Index(i).Interface()
 	}
 
-	return current, nil
+	return items, true
 }
 
 // compare compares two values using the given operator.
@@ -419,6 +1572,12 @@ func (e *ExpressionEvaluator) arithmetic(left, right any, op string) (any, error
 		}
 
 		return leftNum / rightNum, nil
+	case "%":
+		if rightNum == 0 {
+			return nil, errors.New("division by zero")
+		}
+
+		return math.Mod(leftNum, rightNum), nil
 	}
 
 	return nil, fmt.Errorf("unknown arithmetic operator: %s", op)
@@ -461,10 +1620,10 @@ func (e *ExpressionEvaluator) toBool(v any) bool {
 	switch b := v.(type) {
 	case bool:
 		return b
-	case int, int64, int32, uint, uint64, uint32:
-		return b != 0
-	case float64, float32:
-		return b != 0
+	case int, int64, int32, uint, uint64, uint32, float64, float32:
+		f, _ := e.toFloat64(b)
+
+		return f != 0
 	case string:
 		lower := strings.ToLower(b)
 
@@ -2,6 +2,8 @@ package sdk
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 	"testing"
 )
 
@@ -51,6 +53,21 @@ func TestExpressionEvaluatorConditions(t *testing.T) {
 		}
 	})
 
+	t.Run("numeric zero", func(t *testing.T) {
+		data := map[string]any{"count": int64(0), "ratio": float64(0), "total": 3}
+
+		for expr, want := range map[string]bool{"count": false, "ratio": false, "total": true, "total - 3": false} {
+			result, err := evaluator.EvaluateCondition(expr, data)
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %v", expr, err)
+			}
+
+			if result != want {
+				t.Errorf("%s: expected %v, got %v", expr, want, result)
+			}
+		}
+	})
+
 	t.Run("empty expression", func(t *testing.T) {
 		_, err := evaluator.EvaluateCondition("", nil)
 		if err == nil {
@@ -568,3 +585,232 @@ func TestExpressionEvaluatorEdgeCases(t *testing.T) {
 		}
 	})
 }
+
+func TestExpressionEvaluatorPrecedence(t *testing.T) {
+	evaluator := NewExpressionEvaluator()
+
+	testCases := []struct {
+		name     string
+		expr     string
+		data     map[string]any
+		expected any
+	}{
+		{"multiplication before addition", "2 + 3 * 4", nil, 14.0},
+		{"parentheses", "(2 + 3) * 4", nil, 20.0},
+		{"left associative subtraction", "10 - 3 - 2", nil, 5.0},
+		{"modulo", "10 % 4", nil, 2.0},
+		{"unary minus", "-value + 1", map[string]any{"value": 3}, -2.0},
+		{"and before or", "true or false and false", nil, true},
+		{"not binds looser than comparison", "not a == 1", map[string]any{"a": 2}, true},
+		{"ternary", "score >= 0.5 ? 'pass' : 'fail'", map[string]any{"score": 0.7}, "pass"},
+		{"nested ternary", "n > 10 ? 'big' : n > 5 ? 'medium' : 'small'", map[string]any{"n": 7}, "medium"},
+		{"null coalescing missing field", "user.nickname ?? user.name", map[string]any{"user": map[string]any{"name": "Ada"}}, "Ada"},
+		{"null coalescing null value", "value ?? 'default'", map[string]any{"value": nil}, "default"},
+		{"null coalescing present value", "value ?? 'default'", map[string]any{"value": "set"}, "set"},
+		{"list index", "items[1]", map[string]any{"items": []any{"a", "b"}}, "b"},
+		{"negative list index", "items[-1]", map[string]any{"items": []string{"a", "b"}}, "b"},
+		{"string escapes", `'it\'s'`, nil, "it's"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := evaluator.EvaluateTransform(tc.expr, tc.data)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if result != tc.expected {
+				t.Errorf("expected %v (%T), got %v (%T)", tc.expected, tc.expected, result, result)
+			}
+		})
+	}
+}
+
+func TestExpressionEvaluatorFunctions(t *testing.T) {
+	evaluator := NewExpressionEvaluator()
+	data := map[string]any{
+		"name": "Workflow Engine",
+		"tags": []any{"ai", "dag"},
+		"results": []any{
+			map[string]any{"id": "a", "score": 0.9},
+			map[string]any{"id": "b", "score": 0.2},
+			map[string]any{"id": "c", "score": 0.6},
+		},
+		"config": map[string]any{"b": 1, "a": 2},
+	}
+
+	testCases := []struct {
+		name     string
+		expr     string
+		expected string
+	}{
+		{"len string", "len(name)", "15"},
+		{"len list", "len(tags)", "2"},
+		{"contains list", "contains(tags, 'dag')", "true"},
+		{"contains string", "contains(name, 'Engine')", "true"},
+		{"contains map key", "contains(config, 'c')", "false"},
+		{"startsWith", "startsWith(name, 'Work')", "true"},
+		{"endsWith", "endsWith(name, 'x')", "false"},
+		{"lower", "lower(name)", "workflow engine"},
+		{"upper", "upper(tags[0])", "AI"},
+		{"keys", "keys(config)", "[a b]"},
+		{"filter", "len(filter(results, r => r.score > 0.5))", "2"},
+		{"map", "map(filter(results, r => r.score > 0.5), r => r.id)", "[a c]"},
+		{"list literal", "map([1, 2, 3], x => x * 2)", "[2 4 6]"},
+		{"nested lambdas", "filter(tags, t => contains(map(results, r => r.id), 'a'))", "[ai dag]"},
+		{"zero literal is falsy", "0 ? 'yes' : 'no'", "no"},
+		{"arithmetic zero is falsy", "1 - 1 ? 'y' : 'n'", "n"},
+		{"not zero", "!0", "true"},
+		{"not nonzero", "!2.5", "false"},
+		{"zero in and", "1 && 0", "false"},
+		{"zero in or", "0 || 0", "false"},
+		{"filter predicate", "len(filter([0, 1, 2], x => x))", "2"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := evaluator.EvaluateTransform(tc.expr, data)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got := fmt.Sprint(result); got != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, got)
+			}
+		})
+	}
+
+	t.Run("unknown function", func(t *testing.T) {
+		if _, err := evaluator.EvaluateTransform("missing(1)", nil); err == nil {
+			t.Error("expected error for unknown function")
+		}
+	})
+
+	t.Run("filter without lambda", func(t *testing.T) {
+		if _, err := evaluator.EvaluateTransform("filter(tags, 1)", data); err == nil {
+			t.Error("expected error for non-lambda argument")
+		}
+	})
+}
+
+func TestExpressionEvaluatorCustomFunctions(t *testing.T) {
+	evaluator := NewExpressionEvaluator(WithExpressionFunction("double", func(args ...any) (any, error) {
+		n, _ := args[0].(float64)
+
+		return n * 2, nil
+	}))
+
+	if err := evaluator.RegisterFunction("greet", func(args ...any) (any, error) {
+		return fmt.Sprintf("hello %v", args[0]), nil
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	result, err := evaluator.EvaluateTransform("double(21) == 42 ? greet(name) : 'no'", map[string]any{"name": "ada"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result != "hello ada" {
+		t.Errorf("expected 'hello ada', got %v", result)
+	}
+
+	if err := evaluator.RegisterFunction("", nil); err == nil {
+		t.Error("expected error registering an unnamed function")
+	}
+
+	restricted := NewExpressionEvaluator(WithoutExpressionBuiltins(), WithExpressionFunction("double", func(args ...any) (any, error) {
+		return args[0].(float64) * 2, nil
+	}))
+
+	if _, err := restricted.EvaluateTransform("double(len(name))", map[string]any{"name": "ada"}); err == nil {
+		t.Error("expected built-ins to be disabled")
+	}
+}
+
+func TestExpressionEvaluatorLimits(t *testing.T) {
+	t.Run("step limit", func(t *testing.T) {
+		evaluator := NewExpressionEvaluator(WithExpressionMaxSteps(50))
+		items := make([]any, 100)
+
+		_, err := evaluator.EvaluateTransform("map(items, x => x)", map[string]any{"items": items})
+		if !errors.Is(err, ErrExpressionLimitExceeded) {
+			t.Errorf("expected step limit error, got %v", err)
+		}
+	})
+
+	t.Run("depth limit", func(t *testing.T) {
+		evaluator := NewExpressionEvaluator(WithExpressionMaxDepth(10))
+		expr := strings.Repeat("(", 20) + "1" + strings.Repeat(")", 20)
+
+		_, err := evaluator.EvaluateTransform(expr, nil)
+		if !errors.Is(err, ErrExpressionLimitExceeded) {
+			t.Errorf("expected depth limit error, got %v", err)
+		}
+	})
+
+	t.Run("size limit", func(t *testing.T) {
+		evaluator := NewExpressionEvaluator()
+
+		// Each level doubles the string, reaching 512 MB after 30 levels
+		expr := "s"
+		for range 30 {
+			expr = "map([" + expr + "], v => v + v)[0]"
+		}
+
+		_, err := evaluator.EvaluateTransform(expr, map[string]any{"s": "ab"})
+		if !errors.Is(err, ErrExpressionLimitExceeded) {
+			t.Errorf("expected size limit error, got %v", err)
+		}
+
+		// Shared list elements are cheap to build but expensive to format
+		expr = "x"
+		for range 30 {
+			expr = "map([" + expr + "], v => [v, v])[0]"
+		}
+
+		_, err = evaluator.EvaluateTransform("upper("+expr+")", map[string]any{"x": "ab"})
+		if !errors.Is(err, ErrExpressionLimitExceeded) {
+			t.Errorf("expected size limit error when formatting, got %v", err)
+		}
+
+		small := NewExpressionEvaluator(WithExpressionMaxSize(16))
+		if _, err := small.EvaluateTransform("name + name", map[string]any{"name": "ada"}); err != nil {
+			t.Errorf("expected a small concatenation to pass, got %v", err)
+		}
+
+		if _, err := small.EvaluateTransform("name + name + name", map[string]any{"name": "lovelace"}); !errors.Is(err, ErrExpressionLimitExceeded) {
+			t.Errorf("expected size limit error, got %v", err)
+		}
+	})
+
+	t.Run("syntax errors", func(t *testing.T) {
+		evaluator := NewExpressionEvaluator()
+
+		for _, expr := range []string{"1 +", "(a", "a ? b", "'open", "a # b", "a.1"} {
+			var exprErr *ExpressionError
+			if err := evaluator.Validate(expr); !errors.As(err, &exprErr) {
+				t.Errorf("expected syntax error for %q, got %v", expr, err)
+			}
+		}
+	})
+}
+
+func TestExpressionEvaluatorCaching(t *testing.T) {
+	evaluator := NewExpressionEvaluator()
+
+	for i := range 3 {
+		result, err := evaluator.EvaluateTransform("value * 2", map[string]any{"value": float64(i)})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if result != float64(i*2) {
+			t.Errorf("expected %d, got %v", i*2, result)
+		}
+	}
+
+	if len(evaluator.cache) != 1 {
+		t.Errorf("expected the expression to be compiled once, got %d cache entries", len(evaluator.cache))
+	}
+}
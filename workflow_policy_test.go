@@ -0,0 +1,170 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWorkflow_NodeRetry(t *testing.T) {
+	calls := 0
+
+	wf := NewWorkflow("retry", "Retry", nil, nil)
+	_ = wf.AddNode(&WorkflowNode{
+		ID:    "flaky",
+		Type:  NodeTypeTransform,
+		Retry: &RetryConfig{MaxAttempts: 3, InitialDelay: time.Millisecond, MaxDelay: time.Millisecond, Multiplier: 1},
+		TransformHandler: func(ctx context.Context, data map[string]any) (any, error) {
+			calls++
+			if calls < 3 {
+				return nil, errors.New("transient")
+			}
+
+			return "ok", nil
+		},
+	})
+	_ = wf.SetStartNode("flaky")
+
+	execution, err := wf.Execute(context.Background(), map[string]any{})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if execution.NodeExecutions["flaky"].Attempts != 3 {
+		t.Errorf("expected 3 attempts, got %d", execution.NodeExecutions["flaky"].Attempts)
+	}
+}
+
+func TestWorkflow_NodeRetry_PerAttemptTimeout(t *testing.T) {
+	calls := 0
+
+	wf := NewWorkflow("retry_timeout", "Retry Timeout", nil, nil)
+	_ = wf.AddNode(&WorkflowNode{
+		ID:      "slow_then_fast",
+		Type:    NodeTypeTransform,
+		Timeout: 20 * time.Millisecond,
+		Retry:   &RetryConfig{MaxAttempts: 2, InitialDelay: time.Millisecond, MaxDelay: time.Millisecond, Multiplier: 1},
+		TransformHandler: func(ctx context.Context, data map[string]any) (any, error) {
+			calls++
+			if calls == 1 {
+				<-ctx.Done()
+
+				return nil, ctx.Err()
+			}
+
+			return "ok", nil
+		},
+	})
+	_ = wf.SetStartNode("slow_then_fast")
+
+	if _, err := wf.Execute(context.Background(), map[string]any{}); err != nil {
+		t.Fatalf("expected second attempt to get a fresh timeout, got %v", err)
+	}
+}
+
+func TestWorkflow_ErrorEdge(t *testing.T) {
+	wf := NewWorkflow("on_error", "On Error", nil, nil)
+	_ = wf.AddNode(&WorkflowNode{
+		ID:   "charge",
+		Type: NodeTypeTransform,
+		TransformHandler: func(ctx context.Context, data map[string]any) (any, error) {
+			return nil, errors.New("card declined")
+		},
+	})
+	_ = wf.AddNode(&WorkflowNode{ID: "ship", Type: NodeTypeTransform, Transform: "'shipped'"})
+	_ = wf.AddNode(&WorkflowNode{ID: "notify", Type: NodeTypeTransform, Transform: "'notified'"})
+	_ = wf.AddNode(&WorkflowNode{ID: "handle", Type: NodeTypeTransform, Transform: "charge.error"})
+	_ = wf.AddEdge("charge", "ship")
+	_ = wf.AddEdge("ship", "notify")
+	_ = wf.SetStartNode("charge")
+
+	if err := wf.AddErrorEdge("charge", "handle"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	execution, err := wf.Execute(context.Background(), map[string]any{})
+	if err != nil {
+		t.Fatalf("expected handled failure not to fail the workflow, got %v", err)
+	}
+
+	for _, id := range []string{"ship", "notify"} {
+		if status := execution.NodeExecutions[id].Status; status != NodeStatusSkipped {
+			t.Errorf("expected %s to be skipped, got %s", id, status)
+		}
+	}
+
+	handled, ok := execution.NodeExecutions["handle"].Output.(map[string]any)
+	if msg, _ := handled["result"].(string); !ok || !strings.Contains(msg, "card declined") {
+		t.Errorf("expected handler to see the failure, got %v", execution.NodeExecutions["handle"].Output)
+	}
+}
+
+func TestWorkflow_AddErrorEdge_Cycle(t *testing.T) {
+	wf := NewWorkflow("cycle", "Cycle", nil, nil)
+	_ = wf.AddNode(&WorkflowNode{ID: "a", Type: NodeTypeTransform, Transform: "1"})
+	_ = wf.AddNode(&WorkflowNode{ID: "b", Type: NodeTypeTransform, Transform: "1"})
+	_ = wf.AddEdge("a", "b")
+
+	if err := wf.AddErrorEdge("b", "a"); err == nil {
+		t.Error("expected error for error edge creating a cycle")
+	}
+}
+
+func TestWorkflow_Compensation(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		order []string
+	)
+
+	record := func(name string, fail bool) func(ctx context.Context, data map[string]any) (any, error) {
+		return func(ctx context.Context, data map[string]any) (any, error) {
+			mu.Lock()
+			order = append(order, name)
+			mu.Unlock()
+
+			if fail {
+				return nil, errors.New(name + " failed")
+			}
+
+			return name, nil
+		}
+	}
+
+	wf := NewWorkflow("booking", "Booking", nil, nil)
+	_ = wf.AddNode(&WorkflowNode{ID: "flight", Type: NodeTypeTransform, Compensation: "cancel_flight", TransformHandler: record("flight", false)})
+	_ = wf.AddNode(&WorkflowNode{ID: "hotel", Type: NodeTypeTransform, Compensation: "cancel_hotel", TransformHandler: record("hotel", false)})
+	_ = wf.AddNode(&WorkflowNode{ID: "car", Type: NodeTypeTransform, Compensation: "cancel_car", TransformHandler: record("car", true)})
+	_ = wf.AddNode(&WorkflowNode{ID: "cancel_flight", Type: NodeTypeTransform, TransformHandler: record("cancel_flight", false)})
+	_ = wf.AddNode(&WorkflowNode{ID: "cancel_hotel", Type: NodeTypeTransform, TransformHandler: record("cancel_hotel", false)})
+	_ = wf.AddNode(&WorkflowNode{ID: "cancel_car", Type: NodeTypeTransform, TransformHandler: record("cancel_car", false)})
+	_ = wf.AddEdge("flight", "hotel")
+	_ = wf.AddEdge("hotel", "car")
+	_ = wf.SetStartNode("flight")
+
+	execution, err := wf.Execute(context.Background(), map[string]any{})
+	if err == nil {
+		t.Fatal("expected workflow to fail")
+	}
+
+	if execution.Status != WorkflowStatusCompensated {
+		t.Errorf("expected status compensated, got %s", execution.Status)
+	}
+
+	want := []string{"flight", "hotel", "car", "cancel_hotel", "cancel_flight"}
+	if !slices.Equal(order, want) {
+		t.Errorf("expected order %v, got %v", want, order)
+	}
+}
+
+func TestWorkflow_Validate_MissingCompensation(t *testing.T) {
+	wf := NewWorkflow("test", "Test", nil, nil)
+	_ = wf.AddNode(&WorkflowNode{ID: "a", Type: NodeTypeTransform, Transform: "1", Compensation: "missing"})
+
+	if err := wf.validate(); err == nil {
+		t.Error("expected error for missing compensation node")
+	}
+}
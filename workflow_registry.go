@@ -0,0 +1,111 @@
+package sdk
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	logger "github.com/xraph/go-utils/log"
+	"github.com/xraph/go-utils/metrics"
+)
+
+// WorkflowRegistry manages a collection of workflows that can be referenced
+// by ID, for example from sub-workflow nodes.
+type WorkflowRegistry struct {
+	workflows map[string]*Workflow
+	mu        sync.RWMutex
+	logger    logger.Logger
+	metrics   metrics.Metrics
+}
+
+// NewWorkflowRegistry creates a new workflow registry.
+func NewWorkflowRegistry(logger logger.Logger, metrics metrics.Metrics) *WorkflowRegistry {
+	return &WorkflowRegistry{
+		workflows: make(map[string]*Workflow),
+		logger:    logger,
+		metrics:   metrics,
+	}
+}
+
+// Register adds a workflow to the registry.
+func (r *WorkflowRegistry) Register(workflow *Workflow) error {
+	if workflow == nil {
+		return ErrWorkflowNil
+	}
+
+	if workflow.ID == "" {
+		return fmt.Errorf("%w: workflow ID is required", ErrInvalidConfig)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.workflows[workflow.ID]; exists {
+		return fmt.Errorf("%w: %s", ErrWorkflowAlreadyRegistered, workflow.ID)
+	}
+
+	r.workflows[workflow.ID] = workflow
+
+	if r.logger != nil {
+		r.logger.Info("Workflow registered",
+			logger.String("workflow_id", workflow.ID),
+			logger.String("name", workflow.Name),
+		)
+	}
+
+	if r.metrics != nil {
+		r.metrics.Gauge("ai-sdk.registry.workflows_count").Set(float64(len(r.workflows)))
+	}
+
+	return nil
+}
+
+// Unregister removes a workflow from the registry.
+func (r *WorkflowRegistry) Unregister(workflowID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.workflows[workflowID]; !exists {
+		return fmt.Errorf("%w: %s", ErrWorkflowNotFound, workflowID)
+	}
+
+	delete(r.workflows, workflowID)
+
+	if r.logger != nil {
+		r.logger.Info("Workflow unregistered", logger.String("workflow_id", workflowID))
+	}
+
+	if r.metrics != nil {
+		r.metrics.Gauge("ai-sdk.registry.workflows_count").Set(float64(len(r.workflows)))
+	}
+
+	return nil
+}
+
+// Get retrieves a workflow by ID.
+func (r *WorkflowRegistry) Get(workflowID string) (*Workflow, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	workflow, exists := r.workflows[workflowID]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrWorkflowNotFound, workflowID)
+	}
+
+	return workflow, nil
+}
+
+// List returns the IDs of all registered workflows in sorted order.
+func (r *WorkflowRegistry) List() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ids := make([]string, 0, len(r.workflows))
+	for id := range r.workflows {
+		ids = append(ids, id)
+	}
+
+	sort.Strings(ids)
+
+	return ids
+}
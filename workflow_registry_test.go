@@ -0,0 +1,126 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestWorkflowRegistry_RegisterAndGet(t *testing.T) {
+	registry := NewWorkflowRegistry(nil, nil)
+
+	wf := NewWorkflow("child", "Child", nil, nil)
+	if err := registry.Register(wf); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if err := registry.Register(wf); !errors.Is(err, ErrWorkflowAlreadyRegistered) {
+		t.Errorf("expected ErrWorkflowAlreadyRegistered, got %v", err)
+	}
+
+	got, err := registry.Get("child")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if got != wf {
+		t.Error("expected registered workflow to be returned")
+	}
+
+	if _, err := registry.Get("missing"); !errors.Is(err, ErrWorkflowNotFound) {
+		t.Errorf("expected ErrWorkflowNotFound, got %v", err)
+	}
+
+	if ids := registry.List(); len(ids) != 1 || ids[0] != "child" {
+		t.Errorf("expected [child], got %v", ids)
+	}
+
+	if err := registry.Unregister("child"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if err := registry.Unregister("child"); !errors.Is(err, ErrWorkflowNotFound) {
+		t.Errorf("expected ErrWorkflowNotFound, got %v", err)
+	}
+}
+
+func TestWorkflowRegistry_RegisterNil(t *testing.T) {
+	registry := NewWorkflowRegistry(nil, nil)
+
+	if err := registry.Register(nil); !errors.Is(err, ErrWorkflowNil) {
+		t.Errorf("expected ErrWorkflowNil, got %v", err)
+	}
+}
+
+func TestWorkflow_Execute_SubWorkflow(t *testing.T) {
+	child := NewWorkflow("double", "Double", nil, nil)
+	_ = child.AddNode(&WorkflowNode{ID: "double", Type: NodeTypeTransform, Transform: "value * 2"})
+	_ = child.SetStartNode("double")
+
+	registry := NewWorkflowRegistry(nil, nil)
+	_ = registry.Register(child)
+
+	parent := NewWorkflow("parent", "Parent", nil, nil)
+	parent.SetWorkflowRegistry(registry)
+	_ = parent.AddNode(&WorkflowNode{ID: "prep", Type: NodeTypeTransform, Transform: "x + 1"})
+	_ = parent.AddNode(&WorkflowNode{
+		ID:            "call",
+		Type:          NodeTypeSubWorkflow,
+		WorkflowID:    "double",
+		InputMapping:  map[string]string{"value": "prep.result"},
+		OutputMapping: map[string]string{"doubled": "double.result"},
+	})
+	_ = parent.AddEdge("prep", "call")
+	_ = parent.SetStartNode("prep")
+
+	execution, err := parent.Execute(context.Background(), map[string]any{"x": 4.0})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	nodeExec := execution.NodeExecutions["call"]
+	if nodeExec == nil || nodeExec.SubExecution == nil {
+		t.Fatal("expected nested sub-workflow execution")
+	}
+
+	if nodeExec.SubExecution.WorkflowID != "double" {
+		t.Errorf("expected child workflow 'double', got %s", nodeExec.SubExecution.WorkflowID)
+	}
+
+	if nodeExec.Input["value"] != 5.0 {
+		t.Errorf("expected mapped input 5, got %v", nodeExec.Input["value"])
+	}
+
+	output, ok := nodeExec.Output.(map[string]any)
+	if !ok {
+		t.Fatalf("expected map output, got %T", nodeExec.Output)
+	}
+
+	if output["doubled"] != 10.0 {
+		t.Errorf("expected doubled 10, got %v", output["doubled"])
+	}
+}
+
+func TestWorkflow_Execute_SubWorkflowRecursion(t *testing.T) {
+	registry := NewWorkflowRegistry(nil, nil)
+
+	wf := NewWorkflow("loop", "Loop", nil, nil)
+	wf.SetWorkflowRegistry(registry)
+	_ = wf.AddNode(&WorkflowNode{ID: "self", Type: NodeTypeSubWorkflow, WorkflowID: "loop"})
+	_ = wf.SetStartNode("self")
+	_ = registry.Register(wf)
+
+	_, err := wf.Execute(context.Background(), map[string]any{})
+	if !errors.Is(err, ErrWorkflowRecursion) {
+		t.Errorf("expected ErrWorkflowRecursion, got %v", err)
+	}
+}
+
+func TestWorkflow_Validate_MissingWorkflowID(t *testing.T) {
+	wf := NewWorkflow("test", "Test", nil, nil)
+	_ = wf.AddNode(&WorkflowNode{ID: "call", Type: NodeTypeSubWorkflow})
+
+	if err := wf.validate(); err == nil {
+		t.Error("expected error for sub-workflow node without workflow ID")
+	}
+}
@@ -0,0 +1,854 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"math/rand/v2"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	logger "github.com/xraph/go-utils/log"
+	"github.com/xraph/go-utils/metrics"
+)
+
+// OverlapPolicy decides what happens when a schedule fires while a previous
+// run of it is still in progress.
+type OverlapPolicy string
+
+const (
+	// OverlapSkip drops the new run (the default).
+	OverlapSkip OverlapPolicy = "skip"
+	// OverlapQueue starts the new run once the in-flight runs finish.
+	OverlapQueue OverlapPolicy = "queue"
+	// OverlapAllow starts the new run immediately alongside the others.
+	OverlapAllow OverlapPolicy = "allow"
+)
+
+// ScheduleTrigger identifies what started a scheduled run.
+type ScheduleTrigger string
+
+const (
+	ScheduleTriggerCron     ScheduleTrigger = "cron"
+	ScheduleTriggerInterval ScheduleTrigger = "interval"
+	ScheduleTriggerEvent    ScheduleTrigger = "event"
+	ScheduleTriggerManual   ScheduleTrigger = "manual"
+	ScheduleTriggerCatchUp  ScheduleTrigger = "catch_up"
+)
+
+// ScheduleRunStatus represents the status of a scheduled run.
+type ScheduleRunStatus string
+
+const (
+	ScheduleRunQueued    ScheduleRunStatus = "queued"
+	ScheduleRunRunning   ScheduleRunStatus = "running"
+	ScheduleRunCompleted ScheduleRunStatus = "completed"
+	ScheduleRunFailed    ScheduleRunStatus = "failed"
+	ScheduleRunSkipped   ScheduleRunStatus = "skipped"
+)
+
+// ScheduleConfig registers a workflow against a cron expression, an interval
+// and/or an event topic.
+type ScheduleConfig struct {
+	ID       string
+	Workflow *Workflow
+
+	// Cron is a five-field cron expression evaluated in Location (time.Local
+	// when nil). Mutually exclusive with Interval.
+	Cron     string
+	Location *time.Location
+
+	// Interval runs the workflow at a fixed period.
+	Interval time.Duration
+
+	// Topic runs the workflow whenever an event is published to it.
+	Topic string
+
+	// Input is passed to every run; trigger payloads are merged over it.
+	Input map[string]any
+
+	// Overlap decides what to do when a run is still in progress (default skip).
+	Overlap OverlapPolicy
+
+	// CatchUp replays occurrences missed while the scheduler was stopped or
+	// the schedule paused, up to MaxCatchUp (default 10) of the most recent.
+	// Catch-up runs queue behind each other even under OverlapSkip.
+	CatchUp    bool
+	MaxCatchUp int
+
+	// LastRun is the last occurrence that ran, e.g. restored from storage,
+	// used as the starting point for catch-up.
+	LastRun time.Time
+
+	// Jitter delays each timed run by a random duration up to this value.
+	Jitter time.Duration
+
+	// Paused adds the schedule in a paused state.
+	Paused bool
+}
+
+// ScheduleRun records one run of a schedule.
+type ScheduleRun struct {
+	ID          string            `json:"id"`
+	ScheduleID  string            `json:"scheduleId"`
+	WorkflowID  string            `json:"workflowId"`
+	ExecutionID string            `json:"executionId,omitempty"`
+	Trigger     ScheduleTrigger   `json:"trigger"`
+	Status      ScheduleRunStatus `json:"status"`
+	ScheduledAt time.Time         `json:"scheduledAt"`
+	StartedAt   time.Time         `json:"startedAt,omitzero"`
+	EndedAt     time.Time         `json:"endedAt,omitzero"`
+	Error       string            `json:"error,omitempty"`
+}
+
+// ScheduleRunQuery filters run history. Zero fields match everything.
+type ScheduleRunQuery struct {
+	ScheduleID string
+	Status     ScheduleRunStatus
+	Trigger    ScheduleTrigger
+	Since      time.Time
+	Limit      int
+}
+
+// ScheduleInfo describes the current state of a schedule.
+type ScheduleInfo struct {
+	ID         string        `json:"id"`
+	WorkflowID string        `json:"workflowId"`
+	Cron       string        `json:"cron,omitempty"`
+	Interval   time.Duration `json:"interval,omitempty"`
+	Topic      string        `json:"topic,omitempty"`
+	Paused     bool          `json:"paused"`
+	LastRun    time.Time     `json:"lastRun,omitzero"`
+	NextRun    time.Time     `json:"nextRun,omitzero"`
+	Running    int           `json:"running"`
+	Queued     int           `json:"queued"`
+}
+
+// SchedulerConfig configures a Scheduler.
+type SchedulerConfig struct {
+	// HistoryLimit is the number of runs kept per schedule
+	HistoryLimit int
+}
+
+// DefaultSchedulerConfig returns sensible defaults.
+func DefaultSchedulerConfig() SchedulerConfig {
+	return SchedulerConfig{
+		HistoryLimit: 100,
+	}
+}
+
+// Scheduler runs workflows on cron expressions, intervals and in-process
+// event topics.
+type Scheduler struct {
+	schedules map[string]*scheduleEntry
+	config    SchedulerConfig
+
+	// ctx is set while the scheduler is running
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	logger  logger.Logger
+	metrics metrics.Metrics
+	mu      sync.Mutex
+}
+
+// scheduleEntry is the runtime state of a schedule, guarded by Scheduler.mu.
+type scheduleEntry struct {
+	config        ScheduleConfig
+	cron          *CronSchedule
+	paused        bool
+	lastScheduled time.Time
+	nextRun       time.Time
+	running       int
+	queue         []queuedRun
+	history       []*ScheduleRun
+
+	wake chan struct{} // re-evaluates the timer after pause/resume
+	stop chan struct{} // closed when the schedule is removed
+}
+
+// queuedRun is a run waiting for the in-flight runs of its schedule.
+type queuedRun struct {
+	run   *ScheduleRun
+	input map[string]any
+}
+
+// NewScheduler creates a new workflow scheduler.
+func NewScheduler(logger logger.Logger, metrics metrics.Metrics, config SchedulerConfig) *Scheduler {
+	if config.HistoryLimit <= 0 {
+		config.HistoryLimit = DefaultSchedulerConfig().HistoryLimit
+	}
+
+	return &Scheduler{
+		schedules: make(map[string]*scheduleEntry),
+		config:    config,
+		logger:    logger,
+		metrics:   metrics,
+	}
+}
+
+// AddSchedule registers a schedule. If the scheduler is running, the schedule
+// starts immediately.
+func (s *Scheduler) AddSchedule(config ScheduleConfig) error {
+	if config.ID == "" {
+		return fmt.Errorf("%w: schedule ID is required", ErrInvalidConfig)
+	}
+
+	if config.Workflow == nil {
+		return ErrWorkflowNil
+	}
+
+	if config.Cron == "" && config.Interval <= 0 && config.Topic == "" {
+		return fmt.Errorf("%w: schedule %s needs a cron expression, interval or topic", ErrInvalidConfig, config.ID)
+	}
+
+	if config.Cron != "" && config.Interval > 0 {
+		return fmt.Errorf("%w: schedule %s cannot use both cron and interval", ErrInvalidConfig, config.ID)
+	}
+
+	switch config.Overlap {
+	case "":
+		config.Overlap = OverlapSkip
+	case OverlapSkip, OverlapQueue, OverlapAllow:
+	default:
+		return fmt.Errorf("%w: unknown overlap policy %q", ErrInvalidConfig, config.Overlap)
+	}
+
+	if config.MaxCatchUp <= 0 {
+		config.MaxCatchUp = 10
+	}
+
+	entry := &scheduleEntry{
+		config:        config,
+		paused:        config.Paused,
+		lastScheduled: config.LastRun,
+		wake:          make(chan struct{}, 1),
+		stop:          make(chan struct{}),
+	}
+
+	if config.Cron != "" {
+		cron, err := ParseCron(config.Cron, config.Location)
+		if err != nil {
+			return err
+		}
+
+		entry.cron = cron
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.schedules[config.ID]; exists {
+		return fmt.Errorf("%w: %s", ErrScheduleAlreadyExists, config.ID)
+	}
+
+	s.schedules[config.ID] = entry
+
+	if s.ctx != nil {
+		s.startLoopLocked(entry)
+	}
+
+	if s.logger != nil {
+		s.logger.Info("Workflow schedule added",
+			F("schedule", config.ID),
+			F("workflow", config.Workflow.ID),
+		)
+	}
+
+	return nil
+}
+
+// RemoveSchedule removes a schedule. Runs in progress are not cancelled;
+// queued runs are marked as skipped and never start.
+func (s *Scheduler) RemoveSchedule(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.schedules[id]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrScheduleNotFound, id)
+	}
+
+	now := time.Now()
+
+	for _, q := range entry.queue {
+		q.run.Status = ScheduleRunSkipped
+		q.run.EndedAt = now
+		q.run.Error = "schedule removed"
+
+		s.recordMetric(q.run)
+	}
+
+	entry.queue = nil
+
+	close(entry.stop)
+	delete(s.schedules, id)
+
+	return nil
+}
+
+// Start starts timers for all schedules. Runs use ctx and are cancelled when
+// it is done or Stop is called.
+func (s *Scheduler) Start(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ctx != nil {
+		return fmt.Errorf("%w: scheduler already started", ErrInvalidConfig)
+	}
+
+	s.ctx, s.cancel = context.WithCancel(ctx)
+
+	for _, entry := range s.schedules {
+		s.startLoopLocked(entry)
+	}
+
+	if s.logger != nil {
+		s.logger.Info("Workflow scheduler started", F("schedules", len(s.schedules)))
+	}
+
+	return nil
+}
+
+// Stop stops all timers, cancels in-flight runs and waits for them to finish.
+// Queued runs are marked as skipped.
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+
+	if s.ctx == nil {
+		s.mu.Unlock()
+
+		return
+	}
+
+	s.cancel()
+	s.mu.Unlock()
+
+	s.wg.Wait()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+
+	for _, entry := range s.schedules {
+		for _, q := range entry.queue {
+			q.run.Status = ScheduleRunSkipped
+			q.run.EndedAt = now
+			q.run.Error = ErrSchedulerNotRunning.Error()
+		}
+
+		entry.queue = nil
+		entry.nextRun = time.Time{}
+	}
+
+	s.ctx = nil
+	s.cancel = nil
+
+	if s.logger != nil {
+		s.logger.Info("Workflow scheduler stopped")
+	}
+}
+
+// Trigger runs a schedule now, subject to its overlap policy. Manual triggers
+// also work while the schedule is paused.
+func (s *Scheduler) Trigger(id string, input map[string]any) (ScheduleRun, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.schedules[id]
+	if !ok {
+		return ScheduleRun{}, fmt.Errorf("%w: %s", ErrScheduleNotFound, id)
+	}
+
+	if s.ctx == nil {
+		return ScheduleRun{}, ErrSchedulerNotRunning
+	}
+
+	return *s.dispatchLocked(entry, ScheduleTriggerManual, time.Now(), input), nil
+}
+
+// Publish triggers every active schedule subscribed to topic with payload
+// merged into its input, and returns the resulting runs.
+func (s *Scheduler) Publish(topic string, payload map[string]any) []ScheduleRun {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	runs := make([]ScheduleRun, 0)
+
+	if s.ctx == nil {
+		return runs
+	}
+
+	now := time.Now()
+
+	for _, id := range s.sortedIDsLocked() {
+		entry := s.schedules[id]
+		if entry.config.Topic == topic && !entry.paused {
+			runs = append(runs, *s.dispatchLocked(entry, ScheduleTriggerEvent, now, payload))
+		}
+	}
+
+	return runs
+}
+
+// Pause stops a schedule from firing on its timer or topic.
+func (s *Scheduler) Pause(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.schedules[id]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrScheduleNotFound, id)
+	}
+
+	entry.paused = true
+	entry.nextRun = time.Time{}
+	entry.signal()
+
+	return nil
+}
+
+// Resume reactivates a paused schedule, catching up on missed occurrences if
+// the schedule is configured to.
+func (s *Scheduler) Resume(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.schedules[id]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrScheduleNotFound, id)
+	}
+
+	if !entry.paused {
+		return nil
+	}
+
+	entry.paused = false
+
+	if s.ctx != nil {
+		s.catchUpLocked(entry, time.Now())
+	}
+
+	entry.signal()
+
+	return nil
+}
+
+// Schedule returns the state of a schedule.
+func (s *Scheduler) Schedule(id string) (ScheduleInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.schedules[id]
+	if !ok {
+		return ScheduleInfo{}, fmt.Errorf("%w: %s", ErrScheduleNotFound, id)
+	}
+
+	return entry.info(), nil
+}
+
+// Schedules returns the state of all schedules, sorted by ID.
+func (s *Scheduler) Schedules() []ScheduleInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	infos := make([]ScheduleInfo, 0, len(s.schedules))
+	for _, id := range s.sortedIDsLocked() {
+		infos = append(infos, s.schedules[id].info())
+	}
+
+	return infos
+}
+
+// History returns runs matching the query, newest first.
+func (s *Scheduler) History(query ScheduleRunQuery) []ScheduleRun {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	runs := make([]ScheduleRun, 0)
+
+	for id, entry := range s.schedules {
+		if query.ScheduleID != "" && id != query.ScheduleID {
+			continue
+		}
+
+		for _, run := range entry.history {
+			if query.Status != "" && run.Status != query.Status {
+				continue
+			}
+
+			if query.Trigger != "" && run.Trigger != query.Trigger {
+				continue
+			}
+
+			if !query.Since.IsZero() && run.ScheduledAt.Before(query.Since) {
+				continue
+			}
+
+			runs = append(runs, *run)
+		}
+	}
+
+	sort.SliceStable(runs, func(i, j int) bool {
+		return runs[i].ScheduledAt.After(runs[j].ScheduledAt)
+	})
+
+	if query.Limit > 0 && len(runs) > query.Limit {
+		runs = runs[:query.Limit]
+	}
+
+	return runs
+}
+
+// startLoopLocked starts the timer loop of a timed schedule.
+func (s *Scheduler) startLoopLocked(entry *scheduleEntry) {
+	if entry.cron == nil && entry.config.Interval <= 0 {
+		return
+	}
+
+	now := time.Now()
+
+	if entry.lastScheduled.IsZero() {
+		entry.lastScheduled = now
+	}
+
+	if !entry.paused {
+		s.catchUpLocked(entry, now)
+	}
+
+	s.wg.Add(1)
+
+	go s.loop(s.ctx, entry)
+}
+
+// loop fires a timed schedule until the scheduler stops or the schedule is removed.
+func (s *Scheduler) loop(ctx context.Context, entry *scheduleEntry) {
+	defer s.wg.Done()
+
+	trigger := ScheduleTriggerInterval
+	if entry.cron != nil {
+		trigger = ScheduleTriggerCron
+	}
+
+	for {
+		s.mu.Lock()
+
+		var next time.Time
+		if !entry.paused {
+			next = entry.upcoming(time.Now())
+		}
+
+		entry.nextRun = next
+		s.mu.Unlock()
+
+		var (
+			timer  *time.Timer
+			timerC <-chan time.Time
+		)
+
+		if !next.IsZero() {
+			timer = time.NewTimer(time.Until(next) + jitter(entry.config.Jitter))
+			timerC = timer.C
+		}
+
+		select {
+		case <-ctx.Done():
+			stopTimer(timer)
+
+			return
+		case <-entry.stop:
+			stopTimer(timer)
+
+			return
+		case <-entry.wake:
+			stopTimer(timer)
+		case <-timerC:
+			s.mu.Lock()
+
+			if !entry.paused {
+				entry.lastScheduled = next
+				s.dispatchLocked(entry, trigger, next, nil)
+			}
+
+			s.mu.Unlock()
+		}
+	}
+}
+
+// catchUpLocked dispatches occurrences missed since the last scheduled run.
+func (s *Scheduler) catchUpLocked(entry *scheduleEntry, now time.Time) {
+	if !entry.config.CatchUp {
+		return
+	}
+
+	missed := entry.missed(now, entry.config.MaxCatchUp)
+	if len(missed) == 0 {
+		return
+	}
+
+	if s.logger != nil {
+		s.logger.Info("Catching up missed workflow runs",
+			F("schedule", entry.config.ID),
+			F("missed", len(missed)),
+		)
+	}
+
+	for _, at := range missed {
+		s.dispatchLocked(entry, ScheduleTriggerCatchUp, at, nil)
+	}
+
+	entry.lastScheduled = missed[len(missed)-1]
+}
+
+// dispatchLocked records a run and starts, queues or skips it according to
+// the schedule's overlap policy.
+func (s *Scheduler) dispatchLocked(entry *scheduleEntry, trigger ScheduleTrigger, scheduledAt time.Time, payload map[string]any) *ScheduleRun {
+	run := &ScheduleRun{
+		ID:          uuid.New().String(),
+		ScheduleID:  entry.config.ID,
+		WorkflowID:  entry.config.Workflow.ID,
+		Trigger:     trigger,
+		ScheduledAt: scheduledAt,
+	}
+
+	input := maps.Clone(entry.config.Input)
+	if input == nil {
+		input = make(map[string]any)
+	}
+
+	maps.Copy(input, payload)
+
+	s.recordLocked(entry, run)
+
+	policy := entry.config.Overlap
+	if trigger == ScheduleTriggerCatchUp && policy == OverlapSkip {
+		policy = OverlapQueue
+	}
+
+	if entry.running > 0 || (policy == OverlapQueue && len(entry.queue) > 0) {
+		switch policy {
+		case OverlapSkip:
+			run.Status = ScheduleRunSkipped
+			run.EndedAt = time.Now()
+
+			s.recordMetric(run)
+
+			return run
+		case OverlapQueue:
+			run.Status = ScheduleRunQueued
+			entry.queue = append(entry.queue, queuedRun{run: run, input: input})
+
+			return run
+		}
+	}
+
+	s.startRunLocked(entry, run, input)
+
+	return run
+}
+
+// startRunLocked executes a run in the background.
+func (s *Scheduler) startRunLocked(entry *scheduleEntry, run *ScheduleRun, input map[string]any) {
+	ctx := s.ctx
+
+	run.Status = ScheduleRunRunning
+	run.StartedAt = time.Now()
+	entry.running++
+
+	s.wg.Add(1)
+
+	go func() {
+		defer s.wg.Done()
+
+		execution, err := entry.config.Workflow.Execute(ctx, input)
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		run.EndedAt = time.Now()
+		run.Status = ScheduleRunCompleted
+
+		if execution != nil {
+			run.ExecutionID = execution.ID
+		}
+
+		if err != nil {
+			run.Status = ScheduleRunFailed
+			run.Error = err.Error()
+		}
+
+		entry.running--
+
+		s.recordMetric(run)
+
+		if s.logger != nil {
+			s.logger.Debug("Scheduled workflow run finished",
+				F("schedule", run.ScheduleID),
+				F("run", run.ID),
+				F("trigger", run.Trigger),
+				F("status", run.Status),
+			)
+		}
+
+		// Start the next queued run unless the scheduler is stopping
+		if len(entry.queue) > 0 && ctx.Err() == nil {
+			next := entry.queue[0]
+			entry.queue = entry.queue[1:]
+
+			s.startRunLocked(entry, next.run, next.input)
+		}
+	}()
+}
+
+// recordLocked appends a run to the schedule history, dropping the oldest
+// runs beyond the history limit.
+func (s *Scheduler) recordLocked(entry *scheduleEntry, run *ScheduleRun) {
+	entry.history = append(entry.history, run)
+
+	if over := len(entry.history) - s.config.HistoryLimit; over > 0 {
+		entry.history = entry.history[over:]
+	}
+}
+
+// recordMetric counts a finished or skipped run.
+func (s *Scheduler) recordMetric(run *ScheduleRun) {
+	if s.metrics == nil {
+		return
+	}
+
+	s.metrics.Counter("forge.ai.sdk.scheduler.runs",
+		metrics.WithLabel("schedule", run.ScheduleID),
+		metrics.WithLabel("trigger", string(run.Trigger)),
+		metrics.WithLabel("status", string(run.Status)),
+	).Inc()
+}
+
+// sortedIDsLocked returns schedule IDs in a stable order.
+func (s *Scheduler) sortedIDsLocked() []string {
+	ids := make([]string, 0, len(s.schedules))
+	for id := range s.schedules {
+		ids = append(ids, id)
+	}
+
+	sort.Strings(ids)
+
+	return ids
+}
+
+// next returns the occurrence following t, or the zero time for event-only schedules.
+func (e *scheduleEntry) next(t time.Time) time.Time {
+	switch {
+	case e.cron != nil:
+		return e.cron.Next(t)
+	case e.config.Interval > 0:
+		return t.Add(e.config.Interval)
+	default:
+		return time.Time{}
+	}
+}
+
+// upcoming returns the first occurrence after now, skipping missed ones.
+func (e *scheduleEntry) upcoming(now time.Time) time.Time {
+	last := e.lastScheduled
+
+	if e.cron != nil {
+		return e.cron.Next(latest(last, now))
+	}
+
+	if interval := e.config.Interval; interval > 0 {
+		if last.After(now) {
+			return last.Add(interval)
+		}
+
+		// Stay aligned to the original anchor
+		return last.Add((now.Sub(last)/interval + 1) * interval)
+	}
+
+	return time.Time{}
+}
+
+// missed returns up to limit of the most recent occurrences after the last
+// scheduled run and no later than now, oldest first.
+func (e *scheduleEntry) missed(now time.Time, limit int) []time.Time {
+	last := e.lastScheduled
+	if last.IsZero() || !last.Before(now) {
+		return nil
+	}
+
+	// Skip ahead on long interval gaps instead of walking every occurrence
+	if interval := e.config.Interval; interval > 0 && e.cron == nil {
+		if n := int(now.Sub(last) / interval); n > limit {
+			last = last.Add(time.Duration(n-limit) * interval)
+		}
+	}
+
+	missed := make([]time.Time, 0)
+
+	for t := e.next(last); !t.IsZero() && !t.After(now); t = e.next(t) {
+		missed = append(missed, t)
+
+		if len(missed) > limit {
+			missed = missed[1:]
+		}
+	}
+
+	return missed
+}
+
+// info returns a snapshot of the schedule state.
+func (e *scheduleEntry) info() ScheduleInfo {
+	info := ScheduleInfo{
+		ID:         e.config.ID,
+		WorkflowID: e.config.Workflow.ID,
+		Cron:       e.config.Cron,
+		Interval:   e.config.Interval,
+		Topic:      e.config.Topic,
+		Paused:     e.paused,
+		NextRun:    e.nextRun,
+		Running:    e.running,
+		Queued:     len(e.queue),
+	}
+
+	if n := len(e.history); n > 0 {
+		info.LastRun = e.history[n-1].ScheduledAt
+	}
+
+	return info
+}
+
+// signal wakes the schedule's timer loop without blocking.
+func (e *scheduleEntry) signal() {
+	select {
+	case e.wake <- struct{}{}:
+	default:
+	}
+}
+
+// jitter returns a random delay in [0, max).
+func jitter(max time.Duration) time.Duration {
+	if max <= 0 {
+		return 0
+	}
+
+	return time.Duration(rand.Int64N(int64(max)))
+}
+
+// stopTimer stops a timer if one was started.
+func stopTimer(timer *time.Timer) {
+	if timer != nil {
+		timer.Stop()
+	}
+}
+
+// latest returns the later of two times.
+func latest(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,356 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseCron_Next(t *testing.T) {
+	base := time.Date(2026, 3, 14, 10, 7, 30, 0, time.UTC) // Saturday
+
+	tests := []struct {
+		expr     string
+		expected time.Time
+	}{
+		{"* * * * *", time.Date(2026, 3, 14, 10, 8, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2026, 3, 14, 10, 15, 0, 0, time.UTC)},
+		{"0 9-17 * * mon-fri", time.Date(2026, 3, 16, 9, 0, 0, 0, time.UTC)},
+		{"30 2 1 * *", time.Date(2026, 4, 1, 2, 30, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 jan *", time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2026, 3, 14, 11, 0, 0, 0, time.UTC)},
+		{"0 12 13 * fri", time.Date(2026, 3, 20, 12, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		cron, err := ParseCron(tt.expr, time.UTC)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", tt.expr, err)
+		}
+
+		if next := cron.Next(base); !next.Equal(tt.expected) {
+			t.Errorf("%s: expected %v, got %v", tt.expr, tt.expected, next)
+		}
+	}
+}
+
+func TestParseCron_Errors(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "5-1 * * * *", "*/0 * * * *", "* * * foo *"} {
+		if _, err := ParseCron(expr, time.UTC); !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("%q: expected ErrInvalidConfig, got %v", expr, err)
+		}
+	}
+}
+
+// countingWorkflow returns a workflow whose single node counts its runs and
+// blocks until release is closed, when non-nil.
+func countingWorkflow(id string, count *atomic.Int32, release <-chan struct{}) *Workflow {
+	wf := NewWorkflow(id, id, nil, nil)
+	_ = wf.AddNode(&WorkflowNode{
+		ID:   "work",
+		Type: NodeTypeTransform,
+		TransformHandler: func(ctx context.Context, data map[string]any) (any, error) {
+			count.Add(1)
+
+			if release != nil {
+				select {
+				case <-release:
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				}
+			}
+
+			return data, nil
+		},
+	})
+	_ = wf.SetStartNode("work")
+
+	return wf
+}
+
+// waitForRuns polls history until n runs match the query.
+func waitForRuns(t *testing.T, scheduler *Scheduler, query ScheduleRunQuery, n int) []ScheduleRun {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+
+	for {
+		runs := scheduler.History(query)
+		if len(runs) >= n {
+			return runs
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d runs matching %+v, got %d", n, query, len(runs))
+		}
+
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestScheduler_Interval(t *testing.T) {
+	var count atomic.Int32
+
+	scheduler := NewScheduler(nil, nil, DefaultSchedulerConfig())
+	_ = scheduler.AddSchedule(ScheduleConfig{
+		ID:       "tick",
+		Workflow: countingWorkflow("tick", &count, nil),
+		Interval: 20 * time.Millisecond,
+		Jitter:   5 * time.Millisecond,
+	})
+
+	if err := scheduler.Start(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer scheduler.Stop()
+
+	runs := waitForRuns(t, scheduler, ScheduleRunQuery{ScheduleID: "tick", Status: ScheduleRunCompleted}, 3)
+
+	for _, run := range runs {
+		if run.Trigger != ScheduleTriggerInterval || run.ExecutionID == "" {
+			t.Errorf("expected completed interval run with execution, got %+v", run)
+		}
+	}
+
+	info, _ := scheduler.Schedule("tick")
+	if info.NextRun.IsZero() || info.LastRun.IsZero() {
+		t.Errorf("expected next and last run, got %+v", info)
+	}
+}
+
+func TestScheduler_OverlapPolicies(t *testing.T) {
+	tests := []struct {
+		policy   OverlapPolicy
+		statuses []ScheduleRunStatus
+		started  int32
+	}{
+		{OverlapSkip, []ScheduleRunStatus{ScheduleRunRunning, ScheduleRunSkipped}, 1},
+		{OverlapQueue, []ScheduleRunStatus{ScheduleRunRunning, ScheduleRunQueued}, 1},
+		{OverlapAllow, []ScheduleRunStatus{ScheduleRunRunning, ScheduleRunRunning}, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			var count atomic.Int32
+
+			release := make(chan struct{})
+
+			scheduler := NewScheduler(nil, nil, DefaultSchedulerConfig())
+			_ = scheduler.AddSchedule(ScheduleConfig{
+				ID:       "job",
+				Workflow: countingWorkflow("job", &count, release),
+				Topic:    "jobs",
+				Overlap:  tt.policy,
+			})
+			_ = scheduler.Start(context.Background())
+
+			for i, expected := range tt.statuses {
+				run, err := scheduler.Trigger("job", nil)
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+
+				if run.Status != expected {
+					t.Errorf("trigger %d: expected %s, got %s", i, expected, run.Status)
+				}
+			}
+
+			deadline := time.Now().Add(time.Second)
+			for count.Load() < tt.started && time.Now().Before(deadline) {
+				time.Sleep(5 * time.Millisecond)
+			}
+
+			if count.Load() != tt.started {
+				t.Errorf("expected %d concurrent runs, got %d", tt.started, count.Load())
+			}
+
+			close(release)
+
+			completed := len(tt.statuses)
+			if tt.policy == OverlapSkip {
+				completed = 1
+			}
+
+			waitForRuns(t, scheduler, ScheduleRunQuery{Status: ScheduleRunCompleted}, completed)
+			scheduler.Stop()
+		})
+	}
+}
+
+func TestScheduler_RemoveSkipsQueuedRuns(t *testing.T) {
+	var count atomic.Int32
+
+	release := make(chan struct{})
+
+	scheduler := NewScheduler(nil, nil, DefaultSchedulerConfig())
+	_ = scheduler.AddSchedule(ScheduleConfig{
+		ID:       "job",
+		Workflow: countingWorkflow("job", &count, release),
+		Topic:    "jobs",
+		Overlap:  OverlapQueue,
+	})
+	_ = scheduler.Start(context.Background())
+
+	_, _ = scheduler.Trigger("job", nil)
+
+	if run, _ := scheduler.Trigger("job", nil); run.Status != ScheduleRunQueued {
+		t.Fatalf("expected the second run to be queued, got %s", run.Status)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for count.Load() < 1 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if err := scheduler.RemoveSchedule("job"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	close(release)
+
+	// Give a wrongly queued run time to start before Stop cancels it
+	time.Sleep(50 * time.Millisecond)
+	scheduler.Stop()
+
+	if count.Load() != 1 {
+		t.Errorf("expected the queued run not to start after removal, got %d runs", count.Load())
+	}
+}
+
+func TestScheduler_CatchUp(t *testing.T) {
+	var count atomic.Int32
+
+	now := time.Now().Truncate(time.Minute)
+
+	scheduler := NewScheduler(nil, nil, DefaultSchedulerConfig())
+	_ = scheduler.AddSchedule(ScheduleConfig{
+		ID:       "minutely",
+		Workflow: countingWorkflow("minutely", &count, nil),
+		Cron:     "* * * * *",
+		CatchUp:  true,
+		LastRun:  now.Add(-5 * time.Minute),
+	})
+	_ = scheduler.AddSchedule(ScheduleConfig{
+		ID:         "capped",
+		Workflow:   countingWorkflow("capped", &count, nil),
+		Interval:   time.Minute,
+		CatchUp:    true,
+		MaxCatchUp: 3,
+		LastRun:    now.Add(-time.Hour),
+	})
+
+	_ = scheduler.Start(context.Background())
+	defer scheduler.Stop()
+
+	runs := waitForRuns(t, scheduler, ScheduleRunQuery{ScheduleID: "minutely", Status: ScheduleRunCompleted}, 5)
+	if len(runs) != 5 {
+		t.Errorf("expected 5 catch-up runs, got %d", len(runs))
+	}
+
+	for i, run := range runs {
+		if run.Trigger != ScheduleTriggerCatchUp {
+			t.Errorf("expected catch-up trigger, got %s", run.Trigger)
+		}
+
+		if i > 0 && !run.ScheduledAt.Before(runs[i-1].ScheduledAt) {
+			t.Error("expected history newest first")
+		}
+	}
+
+	if !runs[0].ScheduledAt.Equal(now) {
+		t.Errorf("expected latest catch-up at %v, got %v", now, runs[0].ScheduledAt)
+	}
+
+	capped := waitForRuns(t, scheduler, ScheduleRunQuery{ScheduleID: "capped", Status: ScheduleRunCompleted}, 3)
+	if len(capped) != 3 {
+		t.Errorf("expected catch-up capped at 3, got %d", len(capped))
+	}
+}
+
+func TestScheduler_PublishAndPause(t *testing.T) {
+	var count atomic.Int32
+
+	scheduler := NewScheduler(nil, nil, DefaultSchedulerConfig())
+	_ = scheduler.AddSchedule(ScheduleConfig{
+		ID:       "on_order",
+		Workflow: countingWorkflow("on_order", &count, nil),
+		Topic:    "order.created",
+		Input:    map[string]any{"source": "scheduler"},
+		Overlap:  OverlapAllow,
+	})
+
+	if _, err := scheduler.Trigger("on_order", nil); !errors.Is(err, ErrSchedulerNotRunning) {
+		t.Errorf("expected ErrSchedulerNotRunning, got %v", err)
+	}
+
+	_ = scheduler.Start(context.Background())
+	defer scheduler.Stop()
+
+	if runs := scheduler.Publish("order.created", map[string]any{"order": 42}); len(runs) != 1 || runs[0].Trigger != ScheduleTriggerEvent {
+		t.Fatalf("expected one event run, got %+v", runs)
+	}
+
+	if runs := scheduler.Publish("order.deleted", nil); len(runs) != 0 {
+		t.Errorf("expected no runs for unrelated topic, got %d", len(runs))
+	}
+
+	_ = scheduler.Pause("on_order")
+
+	if runs := scheduler.Publish("order.created", nil); len(runs) != 0 {
+		t.Errorf("expected paused schedule to ignore events, got %d", len(runs))
+	}
+
+	if run, err := scheduler.Trigger("on_order", nil); err != nil || run.Trigger != ScheduleTriggerManual {
+		t.Errorf("expected manual trigger while paused, got %+v, %v", run, err)
+	}
+
+	_ = scheduler.Resume("on_order")
+
+	if runs := scheduler.Publish("order.created", nil); len(runs) != 1 {
+		t.Errorf("expected resumed schedule to run, got %d", len(runs))
+	}
+
+	waitForRuns(t, scheduler, ScheduleRunQuery{Status: ScheduleRunCompleted}, 3)
+
+	if runs := scheduler.History(ScheduleRunQuery{Trigger: ScheduleTriggerManual}); len(runs) != 1 {
+		t.Errorf("expected one manual run, got %d", len(runs))
+	}
+
+	if runs := scheduler.History(ScheduleRunQuery{Limit: 2}); len(runs) != 2 {
+		t.Errorf("expected limit to apply, got %d", len(runs))
+	}
+
+	if err := scheduler.Pause("missing"); !errors.Is(err, ErrScheduleNotFound) {
+		t.Errorf("expected ErrScheduleNotFound, got %v", err)
+	}
+}
+
+func TestScheduler_AddScheduleValidation(t *testing.T) {
+	var count atomic.Int32
+
+	scheduler := NewScheduler(nil, nil, DefaultSchedulerConfig())
+	wf := countingWorkflow("wf", &count, nil)
+
+	tests := []ScheduleConfig{
+		{Workflow: wf, Interval: time.Second},
+		{ID: "no_trigger", Workflow: wf},
+		{ID: "both", Workflow: wf, Cron: "* * * * *", Interval: time.Second},
+		{ID: "policy", Workflow: wf, Topic: "t", Overlap: "never"},
+		{ID: "cron", Workflow: wf, Cron: "bad"},
+	}
+
+	for _, config := range tests {
+		if err := scheduler.AddSchedule(config); !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("%s: expected ErrInvalidConfig, got %v", config.ID, err)
+		}
+	}
+
+	_ = scheduler.AddSchedule(ScheduleConfig{ID: "dup", Workflow: wf, Topic: "t"})
+
+	if err := scheduler.AddSchedule(ScheduleConfig{ID: "dup", Workflow: wf, Topic: "t"}); !errors.Is(err, ErrScheduleAlreadyExists) {
+		t.Errorf("expected ErrScheduleAlreadyExists, got %v", err)
+	}
+}
@@ -0,0 +1,241 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"sort"
+	"time"
+)
+
+// PendingSignal describes a signal or approval node that is waiting for input.
+type PendingSignal struct {
+	ExecutionID string
+	NodeID      string
+	Type        NodeType
+	Since       time.Time
+	Deadline    time.Time
+
+	ch chan map[string]any
+}
+
+// Signal delivers a payload to a waiting signal or approval node. For
+// approval nodes the payload's "approved" field decides the outcome.
+//
+// If the node is waiting in this process it resumes immediately. Otherwise,
+// when a store is configured, the payload is persisted and picked up the next
+// time the execution is resumed.
+func (w *Workflow) Signal(executionID, nodeID string, payload map[string]any) error {
+	if payload == nil {
+		payload = make(map[string]any)
+	}
+
+	w.signalsMu.Lock()
+	pending, ok := w.signals[signalKey(executionID, nodeID)]
+
+	if ok {
+		delete(w.signals, signalKey(executionID, nodeID))
+	}
+
+	w.signalsMu.Unlock()
+
+	if ok {
+		pending.ch <- maps.Clone(payload)
+
+		return nil
+	}
+
+	w.mu.RLock()
+	store := w.store
+	w.mu.RUnlock()
+
+	if store == nil {
+		return fmt.Errorf("%w: %s/%s", ErrWorkflowSignalNotPending, executionID, nodeID)
+	}
+
+	ctx := context.Background()
+
+	execution, err := store.Load(ctx, executionID)
+	if err != nil {
+		return err
+	}
+
+	nodeExec, ok := execution.NodeExecutions[nodeID]
+	if !ok || nodeExec.Status != NodeStatusWaiting {
+		return fmt.Errorf("%w: %s/%s", ErrWorkflowSignalNotPending, executionID, nodeID)
+	}
+
+	nodeExec.SignalPayload = maps.Clone(payload)
+
+	if err := store.Save(ctx, execution); err != nil {
+		return fmt.Errorf("failed to persist signal: %w", err)
+	}
+
+	if w.logger != nil {
+		w.logger.Info("Workflow signal persisted for resume",
+			F("workflow", w.ID),
+			F("execution", executionID),
+			F("node", nodeID),
+		)
+	}
+
+	return nil
+}
+
+// PendingSignals returns the signal and approval nodes waiting in this process,
+// oldest first.
+func (w *Workflow) PendingSignals() []PendingSignal {
+	w.signalsMu.Lock()
+	defer w.signalsMu.Unlock()
+
+	pending := make([]PendingSignal, 0, len(w.signals))
+	for _, p := range w.signals {
+		pending = append(pending, PendingSignal{
+			ExecutionID: p.ExecutionID,
+			NodeID:      p.NodeID,
+			Type:        p.Type,
+			Since:       p.Since,
+			Deadline:    p.Deadline,
+		})
+	}
+
+	sort.Slice(pending, func(i, j int) bool {
+		return pending[i].Since.Before(pending[j].Since)
+	})
+
+	return pending
+}
+
+// executeSignalNode suspends the node until a signal arrives or its timeout
+// fires. The timeout deadline is persisted so it survives restarts. A
+// rejection is not kept, so a retry or resume waits for a new decision.
+func (w *Workflow) executeSignalNode(ctx context.Context, node *WorkflowNode, execution *WorkflowExecution, nodeExec *NodeExecution) (any, error) {
+	execution.mu.Lock()
+
+	if nodeExec.WakeAt.IsZero() {
+		nodeExec.WakeAt = time.Now().Add(node.Timeout)
+	}
+
+	deadline := nodeExec.WakeAt
+	payload := nodeExec.SignalPayload
+	execution.mu.Unlock()
+
+	// A payload may already have been delivered while the execution was stopped
+	if payload == nil {
+		var err error
+
+		payload, err = w.awaitSignal(ctx, node, execution, nodeExec, deadline)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if w.logger != nil {
+		w.logger.Debug("Signal node received payload",
+			F("workflow", w.ID),
+			F("node", node.ID),
+			F("type", node.Type),
+		)
+	}
+
+	result := maps.Clone(payload)
+
+	if node.Type == NodeTypeApproval {
+		approved, _ := payload["approved"].(bool)
+		result["approved"] = approved
+
+		if !approved {
+			execution.mu.Lock()
+			nodeExec.SignalPayload = nil
+			nodeExec.WakeAt = time.Time{}
+			execution.mu.Unlock()
+
+			return nil, fmt.Errorf("%w: %s", ErrWorkflowApprovalRejected, node.ID)
+		}
+	}
+
+	return result, nil
+}
+
+// awaitSignal marks the node and execution as waiting and blocks until a
+// payload, the deadline or context cancellation.
+func (w *Workflow) awaitSignal(ctx context.Context, node *WorkflowNode, execution *WorkflowExecution, nodeExec *NodeExecution, deadline time.Time) (map[string]any, error) {
+	key := signalKey(execution.ID, node.ID)
+	pending := &PendingSignal{
+		ExecutionID: execution.ID,
+		NodeID:      node.ID,
+		Type:        node.Type,
+		Since:       time.Now(),
+		Deadline:    deadline,
+		ch:          make(chan map[string]any, 1),
+	}
+
+	w.signalsMu.Lock()
+
+	if w.signals == nil {
+		w.signals = make(map[string]*PendingSignal)
+	}
+
+	w.signals[key] = pending
+	w.signalsMu.Unlock()
+
+	defer func() {
+		w.signalsMu.Lock()
+		if w.signals[key] == pending {
+			delete(w.signals, key)
+		}
+		w.signalsMu.Unlock()
+	}()
+
+	execution.mu.Lock()
+	nodeExec.Status = NodeStatusWaiting
+	execution.Status = WorkflowStatusWaiting
+	execution.mu.Unlock()
+
+	w.saveExecution(ctx, execution)
+
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+
+	var (
+		payload map[string]any
+		err     error
+	)
+
+	select {
+	case payload = <-pending.ch:
+	case <-timer.C:
+		err = fmt.Errorf("%w: %s", ErrWorkflowSignalTimeout, node.ID)
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	execution.mu.Lock()
+
+	nodeExec.Status = NodeStatusRunning
+	nodeExec.SignalPayload = payload
+
+	// Stay in the waiting state while other branches are still waiting
+	stillWaiting := false
+
+	for _, ne := range execution.NodeExecutions {
+		if ne.Status == NodeStatusWaiting {
+			stillWaiting = true
+
+			break
+		}
+	}
+
+	if !stillWaiting {
+		execution.Status = WorkflowStatusRunning
+	}
+
+	execution.mu.Unlock()
+
+	return payload, err
+}
+
+// signalKey identifies a waiting node within an execution.
+func signalKey(executionID, nodeID string) string {
+	return executionID + "/" + nodeID
+}
@@ -0,0 +1,213 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func newApprovalWorkflow(timeout time.Duration) *Workflow {
+	wf := NewWorkflow("approval_wf", "Approval", nil, nil)
+	_ = wf.AddNode(&WorkflowNode{ID: "approve", Type: NodeTypeApproval, Timeout: timeout})
+	_ = wf.AddNode(&WorkflowNode{ID: "after", Type: NodeTypeTransform, Transform: "reviewer"})
+	_ = wf.AddEdge("approve", "after")
+	_ = wf.SetStartNode("approve")
+
+	return wf
+}
+
+// waitForPendingSignal polls until a node is waiting for a signal.
+func waitForPendingSignal(t *testing.T, wf *Workflow) PendingSignal {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if pending := wf.PendingSignals(); len(pending) > 0 {
+			return pending[0]
+		}
+
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	t.Fatal("timed out waiting for pending signal")
+
+	return PendingSignal{}
+}
+
+func TestWorkflow_Signal_Approved(t *testing.T) {
+	wf := newApprovalWorkflow(time.Minute)
+
+	type result struct {
+		execution *WorkflowExecution
+		err       error
+	}
+
+	done := make(chan result, 1)
+
+	go func() {
+		execution, err := wf.Execute(context.Background(), map[string]any{})
+		done <- result{execution, err}
+	}()
+
+	pending := waitForPendingSignal(t, wf)
+	if pending.NodeID != "approve" || pending.Type != NodeTypeApproval {
+		t.Errorf("unexpected pending signal: %+v", pending)
+	}
+
+	if err := wf.Signal(pending.ExecutionID, "approve", map[string]any{"approved": true, "reviewer": "alice"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	res := <-done
+	if res.err != nil {
+		t.Fatalf("expected no error, got %v", res.err)
+	}
+
+	after, ok := res.execution.NodeExecutions["after"].Output.(map[string]any)
+	if !ok || after["result"] != "alice" {
+		t.Errorf("expected payload merged into execution data, got %v", res.execution.NodeExecutions["after"].Output)
+	}
+}
+
+func TestWorkflow_Signal_Rejected(t *testing.T) {
+	wf := newApprovalWorkflow(time.Minute)
+
+	done := make(chan error, 1)
+
+	go func() {
+		_, err := wf.Execute(context.Background(), map[string]any{})
+		done <- err
+	}()
+
+	pending := waitForPendingSignal(t, wf)
+	_ = wf.Signal(pending.ExecutionID, "approve", map[string]any{"approved": false})
+
+	if err := <-done; !errors.Is(err, ErrWorkflowApprovalRejected) {
+		t.Errorf("expected ErrWorkflowApprovalRejected, got %v", err)
+	}
+}
+
+func TestWorkflow_Signal_RetryAfterRejection(t *testing.T) {
+	wf := newApprovalWorkflow(time.Minute)
+	wf.Nodes["approve"].Retry = &RetryConfig{MaxAttempts: 2, InitialDelay: time.Millisecond, MaxDelay: time.Millisecond, Multiplier: 1}
+
+	type result struct {
+		execution *WorkflowExecution
+		err       error
+	}
+
+	done := make(chan result, 1)
+
+	go func() {
+		execution, err := wf.Execute(context.Background(), map[string]any{})
+		done <- result{execution, err}
+	}()
+
+	pending := waitForPendingSignal(t, wf)
+	_ = wf.Signal(pending.ExecutionID, "approve", map[string]any{"approved": false})
+
+	// The retry must wait for a new decision instead of reusing the rejection
+	pending = waitForPendingSignal(t, wf)
+	if err := wf.Signal(pending.ExecutionID, "approve", map[string]any{"approved": true, "reviewer": "dave"}); err != nil {
+		t.Fatalf("expected the retry to wait for a signal, got %v", err)
+	}
+
+	res := <-done
+	if res.err != nil {
+		t.Fatalf("expected no error, got %v", res.err)
+	}
+
+	if attempts := res.execution.NodeExecutions["approve"].Attempts; attempts != 2 {
+		t.Errorf("expected 2 attempts, got %d", attempts)
+	}
+}
+
+func TestWorkflow_Signal_Timeout(t *testing.T) {
+	wf := newApprovalWorkflow(20 * time.Millisecond)
+
+	_, err := wf.Execute(context.Background(), map[string]any{})
+	if !errors.Is(err, ErrWorkflowSignalTimeout) {
+		t.Errorf("expected ErrWorkflowSignalTimeout, got %v", err)
+	}
+}
+
+func TestWorkflow_Signal_NotPending(t *testing.T) {
+	wf := newApprovalWorkflow(time.Minute)
+
+	if err := wf.Signal("missing", "approve", nil); !errors.Is(err, ErrWorkflowSignalNotPending) {
+		t.Errorf("expected ErrWorkflowSignalNotPending, got %v", err)
+	}
+}
+
+func TestWorkflow_Signal_PersistedAcrossRestart(t *testing.T) {
+	store := NewInMemoryWorkflowStore()
+
+	// State left behind by a process that stopped while waiting for approval
+	_ = store.Save(context.Background(), &WorkflowExecution{
+		ID:         "exec1",
+		WorkflowID: "approval_wf",
+		Status:     WorkflowStatusWaiting,
+		StartTime:  time.Now(),
+		NodeExecutions: map[string]*NodeExecution{
+			"approve": {NodeID: "approve", Status: NodeStatusWaiting, Attempts: 1, WakeAt: time.Now().Add(time.Hour)},
+		},
+	})
+
+	wf := newApprovalWorkflow(time.Hour)
+	wf.SetStore(store)
+
+	if err := wf.Signal("exec1", "approve", map[string]any{"approved": true, "reviewer": "bob"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	resumed, err := wf.Resume(context.Background(), "exec1")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if resumed.Status != WorkflowStatusCompleted {
+		t.Errorf("expected status completed, got %s", resumed.Status)
+	}
+
+	after, ok := resumed.NodeExecutions["after"].Output.(map[string]any)
+	if !ok || after["result"] != "bob" {
+		t.Errorf("expected persisted payload to be used, got %v", resumed.NodeExecutions["after"].Output)
+	}
+}
+
+func TestWorkflow_Signal_PersistedDeadline(t *testing.T) {
+	store := NewInMemoryWorkflowStore()
+
+	// The persisted deadline outlives the node timeout of a single attempt
+	_ = store.Save(context.Background(), &WorkflowExecution{
+		ID:         "exec1",
+		WorkflowID: "approval_wf",
+		Status:     WorkflowStatusWaiting,
+		StartTime:  time.Now(),
+		NodeExecutions: map[string]*NodeExecution{
+			"approve": {NodeID: "approve", Status: NodeStatusWaiting, Attempts: 1, WakeAt: time.Now().Add(time.Hour)},
+		},
+	})
+
+	wf := newApprovalWorkflow(20 * time.Millisecond)
+	wf.SetStore(store)
+
+	done := make(chan error, 1)
+
+	go func() {
+		_, err := wf.Resume(context.Background(), "exec1")
+		done <- err
+	}()
+
+	pending := waitForPendingSignal(t, wf)
+	time.Sleep(50 * time.Millisecond)
+
+	if err := wf.Signal(pending.ExecutionID, pending.NodeID, map[string]any{"approved": true, "reviewer": "carol"}); err != nil {
+		t.Fatalf("expected the node to still be waiting, got %v", err)
+	}
+
+	if err := <-done; err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
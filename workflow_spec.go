@@ -0,0 +1,883 @@
+package sdk
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	logger "github.com/xraph/go-utils/log"
+	"github.com/xraph/go-utils/metrics"
+	"gopkg.in/yaml.v3"
+)
+
+// WorkflowSpec is the declarative, file-friendly form of a Workflow.
+// It can be authored as YAML or JSON and loaded with a WorkflowLoader.
+//
+// Example:
+//
+//	id: onboarding
+//	name: User Onboarding
+//	start_nodes: [validate]
+//	nodes:
+//	  - id: validate
+//	    type: tool
+//	    tool: validate_email
+//	  - id: welcome
+//	    type: agent
+//	    agent: email_agent
+//	edges:
+//	  - from: validate
+//	    to: welcome
+type WorkflowSpec struct {
+	ID          string             `json:"id"                    yaml:"id"`
+	Name        string             `json:"name,omitempty"        yaml:"name,omitempty"`
+	Description string             `json:"description,omitempty" yaml:"description,omitempty"`
+	Version     string             `json:"version,omitempty"     yaml:"version,omitempty"`
+	StartNodes  []string           `json:"start_nodes"           yaml:"start_nodes"`
+	Nodes       []WorkflowNodeSpec `json:"nodes"                 yaml:"nodes"`
+	Edges       []WorkflowEdgeSpec `json:"edges,omitempty"       yaml:"edges,omitempty"`
+}
+
+// WorkflowNodeSpec is the declarative form of a WorkflowNode.
+type WorkflowNodeSpec struct {
+	ID          string         `json:"id"                    yaml:"id"`
+	Type        NodeType       `json:"type"                  yaml:"type"`
+	Name        string         `json:"name,omitempty"        yaml:"name,omitempty"`
+	Description string         `json:"description,omitempty" yaml:"description,omitempty"`
+	Timeout     string         `json:"timeout,omitempty"     yaml:"timeout,omitempty"`
+	Config      map[string]any `json:"config,omitempty"      yaml:"config,omitempty"`
+
+	// Failure handling
+	Retry        *WorkflowRetrySpec `json:"retry,omitempty"        yaml:"retry,omitempty"`
+	OnError      []string           `json:"on_error,omitempty"     yaml:"on_error,omitempty"`
+	Compensation string             `json:"compensation,omitempty" yaml:"compensation,omitempty"`
+
+	// Agent node: agent ID, alias or name
+	Agent string `json:"agent,omitempty" yaml:"agent,omitempty"`
+
+	// Tool node
+	Tool        string         `json:"tool,omitempty"         yaml:"tool,omitempty"`
+	ToolVersion string         `json:"tool_version,omitempty" yaml:"tool_version,omitempty"`
+	ToolParams  map[string]any `json:"tool_params,omitempty"  yaml:"tool_params,omitempty"`
+
+	// Condition and transform nodes
+	Condition string `json:"condition,omitempty" yaml:"condition,omitempty"`
+	Transform string `json:"transform,omitempty" yaml:"transform,omitempty"`
+
+	// Wait node
+	Duration string `json:"duration,omitempty" yaml:"duration,omitempty"`
+
+	// Sub-workflow node
+	Workflow      string            `json:"workflow,omitempty"       yaml:"workflow,omitempty"`
+	InputMapping  map[string]string `json:"input_mapping,omitempty"  yaml:"input_mapping,omitempty"`
+	OutputMapping map[string]string `json:"output_mapping,omitempty" yaml:"output_mapping,omitempty"`
+}
+
+// WorkflowRetrySpec is the declarative form of a node's RetryConfig. Omitted
+// fields keep the DefaultRetryConfig values.
+type WorkflowRetrySpec struct {
+	MaxAttempts  int     `json:"max_attempts"            yaml:"max_attempts"`
+	InitialDelay string  `json:"initial_delay,omitempty" yaml:"initial_delay,omitempty"`
+	MaxDelay     string  `json:"max_delay,omitempty"     yaml:"max_delay,omitempty"`
+	Multiplier   float64 `json:"multiplier,omitempty"    yaml:"multiplier,omitempty"`
+	Jitter       *bool   `json:"jitter,omitempty"        yaml:"jitter,omitempty"`
+}
+
+// WorkflowEdgeSpec is a dependency edge between two nodes.
+type WorkflowEdgeSpec struct {
+	From string `json:"from" yaml:"from"`
+	To   string `json:"to"   yaml:"to"`
+}
+
+// WorkflowSpecError describes a problem at a specific location of a spec.
+type WorkflowSpecError struct {
+	Line    int
+	Column  int
+	Path    string
+	Message string
+}
+
+func (e *WorkflowSpecError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Message)
+	}
+
+	return fmt.Sprintf("line %d, column %d: %s: %s", e.Line, e.Column, e.Path, e.Message)
+}
+
+// WorkflowSpecErrors collects every problem found while loading a spec.
+type WorkflowSpecErrors []*WorkflowSpecError
+
+func (e WorkflowSpecErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+
+	return "invalid workflow spec:\n  " + strings.Join(msgs, "\n  ")
+}
+
+// Unwrap allows errors.Is/As to match individual spec errors.
+func (e WorkflowSpecErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+
+	return errs
+}
+
+// WorkflowLoader builds workflows from YAML or JSON specs, resolving tool,
+// agent and sub-workflow references against the configured registries.
+type WorkflowLoader struct {
+	toolRegistry     *ToolRegistry
+	agentRegistry    *AgentRegistry
+	workflowRegistry *WorkflowRegistry
+	evaluator        *ExpressionEvaluator
+	logger           logger.Logger
+	metrics          metrics.Metrics
+}
+
+// NewWorkflowLoader creates a new workflow loader. Either registry may be nil,
+// in which case specs referencing tools or agents fail to load.
+func NewWorkflowLoader(toolRegistry *ToolRegistry, agentRegistry *AgentRegistry) *WorkflowLoader {
+	return &WorkflowLoader{
+		toolRegistry:  toolRegistry,
+		agentRegistry: agentRegistry,
+		evaluator:     NewExpressionEvaluator(),
+	}
+}
+
+// WithWorkflowRegistry sets the registry used to resolve sub-workflow references.
+func (l *WorkflowLoader) WithWorkflowRegistry(wr *WorkflowRegistry) *WorkflowLoader {
+	l.workflowRegistry = wr
+
+	return l
+}
+
+// WithExpressionEvaluator sets the expression evaluator for loaded workflows,
+// e.g. one with custom functions registered.
+func (l *WorkflowLoader) WithExpressionEvaluator(evaluator *ExpressionEvaluator) *WorkflowLoader {
+	if evaluator != nil {
+		l.evaluator = evaluator
+	}
+
+	return l
+}
+
+// WithLogger sets the logger for loaded workflows.
+func (l *WorkflowLoader) WithLogger(logger logger.Logger) *WorkflowLoader {
+	l.logger = logger
+
+	return l
+}
+
+// WithMetrics sets the metrics for loaded workflows.
+func (l *WorkflowLoader) WithMetrics(metrics metrics.Metrics) *WorkflowLoader {
+	l.metrics = metrics
+
+	return l
+}
+
+// LoadFile reads and loads a workflow spec file.
+func (l *WorkflowLoader) LoadFile(path string) (*Workflow, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read workflow spec: %w", err)
+	}
+
+	workflow, err := l.Load(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return workflow, nil
+}
+
+// Load parses a YAML or JSON workflow spec into a Workflow. Problems are
+// reported as WorkflowSpecErrors with line and column information.
+func (l *WorkflowLoader) Load(data []byte) (*Workflow, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+	}
+
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return nil, WorkflowSpecErrors{{Line: 1, Column: 1, Message: "empty workflow spec"}}
+	}
+
+	v := &specValidator{positions: make(map[string]*yaml.Node)}
+	v.validate(doc.Content[0])
+
+	if len(v.errs) > 0 {
+		return nil, v.errs
+	}
+
+	var spec WorkflowSpec
+	if err := doc.Content[0].Decode(&spec); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+	}
+
+	workflow := l.build(&spec, v)
+	if len(v.errs) > 0 {
+		return nil, v.errs
+	}
+
+	return workflow, nil
+}
+
+// build converts a schema-valid spec into a workflow, recording resolution errors.
+func (l *WorkflowLoader) build(spec *WorkflowSpec, v *specValidator) *Workflow {
+	workflow := NewWorkflow(spec.ID, spec.Name, l.logger, l.metrics)
+	if spec.Name == "" {
+		workflow.Name = spec.ID
+	}
+
+	workflow.Description = spec.Description
+
+	if spec.Version != "" {
+		workflow.Version = spec.Version
+	}
+
+	workflow.SetToolRegistry(l.toolRegistry)
+	workflow.SetAgentRegistry(l.agentRegistry)
+	workflow.SetWorkflowRegistry(l.workflowRegistry)
+	workflow.SetExpressionEvaluator(l.evaluator)
+
+	// Nodes that failed to resolve; edges touching them are not reported again
+	unresolved := make(map[string]bool)
+
+	for i := range spec.Nodes {
+		path := fmt.Sprintf("nodes[%d]", i)
+
+		node := l.buildNode(&spec.Nodes[i], path, v)
+		if node == nil {
+			unresolved[spec.Nodes[i].ID] = true
+
+			continue
+		}
+
+		if err := workflow.AddNode(node); err != nil {
+			v.addf(path+".id", "%v", err)
+		}
+	}
+
+	for i, edge := range spec.Edges {
+		path := fmt.Sprintf("edges[%d]", i)
+
+		if unresolved[edge.From] || unresolved[edge.To] {
+			continue
+		}
+
+		if err := workflow.AddEdge(edge.From, edge.To); err != nil {
+			v.addf(path, "%v", err)
+		}
+	}
+
+	for i := range spec.Nodes {
+		nodeSpec := &spec.Nodes[i]
+		if unresolved[nodeSpec.ID] {
+			continue
+		}
+
+		for j, handler := range nodeSpec.OnError {
+			if unresolved[handler] {
+				continue
+			}
+
+			if err := workflow.AddErrorEdge(nodeSpec.ID, handler); err != nil {
+				v.addf(fmt.Sprintf("nodes[%d].on_error[%d]", i, j), "%v", err)
+			}
+		}
+
+		if nodeSpec.Compensation != "" && !unresolved[nodeSpec.Compensation] {
+			if _, exists := workflow.Nodes[nodeSpec.Compensation]; !exists {
+				v.addf(fmt.Sprintf("nodes[%d].compensation", i), "compensation node %s not found", nodeSpec.Compensation)
+			}
+		}
+	}
+
+	for i, nodeID := range spec.StartNodes {
+		if unresolved[nodeID] {
+			continue
+		}
+
+		if err := workflow.SetStartNode(nodeID); err != nil {
+			v.addf(fmt.Sprintf("start_nodes[%d]", i), "%v", err)
+		}
+	}
+
+	return workflow
+}
+
+// buildNode converts a node spec, resolving registry references.
+func (l *WorkflowLoader) buildNode(spec *WorkflowNodeSpec, path string, v *specValidator) *WorkflowNode {
+	node := &WorkflowNode{
+		ID:            spec.ID,
+		Type:          spec.Type,
+		Name:          spec.Name,
+		Description:   spec.Description,
+		Config:        spec.Config,
+		ToolVersion:   spec.ToolVersion,
+		ToolParams:    spec.ToolParams,
+		Condition:     spec.Condition,
+		Transform:     spec.Transform,
+		WorkflowID:    spec.Workflow,
+		InputMapping:  spec.InputMapping,
+		OutputMapping: spec.OutputMapping,
+		Compensation:  spec.Compensation,
+	}
+
+	if spec.Timeout != "" {
+		node.Timeout, _ = time.ParseDuration(spec.Timeout) // validated by schema
+	}
+
+	if spec.Retry != nil {
+		retry := DefaultRetryConfig()
+		retry.MaxAttempts = spec.Retry.MaxAttempts
+
+		if spec.Retry.Jitter != nil {
+			retry.Jitter = *spec.Retry.Jitter
+		}
+
+		// Durations are validated by schema
+		if spec.Retry.InitialDelay != "" {
+			retry.InitialDelay, _ = time.ParseDuration(spec.Retry.InitialDelay)
+		}
+
+		if spec.Retry.MaxDelay != "" {
+			retry.MaxDelay, _ = time.ParseDuration(spec.Retry.MaxDelay)
+		}
+
+		if spec.Retry.Multiplier > 0 {
+			retry.Multiplier = spec.Retry.Multiplier
+		}
+
+		node.Retry = &retry
+	}
+
+	// Reject expressions with syntax errors at load time
+	expressions := map[string]string{".condition": spec.Condition, ".transform": spec.Transform}
+	for key, expr := range spec.InputMapping {
+		expressions[".input_mapping."+key] = expr
+	}
+
+	for key, expr := range spec.OutputMapping {
+		expressions[".output_mapping."+key] = expr
+	}
+
+	for _, field := range slices.Sorted(maps.Keys(expressions)) {
+		if expr := expressions[field]; expr != "" {
+			if err := l.evaluator.Validate(expr); err != nil {
+				v.addf(path+field, "%v", err)
+			}
+		}
+	}
+
+	switch spec.Type {
+	case NodeTypeTool:
+		if l.toolRegistry == nil {
+			v.addf(path+".tool", "tool registry not configured")
+
+			return nil
+		}
+
+		tool, err := l.toolRegistry.GetTool(spec.Tool, spec.ToolVersion)
+		if err != nil {
+			v.addf(path+".tool", "%v", err)
+
+			return nil
+		}
+
+		node.ToolName = tool.Name
+		node.ToolVersion = tool.Version
+	case NodeTypeAgent:
+		agent, err := l.resolveAgent(spec.Agent)
+		if err != nil {
+			v.addf(path+".agent", "%v", err)
+
+			return nil
+		}
+
+		node.AgentID = agent.ID
+	case NodeTypeWait:
+		duration, _ := time.ParseDuration(spec.Duration) // validated by schema
+
+		if node.Config == nil {
+			node.Config = make(map[string]any)
+		}
+
+		node.Config["duration"] = duration
+
+		if node.Timeout == 0 {
+			node.Timeout = duration + 10*time.Second
+		}
+	case NodeTypeSubWorkflow:
+		if l.workflowRegistry != nil {
+			if _, err := l.workflowRegistry.Get(spec.Workflow); err != nil {
+				v.addf(path+".workflow", "%v", err)
+
+				return nil
+			}
+		}
+	}
+
+	return node
+}
+
+// resolveAgent finds an agent by ID or alias, falling back to its name.
+func (l *WorkflowLoader) resolveAgent(ref string) (*Agent, error) {
+	if l.agentRegistry == nil {
+		return nil, fmt.Errorf("%w: agent registry not configured", ErrAgentNotFound)
+	}
+
+	if agent, err := l.agentRegistry.Get(ref); err == nil {
+		return agent, nil
+	}
+
+	for _, agent := range l.agentRegistry.List() {
+		if agent.Name == ref {
+			return agent, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrAgentNotFound, ref)
+}
+
+// specKind is the expected YAML shape of a spec field.
+type specKind int
+
+const (
+	specString specKind = iota
+	specInt
+	specFloat
+	specBool
+	specDuration
+	specStringList
+	specStringMap
+	specMap
+	specNodeList
+	specEdgeList
+	specRetry
+)
+
+// specField describes a field allowed in a spec mapping.
+type specField struct {
+	kind     specKind
+	required bool
+}
+
+var workflowSpecSchema = map[string]specField{
+	"id":          {kind: specString, required: true},
+	"name":        {kind: specString},
+	"description": {kind: specString},
+	"version":     {kind: specString},
+	"start_nodes": {kind: specStringList, required: true},
+	"nodes":       {kind: specNodeList, required: true},
+	"edges":       {kind: specEdgeList},
+}
+
+var workflowNodeSpecSchema = map[string]specField{
+	"id":             {kind: specString, required: true},
+	"type":           {kind: specString, required: true},
+	"name":           {kind: specString},
+	"description":    {kind: specString},
+	"timeout":        {kind: specDuration},
+	"config":         {kind: specMap},
+	"retry":          {kind: specRetry},
+	"on_error":       {kind: specStringList},
+	"compensation":   {kind: specString},
+	"agent":          {kind: specString},
+	"tool":           {kind: specString},
+	"tool_version":   {kind: specString},
+	"tool_params":    {kind: specMap},
+	"condition":      {kind: specString},
+	"transform":      {kind: specString},
+	"duration":       {kind: specDuration},
+	"workflow":       {kind: specString},
+	"input_mapping":  {kind: specStringMap},
+	"output_mapping": {kind: specStringMap},
+}
+
+var workflowRetrySpecSchema = map[string]specField{
+	"max_attempts":  {kind: specInt, required: true},
+	"initial_delay": {kind: specDuration},
+	"max_delay":     {kind: specDuration},
+	"multiplier":    {kind: specFloat},
+	"jitter":        {kind: specBool},
+}
+
+var workflowEdgeSpecSchema = map[string]specField{
+	"from": {kind: specString, required: true},
+	"to":   {kind: specString, required: true},
+}
+
+// requiredFieldByNodeType lists the type-specific field each supported node
+// type needs; an empty string means no extra field is required.
+var requiredFieldByNodeType = map[NodeType]string{
+	NodeTypeAgent:       "agent",
+	NodeTypeTool:        "tool",
+	NodeTypeCondition:   "condition",
+	NodeTypeTransform:   "transform",
+	NodeTypeWait:        "duration",
+	NodeTypeSubWorkflow: "workflow",
+	NodeTypeApproval:    "",
+	NodeTypeSignal:      "",
+}
+
+// specValidator checks a YAML node tree against the workflow spec schema.
+type specValidator struct {
+	errs      WorkflowSpecErrors
+	positions map[string]*yaml.Node // field path -> value node
+	current   *yaml.Node
+}
+
+// add records an error at the given node.
+func (v *specValidator) add(n *yaml.Node, path, format string, args ...any) {
+	v.errs = append(v.errs, &WorkflowSpecError{
+		Line:    n.Line,
+		Column:  n.Column,
+		Path:    path,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// addf records an error at the position of a previously validated path,
+// falling back to its closest known parent.
+func (v *specValidator) addf(path, format string, args ...any) {
+	for p := path; p != ""; p = parentSpecPath(p) {
+		if n, ok := v.positions[p]; ok {
+			v.add(n, path, format, args...)
+
+			return
+		}
+	}
+
+	v.add(v.current, path, format, args...)
+}
+
+// parentSpecPath returns the parent of a field path like "nodes[1].tool".
+func parentSpecPath(path string) string {
+	if i := strings.LastIndexAny(path, ".["); i > 0 {
+		return path[:i]
+	}
+
+	return ""
+}
+
+// validate checks the root mapping of a spec.
+func (v *specValidator) validate(root *yaml.Node) {
+	v.current = root
+	v.validateMapping(root, "", workflowSpecSchema)
+}
+
+// validateMapping checks that n is a mapping with only known, well-typed fields.
+func (v *specValidator) validateMapping(n *yaml.Node, path string, schema map[string]specField) map[string]*yaml.Node {
+	if path != "" {
+		v.positions[path] = n
+	}
+
+	if n.Kind != yaml.MappingNode {
+		v.add(n, path, "expected a mapping")
+
+		return nil
+	}
+
+	fields := make(map[string]*yaml.Node, len(n.Content)/2)
+
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key, value := n.Content[i], n.Content[i+1]
+		fieldPath := joinSpecPath(path, key.Value)
+
+		field, known := schema[key.Value]
+		if !known {
+			v.add(key, fieldPath, "unknown field (allowed: %s)", strings.Join(sortedKeys(schema), ", "))
+
+			continue
+		}
+
+		if _, dup := fields[key.Value]; dup {
+			v.add(key, fieldPath, "duplicate field")
+
+			continue
+		}
+
+		fields[key.Value] = value
+		v.positions[fieldPath] = value
+		v.validateValue(value, fieldPath, field.kind)
+	}
+
+	for _, name := range sortedKeys(schema) {
+		if _, ok := fields[name]; !ok && schema[name].required {
+			v.add(n, joinSpecPath(path, name), "required field is missing")
+		}
+	}
+
+	return fields
+}
+
+// validateValue checks a single field value against its expected kind.
+func (v *specValidator) validateValue(n *yaml.Node, path string, kind specKind) {
+	switch kind {
+	case specString:
+		if n.Kind != yaml.ScalarNode || n.Tag == "!!null" {
+			v.add(n, path, "expected a string")
+		}
+	case specInt, specFloat, specBool:
+		tags := map[specKind][]string{
+			specInt:   {"!!int"},
+			specFloat: {"!!int", "!!float"},
+			specBool:  {"!!bool"},
+		}
+		names := map[specKind]string{specInt: "an integer", specFloat: "a number", specBool: "a boolean"}
+
+		if n.Kind != yaml.ScalarNode || !slices.Contains(tags[kind], n.Tag) {
+			v.add(n, path, "expected %s", names[kind])
+		}
+	case specRetry:
+		v.validateMapping(n, path, workflowRetrySpecSchema)
+	case specDuration:
+		if n.Kind != yaml.ScalarNode {
+			v.add(n, path, "expected a duration such as \"30s\"")
+
+			return
+		}
+
+		if _, err := time.ParseDuration(n.Value); err != nil {
+			v.add(n, path, "invalid duration %q", n.Value)
+		}
+	case specStringList:
+		if n.Kind != yaml.SequenceNode {
+			v.add(n, path, "expected a list of strings")
+
+			return
+		}
+
+		for i, item := range n.Content {
+			v.validateValue(item, fmt.Sprintf("%s[%d]", path, i), specString)
+		}
+	case specStringMap:
+		if n.Kind != yaml.MappingNode {
+			v.add(n, path, "expected a mapping of strings")
+
+			return
+		}
+
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			v.validateValue(n.Content[i+1], joinSpecPath(path, n.Content[i].Value), specString)
+		}
+	case specMap:
+		if n.Kind != yaml.MappingNode {
+			v.add(n, path, "expected a mapping")
+		}
+	case specNodeList:
+		v.validateNodes(n, path)
+	case specEdgeList:
+		if n.Kind != yaml.SequenceNode {
+			v.add(n, path, "expected a list of edges")
+
+			return
+		}
+
+		for i, item := range n.Content {
+			v.validateMapping(item, fmt.Sprintf("%s[%d]", path, i), workflowEdgeSpecSchema)
+		}
+	}
+}
+
+// validateNodes checks the node list, including type-specific requirements.
+func (v *specValidator) validateNodes(n *yaml.Node, path string) {
+	if n.Kind != yaml.SequenceNode {
+		v.add(n, path, "expected a list of nodes")
+
+		return
+	}
+
+	if len(n.Content) == 0 {
+		v.add(n, path, "workflow must have at least one node")
+	}
+
+	for i, item := range n.Content {
+		nodePath := fmt.Sprintf("%s[%d]", path, i)
+
+		fields := v.validateMapping(item, nodePath, workflowNodeSpecSchema)
+		if fields == nil {
+			continue
+		}
+
+		typeNode, ok := fields["type"]
+		if !ok || typeNode.Kind != yaml.ScalarNode {
+			continue
+		}
+
+		required, supported := requiredFieldByNodeType[NodeType(typeNode.Value)]
+		if !supported {
+			v.add(typeNode, nodePath+".type", "unsupported node type %q", typeNode.Value)
+
+			continue
+		}
+
+		if _, ok := fields[required]; required != "" && !ok {
+			v.add(item, joinSpecPath(nodePath, required), "required for %s nodes", typeNode.Value)
+		}
+	}
+}
+
+// joinSpecPath appends a field name to a path.
+func joinSpecPath(path, field string) string {
+	if path == "" {
+		return field
+	}
+
+	return path + "." + field
+}
+
+// sortedKeys returns the keys of a schema in sorted order.
+func sortedKeys(schema map[string]specField) []string {
+	keys := make([]string, 0, len(schema))
+	for k := range schema {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// ToSpec converts the workflow into its declarative form. Nodes that rely on
+// Go handlers (ConditionHandler, TransformHandler) cannot be serialized.
+func (w *Workflow) ToSpec() (*WorkflowSpec, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	spec := &WorkflowSpec{
+		ID:          w.ID,
+		Name:        w.Name,
+		Description: w.Description,
+		Version:     w.Version,
+		StartNodes:  slices.Clone(w.StartNodes),
+		Nodes:       make([]WorkflowNodeSpec, 0, len(w.Nodes)),
+		Edges:       make([]WorkflowEdgeSpec, 0),
+	}
+
+	nodeIDs := make([]string, 0, len(w.Nodes))
+	for id := range w.Nodes {
+		nodeIDs = append(nodeIDs, id)
+	}
+
+	sort.Strings(nodeIDs)
+
+	for _, id := range nodeIDs {
+		node := w.Nodes[id]
+
+		if node.ConditionHandler != nil && node.Condition == "" {
+			return nil, fmt.Errorf("%w: node %s uses a condition handler that cannot be serialized", ErrInvalidConfig, id)
+		}
+
+		if node.TransformHandler != nil && node.Transform == "" {
+			return nil, fmt.Errorf("%w: node %s uses a transform handler that cannot be serialized", ErrInvalidConfig, id)
+		}
+
+		nodeSpec := WorkflowNodeSpec{
+			ID:            node.ID,
+			Type:          node.Type,
+			Name:          node.Name,
+			Description:   node.Description,
+			Agent:         node.AgentID,
+			Tool:          node.ToolName,
+			ToolVersion:   node.ToolVersion,
+			ToolParams:    node.ToolParams,
+			Condition:     node.Condition,
+			Transform:     node.Transform,
+			Workflow:      node.WorkflowID,
+			InputMapping:  node.InputMapping,
+			OutputMapping: node.OutputMapping,
+			OnError:       slices.Clone(w.ErrorEdges[id]),
+			Compensation:  node.Compensation,
+		}
+
+		if node.Retry != nil {
+			jitter := node.Retry.Jitter
+			nodeSpec.Retry = &WorkflowRetrySpec{
+				MaxAttempts:  node.Retry.MaxAttempts,
+				InitialDelay: node.Retry.InitialDelay.String(),
+				MaxDelay:     node.Retry.MaxDelay.String(),
+				Multiplier:   node.Retry.Multiplier,
+				Jitter:       &jitter,
+			}
+		}
+
+		if node.Timeout > 0 {
+			nodeSpec.Timeout = node.Timeout.String()
+		}
+
+		if len(node.Config) > 0 {
+			nodeSpec.Config = make(map[string]any, len(node.Config))
+
+			for k, val := range node.Config {
+				if d, ok := val.(time.Duration); ok && k == "duration" && node.Type == NodeTypeWait {
+					nodeSpec.Duration = d.String()
+
+					continue
+				}
+
+				nodeSpec.Config[k] = val
+			}
+
+			if len(nodeSpec.Config) == 0 {
+				nodeSpec.Config = nil
+			}
+		}
+
+		if node.Type == NodeTypeWait && nodeSpec.Duration == "" {
+			nodeSpec.Duration = waitNodeDuration(node).String()
+		}
+
+		spec.Nodes = append(spec.Nodes, nodeSpec)
+	}
+
+	for _, from := range sortedEdgeSources(w.Edges) {
+		for _, to := range w.Edges[from] {
+			spec.Edges = append(spec.Edges, WorkflowEdgeSpec{From: from, To: to})
+		}
+	}
+
+	return spec, nil
+}
+
+// sortedEdgeSources returns the source nodes of an edge map in sorted order.
+func sortedEdgeSources(edges map[string][]string) []string {
+	sources := make([]string, 0, len(edges))
+	for from := range edges {
+		sources = append(sources, from)
+	}
+
+	sort.Strings(sources)
+
+	return sources
+}
+
+// ToSpecYAML serializes the workflow as a YAML spec.
+func (w *Workflow) ToSpecYAML() ([]byte, error) {
+	spec, err := w.ToSpec()
+	if err != nil {
+		return nil, err
+	}
+
+	return yaml.Marshal(spec)
+}
+
+// ToSpecJSON serializes the workflow as a JSON spec.
+func (w *Workflow) ToSpecJSON() ([]byte, error) {
+	spec, err := w.ToSpec()
+	if err != nil {
+		return nil, err
+	}
+
+	return json.MarshalIndent(spec, "", "  ")
+}
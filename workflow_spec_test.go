@@ -0,0 +1,300 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newSpecTestToolRegistry() *ToolRegistry {
+	registry := NewToolRegistry(nil, nil)
+	_ = registry.RegisterTool(&ToolDefinition{
+		Name:    "validate_email",
+		Version: "1.0.0",
+		Handler: func(ctx context.Context, params map[string]any) (any, error) {
+			return map[string]any{"valid": true}, nil
+		},
+	})
+
+	return registry
+}
+
+func TestWorkflowLoader_LoadYAML(t *testing.T) {
+	spec := `
+id: onboarding
+name: User Onboarding
+start_nodes: [validate]
+nodes:
+  - id: validate
+    type: tool
+    tool: validate_email
+  - id: check
+    type: condition
+    condition: "validate.valid == true"
+  - id: pause
+    type: wait
+    duration: 10ms
+edges:
+  - from: validate
+    to: check
+  - from: check
+    to: pause
+`
+
+	wf, err := NewWorkflowLoader(newSpecTestToolRegistry(), nil).Load([]byte(spec))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if wf.ID != "onboarding" || len(wf.Nodes) != 3 {
+		t.Fatalf("unexpected workflow: id=%s nodes=%d", wf.ID, len(wf.Nodes))
+	}
+
+	if d := wf.Nodes["pause"].Config["duration"]; d != 10*time.Millisecond {
+		t.Errorf("expected wait duration 10ms, got %v", d)
+	}
+
+	execution, err := wf.Execute(context.Background(), map[string]any{})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if execution.Status != WorkflowStatusCompleted {
+		t.Errorf("expected status completed, got %s", execution.Status)
+	}
+}
+
+func TestWorkflowLoader_LoadJSON(t *testing.T) {
+	spec := `{
+  "id": "json_wf",
+  "start_nodes": ["double"],
+  "nodes": [
+    {"id": "double", "type": "transform", "transform": "value * 2"}
+  ]
+}`
+
+	wf, err := NewWorkflowLoader(nil, nil).Load([]byte(spec))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if wf.Name != "json_wf" {
+		t.Errorf("expected name to default to ID, got %s", wf.Name)
+	}
+}
+
+func TestWorkflowLoader_SchemaErrors(t *testing.T) {
+	spec := `id: broken
+start_nodes: [a]
+nodes:
+  - id: a
+    type: tool
+    tool: missing_tool
+  - id: b
+    type: wait
+    duration: soon
+  - id: c
+    type: teleport
+    colour: blue
+`
+
+	_, err := NewWorkflowLoader(newSpecTestToolRegistry(), nil).Load([]byte(spec))
+
+	var specErrs WorkflowSpecErrors
+	if !errors.As(err, &specErrs) {
+		t.Fatalf("expected WorkflowSpecErrors, got %v", err)
+	}
+
+	want := []string{
+		"line 9, column 15: nodes[1].duration: invalid duration",
+		"line 11, column 11: nodes[2].type: unsupported node type",
+		"line 12, column 5: nodes[2].colour: unknown field",
+	}
+
+	for _, w := range want {
+		if !strings.Contains(err.Error(), w) {
+			t.Errorf("expected error containing %q, got:\n%v", w, err)
+		}
+	}
+}
+
+func TestWorkflowLoader_ResolutionErrors(t *testing.T) {
+	spec := `id: broken
+start_nodes: [a]
+nodes:
+  - id: a
+    type: tool
+    tool: missing_tool
+  - id: b
+    type: agent
+    agent: ghost
+`
+
+	_, err := NewWorkflowLoader(newSpecTestToolRegistry(), NewAgentRegistry(nil, nil)).Load([]byte(spec))
+	if err == nil {
+		t.Fatal("expected error for unresolved references")
+	}
+
+	for _, w := range []string{"line 6, column 11: nodes[0].tool", "line 9, column 12: nodes[1].agent"} {
+		if !strings.Contains(err.Error(), w) {
+			t.Errorf("expected error containing %q, got:\n%v", w, err)
+		}
+	}
+}
+
+func TestWorkflow_ToSpec_RoundTrip(t *testing.T) {
+	wf := NewWorkflow("roundtrip", "Round Trip", nil, nil)
+	_ = wf.AddNode(&WorkflowNode{ID: "validate", Type: NodeTypeTool, ToolName: "validate_email", Timeout: time.Minute})
+	_ = wf.AddNode(&WorkflowNode{ID: "pause", Type: NodeTypeWait, Config: map[string]any{"duration": time.Second}})
+	_ = wf.AddEdge("validate", "pause")
+	_ = wf.SetStartNode("validate")
+
+	data, err := wf.ToSpecYAML()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	loaded, err := NewWorkflowLoader(newSpecTestToolRegistry(), nil).Load(data)
+	if err != nil {
+		t.Fatalf("expected no error loading serialized spec, got %v\n%s", err, data)
+	}
+
+	if loaded.Nodes["validate"].Timeout != time.Minute {
+		t.Errorf("expected timeout 1m, got %v", loaded.Nodes["validate"].Timeout)
+	}
+
+	if loaded.Nodes["pause"].Config["duration"] != time.Second {
+		t.Errorf("expected duration 1s, got %v", loaded.Nodes["pause"].Config["duration"])
+	}
+
+	if len(loaded.Edges["validate"]) != 1 {
+		t.Errorf("expected edge to be preserved, got %v", loaded.Edges)
+	}
+
+	if _, err := wf.ToSpecJSON(); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
+
+func TestWorkflow_ToSpec_HandlerNotSerializable(t *testing.T) {
+	wf := NewWorkflow("handlers", "Handlers", nil, nil)
+	_ = wf.AddNode(&WorkflowNode{
+		ID:   "t",
+		Type: NodeTypeTransform,
+		TransformHandler: func(ctx context.Context, data map[string]any) (any, error) {
+			return nil, nil
+		},
+	})
+
+	if _, err := wf.ToSpec(); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected ErrInvalidConfig, got %v", err)
+	}
+}
+
+func TestWorkflowLoader_FailurePolicies(t *testing.T) {
+	spec := `id: booking
+start_nodes: [book]
+nodes:
+  - id: book
+    type: transform
+    transform: "1"
+    timeout: 5s
+    retry:
+      max_attempts: 3
+      initial_delay: 10ms
+    on_error: [handle]
+    compensation: cancel
+  - id: handle
+    type: transform
+    transform: "1"
+  - id: cancel
+    type: transform
+    transform: "1"
+`
+
+	wf, err := NewWorkflowLoader(nil, nil).Load([]byte(spec))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	book := wf.Nodes["book"]
+	if book.Retry == nil || book.Retry.MaxAttempts != 3 || book.Retry.InitialDelay != 10*time.Millisecond || !book.Retry.Jitter {
+		t.Errorf("unexpected retry config: %+v", book.Retry)
+	}
+
+	noJitter, err := NewWorkflowLoader(nil, nil).Load([]byte(strings.Replace(spec, "initial_delay: 10ms", "initial_delay: 10ms\n      jitter: false", 1)))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if noJitter.Nodes["book"].Retry.Jitter {
+		t.Error("expected an explicit jitter: false to disable jitter")
+	}
+
+	if book.Compensation != "cancel" {
+		t.Errorf("expected compensation 'cancel', got %q", book.Compensation)
+	}
+
+	if len(wf.ErrorEdges["book"]) != 1 || wf.ErrorEdges["book"][0] != "handle" {
+		t.Errorf("expected error edge to handle, got %v", wf.ErrorEdges["book"])
+	}
+
+	_, err = NewWorkflowLoader(nil, nil).Load([]byte(strings.Replace(spec, "max_attempts: 3", "max_attempts: many", 1)))
+	if err == nil || !strings.Contains(err.Error(), "line 9, column 21: nodes[0].retry.max_attempts: expected an integer") {
+		t.Errorf("expected line-numbered retry error, got %v", err)
+	}
+}
+
+func TestWorkflowLoader_ExpressionErrors(t *testing.T) {
+	spec := `
+id: broken
+start_nodes: [score]
+nodes:
+  - id: score
+    type: transform
+    transform: "(value * 2"
+`
+
+	_, err := NewWorkflowLoader(nil, nil).Load([]byte(spec))
+
+	var specErrs WorkflowSpecErrors
+	if !errors.As(err, &specErrs) || len(specErrs) != 1 {
+		t.Fatalf("expected one spec error, got %v", err)
+	}
+
+	if specErrs[0].Path != "nodes[0].transform" || specErrs[0].Line != 7 {
+		t.Errorf("expected error at nodes[0].transform line 7, got %+v", specErrs[0])
+	}
+}
+
+func TestWorkflowLoader_ExpressionFunctions(t *testing.T) {
+	spec := `
+id: custom
+start_nodes: [slug]
+nodes:
+  - id: slug
+    type: transform
+    transform: "slugify(title)"
+`
+
+	evaluator := NewExpressionEvaluator(WithExpressionFunction("slugify", func(args ...any) (any, error) {
+		return strings.ReplaceAll(strings.ToLower(args[0].(string)), " ", "-"), nil
+	}))
+
+	wf, err := NewWorkflowLoader(nil, nil).WithExpressionEvaluator(evaluator).Load([]byte(spec))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	execution, err := wf.Execute(context.Background(), map[string]any{"title": "Hello World"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	output, _ := execution.NodeExecutions["slug"].Output.(map[string]any)
+	if output["result"] != "hello-world" {
+		t.Errorf("expected custom function result, got %v", output)
+	}
+}
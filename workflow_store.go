@@ -0,0 +1,410 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// WorkflowStore provides persistence for workflow executions so that a run
+// can be resumed after a crash or restart.
+// Implementations can use memory, files, databases, etc.
+type WorkflowStore interface {
+	// Save persists a snapshot of an execution
+	Save(ctx context.Context, execution *WorkflowExecution) error
+
+	// Load retrieves an execution by ID
+	Load(ctx context.Context, executionID string) (*WorkflowExecution, error)
+
+	// Delete removes an execution
+	Delete(ctx context.Context, executionID string) error
+
+	// List returns all executions of a workflow
+	List(ctx context.Context, workflowID string) ([]*WorkflowExecution, error)
+}
+
+// InMemoryWorkflowStore is a simple in-memory implementation.
+// Useful for testing and development.
+type InMemoryWorkflowStore struct {
+	executions map[string]*WorkflowExecution
+	mu         sync.RWMutex
+}
+
+// NewInMemoryWorkflowStore creates a new in-memory workflow store.
+func NewInMemoryWorkflowStore() *InMemoryWorkflowStore {
+	return &InMemoryWorkflowStore{
+		executions: make(map[string]*WorkflowExecution),
+	}
+}
+
+// Save persists an execution in memory.
+func (s *InMemoryWorkflowStore) Save(ctx context.Context, execution *WorkflowExecution) error {
+	if execution == nil {
+		return errors.New("execution cannot be nil")
+	}
+
+	if execution.ID == "" {
+		return errors.New("execution ID is required")
+	}
+
+	// Clone before locking the store to prevent external modifications
+	clone := execution.Clone()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.executions[execution.ID] = clone
+
+	return nil
+}
+
+// Load retrieves an execution from memory.
+func (s *InMemoryWorkflowStore) Load(ctx context.Context, executionID string) (*WorkflowExecution, error) {
+	if executionID == "" {
+		return nil, errors.New("execution ID is required")
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	execution, exists := s.executions[executionID]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrWorkflowExecutionNotFound, executionID)
+	}
+
+	return execution.Clone(), nil
+}
+
+// Delete removes an execution from memory.
+func (s *InMemoryWorkflowStore) Delete(ctx context.Context, executionID string) error {
+	if executionID == "" {
+		return errors.New("execution ID is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.executions[executionID]; !exists {
+		return fmt.Errorf("%w: %s", ErrWorkflowExecutionNotFound, executionID)
+	}
+
+	delete(s.executions, executionID)
+
+	return nil
+}
+
+// List returns all executions of a workflow, oldest first.
+func (s *InMemoryWorkflowStore) List(ctx context.Context, workflowID string) ([]*WorkflowExecution, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	executions := make([]*WorkflowExecution, 0)
+
+	for _, execution := range s.executions {
+		if execution.WorkflowID == workflowID {
+			executions = append(executions, execution.Clone())
+		}
+	}
+
+	sortExecutions(executions)
+
+	return executions, nil
+}
+
+// FileWorkflowStore persists each execution as a JSON file in a directory.
+// Internal input keys (prefixed with "__", such as injected registries) are
+// not persisted; configure registries on the workflow itself when resuming.
+type FileWorkflowStore struct {
+	dir string
+	mu  sync.RWMutex
+}
+
+// NewFileWorkflowStore creates a file-backed workflow store rooted at dir,
+// creating the directory if needed.
+func NewFileWorkflowStore(dir string) (*FileWorkflowStore, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("%w: directory is required", ErrInvalidConfig)
+	}
+
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create workflow store directory: %w", err)
+	}
+
+	return &FileWorkflowStore{dir: dir}, nil
+}
+
+// Save writes an execution to disk atomically.
+func (s *FileWorkflowStore) Save(ctx context.Context, execution *WorkflowExecution) error {
+	if execution == nil {
+		return errors.New("execution cannot be nil")
+	}
+
+	path, err := s.path(execution.ID)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(execution)
+	if err != nil {
+		return fmt.Errorf("failed to encode execution %s: %w", execution.ID, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Write to a temporary file and rename so readers never see partial data
+	tmp, err := os.CreateTemp(s.dir, ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to save execution %s: %w", execution.ID, err)
+	}
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+
+		return fmt.Errorf("failed to save execution %s: %w", execution.ID, err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+
+		return fmt.Errorf("failed to save execution %s: %w", execution.ID, err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		_ = os.Remove(tmp.Name())
+
+		return fmt.Errorf("failed to save execution %s: %w", execution.ID, err)
+	}
+
+	return nil
+}
+
+// Load reads an execution from disk.
+func (s *FileWorkflowStore) Load(ctx context.Context, executionID string) (*WorkflowExecution, error) {
+	path, err := s.path(executionID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	data, err := os.ReadFile(path)
+	s.mu.RUnlock()
+
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %s", ErrWorkflowExecutionNotFound, executionID)
+		}
+
+		return nil, fmt.Errorf("failed to load execution %s: %w", executionID, err)
+	}
+
+	execution := &WorkflowExecution{}
+	if err := json.Unmarshal(data, execution); err != nil {
+		return nil, fmt.Errorf("failed to decode execution %s: %w", executionID, err)
+	}
+
+	return execution, nil
+}
+
+// Delete removes an execution file.
+func (s *FileWorkflowStore) Delete(ctx context.Context, executionID string) error {
+	path, err := s.path(executionID)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.Remove(path); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("%w: %s", ErrWorkflowExecutionNotFound, executionID)
+		}
+
+		return fmt.Errorf("failed to delete execution %s: %w", executionID, err)
+	}
+
+	return nil
+}
+
+// List returns all executions of a workflow, oldest first.
+func (s *FileWorkflowStore) List(ctx context.Context, workflowID string) ([]*WorkflowExecution, error) {
+	s.mu.RLock()
+	entries, err := os.ReadDir(s.dir)
+	s.mu.RUnlock()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list executions: %w", err)
+	}
+
+	executions := make([]*WorkflowExecution, 0)
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+
+		execution, err := s.Load(ctx, strings.TrimSuffix(name, ".json"))
+		if err != nil {
+			return nil, err
+		}
+
+		if execution.WorkflowID == workflowID {
+			executions = append(executions, execution)
+		}
+	}
+
+	sortExecutions(executions)
+
+	return executions, nil
+}
+
+// path returns the file path for an execution, rejecting IDs that would
+// escape the store directory.
+func (s *FileWorkflowStore) path(executionID string) (string, error) {
+	if executionID == "" {
+		return "", errors.New("execution ID is required")
+	}
+
+	if strings.ContainsAny(executionID, `/\`) || executionID == "." || executionID == ".." {
+		return "", fmt.Errorf("%w: invalid execution ID %q", ErrInvalidConfig, executionID)
+	}
+
+	return filepath.Join(s.dir, executionID+".json"), nil
+}
+
+// sortExecutions orders executions by start time.
+func sortExecutions(executions []*WorkflowExecution) {
+	sort.Slice(executions, func(i, j int) bool {
+		return executions[i].StartTime.Before(executions[j].StartTime)
+	})
+}
+
+// Clone returns a deep copy of the execution, including nested sub-workflow executions.
+func (e *WorkflowExecution) Clone() *WorkflowExecution {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	clone := &WorkflowExecution{
+		ID:             e.ID,
+		WorkflowID:     e.WorkflowID,
+		Status:         e.Status,
+		StartTime:      e.StartTime,
+		EndTime:        e.EndTime,
+		Input:          maps.Clone(e.Input),
+		Output:         maps.Clone(e.Output),
+		NodeExecutions: make(map[string]*NodeExecution, len(e.NodeExecutions)),
+		Error:          e.Error,
+	}
+
+	for id, nodeExec := range e.NodeExecutions {
+		nodeClone := *nodeExec
+		nodeClone.Input = maps.Clone(nodeExec.Input)
+
+		if nodeExec.SubExecution != nil {
+			nodeClone.SubExecution = nodeExec.SubExecution.Clone()
+		}
+
+		clone.NodeExecutions[id] = &nodeClone
+	}
+
+	return clone
+}
+
+// workflowExecutionJSON is the serialized form of a WorkflowExecution.
+type workflowExecutionJSON struct {
+	*workflowExecutionAlias
+
+	// Shadow the alias fields that need custom encoding
+	Input map[string]any `json:"Input,omitempty"`
+	Error string         `json:"Error,omitempty"`
+}
+
+type workflowExecutionAlias WorkflowExecution
+
+// MarshalJSON encodes the execution, converting errors to strings and
+// dropping internal input keys.
+func (e *WorkflowExecution) MarshalJSON() ([]byte, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	input := make(map[string]any, len(e.Input))
+
+	for k, v := range e.Input {
+		if !strings.HasPrefix(k, "__") {
+			input[k] = v
+		}
+	}
+
+	out := workflowExecutionJSON{
+		workflowExecutionAlias: (*workflowExecutionAlias)(e),
+		Input:                  input,
+	}
+
+	if e.Error != nil {
+		out.Error = e.Error.Error()
+	}
+
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON decodes an execution produced by MarshalJSON.
+func (e *WorkflowExecution) UnmarshalJSON(data []byte) error {
+	in := workflowExecutionJSON{workflowExecutionAlias: (*workflowExecutionAlias)(e)}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+
+	e.Input = in.Input
+	if in.Error != "" {
+		e.Error = errors.New(in.Error)
+	}
+
+	if e.NodeExecutions == nil {
+		e.NodeExecutions = make(map[string]*NodeExecution)
+	}
+
+	return nil
+}
+
+// nodeExecutionJSON is the serialized form of a NodeExecution.
+type nodeExecutionJSON struct {
+	*nodeExecutionAlias
+
+	Error string `json:"Error,omitempty"`
+}
+
+type nodeExecutionAlias NodeExecution
+
+// MarshalJSON encodes the node execution, converting its error to a string.
+func (n *NodeExecution) MarshalJSON() ([]byte, error) {
+	out := nodeExecutionJSON{nodeExecutionAlias: (*nodeExecutionAlias)(n)}
+	if n.Error != nil {
+		out.Error = n.Error.Error()
+	}
+
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON decodes a node execution produced by MarshalJSON.
+func (n *NodeExecution) UnmarshalJSON(data []byte) error {
+	in := nodeExecutionJSON{nodeExecutionAlias: (*nodeExecutionAlias)(n)}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+
+	if in.Error != "" {
+		n.Error = errors.New(in.Error)
+	}
+
+	return nil
+}
@@ -0,0 +1,338 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestInMemoryWorkflowStore_SaveLoad(t *testing.T) {
+	store := NewInMemoryWorkflowStore()
+	ctx := context.Background()
+
+	execution := &WorkflowExecution{
+		ID:         "exec1",
+		WorkflowID: "wf",
+		Status:     WorkflowStatusRunning,
+		NodeExecutions: map[string]*NodeExecution{
+			"node1": {NodeID: "node1", Status: NodeStatusCompleted, Output: "done"},
+		},
+	}
+
+	if err := store.Save(ctx, execution); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// Mutating the original must not affect the stored copy
+	execution.NodeExecutions["node1"].Status = NodeStatusFailed
+
+	loaded, err := store.Load(ctx, "exec1")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if loaded.NodeExecutions["node1"].Status != NodeStatusCompleted {
+		t.Errorf("expected stored status completed, got %s", loaded.NodeExecutions["node1"].Status)
+	}
+
+	list, _ := store.List(ctx, "wf")
+	if len(list) != 1 {
+		t.Errorf("expected 1 execution, got %d", len(list))
+	}
+
+	if err := store.Delete(ctx, "exec1"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if _, err := store.Load(ctx, "exec1"); !errors.Is(err, ErrWorkflowExecutionNotFound) {
+		t.Errorf("expected ErrWorkflowExecutionNotFound, got %v", err)
+	}
+}
+
+func TestFileWorkflowStore_RoundTrip(t *testing.T) {
+	store, err := NewFileWorkflowStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	ctx := context.Background()
+	wakeAt := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	execution := &WorkflowExecution{
+		ID:         "exec1",
+		WorkflowID: "wf",
+		Status:     WorkflowStatusFailed,
+		Input:      map[string]any{"name": "test", "__tool_registry": NewToolRegistry(nil, nil)},
+		Error:      errors.New("boom"),
+		NodeExecutions: map[string]*NodeExecution{
+			"wait": {NodeID: "wait", Status: NodeStatusFailed, Error: errors.New("node boom"), WakeAt: wakeAt},
+		},
+	}
+
+	if err := store.Save(ctx, execution); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	loaded, err := store.Load(ctx, "exec1")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if loaded.Error == nil || loaded.Error.Error() != "boom" {
+		t.Errorf("expected error 'boom', got %v", loaded.Error)
+	}
+
+	if _, ok := loaded.Input["__tool_registry"]; ok {
+		t.Error("expected internal input keys to be dropped")
+	}
+
+	if loaded.Input["name"] != "test" {
+		t.Errorf("expected input name 'test', got %v", loaded.Input["name"])
+	}
+
+	nodeExec := loaded.NodeExecutions["wait"]
+	if nodeExec == nil || nodeExec.Error == nil || nodeExec.Error.Error() != "node boom" {
+		t.Fatalf("expected node error 'node boom', got %+v", nodeExec)
+	}
+
+	if !nodeExec.WakeAt.Equal(wakeAt) {
+		t.Errorf("expected wake time %v, got %v", wakeAt, nodeExec.WakeAt)
+	}
+
+	if _, err := store.Load(ctx, "../escape"); err == nil {
+		t.Error("expected error for execution ID with path separators")
+	}
+}
+
+func TestWorkflow_Resume(t *testing.T) {
+	store, err := NewFileWorkflowStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var firstRuns, secondRuns atomic.Int32
+
+	wf := NewWorkflow("resumable", "Resumable", nil, nil)
+	wf.SetStore(store)
+	_ = wf.AddNode(&WorkflowNode{
+		ID:   "first",
+		Type: NodeTypeTransform,
+		TransformHandler: func(ctx context.Context, data map[string]any) (any, error) {
+			firstRuns.Add(1)
+
+			return "ok", nil
+		},
+	})
+	_ = wf.AddNode(&WorkflowNode{
+		ID:   "second",
+		Type: NodeTypeTransform,
+		TransformHandler: func(ctx context.Context, data map[string]any) (any, error) {
+			if secondRuns.Add(1) == 1 {
+				return nil, errors.New("transient failure")
+			}
+
+			return "recovered", nil
+		},
+	})
+	_ = wf.AddEdge("first", "second")
+	_ = wf.SetStartNode("first")
+
+	execution, err := wf.Execute(context.Background(), map[string]any{})
+	if err == nil {
+		t.Fatal("expected first execution to fail")
+	}
+
+	resumed, err := wf.Resume(context.Background(), execution.ID)
+	if err != nil {
+		t.Fatalf("expected no error on resume, got %v", err)
+	}
+
+	if resumed.Status != WorkflowStatusCompleted {
+		t.Errorf("expected status completed, got %s", resumed.Status)
+	}
+
+	if firstRuns.Load() != 1 {
+		t.Errorf("expected completed node to be skipped, ran %d times", firstRuns.Load())
+	}
+
+	if secondRuns.Load() != 2 {
+		t.Errorf("expected failed node to be re-run, ran %d times", secondRuns.Load())
+	}
+
+	if resumed.NodeExecutions["second"].Attempts != 2 {
+		t.Errorf("expected 2 attempts, got %d", resumed.NodeExecutions["second"].Attempts)
+	}
+}
+
+func TestWorkflow_Resume_DurableWait(t *testing.T) {
+	store := NewInMemoryWorkflowStore()
+
+	wf := NewWorkflow("waiter", "Waiter", nil, nil)
+	wf.SetStore(store)
+	_ = wf.AddNode(&WorkflowNode{
+		ID:     "wait",
+		Type:   NodeTypeWait,
+		Config: map[string]any{"duration": time.Hour},
+	})
+	_ = wf.SetStartNode("wait")
+
+	// Simulate a process that stopped while the timer was pending
+	_ = store.Save(context.Background(), &WorkflowExecution{
+		ID:         "exec1",
+		WorkflowID: "waiter",
+		Status:     WorkflowStatusRunning,
+		StartTime:  time.Now().Add(-2 * time.Hour),
+		NodeExecutions: map[string]*NodeExecution{
+			"wait": {NodeID: "wait", Status: NodeStatusRunning, Attempts: 1, WakeAt: time.Now().Add(-time.Hour)},
+		},
+	})
+
+	start := time.Now()
+
+	resumed, err := wf.Resume(context.Background(), "exec1")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if time.Since(start) > time.Second {
+		t.Errorf("expected elapsed timer to fire immediately, took %v", time.Since(start))
+	}
+
+	if resumed.Status != WorkflowStatusCompleted {
+		t.Errorf("expected status completed, got %s", resumed.Status)
+	}
+}
+
+func TestWorkflow_Resume_BlockedBranch(t *testing.T) {
+	store := NewInMemoryWorkflowStore()
+
+	var checks, ships, handled atomic.Int32
+
+	count := func(counter *atomic.Int32) func(ctx context.Context, data map[string]any) (any, error) {
+		return func(ctx context.Context, data map[string]any) (any, error) {
+			counter.Add(1)
+
+			return "ok", nil
+		}
+	}
+
+	wf := NewWorkflow("branch", "Branch", nil, nil)
+	wf.SetStore(store)
+	_ = wf.AddNode(&WorkflowNode{
+		ID:   "check",
+		Type: NodeTypeCondition,
+		ConditionHandler: func(ctx context.Context, data map[string]any) (bool, error) {
+			checks.Add(1)
+
+			return false, errors.New("stock check failed")
+		},
+	})
+	_ = wf.AddNode(&WorkflowNode{ID: "ship", Type: NodeTypeTransform, TransformHandler: count(&ships)})
+	_ = wf.AddNode(&WorkflowNode{ID: "notify", Type: NodeTypeTransform, TransformHandler: count(&ships)})
+	_ = wf.AddNode(&WorkflowNode{ID: "handle", Type: NodeTypeTransform, TransformHandler: count(&handled)})
+	_ = wf.AddEdge("check", "ship")
+	_ = wf.AddEdge("ship", "notify")
+	_ = wf.AddErrorEdge("check", "handle")
+	_ = wf.SetStartNode("check")
+
+	// Simulate a process that stopped after the failed check was routed to
+	// its handler and the regular branch was skipped
+	_ = store.Save(context.Background(), &WorkflowExecution{
+		ID:         "exec1",
+		WorkflowID: "branch",
+		Status:     WorkflowStatusRunning,
+		StartTime:  time.Now(),
+		NodeExecutions: map[string]*NodeExecution{
+			"check":  {NodeID: "check", Status: NodeStatusFailed, Attempts: 1, Error: errors.New("stock check failed")},
+			"ship":   {NodeID: "ship", Status: NodeStatusSkipped},
+			"handle": {NodeID: "handle", Status: NodeStatusRunning, Attempts: 1},
+		},
+	})
+
+	resumed, err := wf.Resume(context.Background(), "exec1")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if resumed.Status != WorkflowStatusCompleted {
+		t.Errorf("expected status completed, got %s", resumed.Status)
+	}
+
+	if checks.Load() != 0 {
+		t.Errorf("expected the routed failure not to be re-run, ran %d times", checks.Load())
+	}
+
+	if ships.Load() != 0 {
+		t.Errorf("expected the blocked branch not to run, ran %d nodes", ships.Load())
+	}
+
+	if handled.Load() != 1 {
+		t.Errorf("expected the in-flight handler to be re-run once, ran %d times", handled.Load())
+	}
+
+	if status := resumed.NodeExecutions["notify"].Status; status != NodeStatusSkipped {
+		t.Errorf("expected notify to be skipped, got %s", status)
+	}
+}
+
+func TestWorkflow_Resume_Compensated(t *testing.T) {
+	var runs atomic.Int32
+
+	wf := NewWorkflow("booking", "Booking", nil, nil)
+	wf.SetStore(NewInMemoryWorkflowStore())
+
+	count := func(ctx context.Context, data map[string]any) (any, error) {
+		runs.Add(1)
+
+		return "ok", nil
+	}
+
+	_ = wf.AddNode(&WorkflowNode{ID: "flight", Type: NodeTypeTransform, Compensation: "cancel_flight", TransformHandler: count})
+	_ = wf.AddNode(&WorkflowNode{ID: "cancel_flight", Type: NodeTypeTransform, TransformHandler: count})
+	_ = wf.AddNode(&WorkflowNode{
+		ID:   "car",
+		Type: NodeTypeTransform,
+		TransformHandler: func(ctx context.Context, data map[string]any) (any, error) {
+			return nil, errors.New("no cars left")
+		},
+	})
+	_ = wf.AddEdge("flight", "car")
+	_ = wf.SetStartNode("flight")
+
+	execution, _ := wf.Execute(context.Background(), map[string]any{})
+	if execution.Status != WorkflowStatusCompensated {
+		t.Fatalf("expected status compensated, got %s", execution.Status)
+	}
+
+	before := runs.Load()
+
+	resumed, err := wf.Resume(context.Background(), execution.ID)
+	if !errors.Is(err, ErrWorkflowNotResumable) {
+		t.Errorf("expected ErrWorkflowNotResumable, got %v", err)
+	}
+
+	if resumed == nil || resumed.Status != WorkflowStatusCompensated {
+		t.Errorf("expected the execution to stay compensated, got %+v", resumed)
+	}
+
+	if runs.Load() != before {
+		t.Errorf("expected no nodes to run, got %d runs", runs.Load()-before)
+	}
+
+	execution.Status = WorkflowStatusCancelled
+	_ = wf.store.Save(context.Background(), execution)
+
+	if _, err := wf.Resume(context.Background(), execution.ID); !errors.Is(err, ErrWorkflowNotResumable) {
+		t.Errorf("expected a cancelled execution not to resume, got %v", err)
+	}
+}
+
+func TestWorkflow_Resume_NoStore(t *testing.T) {
+	wf := NewWorkflow("test", "Test", nil, nil)
+
+	if _, err := wf.Resume(context.Background(), "exec1"); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected ErrInvalidConfig, got %v", err)
+	}
+}
@@ -0,0 +1,138 @@
+package sdk
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/xraph/ai-sdk/llm"
+)
+
+// WorkflowEventType identifies a workflow execution event.
+type WorkflowEventType string
+
+const (
+	WorkflowEventNodeStarted   WorkflowEventType = "node_started"
+	WorkflowEventNodeCompleted WorkflowEventType = "node_completed"
+	WorkflowEventNodeFailed    WorkflowEventType = "node_failed"
+	WorkflowEventNodeSkipped   WorkflowEventType = "node_skipped"
+	WorkflowEventNodeStream    WorkflowEventType = "node_stream"
+	WorkflowEventFinished      WorkflowEventType = "workflow_finished"
+)
+
+// WorkflowEvent describes progress of a streamed workflow execution.
+type WorkflowEvent struct {
+	Type        WorkflowEventType `json:"type"`
+	WorkflowID  string            `json:"workflowId"`
+	ExecutionID string            `json:"executionId"`
+	NodeID      string            `json:"nodeId,omitempty"`
+	NodeType    NodeType          `json:"nodeType,omitempty"`
+	Attempt     int               `json:"attempt,omitempty"`
+	Output      any               `json:"output,omitempty"`
+	Error       string            `json:"error,omitempty"`
+	Duration    time.Duration     `json:"duration,omitempty"`
+	Timestamp   time.Time         `json:"timestamp"`
+
+	// Status is set on workflow_finished events
+	Status WorkflowStatus `json:"status,omitempty"`
+
+	// StreamEvent carries token, tool and UI-part events forwarded from the
+	// node's agent on node_stream events
+	StreamEvent *llm.ClientStreamEvent `json:"streamEvent,omitempty"`
+}
+
+// WorkflowEventHandler receives workflow events. Calls are serialized, so the
+// handler does not need to be safe for concurrent use.
+type WorkflowEventHandler func(WorkflowEvent)
+
+// workflowEventsKey carries the event emitter through the execution context.
+type workflowEventsKey struct{}
+
+// workflowEmitter serializes events from parallel branches.
+type workflowEmitter struct {
+	mu      sync.Mutex
+	handler WorkflowEventHandler
+}
+
+// streamEventSinkKey carries a ClientStreamEvent sink through a context.
+type streamEventSinkKey struct{}
+
+// WithStreamEventSink returns a context that asks agents and tools running
+// under it to report their token, tool and UI-part events to sink.
+func WithStreamEventSink(ctx context.Context, sink func(llm.ClientStreamEvent)) context.Context {
+	return context.WithValue(ctx, streamEventSinkKey{}, sink)
+}
+
+// StreamEventSinkFromContext returns the stream event sink of ctx, or nil.
+func StreamEventSinkFromContext(ctx context.Context) func(llm.ClientStreamEvent) {
+	sink, _ := ctx.Value(streamEventSinkKey{}).(func(llm.ClientStreamEvent))
+
+	return sink
+}
+
+// ExecuteStream runs the workflow like Execute while reporting node progress
+// to handler as it happens. Agent nodes forward their stream events tagged
+// with the node ID, and sub-workflows report their own nodes through the same
+// handler under the child execution ID.
+func (w *Workflow) ExecuteStream(ctx context.Context, input map[string]any, handler WorkflowEventHandler) (*WorkflowExecution, error) {
+	if handler != nil {
+		ctx = context.WithValue(ctx, workflowEventsKey{}, &workflowEmitter{handler: handler})
+	}
+
+	return w.Execute(ctx, input)
+}
+
+// emit delivers an event to the handler of ctx, if any.
+func (w *Workflow) emit(ctx context.Context, execution *WorkflowExecution, event WorkflowEvent) {
+	emitter, ok := ctx.Value(workflowEventsKey{}).(*workflowEmitter)
+	if !ok {
+		return
+	}
+
+	event.WorkflowID = w.ID
+	event.ExecutionID = execution.ID
+	event.Timestamp = time.Now()
+
+	emitter.mu.Lock()
+	defer emitter.mu.Unlock()
+
+	emitter.handler(event)
+}
+
+// emitNodeResult reports the outcome of a finished node.
+func (w *Workflow) emitNodeResult(ctx context.Context, execution *WorkflowExecution, node *WorkflowNode, nodeExec *NodeExecution) {
+	execution.mu.RLock()
+	event := WorkflowEvent{
+		Type:     WorkflowEventNodeCompleted,
+		NodeID:   node.ID,
+		NodeType: node.Type,
+		Attempt:  nodeExec.Attempts,
+		Output:   nodeExec.Output,
+		Duration: nodeExec.EndTime.Sub(nodeExec.StartTime),
+	}
+
+	if nodeExec.Error != nil {
+		event.Type = WorkflowEventNodeFailed
+		event.Error = nodeExec.Error.Error()
+	}
+	execution.mu.RUnlock()
+
+	w.emit(ctx, execution, event)
+}
+
+// nodeStreamContext forwards stream events of nested agents to the workflow
+// handler tagged with the node ID.
+func (w *Workflow) nodeStreamContext(ctx context.Context, execution *WorkflowExecution, node *WorkflowNode) context.Context {
+	if _, ok := ctx.Value(workflowEventsKey{}).(*workflowEmitter); !ok {
+		return ctx
+	}
+
+	return WithStreamEventSink(ctx, func(event llm.ClientStreamEvent) {
+		w.emit(ctx, execution, WorkflowEvent{
+			Type:        WorkflowEventNodeStream,
+			NodeID:      node.ID,
+			NodeType:    node.Type,
+			StreamEvent: &event,
+		})
+	})
+}
@@ -0,0 +1,224 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/xraph/ai-sdk/llm"
+	"github.com/xraph/ai-sdk/testhelpers"
+)
+
+func TestWorkflow_ExecuteStream_NodeEvents(t *testing.T) {
+	wf := NewWorkflow("stream", "Stream", nil, nil)
+	_ = wf.AddNode(&WorkflowNode{ID: "fetch", Type: NodeTypeTransform, Transform: "1 + 1"})
+	_ = wf.AddNode(&WorkflowNode{
+		ID:   "charge",
+		Type: NodeTypeTransform,
+		TransformHandler: func(ctx context.Context, data map[string]any) (any, error) {
+			return nil, errors.New("card declined")
+		},
+	})
+	_ = wf.AddNode(&WorkflowNode{ID: "ship", Type: NodeTypeTransform, Transform: "'shipped'"})
+	_ = wf.AddNode(&WorkflowNode{ID: "handle", Type: NodeTypeTransform, Transform: "'handled'"})
+	_ = wf.AddEdge("fetch", "charge")
+	_ = wf.AddEdge("charge", "ship")
+	_ = wf.AddErrorEdge("charge", "handle")
+	_ = wf.SetStartNode("fetch")
+
+	var events []WorkflowEvent
+
+	execution, err := wf.ExecuteStream(context.Background(), map[string]any{}, func(event WorkflowEvent) {
+		events = append(events, event)
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	seen := make(map[string]WorkflowEventType)
+	for _, event := range events {
+		if event.ExecutionID != execution.ID || event.WorkflowID != "stream" {
+			t.Errorf("expected event tagged with execution, got %+v", event)
+		}
+
+		if event.Type != WorkflowEventNodeStarted {
+			seen[event.NodeID] = event.Type
+		}
+	}
+
+	expected := map[string]WorkflowEventType{
+		"fetch":  WorkflowEventNodeCompleted,
+		"charge": WorkflowEventNodeFailed,
+		"ship":   WorkflowEventNodeSkipped,
+		"handle": WorkflowEventNodeCompleted,
+		"":       WorkflowEventFinished,
+	}
+
+	for nodeID, eventType := range expected {
+		if seen[nodeID] != eventType {
+			t.Errorf("expected %s event for %q, got %q", eventType, nodeID, seen[nodeID])
+		}
+	}
+
+	if events[0].Type != WorkflowEventNodeStarted || events[0].NodeID != "fetch" {
+		t.Errorf("expected first event to start fetch, got %+v", events[0])
+	}
+
+	last := events[len(events)-1]
+	if last.Type != WorkflowEventFinished || last.Status != WorkflowStatusCompleted {
+		t.Errorf("expected workflow_finished last, got %+v", last)
+	}
+}
+
+func TestWorkflow_ExecuteStream_Failure(t *testing.T) {
+	wf := NewWorkflow("stream_fail", "Stream Fail", nil, nil)
+	_ = wf.AddNode(&WorkflowNode{
+		ID:   "boom",
+		Type: NodeTypeTransform,
+		TransformHandler: func(ctx context.Context, data map[string]any) (any, error) {
+			return nil, errors.New("boom")
+		},
+	})
+	_ = wf.SetStartNode("boom")
+
+	var last WorkflowEvent
+
+	_, err := wf.ExecuteStream(context.Background(), map[string]any{}, func(event WorkflowEvent) {
+		last = event
+	})
+	if err == nil {
+		t.Fatal("expected workflow error")
+	}
+
+	if last.Type != WorkflowEventFinished || last.Status != WorkflowStatusFailed || last.Error == "" {
+		t.Errorf("expected failed workflow_finished event, got %+v", last)
+	}
+}
+
+func TestWorkflow_ExecuteStream_AgentEvents(t *testing.T) {
+	calls := 0
+	mockLLM := testhelpers.NewMockLLM()
+	mockLLM.ChatStreamFunc = func(ctx context.Context, req llm.ChatRequest, handler func(llm.ChatStreamEvent) error) error {
+		calls++
+		if calls == 1 {
+			// The arguments arrive in two deltas and only the first carries the ID
+			for _, tc := range []llm.ToolCall{
+				{ID: "call_1", Type: "function", Function: &llm.FunctionCall{Name: "lookup", Arguments: `{"city":`}},
+				{Function: &llm.FunctionCall{Arguments: `"Paris"}`}},
+			} {
+				if err := handler(llm.ChatStreamEvent{
+					Choices: []llm.ChatChoice{{Delta: &llm.ChatMessage{ToolCalls: []llm.ToolCall{tc}}}},
+				}); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		}
+
+		for _, token := range []string{"Sunny", " in Paris"} {
+			if err := handler(llm.ChatStreamEvent{
+				Choices: []llm.ChatChoice{{Delta: &llm.ChatMessage{Content: token}}},
+			}); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	var lookupCity any
+
+	store := &inMemoryStateStore{states: make(map[string]*AgentState)}
+	agent, _ := NewAgent("weather", "Weather", mockLLM, store, nil, nil, &AgentOptions{
+		Tools: []Tool{{
+			Name: "lookup",
+			Handler: func(ctx context.Context, args map[string]any) (any, error) {
+				lookupCity = args["city"]
+
+				return "sunny", nil
+			},
+		}},
+	})
+
+	registry := NewAgentRegistry(nil, nil)
+	_ = registry.Register(agent)
+
+	wf := NewWorkflow("agent_stream", "Agent Stream", nil, nil)
+	wf.SetAgentRegistry(registry)
+	_ = wf.AddNode(&WorkflowNode{ID: "forecast", Type: NodeTypeAgent, AgentID: "weather", Config: map[string]any{"input": "Weather?"}})
+	_ = wf.SetStartNode("forecast")
+
+	var (
+		content   string
+		toolEvent bool
+	)
+
+	execution, err := wf.ExecuteStream(context.Background(), map[string]any{}, func(event WorkflowEvent) {
+		if event.Type != WorkflowEventNodeStream {
+			return
+		}
+
+		if event.NodeID != "forecast" || event.StreamEvent == nil {
+			t.Errorf("expected stream event tagged with node, got %+v", event)
+
+			return
+		}
+
+		switch event.StreamEvent.Type {
+		case llm.EventContentDelta:
+			content += event.StreamEvent.Delta
+		case llm.EventToolResultStart:
+			toolEvent = event.StreamEvent.ToolName == "lookup" && event.StreamEvent.ToolID == "call_1"
+		}
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if content != "Sunny in Paris" {
+		t.Errorf("expected forwarded tokens, got %q", content)
+	}
+
+	if !toolEvent {
+		t.Error("expected forwarded tool result event with the tool call ID")
+	}
+
+	if lookupCity != "Paris" {
+		t.Errorf("expected parsed tool arguments, got %v", lookupCity)
+	}
+
+	output, _ := execution.NodeExecutions["forecast"].Output.(map[string]any)
+	if output["content"] != "Sunny in Paris" {
+		t.Errorf("expected agent output, got %v", output)
+	}
+}
+
+func TestWorkflow_Execute_NoStreamEvents(t *testing.T) {
+	mockLLM := testhelpers.NewMockLLM()
+	mockLLM.ChatFunc = func(ctx context.Context, req llm.ChatRequest) (llm.ChatResponse, error) {
+		return llm.ChatResponse{
+			Choices: []llm.ChatChoice{{Message: llm.ChatMessage{Content: "done"}, FinishReason: "stop"}},
+		}, nil
+	}
+	mockLLM.ChatStreamFunc = func(ctx context.Context, req llm.ChatRequest, handler func(llm.ChatStreamEvent) error) error {
+		t.Error("expected non-streamed execution not to stream")
+
+		return nil
+	}
+
+	store := &inMemoryStateStore{states: make(map[string]*AgentState)}
+	agent, _ := NewAgent("plain", "Plain", mockLLM, store, nil, nil, nil)
+
+	registry := NewAgentRegistry(nil, nil)
+	_ = registry.Register(agent)
+
+	wf := NewWorkflow("plain", "Plain", nil, nil)
+	wf.SetAgentRegistry(registry)
+	_ = wf.AddNode(&WorkflowNode{ID: "answer", Type: NodeTypeAgent, AgentID: "plain", Config: map[string]any{"input": "Hi"}})
+	_ = wf.SetStartNode("answer")
+
+	if _, err := wf.Execute(context.Background(), map[string]any{}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}